@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -17,6 +18,7 @@ func newAuditCommand() *cobra.Command {
 	cmd.AddCommand(newAuditListCommand())
 	cmd.AddCommand(newAuditEnableCommand())
 	cmd.AddCommand(newAuditDisableCommand())
+	cmd.AddCommand(newAuditVerifyCommand())
 
 	return cmd
 }
@@ -63,3 +65,42 @@ func newAuditDisableCommand() *cobra.Command {
 	}
 	return cmd
 }
+
+// newAuditVerifyCommand creates the audit verify command
+func newAuditVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the audit log hash chain",
+		Long: `Walk the server audit log hash chain and its signed checkpoints and
+report the first entry that was modified, removed or reordered.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultClient := newOperatorClient(cmd)
+			result, err := vaultClient.VerifyAudit(context.Background())
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Entries Checked:     %d\n", result.EntriesChecked)
+			fmt.Printf("Sequence Range:      %d-%d\n", result.FirstSequence, result.LastSequence)
+			fmt.Printf("Checkpoints Checked: %d\n", result.CheckpointsChecked)
+			fmt.Printf("Checkpoint Key:      %s\n", result.PublicKey)
+
+			if !result.Valid {
+				if result.BrokenSequence != nil {
+					fmt.Printf("Broken At Sequence:  %d\n", *result.BrokenSequence)
+				}
+				if result.BrokenEntryID != nil {
+					fmt.Printf("Broken Entry:        %s\n", *result.BrokenEntryID)
+				}
+				return fmt.Errorf("audit log verification failed: %s", result.Reason)
+			}
+
+			fmt.Println("Audit log intact")
+			return nil
+		},
+	}
+
+	addOperatorFlags(cmd)
+
+	return cmd
+}
@@ -70,7 +70,12 @@ Examples:
   vault database --type postgresql backup                Create PostgreSQL backup
   vault database restore backup.X                        Restore from backup
   vault database vacuum                                  Optimize SQLite database
-  vault database reset                                   Reset database (WARNING!)`,
+  vault database reset                                   Reset database (WARNING!)
+
+Dynamic Credentials:
+  vault data config app --type postgresql --host db.internal --username vault --password s3cret
+  vault data role readonly --connection app --creation-statements "..."
+  vault data creds readonly`,
 		RunE: runDataCommand,
 	}
 
@@ -87,16 +92,8 @@ Examples:
 	cmd.Flags().Bool("analyze", false, "Analyze database statistics")
 	cmd.Flags().String("sql", "", "Execute custom SQL query")
 
-	// Database type selection
-	cmd.Flags().String("type", "sqlite", "Database type (sqlite, postgresql, mysql, mariadb, mongodb, redis)")
-
-	// Connection parameters for different database types
-	cmd.Flags().String("host", "", "Database host (for remote databases)")
-	cmd.Flags().Int("port", 0, "Database port (for remote databases)")
-	cmd.Flags().String("username", "", "Database username (for remote databases)")
-	cmd.Flags().String("password", "", "Database password (for remote databases)")
-	cmd.Flags().String("database", "", "Database name (for remote databases)")
-	cmd.Flags().String("url", "", "Full database connection URL (overrides other connection params)")
+	// Database type and connection parameters
+	addDatabaseConnectionFlags(cmd)
 
 	// Database creation and management
 	cmd.Flags().Bool("create", false, "Create new database")
@@ -104,6 +101,11 @@ Examples:
 	cmd.Flags().Bool("list", false, "List all databases")
 	cmd.Flags().Bool("init", false, "Initialize database with schema")
 
+	// Dynamic credentials issued by the server
+	cmd.AddCommand(newDataConfigCommand())
+	cmd.AddCommand(newDataRoleCommand())
+	cmd.AddCommand(newDataCredsCommand())
+
 	return cmd
 }
 
@@ -123,15 +125,6 @@ func runDataCommand(cmd *cobra.Command, args []string) error {
 	analyze, _ := cmd.Flags().GetBool("analyze")
 	sql, _ := cmd.Flags().GetString("sql")
 
-	// Database type and connection flags
-	dbType, _ := cmd.Flags().GetString("type")
-	host, _ := cmd.Flags().GetString("host")
-	port, _ := cmd.Flags().GetInt("port")
-	username, _ := cmd.Flags().GetString("username")
-	password, _ := cmd.Flags().GetString("password")
-	database, _ := cmd.Flags().GetString("database")
-	url, _ := cmd.Flags().GetString("url")
-
 	// Database management flags
 	create, _ := cmd.Flags().GetBool("create")
 	drop, _ := cmd.Flags().GetBool("drop")
@@ -139,24 +132,9 @@ func runDataCommand(cmd *cobra.Command, args []string) error {
 	init, _ := cmd.Flags().GetBool("init")
 
 	// Create database configuration
-	dbConfig := &DatabaseConfig{
-		Type:     DatabaseType(dbType),
-		Host:     host,
-		Port:     port,
-		Username: username,
-		Password: password,
-		Database: database,
-		URL:      url,
-	}
-
-	// Validate database type
-	if !isValidDatabaseType(dbConfig.Type) {
-		return fmt.Errorf("unsupported database type: %s. Supported types: sqlite, postgresql, mysql, mariadb, mongodb, redis", dbConfig.Type)
-	}
-
-	// Set default ports for different database types
-	if dbConfig.Port == 0 {
-		dbConfig.Port = getDefaultPort(dbConfig.Type)
+	dbConfig, err := databaseConfigFromFlags(cmd)
+	if err != nil {
+		return err
 	}
 
 	// Load configuration for SQLite-specific operations
@@ -255,6 +233,54 @@ func runDataCommand(cmd *cobra.Command, args []string) error {
 	return showDatabaseInfo(dbConfig, ctx)
 }
 
+// addDatabaseConnectionFlags adds the database type and connection flags
+func addDatabaseConnectionFlags(cmd *cobra.Command) {
+	// Database type selection
+	cmd.Flags().String("type", "sqlite", "Database type (sqlite, postgresql, mysql, mariadb, mongodb, redis)")
+
+	// Connection parameters for different database types
+	cmd.Flags().String("host", "", "Database host (for remote databases)")
+	cmd.Flags().Int("port", 0, "Database port (for remote databases)")
+	cmd.Flags().String("username", "", "Database username (for remote databases)")
+	cmd.Flags().String("password", "", "Database password (for remote databases)")
+	cmd.Flags().String("database", "", "Database name (for remote databases)")
+	cmd.Flags().String("url", "", "Full database connection URL (overrides other connection params)")
+}
+
+// databaseConfigFromFlags builds a validated database configuration from the
+// connection flags
+func databaseConfigFromFlags(cmd *cobra.Command) (*DatabaseConfig, error) {
+	dbType, _ := cmd.Flags().GetString("type")
+	host, _ := cmd.Flags().GetString("host")
+	port, _ := cmd.Flags().GetInt("port")
+	username, _ := cmd.Flags().GetString("username")
+	password, _ := cmd.Flags().GetString("password")
+	database, _ := cmd.Flags().GetString("database")
+	url, _ := cmd.Flags().GetString("url")
+
+	dbConfig := &DatabaseConfig{
+		Type:     DatabaseType(dbType),
+		Host:     host,
+		Port:     port,
+		Username: username,
+		Password: password,
+		Database: database,
+		URL:      url,
+	}
+
+	// Validate database type
+	if !isValidDatabaseType(dbConfig.Type) {
+		return nil, fmt.Errorf("unsupported database type: %s. Supported types: sqlite, postgresql, mysql, mariadb, mongodb, redis", dbConfig.Type)
+	}
+
+	// Set default ports for different database types
+	if dbConfig.Port == 0 {
+		dbConfig.Port = getDefaultPort(dbConfig.Type)
+	}
+
+	return dbConfig, nil
+}
+
 // getDatabasePath returns the path to the local database
 func getDatabasePath(ctx *context.Context) string {
 	// Try to get database path from context or use default
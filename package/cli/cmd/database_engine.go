@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// newDataConfigCommand creates the data config command
+func newDataConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config [name]",
+		Short: "Configure a database connection for dynamic credentials",
+		Long: `Store a privileged connection the server uses to create and drop
+short-lived database users. The connection takes the same type and connection
+flags as the data command; for SQLite, --database is the file path.
+
+Examples:
+  vault data config app --type postgresql --host db.internal --username vault --password s3cret --database app
+  vault data config app --type mysql --url "vault:s3cret@tcp(db.internal:3306)/app"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dbConfig, err := databaseConfigFromFlags(cmd)
+			if err != nil {
+				return err
+			}
+			verify, _ := cmd.Flags().GetBool("verify")
+
+			vaultClient := newOperatorClient(cmd)
+			err = vaultClient.WriteDatabaseConnection(context.Background(), &types.DatabaseConnectionRequest{
+				Name:             args[0],
+				Type:             string(dbConfig.Type),
+				Host:             dbConfig.Host,
+				Port:             dbConfig.Port,
+				Username:         dbConfig.Username,
+				Password:         dbConfig.Password,
+				Database:         dbConfig.Database,
+				URL:              dbConfig.URL,
+				VerifyConnection: &verify,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Database connection %s saved\n", args[0])
+			return nil
+		},
+	}
+
+	addDatabaseConnectionFlags(cmd)
+	cmd.Flags().Bool("verify", true, "Connect to the database before saving the connection")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+// newDataRoleCommand creates the data role command
+func newDataRoleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "role [name]",
+		Short: "Define a role issuing database users",
+		Long: `Define how users are created and dropped on a connection. Statements are
+SQL templates separated by semicolons; {{name}}, {{password}} and
+{{expiration}} are replaced with the generated username, password and lease
+expiry. PostgreSQL and MySQL roles drop the user by default when no
+revocation statements are given.
+
+Examples:
+  vault data role readonly --connection app --default-ttl 3600 --max-ttl 86400 \
+    --creation-statements "CREATE ROLE \"{{name}}\" WITH LOGIN PASSWORD '{{password}}' VALID UNTIL '{{expiration}}'; GRANT SELECT ON ALL TABLES IN SCHEMA public TO \"{{name}}\";" \
+    --renew-statements "ALTER ROLE \"{{name}}\" VALID UNTIL '{{expiration}}';"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			connection, _ := cmd.Flags().GetString("connection")
+			creation, _ := cmd.Flags().GetString("creation-statements")
+			revocation, _ := cmd.Flags().GetString("revocation-statements")
+			renew, _ := cmd.Flags().GetString("renew-statements")
+			defaultTTL, _ := cmd.Flags().GetInt("default-ttl")
+			maxTTL, _ := cmd.Flags().GetInt("max-ttl")
+
+			if connection == "" || creation == "" {
+				return fmt.Errorf("--connection and --creation-statements are required")
+			}
+
+			vaultClient := newOperatorClient(cmd)
+			err := vaultClient.WriteDatabaseRole(context.Background(), &types.DatabaseRoleRequest{
+				Name:                 args[0],
+				Connection:           connection,
+				CreationStatements:   creation,
+				RevocationStatements: revocation,
+				RenewStatements:      renew,
+				DefaultTTL:           defaultTTL,
+				MaxTTL:               maxTTL,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Database role %s saved\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().String("connection", "", "Connection the users are created on")
+	cmd.Flags().String("creation-statements", "", "SQL creating a user")
+	cmd.Flags().String("revocation-statements", "", "SQL dropping a user")
+	cmd.Flags().String("renew-statements", "", "SQL extending a user when its lease is renewed")
+	cmd.Flags().Int("default-ttl", 0, "Lease time-to-live in seconds (default: 3600)")
+	cmd.Flags().Int("max-ttl", 0, "Longest lease time-to-live in seconds, renewals included")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+// newDataCredsCommand creates the data creds command
+func newDataCredsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "creds [role]",
+		Short: "Generate short-lived database credentials",
+		Long: `Create a database user from a role. The user is dropped when its lease
+expires or is revoked; renew it with 'vault lease renew'.
+
+Examples:
+  vault data creds readonly
+  vault data creds -format=json readonly`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+
+			vaultClient := newOperatorClient(cmd)
+			creds, err := vaultClient.GenerateDatabaseCredentials(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				data, _ := json.MarshalIndent(creds, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+			fmt.Printf("Lease ID:       %s\n", creds.LeaseID)
+			fmt.Printf("Lease Duration: %s\n", time.Duration(creds.LeaseDuration)*time.Second)
+			fmt.Printf("Renewable:      %t\n", creds.Renewable)
+			fmt.Printf("Username:       %s\n", creds.Data.Username)
+			fmt.Printf("Password:       %s\n", creds.Data.Password)
+			return nil
+		},
+	}
+
+	addOperatorFlags(cmd)
+
+	return cmd
+}
@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -11,7 +14,10 @@ func newLeaseCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "lease",
 		Short: "Interact with leases",
-		Long:  `Manage dynamic secret leases.`,
+		Long: `Manage leases on dynamic secrets and wrapping tokens.
+
+Every dynamic credential is issued with a lease that expires on its own
+unless renewed. Revoking a lease releases the credential behind it.`,
 	}
 
 	cmd.AddCommand(newLeaseListCommand())
@@ -26,13 +32,44 @@ func newLeaseListCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List active leases",
+		Long: `List the active leases of the current token, soonest expiry first.
+
+Examples:
+  vault lease list
+  vault lease list -prefix=database/creds/readonly
+  vault lease list -all`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, _ := cmd.Flags().GetString("prefix")
+			all, _ := cmd.Flags().GetBool("all")
+			format, _ := cmd.Flags().GetString("format")
+
+			vaultClient := newOperatorClient(cmd)
+			leases, err := vaultClient.ListLeases(context.Background(), prefix, all)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				data, _ := json.MarshalIndent(leases, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+			if len(leases) == 0 {
+				fmt.Println("No active leases")
+				return nil
+			}
 			fmt.Println("Active leases:")
-			fmt.Println("  database/creds/my-role    expires in 1h")
-			fmt.Println("  aws/creds/my-role        expires in 30m")
+			for _, lease := range leases {
+				fmt.Printf("  %s    expires in %s\n", lease.LeaseID, time.Duration(lease.LeaseDuration)*time.Second)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().String("prefix", "", "Only list leases whose ID starts with this prefix")
+	cmd.Flags().Bool("all", false, "List the leases of every user (requires an admin token)")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -41,12 +78,38 @@ func newLeaseRenewCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "renew [lease-id]",
 		Short: "Renew a lease",
-		Args:  cobra.ExactArgs(1),
+		Long: `Extend a lease by an increment, or by its original TTL when none is given.
+A lease is never extended past its max TTL.
+
+Examples:
+  vault lease renew database/creds/readonly/2f6a...
+  vault lease renew -increment=3600 database/creds/readonly/2f6a...`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Renewed lease %s\n", args[0])
+			increment, _ := cmd.Flags().GetInt("increment")
+			format, _ := cmd.Flags().GetString("format")
+
+			vaultClient := newOperatorClient(cmd)
+			lease, err := vaultClient.RenewLease(context.Background(), args[0], increment)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				data, _ := json.MarshalIndent(lease, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+			fmt.Printf("Renewed lease %s\n", lease.LeaseID)
+			fmt.Printf("Lease Duration: %s\n", time.Duration(lease.LeaseDuration)*time.Second)
+			fmt.Printf("Expires At:     %s\n", lease.ExpireTime.Format(time.RFC3339))
 			return nil
 		},
 	}
+
+	cmd.Flags().Int("increment", 0, "Seconds to extend the lease by")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -55,12 +118,38 @@ func newLeaseRevokeCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "revoke [lease-id]",
 		Short: "Revoke a lease",
-		Args:  cobra.ExactArgs(1),
+		Long: `Revoke a lease and release the credential behind it. With -prefix, the
+argument is a prefix and every lease under it is revoked (requires an admin
+token).
+
+Examples:
+  vault lease revoke database/creds/readonly/2f6a...
+  vault lease revoke -prefix database/creds/readonly`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			prefix, _ := cmd.Flags().GetBool("prefix")
+
+			vaultClient := newOperatorClient(cmd)
+			if prefix {
+				revoked, err := vaultClient.RevokeLeasePrefix(context.Background(), args[0])
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Revoked %d leases under %s\n", revoked, args[0])
+				return nil
+			}
+
+			if err := vaultClient.RevokeLease(context.Background(), args[0]); err != nil {
+				return err
+			}
 			fmt.Printf("Revoked lease %s\n", args[0])
 			return nil
 		},
 	}
+
+	cmd.Flags().Bool("prefix", false, "Treat the argument as a prefix and revoke every lease under it")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
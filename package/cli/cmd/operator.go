@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/client"
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // newOperatorCommand creates the operator command
@@ -27,15 +33,36 @@ func newOperatorInitCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize a new Vault",
+		Long: `Initialize the Vault server. A root key is generated and split into
+key shares, a threshold of which is needed to unseal the server.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Println("Initializing Vault...")
-			fmt.Println("Unseal key 1: abc123")
-			fmt.Println("Unseal key 2: def456")
-			fmt.Println("Unseal key 3: ghi789")
-			fmt.Println("Root token: s.root123456")
+			shares, _ := cmd.Flags().GetInt("key-shares")
+			threshold, _ := cmd.Flags().GetInt("key-threshold")
+
+			vaultClient := newOperatorClient(cmd)
+			result, err := vaultClient.InitVault(context.Background(), &types.InitRequest{
+				SecretShares:    shares,
+				SecretThreshold: threshold,
+			})
+			if err != nil {
+				return err
+			}
+
+			for i, key := range result.Keys {
+				fmt.Printf("Unseal Key %d: %s\n", i+1, key)
+			}
+			fmt.Println()
+			fmt.Printf("Vault initialized with %d key shares and a key threshold of %d.\n", shares, threshold)
+			fmt.Println("Store these keys securely, they cannot be recovered. The Vault is sealed;")
+			fmt.Printf("provide %d of these keys with 'vault operator unseal' to unseal it.\n", threshold)
 			return nil
 		},
 	}
+
+	cmd.Flags().Int("key-shares", 5, "Number of key shares to split the root key into")
+	cmd.Flags().Int("key-threshold", 3, "Number of key shares required to unseal")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -45,10 +72,18 @@ func newOperatorSealCommand() *cobra.Command {
 		Use:   "seal",
 		Short: "Seal the Vault",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			vaultClient := newOperatorClient(cmd)
+			if _, err := vaultClient.Seal(context.Background()); err != nil {
+				return err
+			}
+
 			fmt.Println("Vault sealed")
 			return nil
 		},
 	}
+
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -57,20 +92,80 @@ func newOperatorUnsealCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "unseal [key]",
 		Short: "Unseal the Vault",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Provide one unseal key share. Run the command once per key share until
+the key threshold is reached. The key is prompted for when not given.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) > 0 {
-				fmt.Printf("Unsealing with key: %s\n", args[0])
-			} else {
-				fmt.Println("Unsealing Vault...")
+			reset, _ := cmd.Flags().GetBool("reset")
+			vaultClient := newOperatorClient(cmd)
+
+			var key string
+			if !reset {
+				if len(args) > 0 {
+					key = args[0]
+				} else {
+					fmt.Print("Unseal Key (will be hidden): ")
+					input, err := term.ReadPassword(int(os.Stdin.Fd()))
+					fmt.Println()
+					if err != nil {
+						return fmt.Errorf("failed to read unseal key: %w", err)
+					}
+					key = strings.TrimSpace(string(input))
+				}
 			}
-			fmt.Println("Vault unsealed")
+
+			status, err := vaultClient.Unseal(context.Background(), key, reset)
+			if err != nil {
+				return err
+			}
+
+			printSealStatus(status)
 			return nil
 		},
 	}
+
+	cmd.Flags().Bool("reset", false, "Discard previously provided unseal keys")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
+// addOperatorFlags adds the server connection flags shared by operator commands
+func addOperatorFlags(cmd *cobra.Command) {
+	address := os.Getenv("VAULT_ADDR")
+	if address == "" {
+		address = "http://127.0.0.1:8080"
+	}
+
+	cmd.Flags().String("address", address, "Vault server address (env: VAULT_ADDR)")
+	cmd.Flags().String("token", os.Getenv("VAULT_TOKEN"), "Vault token (env: VAULT_TOKEN)")
+}
+
+// newOperatorClient creates a server client from the operator flags
+func newOperatorClient(cmd *cobra.Command) *client.HTTPClient {
+	address, _ := cmd.Flags().GetString("address")
+	token, _ := cmd.Flags().GetString("token")
+
+	vaultClient := client.NewHTTPClient(strings.TrimRight(address, "/"))
+	if token != "" {
+		vaultClient.SetToken(token)
+	}
+	return vaultClient
+}
+
+// printSealStatus prints the seal state of the server
+func printSealStatus(status *types.SealStatus) {
+	fmt.Printf("Initialized:  %t\n", status.Initialized)
+	fmt.Printf("Sealed:       %t\n", status.Sealed)
+	fmt.Printf("Total Shares: %d\n", status.Shares)
+	fmt.Printf("Threshold:    %d\n", status.Threshold)
+	if status.Sealed {
+		fmt.Printf("Unseal Progress: %d/%d\n", status.Progress, status.Threshold)
+	} else {
+		fmt.Println("Vault unsealed")
+	}
+}
+
 // newOperatorStepDownCommand creates the operator step-down command
 func newOperatorStepDownCommand() *cobra.Command {
 	cmd := &cobra.Command{
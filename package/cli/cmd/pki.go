@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"time"
 
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
 	"github.com/spf13/cobra"
 )
 
@@ -11,9 +17,14 @@ func newPkiCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "pki",
 		Short: "Interact with Vault's PKI Secrets Engine",
-		Long:  `Manage PKI certificates and keys.`,
+		Long: `Manage the server's certificate authority and the certificates it issues.
+
+The CA certificate, CRL and OCSP responder are published without a token at
+/api/v1/pki/ca, /api/v1/pki/crl and /api/v1/pki/ocsp.`,
 	}
 
+	cmd.AddCommand(newPkiRootCommand())
+	cmd.AddCommand(newPkiRoleCommand())
 	cmd.AddCommand(newPkiIssueCommand())
 	cmd.AddCommand(newPkiSignCommand())
 	cmd.AddCommand(newPkiRevokeCommand())
@@ -22,31 +33,223 @@ func newPkiCommand() *cobra.Command {
 	return cmd
 }
 
+// newPkiRootCommand creates the pki root command
+func newPkiRootCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "root",
+		Short: "Manage the root CA",
+	}
+
+	cmd.AddCommand(newPkiRootGenerateCommand())
+
+	return cmd
+}
+
+// newPkiRootGenerateCommand creates the pki root generate command
+func newPkiRootGenerateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate [common-name]",
+		Short: "Generate a self-signed root CA",
+		Long: `Generate the root CA that signs every certificate of the engine. The
+private key never leaves the server.
+
+Examples:
+  vault pki root generate "Example Root CA"
+  vault pki root generate "Example Root CA" --key-type ec --key-bits 384 --ttl 315360000`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			organization, _ := cmd.Flags().GetString("organization")
+			keyType, _ := cmd.Flags().GetString("key-type")
+			keyBits, _ := cmd.Flags().GetInt("key-bits")
+			ttl, _ := cmd.Flags().GetInt("ttl")
+
+			vaultClient := newOperatorClient(cmd)
+			ca, err := vaultClient.GeneratePKIRoot(context.Background(), &types.PKIGenerateRootRequest{
+				CommonName:   args[0],
+				Organization: organization,
+				KeyType:      keyType,
+				KeyBits:      keyBits,
+				TTL:          ttl,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Serial Number: %s\n", ca.SerialNumber)
+			fmt.Printf("Expiration:    %s\n", time.Unix(ca.Expiration, 0).Format(time.RFC3339))
+			fmt.Print(ca.Certificate)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("organization", "", "Organization of the CA subject")
+	cmd.Flags().String("key-type", "rsa", "Key type (rsa, ec, ed25519)")
+	cmd.Flags().Int("key-bits", 0, "Key size (default: 2048 for rsa, 256 for ec)")
+	cmd.Flags().Int("ttl", 0, "Certificate lifetime in seconds (default: 10 years)")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+// newPkiRoleCommand creates the pki role command
+func newPkiRoleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "role [name]",
+		Short: "Define a role issuing certificates",
+		Long: `Define which names, key types and lifetimes certificates issued through a
+role may have. Names below an allowed domain are allowed by default; the
+domain itself only with --allow-bare-domains.
+
+Examples:
+  vault pki role web --allowed-domains example.com --ttl 86400 --max-ttl 2592000
+  vault pki role internal --allow-any-name --allow-ip-sans --key-type any`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			allowedDomains, _ := cmd.Flags().GetStringSlice("allowed-domains")
+			allowBareDomains, _ := cmd.Flags().GetBool("allow-bare-domains")
+			allowSubdomains, _ := cmd.Flags().GetBool("allow-subdomains")
+			allowAnyName, _ := cmd.Flags().GetBool("allow-any-name")
+			allowIPSANs, _ := cmd.Flags().GetBool("allow-ip-sans")
+			serverFlag, _ := cmd.Flags().GetBool("server-flag")
+			clientFlag, _ := cmd.Flags().GetBool("client-flag")
+			keyType, _ := cmd.Flags().GetString("key-type")
+			keyBits, _ := cmd.Flags().GetInt("key-bits")
+			ttl, _ := cmd.Flags().GetInt("ttl")
+			maxTTL, _ := cmd.Flags().GetInt("max-ttl")
+
+			vaultClient := newOperatorClient(cmd)
+			err := vaultClient.WritePKIRole(context.Background(), &types.PKIRoleRequest{
+				Name:             args[0],
+				AllowedDomains:   allowedDomains,
+				AllowBareDomains: allowBareDomains,
+				AllowSubdomains:  &allowSubdomains,
+				AllowAnyName:     allowAnyName,
+				AllowIPSANs:      allowIPSANs,
+				ServerFlag:       &serverFlag,
+				ClientFlag:       &clientFlag,
+				KeyType:          keyType,
+				KeyBits:          keyBits,
+				TTL:              ttl,
+				MaxTTL:           maxTTL,
+			})
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("PKI role %s saved\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSlice("allowed-domains", []string{}, "Domains certificates may be issued for")
+	cmd.Flags().Bool("allow-bare-domains", false, "Allow the allowed domains themselves")
+	cmd.Flags().Bool("allow-subdomains", true, "Allow names below the allowed domains")
+	cmd.Flags().Bool("allow-any-name", false, "Allow any name")
+	cmd.Flags().Bool("allow-ip-sans", false, "Allow IP subject alternative names")
+	cmd.Flags().Bool("server-flag", true, "Allow server authentication")
+	cmd.Flags().Bool("client-flag", true, "Allow client authentication")
+	cmd.Flags().String("key-type", "rsa", "Key type (rsa, ec, ed25519, or any for signing CSRs)")
+	cmd.Flags().Int("key-bits", 0, "Key size (default: 2048 for rsa, 256 for ec)")
+	cmd.Flags().Int("ttl", 0, "Certificate lifetime in seconds (default: 86400)")
+	cmd.Flags().Int("max-ttl", 0, "Longest certificate lifetime in seconds (default: 2592000)")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
 // newPkiIssueCommand creates the pki issue command
 func newPkiIssueCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "issue [role] [common-name]",
 		Short: "Issue a certificate",
-		Args:  cobra.ExactArgs(2),
+		Long: `Issue a certificate and a new private key from a role. The key is returned
+once and not stored by the server.
+
+Examples:
+  vault pki issue web api.example.com --alt-names www.example.com --ttl 3600
+  vault pki issue -format=json web api.example.com`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Issued certificate for %s from role %s\n", args[1], args[0])
+			altNames, _ := cmd.Flags().GetStringSlice("alt-names")
+			ipSANs, _ := cmd.Flags().GetStringSlice("ip-sans")
+			ttl, _ := cmd.Flags().GetInt("ttl")
+
+			vaultClient := newOperatorClient(cmd)
+			cert, err := vaultClient.IssuePKICertificate(context.Background(), args[0], &types.PKIIssueRequest{
+				CommonName: args[1],
+				AltNames:   altNames,
+				IPSANs:     ipSANs,
+				TTL:        ttl,
+			})
+			if err != nil {
+				return err
+			}
+
+			printPkiCertificate(cmd, cert)
 			return nil
 		},
 	}
+
+	cmd.Flags().StringSlice("alt-names", []string{}, "Additional DNS names")
+	cmd.Flags().StringSlice("ip-sans", []string{}, "IP subject alternative names")
+	cmd.Flags().Int("ttl", 0, "Certificate lifetime in seconds (default: the role's)")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
 // newPkiSignCommand creates the pki sign command
 func newPkiSignCommand() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "sign [role] [csr]",
+		Use:   "sign [role] [csr-file]",
 		Short: "Sign a certificate signing request",
-		Args:  cobra.ExactArgs(2),
+		Long: `Sign a PEM-encoded CSR from a role. Use - to read the CSR from standard
+input. Names are taken from the CSR unless given as flags.
+
+Examples:
+  vault pki sign web server.csr
+  openssl req -new -key server.key -subj /CN=api.example.com | vault pki sign web -`,
+		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Signed CSR for role %s\n", args[0])
+			commonName, _ := cmd.Flags().GetString("common-name")
+			altNames, _ := cmd.Flags().GetStringSlice("alt-names")
+			ipSANs, _ := cmd.Flags().GetStringSlice("ip-sans")
+			ttl, _ := cmd.Flags().GetInt("ttl")
+
+			var csr []byte
+			var err error
+			if args[1] == "-" {
+				csr, err = io.ReadAll(os.Stdin)
+			} else {
+				csr, err = os.ReadFile(args[1])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read CSR: %w", err)
+			}
+
+			vaultClient := newOperatorClient(cmd)
+			cert, err := vaultClient.SignPKICertificate(context.Background(), args[0], &types.PKISignRequest{
+				CSR:        string(csr),
+				CommonName: commonName,
+				AltNames:   altNames,
+				IPSANs:     ipSANs,
+				TTL:        ttl,
+			})
+			if err != nil {
+				return err
+			}
+
+			printPkiCertificate(cmd, cert)
 			return nil
 		},
 	}
+
+	cmd.Flags().String("common-name", "", "Common name (default: the CSR's)")
+	cmd.Flags().StringSlice("alt-names", []string{}, "DNS names (default: the CSR's)")
+	cmd.Flags().StringSlice("ip-sans", []string{}, "IP subject alternative names (default: the CSR's)")
+	cmd.Flags().Int("ttl", 0, "Certificate lifetime in seconds (default: the role's)")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -55,12 +258,26 @@ func newPkiRevokeCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "revoke [serial]",
 		Short: "Revoke a certificate",
-		Args:  cobra.ExactArgs(1),
+		Long: `Revoke a certificate by serial number, with or without colons. The
+certificate is listed on the next CRL and reported revoked over OCSP.
+
+Examples:
+  vault pki revoke 4f:cd:68:13:ec:ae:25:9c:97:d4:01:44:e8:5d:92:1b:13:fc:55:7e`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Revoked certificate %s\n", args[0])
+			vaultClient := newOperatorClient(cmd)
+			revokedAt, err := vaultClient.RevokePKICertificate(context.Background(), args[0])
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("Revoked certificate %s at %s\n", args[0], revokedAt.Format(time.RFC3339))
 			return nil
 		},
 	}
+
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
@@ -69,19 +286,72 @@ func newPkiListCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list [role]",
 		Short: "List issued certificates",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `List the certificates the engine issued or signed, newest first.
+
+Examples:
+  vault pki list
+  vault pki list web --revoked`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			role := "default"
+			revoked, _ := cmd.Flags().GetBool("revoked")
+			format, _ := cmd.Flags().GetString("format")
+
+			role := ""
 			if len(args) > 0 {
 				role = args[0]
 			}
-			fmt.Printf("Listed certificates for role %s\n", role)
+
+			vaultClient := newOperatorClient(cmd)
+			certs, err := vaultClient.ListPKICertificates(context.Background(), role, revoked)
+			if err != nil {
+				return err
+			}
+
+			if format == "json" {
+				data, _ := json.MarshalIndent(certs, "", "  ")
+				fmt.Println(string(data))
+				return nil
+			}
+			if len(certs) == 0 {
+				fmt.Println("No certificates")
+				return nil
+			}
+			fmt.Println("Certificates:")
+			for _, cert := range certs {
+				status := "expires " + cert.NotAfter.Format(time.RFC3339)
+				if cert.RevokedAt != nil {
+					status = "revoked " + cert.RevokedAt.Format(time.RFC3339)
+				}
+				fmt.Printf("  %s  %-30s %s\n", cert.SerialNumber, cert.CommonName, status)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().Bool("revoked", false, "Only list revoked certificates")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
+// printPkiCertificate prints an issued or signed certificate
+func printPkiCertificate(cmd *cobra.Command, cert *types.PKICertificate) {
+	format, _ := cmd.Flags().GetString("format")
+	if format == "json" {
+		data, _ := json.MarshalIndent(cert, "", "  ")
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Printf("Serial Number: %s\n", cert.SerialNumber)
+	fmt.Printf("Expiration:    %s\n", time.Unix(cert.Expiration, 0).Format(time.RFC3339))
+	fmt.Print(cert.Certificate)
+	fmt.Print(cert.IssuingCA)
+	if cert.PrivateKey != "" {
+		fmt.Print(cert.PrivateKey)
+	}
+}
+
 // newPluginCommand creates the plugin command
 func newPluginCommand() *cobra.Command {
 	cmd := &cobra.Command{
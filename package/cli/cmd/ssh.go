@@ -1,14 +1,23 @@
 package cmd
 
 import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
 	"fmt"
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/client"
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh"
 )
 
 // Helper functions for role-based defaults
@@ -108,6 +117,7 @@ Just specify the target and everything else (certificates, authentication, keys)
 				AutoMode: auto || (role == "" && username == "" && port == 0),
 				Verbose:  verbose,
 				DryRun:   dryRun,
+				TTL:      ttl,
 			}
 
 			// Intelligent target analysis
@@ -164,6 +174,8 @@ Just specify the target and everything else (certificates, authentication, keys)
 	cmd.Flags().BoolVar(&auto, "auto", true, "Enable full auto-detection (default)")
 
 	cmd.AddCommand(newSshConnectCommand())
+	cmd.AddCommand(newSshCACommand())
+	cmd.AddCommand(newSshRoleCommand())
 	cmd.AddCommand(newSshSignCommand())
 	cmd.AddCommand(newSshListCommand())
 	cmd.AddCommand(newSshRolesCommand())
@@ -1037,47 +1049,108 @@ func newSshSignCommand() *cobra.Command {
 		username        string
 		principals      []string
 		validPrincipals string
+		extensions      []string
+		criticalOptions []string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "sign [public-key]",
 		Short: "Sign an SSH public key",
-		Long:  `Sign an SSH public key with Vault SSH secrets engine to create a certificate.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Sign an SSH public key with Vault SSH secrets engine to create a certificate.
+The certificate is written next to the key as <key>-cert.pub, where ssh picks
+it up together with the private key.
+
+Examples:
+  vault ssh sign ~/.ssh/id_ed25519.pub --role dev --username alice
+  vault ssh sign ~/.ssh/id_ed25519.pub --role ops --principals root,admin --ttl 30m \
+    --extension permit-pty --critical-option source-address=10.0.0.0/8`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			fmt.Printf("Signed SSH public key: %s\n", args[0])
-			if role != "" {
-				fmt.Printf("Role: %s\n", role)
+			if role == "" {
+				return fmt.Errorf("--role is required")
 			}
-			if ttl != "" {
-				fmt.Printf("TTL: %s\n", ttl)
+
+			publicKey, err := os.ReadFile(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to read public key: %w", err)
 			}
+
 			if username != "" {
-				fmt.Printf("Username: %s\n", username)
+				principals = append(principals, username)
+			}
+			for _, principal := range strings.Split(validPrincipals, ",") {
+				if principal = strings.TrimSpace(principal); principal != "" {
+					principals = append(principals, principal)
+				}
 			}
-			if len(principals) > 0 {
-				fmt.Printf("Principals: %v\n", principals)
+
+			req := &types.SSHSignRequest{
+				PublicKey:       string(publicKey),
+				ValidPrincipals: principals,
+			}
+			if req.Extensions, err = parseSSHOptions(extensions); err != nil {
+				return err
+			}
+			if req.CriticalOptions, err = parseSSHOptions(criticalOptions); err != nil {
+				return err
+			}
+			if ttl != "" {
+				duration, err := time.ParseDuration(ttl)
+				if err != nil {
+					return fmt.Errorf("invalid TTL: %w", err)
+				}
+				req.TTL = int(duration.Seconds())
 			}
-			if validPrincipals != "" {
-				fmt.Printf("Valid principals: %s\n", validPrincipals)
+
+			vaultClient := newOperatorClient(cmd)
+			signed, err := vaultClient.SignSSHKey(context.Background(), role, req)
+			if err != nil {
+				return err
 			}
-			fmt.Println("Certificate ID: cert-ssh-001")
-			fmt.Println("Signature algorithm: rsa-sha2-512")
-			fmt.Println("Valid from: 2024-01-14 00:00:00")
-			fmt.Println("Valid until: 2024-01-21 00:00:00")
+
+			certFile := strings.TrimSuffix(args[0], ".pub") + "-cert.pub"
+			if err := os.WriteFile(certFile, []byte(signed.SignedKey), 0644); err != nil {
+				return fmt.Errorf("failed to write certificate: %w", err)
+			}
+
+			fmt.Printf("Signed SSH public key: %s\n", args[0])
+			fmt.Printf("Certificate ID: %s\n", signed.KeyID)
+			fmt.Printf("Serial number: %d\n", signed.SerialNumber)
+			fmt.Printf("Valid until: %s\n", signed.ValidBefore.Local().Format("2006-01-02 15:04:05"))
+			fmt.Printf("Certificate written to %s\n", certFile)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&role, "role", "", "SSH role for signing")
-	cmd.Flags().StringVar(&ttl, "ttl", "24h", "Certificate time-to-live")
+	cmd.Flags().StringVar(&ttl, "ttl", "", "Certificate time-to-live (default: the role's)")
 	cmd.Flags().StringVar(&username, "username", "", "Username for the certificate")
 	cmd.Flags().StringSliceVar(&principals, "principals", []string{}, "Additional principals for the certificate")
 	cmd.Flags().StringVar(&validPrincipals, "valid-principals", "", "Valid principals for the certificate")
+	cmd.Flags().StringSliceVar(&extensions, "extension", []string{}, "Extension as name or name=value (default: the role's)")
+	cmd.Flags().StringSliceVar(&criticalOptions, "critical-option", []string{}, "Critical option as name=value (default: the role's)")
+	addOperatorFlags(cmd)
 
 	return cmd
 }
 
+// parseSSHOptions turns name or name=value flags into certificate options
+func parseSSHOptions(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	options := make(map[string]string, len(values))
+	for _, value := range values {
+		name, data, _ := strings.Cut(value, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid option %q", value)
+		}
+		options[name] = data
+	}
+	return options, nil
+}
+
 // newSshConnectCommand creates the ssh connect command
 func newSshConnectCommand() *cobra.Command {
 	var (
@@ -1232,27 +1305,43 @@ func newSshVerifyCommand() *cobra.Command {
 // newSshRevokeCommand creates the ssh revoke command
 func newSshRevokeCommand() *cobra.Command {
 	var (
-		force  bool
-		reason string
-		certID string
-		serial string
+		force   bool
+		reason  string
+		certID  string
+		serial  uint64
+		krlFile string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "revoke [certificate-id]",
+		Use:   "revoke [serial|certificate-id]",
 		Short: "Revoke SSH certificate",
-		Long:  `Revoke an SSH certificate and add it to the revocation list.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Revoke an SSH certificate and add it to the revocation list. A certificate ID
+revokes every certificate signed for the same key through the same role.
+
+SSH servers reject revoked certificates once the KRL is installed as their
+RevokedKeys file; --krl writes it after revoking, and it can be fetched from
+/api/v1/ssh/krl at any time.
+
+Examples:
+  vault ssh revoke 4827364918273645 --force
+  vault ssh revoke --cert-id vault-dev-SHA256:... --force --krl /etc/ssh/revoked_keys`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cert := args[0]
-			if cert == "" && certID == "" && serial == "" {
+			if len(args) > 0 {
+				if n, err := strconv.ParseUint(args[0], 10, 64); err == nil {
+					serial = n
+				} else {
+					certID = args[0]
+				}
+			}
+			if certID == "" && serial == 0 {
 				return fmt.Errorf("certificate ID or serial number required")
 			}
 
-			if cert == "" && certID != "" {
-				cert = certID
+			cert := certID
+			if cert == "" {
+				cert = strconv.FormatUint(serial, 10)
 			}
-
 			fmt.Printf("Revoking certificate: %s\n", cert)
 
 			if !force {
@@ -1261,16 +1350,38 @@ func newSshRevokeCommand() *cobra.Command {
 				return nil
 			}
 
-			fmt.Println("✓ Certificate revoked successfully")
-			fmt.Println("✓ Added to revocation list")
-			fmt.Println("✓ CRL updated")
+			vaultClient := newOperatorClient(cmd)
+			revoked, err := vaultClient.RevokeSSHCertificate(context.Background(), &types.SSHRevokeRequest{
+				SerialNumber: serial,
+				KeyID:        certID,
+				Reason:       reason,
+			})
+			if err != nil {
+				return err
+			}
+
+			if len(revoked) == 0 {
+				fmt.Println("✓ Certificate was already revoked")
+			} else {
+				fmt.Printf("✓ Revoked %d certificate(s)\n", len(revoked))
+				for _, s := range revoked {
+					fmt.Printf("  Serial number: %d\n", s)
+				}
+			}
 
 			if reason != "" {
 				fmt.Printf("Revocation reason: %s\n", reason)
 			}
 
-			if serial != "" {
-				fmt.Printf("Serial number: %s\n", serial)
+			if krlFile != "" {
+				krl, err := vaultClient.SSHKRL(context.Background())
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(krlFile, krl, 0644); err != nil {
+					return fmt.Errorf("failed to write KRL: %w", err)
+				}
+				fmt.Printf("✓ KRL written to %s\n", krlFile)
 			}
 
 			return nil
@@ -1280,7 +1391,9 @@ func newSshRevokeCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&force, "force", false, "Force revocation without confirmation")
 	cmd.Flags().StringVar(&reason, "reason", "", "Reason for revocation")
 	cmd.Flags().StringVar(&certID, "cert-id", "", "Certificate ID to revoke")
-	cmd.Flags().StringVar(&serial, "serial", "", "Serial number to revoke")
+	cmd.Flags().Uint64Var(&serial, "serial", 0, "Serial number to revoke")
+	cmd.Flags().StringVar(&krlFile, "krl", "", "Write the updated KRL to this file")
+	addOperatorFlags(cmd)
 
 	return cmd
 }
@@ -1477,6 +1590,7 @@ type CertificateRequest struct {
 type Certificate struct {
 	ID        string
 	CertFile  string
+	KeyFile   string
 	ExpiresAt string
 	Role      string
 	Username  string
@@ -1713,6 +1827,7 @@ func executeSecureSSH(session *SSHSesssion) error {
 		Target:   session.Target,
 		Port:     session.Port,
 		Username: session.Username,
+		KeyFile:  cert.KeyFile,
 		CertFile: cert.CertFile,
 		Timeout:  30,
 	}
@@ -1825,7 +1940,7 @@ func establishSSHConnection(config *SSHConfig) (*SSHConnection, error) {
 	// Build SSH command
 	args := []string{
 		"-p", fmt.Sprintf("%d", config.Port),
-		"-i", config.CertFile,
+		"-o", "CertificateFile=" + config.CertFile,
 		"-o", "StrictHostKeyChecking=no",
 		"-o", "UserKnownHostsFile=/dev/null",
 		"-o", fmt.Sprintf("ConnectTimeout=%d", config.Timeout),
@@ -1861,10 +1976,14 @@ func startInteractiveSession(conn *SSHConnection) error {
 	err := conn.Process.Wait()
 	conn.Connected = false
 
-	// Clean up certificate file
+	// Clean up certificate and key files
 	if conn.Config.CertFile != "" {
 		os.Remove(conn.Config.CertFile)
 	}
+	if conn.Config.KeyFile != "" {
+		os.Remove(conn.Config.KeyFile)
+		os.Remove(filepath.Dir(conn.Config.KeyFile))
+	}
 
 	return err
 }
@@ -1876,38 +1995,77 @@ func (conn *SSHConnection) Close() error {
 		conn.Connected = false
 	}
 
-	// Clean up certificate file
+	// Clean up certificate and key files
 	if conn.Config.CertFile != "" {
 		os.Remove(conn.Config.CertFile)
 	}
+	if conn.Config.KeyFile != "" {
+		os.Remove(conn.Config.KeyFile)
+		os.Remove(filepath.Dir(conn.Config.KeyFile))
+	}
 
 	return nil
 }
 
 // Certificate operations
 func requestCertificate(config *VaultConfig, req *CertificateRequest) (*Certificate, error) {
-	// In real implementation, this would:
-	// 1. Make HTTP request to Vault's SSH secrets engine
-	// 2. Submit certificate signing request
-	// 3. Parse response and save certificate
-	// 4. Return certificate metadata
+	// A fresh key per session, so nothing long-lived is left on disk
+	dir, err := os.MkdirTemp("", "vault-ssh-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key directory: %v", err)
+	}
 
-	certID := fmt.Sprintf("cert-ssh-%d", time.Now().Unix())
-	certFile := fmt.Sprintf("/tmp/%s-cert.pub", certID)
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key: %v", err)
+	}
+	sshPublicKey, err := ssh.NewPublicKey(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(privateKey, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %v", err)
+	}
 
-	// Create a temporary certificate file
-	certContent := fmt.Sprintf(`ssh-cert-vault %s %s %s %s`,
-		req.Username, req.Role, req.Target, time.Now().Add(time.Hour).Format(time.RFC3339))
+	keyFile := filepath.Join(dir, "id_ed25519")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(block), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write private key: %v", err)
+	}
 
-	err := os.WriteFile(certFile, []byte(certContent), 0644)
+	signReq := &types.SSHSignRequest{
+		PublicKey:       string(ssh.MarshalAuthorizedKey(sshPublicKey)),
+		ValidPrincipals: []string{req.Username},
+	}
+	if req.TTL != "" {
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TTL: %v", err)
+		}
+		signReq.TTL = int(ttl.Seconds())
+	}
+
+	vaultClient := client.NewHTTPClient(strings.TrimRight(config.Address, "/"))
+	if config.Token != "" {
+		vaultClient.SetToken(config.Token)
+	}
+	signed, err := vaultClient.SignSSHKey(context.Background(), req.Role, signReq)
 	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	certFile := keyFile + "-cert.pub"
+	if err := os.WriteFile(certFile, []byte(signed.SignedKey), 0644); err != nil {
+		os.RemoveAll(dir)
 		return nil, fmt.Errorf("failed to create certificate file: %v", err)
 	}
 
 	return &Certificate{
-		ID:        certID,
+		ID:        signed.KeyID,
 		CertFile:  certFile,
-		ExpiresAt: time.Now().Add(time.Hour).Format(time.RFC3339),
+		KeyFile:   keyFile,
+		ExpiresAt: signed.ValidBefore.Format(time.RFC3339),
 		Role:      req.Role,
 		Username:  req.Username,
 	}, nil
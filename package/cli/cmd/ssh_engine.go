@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+	"github.com/spf13/cobra"
+)
+
+// newSshCACommand creates the ssh ca command
+func newSshCACommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ca",
+		Short: "Generate the SSH certificate authority",
+		Long: `Generate the key the server signs SSH certificates with. The private key
+never leaves the server; install the printed public key on SSH servers as
+TrustedUserCAKeys. It can be fetched again from /api/v1/ssh/public_key.
+
+Examples:
+  vault ssh ca
+  vault ssh ca --key-type rsa > /etc/ssh/trusted-user-ca-keys.pem`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			keyType, _ := cmd.Flags().GetString("key-type")
+
+			vaultClient := newOperatorClient(cmd)
+			publicKey, err := vaultClient.ConfigureSSHCA(context.Background(), keyType)
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(publicKey)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("key-type", "ed25519", "CA key type (ed25519, rsa, ec)")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+// newSshRoleCommand creates the ssh role command
+func newSshRoleCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "role [name]",
+		Short: "Define a role signing SSH keys",
+		Long: `Define which principals, extensions, critical options and lifetimes
+certificates signed through a role may have. Use * to allow any value.
+User roles without extensions allow and default to permit-pty.
+
+Examples:
+  vault ssh role dev --allowed-principals alice,bob --ttl 3600
+  vault ssh role ops --allowed-principals "*" --default-principals ops \
+    --allowed-extensions permit-pty,permit-port-forwarding --allowed-critical-options source-address
+  vault ssh role hosts --cert-type host --allowed-principals "*.example.com"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			certType, _ := cmd.Flags().GetString("cert-type")
+			allowedPrincipals, _ := cmd.Flags().GetStringSlice("allowed-principals")
+			defaultPrincipals, _ := cmd.Flags().GetStringSlice("default-principals")
+			allowedExtensions, _ := cmd.Flags().GetStringSlice("allowed-extensions")
+			defaultExtensions, _ := cmd.Flags().GetStringSlice("default-extensions")
+			allowedCriticalOptions, _ := cmd.Flags().GetStringSlice("allowed-critical-options")
+			defaultCriticalOptions, _ := cmd.Flags().GetStringSlice("default-critical-options")
+			ttl, _ := cmd.Flags().GetInt("ttl")
+			maxTTL, _ := cmd.Flags().GetInt("max-ttl")
+
+			if len(allowedPrincipals) == 0 {
+				return fmt.Errorf("--allowed-principals is required")
+			}
+
+			req := &types.SSHRoleRequest{
+				Name:                   args[0],
+				CertType:               certType,
+				AllowedPrincipals:      allowedPrincipals,
+				DefaultPrincipals:      defaultPrincipals,
+				AllowedExtensions:      allowedExtensions,
+				AllowedCriticalOptions: allowedCriticalOptions,
+				TTL:                    ttl,
+				MaxTTL:                 maxTTL,
+			}
+			var err error
+			if req.DefaultExtensions, err = parseSSHOptions(defaultExtensions); err != nil {
+				return err
+			}
+			if req.DefaultCriticalOptions, err = parseSSHOptions(defaultCriticalOptions); err != nil {
+				return err
+			}
+
+			vaultClient := newOperatorClient(cmd)
+			if err := vaultClient.WriteSSHRole(context.Background(), req); err != nil {
+				return err
+			}
+
+			fmt.Printf("SSH role %s saved\n", args[0])
+			return nil
+		},
+	}
+
+	cmd.Flags().String("cert-type", "user", "Certificate type (user, host)")
+	cmd.Flags().StringSlice("allowed-principals", []string{}, "Principals certificates may name")
+	cmd.Flags().StringSlice("default-principals", []string{}, "Principals used when a request names none")
+	cmd.Flags().StringSlice("allowed-extensions", []string{}, "Extensions certificates may carry")
+	cmd.Flags().StringSlice("default-extensions", []string{}, "Extensions used when a request asks for none, as name or name=value")
+	cmd.Flags().StringSlice("allowed-critical-options", []string{}, "Critical options certificates may carry")
+	cmd.Flags().StringSlice("default-critical-options", []string{}, "Critical options used when a request asks for none, as name=value")
+	cmd.Flags().Int("ttl", 0, "Certificate lifetime in seconds (default: 3600)")
+	cmd.Flags().Int("max-ttl", 0, "Longest certificate lifetime in seconds (default: 86400)")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
@@ -1,10 +1,15 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/client"
 	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/ui"
 	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
 	"github.com/spf13/cobra"
@@ -24,7 +29,7 @@ This command provides subcommands for:
   - Watching TOTP codes in real-time
   - Updating TOTP entries
   - Deleting TOTP entries
-  - Importing TOTP entries from QR codes`,
+  - Importing and exporting otpauth:// URIs and QR codes`,
 	}
 
 	cmd.AddCommand(newTOTPAddCommand())
@@ -34,6 +39,7 @@ This command provides subcommands for:
 	cmd.AddCommand(newTOTPUpdateCommand())
 	cmd.AddCommand(newTOTPDeleteCommand())
 	cmd.AddCommand(newTOTPImportCommand())
+	cmd.AddCommand(newTOTPExportCommand())
 
 	return cmd
 }
@@ -74,19 +80,24 @@ func newTOTPGenerateCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "generate [name]",
 		Short: "Generate a TOTP code",
-		Long: `Generate a TOTP code for a specific entry.
+		Long: `Generate the current code of a server entry, given by name or ID.
+
+--qr draws the entry's otpauth:// URI as a QR code in the terminal, for an
+authenticator app to scan. Copying to the clipboard needs xclip, xsel or
+wl-copy on Linux.
 
 Examples:
   vault totp generate "GitHub"
   vault totp generate "GitHub" --copy
-  vault totp generate "GitHub" --show-qr`,
+  vault totp generate "GitHub" --qr`,
 		RunE: runTOTPGenerateCommand,
 	}
 
 	cmd.Flags().Bool("copy", false, "Copy code to clipboard")
-	cmd.Flags().Bool("show-qr", false, "Show QR code")
+	cmd.Flags().Bool("qr", false, "Show QR code")
 	cmd.Flags().Bool("show-secret", false, "Show secret key")
 	cmd.Flags().String("format", "table", "Output format (table, json)")
+	addOperatorFlags(cmd)
 
 	return cmd
 }
@@ -204,27 +215,62 @@ Examples:
 func newTOTPImportCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "import",
-		Short: "Import TOTP entry",
-		Long: `Import a TOTP entry from various sources.
+		Short: "Import TOTP entries",
+		Long: `Import TOTP entries into the server from various sources.
 
 Supported formats:
-  - QR code image file
   - otpauth:// URI
+  - otpauth-migration:// URI, the "Transfer accounts" export of Google
+    Authenticator, which may hold several entries
   - Plain secret key
 
+A file passed with --file holds one URI per line. Entries are imported
+together or not at all; each gets backup codes that are only shown now.
+
 Examples:
-  vault totp import --qr-code qrcode.png
   vault totp import --uri "otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP"
+  vault totp import --uri "otpauth-migration://offline?data=..." --preview
+  vault totp import --file accounts.txt --team 5f0c...
   vault totp import --secret "JBSWY3DPEHPK3PXP" --name "Example"`,
 		RunE: runTOTPImportCommand,
 	}
 
-	cmd.Flags().String("qr-code", "", "QR code image file path")
-	cmd.Flags().String("uri", "", "otpauth:// URI")
+	cmd.Flags().StringSlice("uri", []string{}, "otpauth:// or otpauth-migration:// URI (repeatable)")
+	cmd.Flags().String("file", "", "File with one URI per line, - for stdin")
 	cmd.Flags().String("secret", "", "Plain secret key")
 	cmd.Flags().String("name", "", "Name for the entry (required with --secret)")
 	cmd.Flags().String("issuer", "", "Issuer for the entry")
+	cmd.Flags().String("team", "", "Team whose collection receives the entries")
 	cmd.Flags().Bool("preview", false, "Preview import without importing")
+	addOperatorFlags(cmd)
+
+	return cmd
+}
+
+// newTOTPExportCommand creates the TOTP export command
+func newTOTPExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export [name]",
+		Short: "Export TOTP entries",
+		Long: `Export server entries as otpauth:// URIs, to move them to another
+authenticator. Without a name, every entry you can read is exported; with
+--migration they are packed into otpauth-migration:// URIs that
+Google Authenticator imports. The URIs contain the secret keys.
+
+Examples:
+  vault totp export
+  vault totp export "GitHub" --qr
+  vault totp export "GitHub" --png github.png
+  vault totp export --migration --qr`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runTOTPExportCommand,
+	}
+
+	cmd.Flags().Bool("migration", false, "Export as otpauth-migration:// URIs for Google Authenticator")
+	cmd.Flags().Bool("qr", false, "Show each URI as a QR code")
+	cmd.Flags().String("png", "", "Write the entry's QR code to a PNG file")
+	cmd.Flags().Int("size", 256, "Width of the PNG QR code in pixels")
+	addOperatorFlags(cmd)
 
 	return cmd
 }
@@ -300,32 +346,71 @@ func runTOTPGenerateCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("TOTP entry name is required")
 	}
 
-	name := args[0]
 	copyCode, _ := cmd.Flags().GetBool("copy")
-	showQR, _ := cmd.Flags().GetBool("show-qr")
+	showQR, _ := cmd.Flags().GetBool("qr")
 	showSecret, _ := cmd.Flags().GetBool("show-secret")
+	format, _ := cmd.Flags().GetString("format")
 
-	fmt.Printf("%sGenerating TOTP code for: %s%s\n", ui.Blue, name, ui.Reset)
+	ctx := context.Background()
+	vaultClient := newOperatorClient(cmd)
+	entry, err := findTOTPRecord(ctx, vaultClient, args[0])
+	if err != nil {
+		return err
+	}
 
-	// TODO: Fetch from vault and generate TOTP
-	code := "123456"    // Placeholder
-	timeRemaining := 15 // Placeholder
+	code, err := vaultClient.GenerateTOTPCode(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	timeRemaining := int(time.Until(code.ExpiresAt).Round(time.Second).Seconds())
 
-	fmt.Printf("%sCode: %s%s (valid for %ds)\n", ui.Green, ui.Bold, code, ui.Reset, timeRemaining)
+	var uri, secret string
+	if showQR || showSecret {
+		if uri, err = vaultClient.ExportTOTPURI(ctx, entry.ID); err != nil {
+			return err
+		}
+		parsed, err := types.NewTOTPUtility().ParseURI(uri)
+		if err != nil {
+			return err
+		}
+		secret = parsed.Secret
+	}
 
-	if showSecret {
-		fmt.Printf("Secret: %s(show secret here)%s\n", ui.Yellow, ui.Reset)
+	if format == "json" {
+		output := map[string]interface{}{
+			"id":         entry.ID,
+			"name":       entry.Name,
+			"code":       code.Code,
+			"expires_at": code.ExpiresAt,
+		}
+		if showSecret {
+			output["secret"] = secret
+			output["uri"] = uri
+		}
+		data, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("%sGenerating TOTP code for: %s%s\n", ui.Blue, entry.Name, ui.Reset)
+		fmt.Printf("%sCode: %s%s%s (valid for %ds)\n", ui.Green, ui.Bold, code.Code, ui.Reset, timeRemaining)
+		if showSecret {
+			fmt.Printf("Secret: %s%s%s\n", ui.Yellow, secret, ui.Reset)
+		}
 	}
 
 	if copyCode {
+		if err := ui.CopyToClipboard(code.Code); err != nil {
+			return err
+		}
 		fmt.Printf("%sCode copied to clipboard%s\n", ui.Green, ui.Reset)
-		// TODO: Implement clipboard copy
 	}
 
 	if showQR {
+		qr, err := ui.RenderQRCode(uri)
+		if err != nil {
+			return err
+		}
 		fmt.Printf("%sQR Code:%s\n", ui.Blue, ui.Reset)
-		fmt.Println("(QR code would be displayed here)")
-		// TODO: Implement QR code generation
+		fmt.Print(qr)
 	}
 
 	return nil
@@ -415,13 +500,14 @@ func runTOTPDeleteCommand(cmd *cobra.Command, args []string) error {
 }
 
 func runTOTPImportCommand(cmd *cobra.Command, args []string) error {
-	qrCode, _ := cmd.Flags().GetString("qr-code")
-	uri, _ := cmd.Flags().GetString("uri")
+	uris, _ := cmd.Flags().GetStringSlice("uri")
+	file, _ := cmd.Flags().GetString("file")
 	secret, _ := cmd.Flags().GetString("secret")
+	team, _ := cmd.Flags().GetString("team")
 	preview, _ := cmd.Flags().GetBool("preview")
 
-	if qrCode == "" && uri == "" && secret == "" {
-		return fmt.Errorf("one of --qr-code, --uri, or --secret is required")
+	if len(uris) == 0 && file == "" && secret == "" {
+		return fmt.Errorf("one of --uri, --file, or --secret is required")
 	}
 
 	if secret != "" {
@@ -429,26 +515,139 @@ func runTOTPImportCommand(cmd *cobra.Command, args []string) error {
 		if name == "" {
 			return fmt.Errorf("--name is required when using --secret")
 		}
+		issuer, _ := cmd.Flags().GetString("issuer")
+
+		uri, err := types.NewTOTPUtility().GenerateURI(&types.TOTPEntry{
+			Account:   name,
+			Issuer:    issuer,
+			Secret:    secret,
+			Algorithm: types.TOTPAlgorithmSHA1,
+			Digits:    6,
+			Period:    30,
+		})
+		if err != nil {
+			return err
+		}
+		uris = append(uris, uri)
+	}
+
+	if file != "" {
+		lines, err := readTOTPURIs(file)
+		if err != nil {
+			return err
+		}
+		uris = append(uris, lines...)
 	}
 
-	fmt.Printf("%sImporting TOTP entry%s\n", ui.Blue, ui.Reset)
+	fmt.Printf("%sImporting TOTP entries%s\n", ui.Blue, ui.Reset)
 
 	if preview {
 		fmt.Printf("%sPreview mode - no changes will be made%s\n", ui.Yellow, ui.Reset)
 	}
 
-	// TODO: Implement import based on source
-	if qrCode != "" {
-		fmt.Printf("From QR code: %s\n", qrCode)
+	vaultClient := newOperatorClient(cmd)
+	entries, err := vaultClient.ImportTOTPs(context.Background(), &types.TOTPImportRequest{
+		URIs:   uris,
+		TeamID: team,
+		DryRun: preview,
+	})
+	if err != nil {
+		return err
 	}
-	if uri != "" {
-		fmt.Printf("From URI: %s\n", uri)
+
+	for _, entry := range entries {
+		name := entry.Name
+		if entry.Issuer != "" {
+			name = entry.Issuer + ":" + entry.Name
+		}
+		fmt.Printf("  %-40s %s, %d digits, %ds\n", name, entry.Algorithm, entry.Digits, entry.Period)
+		if preview {
+			continue
+		}
+		fmt.Printf("    ID: %s\n", entry.ID)
+		fmt.Printf("    Backup codes: %s\n", strings.Join(entry.BackupCodes, " "))
 	}
-	if secret != "" {
-		fmt.Printf("From secret: %s\n", secret)
+
+	if preview {
+		fmt.Printf("%s%d entries would be imported%s\n", ui.Green, len(entries), ui.Reset)
+		return nil
 	}
+	fmt.Printf("%sImported %d entries; the backup codes are not shown again%s\n", ui.Green, len(entries), ui.Reset)
+
+	return nil
+}
 
-	fmt.Printf("%sImport completed successfully%s\n", ui.Green, ui.Reset)
+func runTOTPExportCommand(cmd *cobra.Command, args []string) error {
+	migration, _ := cmd.Flags().GetBool("migration")
+	showQR, _ := cmd.Flags().GetBool("qr")
+	pngFile, _ := cmd.Flags().GetString("png")
+	size, _ := cmd.Flags().GetInt("size")
+
+	ctx := context.Background()
+	vaultClient := newOperatorClient(cmd)
+
+	var uris []string
+	if len(args) > 0 {
+		entry, err := findTOTPRecord(ctx, vaultClient, args[0])
+		if err != nil {
+			return err
+		}
+
+		if pngFile != "" {
+			png, err := vaultClient.TOTPQRCode(ctx, entry.ID, size)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(pngFile, png, 0600); err != nil {
+				return fmt.Errorf("failed to write QR code: %w", err)
+			}
+			fmt.Printf("%sQR code written to %s%s\n", ui.Green, pngFile, ui.Reset)
+			return nil
+		}
+
+		uri, err := vaultClient.ExportTOTPURI(ctx, entry.ID)
+		if err != nil {
+			return err
+		}
+		uris = []string{uri}
+	} else {
+		if pngFile != "" {
+			return fmt.Errorf("--png requires an entry name")
+		}
+
+		format := "otpauth"
+		if migration {
+			format = "migration"
+		}
+
+		var skipped []string
+		var err error
+		if uris, skipped, err = vaultClient.ExportTOTPs(ctx, format); err != nil {
+			return err
+		}
+		for _, name := range skipped {
+			fmt.Fprintf(os.Stderr, "%sSkipped '%s': Google Authenticator only takes 30 second periods and 6 or 8 digits%s\n", ui.Yellow, name, ui.Reset)
+		}
+		if len(uris) == 0 {
+			fmt.Println("No TOTP entries")
+			return nil
+		}
+	}
+
+	for i, uri := range uris {
+		fmt.Println(uri)
+		if !showQR {
+			continue
+		}
+		qr, err := ui.RenderQRCode(uri)
+		if err != nil {
+			return err
+		}
+		fmt.Print(qr)
+		if len(uris) > 1 {
+			fmt.Printf("%s%d/%d%s\n\n", ui.Dim, i+1, len(uris), ui.Reset)
+		}
+	}
 
 	return nil
 }
@@ -573,3 +772,52 @@ func createTOTPInteractive(cmd *cobra.Command) error {
 
 	return nil
 }
+
+// findTOTPRecord finds a server entry by ID or name
+func findTOTPRecord(ctx context.Context, vaultClient *client.HTTPClient, nameOrID string) (*types.TOTPRecord, error) {
+	entries, err := vaultClient.ListTOTPs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *types.TOTPRecord
+	for i := range entries {
+		if entries[i].ID == nameOrID {
+			return &entries[i], nil
+		}
+		if strings.EqualFold(entries[i].Name, nameOrID) {
+			if found != nil {
+				return nil, fmt.Errorf("several TOTP entries are named '%s', use the ID", nameOrID)
+			}
+			found = &entries[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("TOTP entry '%s' not found", nameOrID)
+	}
+	return found, nil
+}
+
+// readTOTPURIs reads one URI per line from a file or stdin, skipping blank
+// lines and # comments
+func readTOTPURIs(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read URIs: %w", err)
+	}
+
+	var uris []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			uris = append(uris, line)
+		}
+	}
+	return uris, nil
+}
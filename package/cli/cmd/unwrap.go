@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/config"
 	"github.com/skygenesisenterprise/aether-vault/package/cli/internal/context"
@@ -15,22 +18,36 @@ func newUnwrapCommand() *cobra.Command {
 		Short: "Unwrap a wrapped secret",
 		Long: `Unwrap a secret that was wrapped using the response-wrapping functionality.
 
+A wrapping token can be unwrapped exactly once; the token itself is the only
+credential needed. Use -lookup to inspect a token without consuming it.
+The token may also be given with VAULT_WRAPPING_TOKEN so it stays out of
+process listings and shell history.
+
 Examples:
-  vault unwrap s.1234567890abcdef
-  vault unwrap -format=json s.1234567890abcdef`,
+  vault unwrap w.1234567890abcdef
+  vault unwrap -format=json w.1234567890abcdef
+  vault unwrap -lookup w.1234567890abcdef`,
 		RunE: runUnwrapCommand,
 	}
 
+	cmd.Flags().Bool("lookup", false, "Show the token creation path and TTL without unwrapping it")
+	addOperatorFlags(cmd)
+
 	return cmd
 }
 
 // runUnwrapCommand executes the unwrap command
 func runUnwrapCommand(cmd *cobra.Command, args []string) error {
-	if len(args) == 0 {
+	token := os.Getenv("VAULT_WRAPPING_TOKEN")
+	if len(args) > 0 {
+		token = args[0]
+	}
+	if token == "" {
 		return fmt.Errorf("token argument is required")
 	}
 
-	token := args[0]
+	format, _ := cmd.Flags().GetString("format")
+	lookup, _ := cmd.Flags().GetBool("lookup")
 
 	// Load configuration
 	cfg, err := config.Load()
@@ -38,11 +55,29 @@ func runUnwrapCommand(cmd *cobra.Command, args []string) error {
 		cfg = config.Defaults()
 	}
 
-	// Create context
-	ctx, err := context.New(cfg)
+	// Create context; the wrapping token authenticates the request
+	ctx, err := context.NewWithoutServer(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create context: %w", err)
 	}
+	ctx.Client = newOperatorClient(cmd)
+
+	if lookup {
+		info, err := ctx.LookupWrapping(token)
+		if err != nil {
+			return fmt.Errorf("failed to look up token: %w", err)
+		}
+
+		if format == "json" {
+			return printUnwrapJSON(info)
+		}
+		fmt.Printf("Accessor:      %s\n", info.Accessor)
+		fmt.Printf("Creation Path: %s\n", info.CreationPath)
+		fmt.Printf("Creation Time: %s\n", info.CreationTime.Format(time.RFC3339))
+		fmt.Printf("Creation TTL:  %ds\n", info.CreationTTL)
+		fmt.Printf("TTL:           %ds\n", info.TTL)
+		return nil
+	}
 
 	// Unwrap the secret
 	data, err := ctx.Unwrap(token)
@@ -50,9 +85,17 @@ func runUnwrapCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to unwrap token: %w", err)
 	}
 
-	// Display unwrapped data
-	fmt.Printf("Successfully unwrapped token\n")
-	fmt.Printf("Data: %+v\n", data)
+	// Display unwrapped data; the notice goes to stderr so stdout stays
+	// parseable when piped
+	if format != "json" {
+		fmt.Fprintln(os.Stderr, "Successfully unwrapped token")
+	}
+	return printUnwrapJSON(data["data"])
+}
 
-	return nil
+// printUnwrapJSON writes v to stdout as indented JSON
+func printUnwrapJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
 }
@@ -3,8 +3,10 @@ module github.com/skygenesisenterprise/aether-vault/package/cli
 go 1.25.5
 
 require (
+	github.com/atotto/clipboard v0.1.4
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/mux v1.8.1
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cobra v1.8.0
 	golang.org/x/crypto v0.46.0
 	golang.org/x/term v0.38.0
@@ -0,0 +1,38 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+)
+
+// WriteDatabaseConnection creates or replaces a database secrets engine
+// connection, requires an admin token
+func (c *HTTPClient) WriteDatabaseConnection(ctx context.Context, req *types.DatabaseConnectionRequest) error {
+	var response map[string]interface{}
+	if err := c.sysRequest("POST", "/api/v1/database/config", req, &response); err != nil {
+		return fmt.Errorf("failed to write database connection: %w", err)
+	}
+	return nil
+}
+
+// WriteDatabaseRole creates or replaces a database secrets engine role,
+// requires an admin token
+func (c *HTTPClient) WriteDatabaseRole(ctx context.Context, req *types.DatabaseRoleRequest) error {
+	var response map[string]interface{}
+	if err := c.sysRequest("POST", "/api/v1/database/roles", req, &response); err != nil {
+		return fmt.Errorf("failed to write database role: %w", err)
+	}
+	return nil
+}
+
+// GenerateDatabaseCredentials creates a database user from a role
+func (c *HTTPClient) GenerateDatabaseCredentials(ctx context.Context, role string) (*types.DatabaseCredentials, error) {
+	var creds types.DatabaseCredentials
+	if err := c.sysRequest("GET", "/api/v1/database/creds/"+url.PathEscape(role), nil, &creds); err != nil {
+		return nil, fmt.Errorf("failed to generate database credentials: %w", err)
+	}
+	return &creds, nil
+}
@@ -55,6 +55,7 @@ func (c *HTTPClient) makeRequest(method, path string, body interface{}) (*http.R
 	// Set headers
 	if c.token != "" {
 		req.Header.Set("X-Vault-Token", c.token)
+		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+)
+
+// GeneratePKIRoot creates the self-signed root CA of the PKI secrets engine,
+// requires an admin token
+func (c *HTTPClient) GeneratePKIRoot(ctx context.Context, req *types.PKIGenerateRootRequest) (*types.PKICA, error) {
+	var ca types.PKICA
+	if err := c.sysRequest("POST", "/api/v1/pki/root/generate", req, &ca); err != nil {
+		return nil, fmt.Errorf("failed to generate root CA: %w", err)
+	}
+	return &ca, nil
+}
+
+// WritePKIRole creates or replaces a PKI role, requires an admin token
+func (c *HTTPClient) WritePKIRole(ctx context.Context, req *types.PKIRoleRequest) error {
+	var response map[string]interface{}
+	if err := c.sysRequest("POST", "/api/v1/pki/roles", req, &response); err != nil {
+		return fmt.Errorf("failed to write PKI role: %w", err)
+	}
+	return nil
+}
+
+// IssuePKICertificate issues a certificate and key from a role
+func (c *HTTPClient) IssuePKICertificate(ctx context.Context, role string, req *types.PKIIssueRequest) (*types.PKICertificate, error) {
+	var cert types.PKICertificate
+	if err := c.sysRequest("POST", "/api/v1/pki/issue/"+url.PathEscape(role), req, &cert); err != nil {
+		return nil, fmt.Errorf("failed to issue certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// SignPKICertificate signs a CSR from a role
+func (c *HTTPClient) SignPKICertificate(ctx context.Context, role string, req *types.PKISignRequest) (*types.PKICertificate, error) {
+	var cert types.PKICertificate
+	if err := c.sysRequest("POST", "/api/v1/pki/sign/"+url.PathEscape(role), req, &cert); err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	return &cert, nil
+}
+
+// RevokePKICertificate revokes a certificate by serial number, requires an
+// admin token
+func (c *HTTPClient) RevokePKICertificate(ctx context.Context, serial string) (time.Time, error) {
+	var response struct {
+		RevocationTime time.Time `json:"revocation_time"`
+	}
+	if err := c.sysRequest("POST", "/api/v1/pki/revoke", map[string]string{"serial_number": serial}, &response); err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke certificate: %w", err)
+	}
+	return response.RevocationTime, nil
+}
+
+// ListPKICertificates lists tracked certificates, optionally of one role or
+// only revoked ones
+func (c *HTTPClient) ListPKICertificates(ctx context.Context, role string, revoked bool) ([]types.PKICertificateRecord, error) {
+	query := url.Values{}
+	if role != "" {
+		query.Set("role", role)
+	}
+	if revoked {
+		query.Set("revoked", "true")
+	}
+
+	path := "/api/v1/pki/certs"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	var response struct {
+		Certificates []types.PKICertificateRecord `json:"certificates"`
+	}
+	if err := c.sysRequest("GET", path, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list certificates: %w", err)
+	}
+	return response.Certificates, nil
+}
@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+)
+
+// ConfigureSSHCA generates the SSH CA key of the given type, requires an
+// admin token
+func (c *HTTPClient) ConfigureSSHCA(ctx context.Context, keyType string) (string, error) {
+	var response struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := c.sysRequest("POST", "/api/v1/ssh/config/ca", map[string]string{"key_type": keyType}, &response); err != nil {
+		return "", fmt.Errorf("failed to configure SSH CA: %w", err)
+	}
+	return response.PublicKey, nil
+}
+
+// WriteSSHRole creates or replaces an SSH role, requires an admin token
+func (c *HTTPClient) WriteSSHRole(ctx context.Context, req *types.SSHRoleRequest) error {
+	var response map[string]interface{}
+	if err := c.sysRequest("POST", "/api/v1/ssh/roles", req, &response); err != nil {
+		return fmt.Errorf("failed to write SSH role: %w", err)
+	}
+	return nil
+}
+
+// SignSSHKey signs a public key into a certificate from a role
+func (c *HTTPClient) SignSSHKey(ctx context.Context, role string, req *types.SSHSignRequest) (*types.SSHSignedKey, error) {
+	var signed types.SSHSignedKey
+	if err := c.sysRequest("POST", "/api/v1/ssh/sign/"+url.PathEscape(role), req, &signed); err != nil {
+		return nil, fmt.Errorf("failed to sign SSH key: %w", err)
+	}
+	return &signed, nil
+}
+
+// RevokeSSHCertificate revokes SSH certificates and returns the revoked
+// serials, requires an admin token
+func (c *HTTPClient) RevokeSSHCertificate(ctx context.Context, req *types.SSHRevokeRequest) ([]uint64, error) {
+	var response struct {
+		Revoked []uint64 `json:"revoked"`
+	}
+	if err := c.sysRequest("POST", "/api/v1/ssh/revoke", req, &response); err != nil {
+		return nil, fmt.Errorf("failed to revoke SSH certificate: %w", err)
+	}
+	return response.Revoked, nil
+}
+
+// SSHKRL downloads the OpenSSH key revocation list of the SSH CA
+func (c *HTTPClient) SSHKRL(ctx context.Context) ([]byte, error) {
+	resp, err := c.makeRequest("GET", "/api/v1/ssh/krl", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download KRL: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download KRL: request failed with status: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
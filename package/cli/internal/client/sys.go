@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+)
+
+// InitVault initializes the server and returns the unseal key shares
+func (c *HTTPClient) InitVault(ctx context.Context, request *types.InitRequest) (*types.InitResponse, error) {
+	var response types.InitResponse
+	if err := c.sysRequest("POST", "/api/v1/sys/init", request, &response); err != nil {
+		return nil, fmt.Errorf("failed to initialize vault: %w", err)
+	}
+	return &response, nil
+}
+
+// SealStatus returns the seal state of the server
+func (c *HTTPClient) SealStatus(ctx context.Context) (*types.SealStatus, error) {
+	var status types.SealStatus
+	if err := c.sysRequest("GET", "/api/v1/sys/seal-status", nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to get seal status: %w", err)
+	}
+	return &status, nil
+}
+
+// Unseal submits one unseal key share, or discards submitted shares when reset is set
+func (c *HTTPClient) Unseal(ctx context.Context, key string, reset bool) (*types.SealStatus, error) {
+	request := map[string]interface{}{
+		"key":   key,
+		"reset": reset,
+	}
+
+	var status types.SealStatus
+	if err := c.sysRequest("POST", "/api/v1/sys/unseal", request, &status); err != nil {
+		return nil, fmt.Errorf("failed to unseal vault: %w", err)
+	}
+	return &status, nil
+}
+
+// Seal seals the server, requires an admin token
+func (c *HTTPClient) Seal(ctx context.Context) (*types.SealStatus, error) {
+	var status types.SealStatus
+	if err := c.sysRequest("POST", "/api/v1/sys/seal", nil, &status); err != nil {
+		return nil, fmt.Errorf("failed to seal vault: %w", err)
+	}
+	return &status, nil
+}
+
+// sysRequest performs a request against the server sys API and decodes the result
+func (c *HTTPClient) sysRequest(method, path string, body, result interface{}) error {
+	resp, err := c.makeRequest(method, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var errorResponse struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err == nil && errorResponse.Error.Message != "" {
+			return fmt.Errorf("%s (status %d)", errorResponse.Error.Message, resp.StatusCode)
+		}
+		return fmt.Errorf("request failed with status: %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// VerifyAudit walks the server audit hash chain, requires an admin or auditor token
+func (c *HTTPClient) VerifyAudit(ctx context.Context) (*types.AuditVerification, error) {
+	var result types.AuditVerification
+	if err := c.sysRequest("GET", "/api/v1/audit/verify", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to verify audit log: %w", err)
+	}
+	return &result, nil
+}
+
+// Unwrap returns the response behind a wrapping token, consuming the token
+func (c *HTTPClient) Unwrap(ctx context.Context, token string) (interface{}, error) {
+	var response struct {
+		Data interface{} `json:"data"`
+	}
+	if err := c.sysRequest("POST", "/api/v1/sys/wrapping/unwrap", map[string]string{"token": token}, &response); err != nil {
+		return nil, fmt.Errorf("failed to unwrap token: %w", err)
+	}
+	return response.Data, nil
+}
+
+// LookupWrapping describes a wrapping token without consuming it
+func (c *HTTPClient) LookupWrapping(ctx context.Context, token string) (*types.WrappingLookup, error) {
+	var lookup types.WrappingLookup
+	if err := c.sysRequest("POST", "/api/v1/sys/wrapping/lookup", map[string]string{"token": token}, &lookup); err != nil {
+		return nil, fmt.Errorf("failed to look up token: %w", err)
+	}
+	return &lookup, nil
+}
+
+// ListLeases returns the caller's active leases under prefix, or every user's
+// leases when all is set, which requires an admin token
+func (c *HTTPClient) ListLeases(ctx context.Context, prefix string, all bool) ([]types.Lease, error) {
+	path := "/api/v1/sys/leases"
+	if all {
+		path += "/all"
+	}
+	if prefix != "" {
+		path += "?prefix=" + url.QueryEscape(prefix)
+	}
+
+	var response struct {
+		Leases []types.Lease `json:"leases"`
+	}
+	if err := c.sysRequest("GET", path, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list leases: %w", err)
+	}
+	return response.Leases, nil
+}
+
+// RenewLease extends a lease by increment seconds, or by its original TTL
+// when increment is zero
+func (c *HTTPClient) RenewLease(ctx context.Context, leaseID string, increment int) (*types.Lease, error) {
+	request := map[string]interface{}{
+		"lease_id":  leaseID,
+		"increment": increment,
+	}
+
+	var lease types.Lease
+	if err := c.sysRequest("PUT", "/api/v1/sys/leases/renew", request, &lease); err != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// RevokeLease revokes a lease and the credential behind it
+func (c *HTTPClient) RevokeLease(ctx context.Context, leaseID string) error {
+	var response map[string]interface{}
+	if err := c.sysRequest("PUT", "/api/v1/sys/leases/revoke", map[string]string{"lease_id": leaseID}, &response); err != nil {
+		return fmt.Errorf("failed to revoke lease: %w", err)
+	}
+	return nil
+}
+
+// RevokeLeasePrefix revokes every lease under prefix, requires an admin token
+func (c *HTTPClient) RevokeLeasePrefix(ctx context.Context, prefix string) (int, error) {
+	var response struct {
+		Revoked int `json:"revoked"`
+	}
+	if err := c.sysRequest("PUT", "/api/v1/sys/leases/revoke-prefix", map[string]string{"prefix": prefix}, &response); err != nil {
+		return 0, fmt.Errorf("failed to revoke leases: %w", err)
+	}
+	return response.Revoked, nil
+}
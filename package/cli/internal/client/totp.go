@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/skygenesisenterprise/aether-vault/package/cli/pkg/types"
+)
+
+// ListTOTPs lists the TOTP entries the token can read
+func (c *HTTPClient) ListTOTPs(ctx context.Context) ([]types.TOTPRecord, error) {
+	var response struct {
+		TOTPs []types.TOTPRecord `json:"totps"`
+	}
+	if err := c.sysRequest("GET", "/api/v1/totp", nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to list TOTP entries: %w", err)
+	}
+	return response.TOTPs, nil
+}
+
+// GenerateTOTPCode generates the current code of an entry
+func (c *HTTPClient) GenerateTOTPCode(ctx context.Context, id string) (*types.TOTPCode, error) {
+	var code types.TOTPCode
+	if err := c.sysRequest("POST", "/api/v1/totp/"+url.PathEscape(id)+"/generate", nil, &code); err != nil {
+		return nil, fmt.Errorf("failed to generate TOTP code: %w", err)
+	}
+	return &code, nil
+}
+
+// ImportTOTPs creates entries from otpauth:// and otpauth-migration:// URIs,
+// all of them or none
+func (c *HTTPClient) ImportTOTPs(ctx context.Context, req *types.TOTPImportRequest) ([]types.TOTPRecord, error) {
+	var response struct {
+		Imported []types.TOTPRecord `json:"imported"`
+	}
+	if err := c.sysRequest("POST", "/api/v1/totp/import", req, &response); err != nil {
+		return nil, fmt.Errorf("failed to import TOTP entries: %w", err)
+	}
+	return response.Imported, nil
+}
+
+// ExportTOTPs exports every readable entry as otpauth:// URIs, or as
+// otpauth-migration:// batches for the "migration" format, and returns the
+// names of the entries that format cannot hold
+func (c *HTTPClient) ExportTOTPs(ctx context.Context, format string) ([]string, []string, error) {
+	var response struct {
+		URIs    []string `json:"uris"`
+		Skipped []string `json:"skipped"`
+	}
+	if err := c.sysRequest("GET", "/api/v1/totp/export?format="+url.QueryEscape(format), nil, &response); err != nil {
+		return nil, nil, fmt.Errorf("failed to export TOTP entries: %w", err)
+	}
+	return response.URIs, response.Skipped, nil
+}
+
+// ExportTOTPURI exports an entry as an otpauth:// URI
+func (c *HTTPClient) ExportTOTPURI(ctx context.Context, id string) (string, error) {
+	var response struct {
+		URI string `json:"uri"`
+	}
+	if err := c.sysRequest("GET", "/api/v1/totp/"+url.PathEscape(id)+"/uri", nil, &response); err != nil {
+		return "", fmt.Errorf("failed to export TOTP entry: %w", err)
+	}
+	return response.URI, nil
+}
+
+// TOTPQRCode downloads an entry's otpauth:// URI as a PNG QR code of size pixels
+func (c *HTTPClient) TOTPQRCode(ctx context.Context, id string, size int) ([]byte, error) {
+	resp, err := c.makeRequest("GET", fmt.Sprintf("/api/v1/totp/%s/qr?size=%d", url.PathEscape(id), size), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download QR code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var errorResponse struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&errorResponse); err == nil && errorResponse.Error.Message != "" {
+			return nil, fmt.Errorf("failed to download QR code: %s (status %d)", errorResponse.Error.Message, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to download QR code: request failed with status: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
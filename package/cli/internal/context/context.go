@@ -315,15 +315,30 @@ func (c *Context) List(path string) (map[string]interface{}, error) {
 	}, nil
 }
 
-// Unwrap unwraps a wrapped secret
+// Unwrap unwraps a wrapped secret, consuming the wrapping token
 func (c *Context) Unwrap(token string) (map[string]interface{}, error) {
-	// TODO: Implement actual Vault unwrap logic
-	// For now, return mock data
+	vaultClient, ok := c.Client.(*client.HTTPClient)
+	if !ok {
+		return nil, fmt.Errorf("unwrapping requires a server client")
+	}
+
+	data, err := vaultClient.Unwrap(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+
 	return map[string]interface{}{
-		"token": token,
-		"data": map[string]interface{}{
-			"secret": "unwrapped-data",
-			"ttl":    "1h",
-		},
+		"data": data,
 	}, nil
 }
+
+// LookupWrapping reports the creation path and remaining TTL of a wrapping
+// token without consuming it
+func (c *Context) LookupWrapping(token string) (*types.WrappingLookup, error) {
+	vaultClient, ok := c.Client.(*client.HTTPClient)
+	if !ok {
+		return nil, fmt.Errorf("token lookup requires a server client")
+	}
+
+	return vaultClient.LookupWrapping(context.Background(), token)
+}
@@ -0,0 +1,16 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/atotto/clipboard"
+)
+
+// CopyToClipboard copies text to the system clipboard; on Linux this needs
+// xclip, xsel or wl-copy
+func CopyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// RenderQRCode renders content as a QR code drawn with Unicode half blocks,
+// two modules per character, for terminals with a dark background
+func RenderQRCode(content string) (string, error) {
+	code, err := qrcode.New(content, qrcode.Low)
+	if err != nil {
+		return "", fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return code.ToSmallString(false), nil
+}
@@ -317,8 +317,10 @@ func (v *TOTPViewer) CopySelectedCode() error {
 		return fmt.Errorf("no entry selected")
 	}
 
-	// TODO: Implement clipboard copy
-	fmt.Printf("\n%sCode copied to clipboard: %s%s\n", Success("Code copied to clipboard: "+entry.Code), Reset)
+	if err := CopyToClipboard(entry.Code); err != nil {
+		return err
+	}
+	fmt.Printf("\n%s\n", Success("Code copied to clipboard: "+entry.Code))
 	return nil
 }
 
@@ -0,0 +1,27 @@
+package types
+
+// AuditVerification represents the result of verifying the audit hash chain
+type AuditVerification struct {
+	// Whether every entry and checkpoint verified
+	Valid bool `json:"valid"`
+
+	// Number of entries checked
+	EntriesChecked int64 `json:"entries_checked"`
+
+	// First and last sequence numbers checked
+	FirstSequence int64 `json:"first_sequence"`
+	LastSequence  int64 `json:"last_sequence"`
+
+	// Number of signed checkpoints checked
+	CheckpointsChecked int `json:"checkpoints_checked"`
+
+	// Sequence and entry ID of the first broken link
+	BrokenSequence *int64  `json:"broken_sequence,omitempty"`
+	BrokenEntryID  *string `json:"broken_entry_id,omitempty"`
+
+	// Why verification failed
+	Reason string `json:"reason,omitempty"`
+
+	// Ed25519 public key of the checkpoint signatures, base64 encoded
+	PublicKey string `json:"public_key"`
+}
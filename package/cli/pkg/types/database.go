@@ -0,0 +1,58 @@
+package types
+
+// DatabaseConnectionRequest configures a privileged connection of the
+// database secrets engine
+type DatabaseConnectionRequest struct {
+	// Connection name referenced by roles
+	Name string `json:"name"`
+
+	// Database type (sqlite, postgresql, mysql, mariadb)
+	Type string `json:"type"`
+
+	// Connection parameters; for SQLite, Database is the file path
+	Host     string `json:"host,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Database string `json:"database,omitempty"`
+
+	// Full connection URL, overrides the parameters above
+	URL string `json:"url,omitempty"`
+
+	// Whether the server connects before saving (default true)
+	VerifyConnection *bool `json:"verify_connection,omitempty"`
+}
+
+// DatabaseRoleRequest defines how the database secrets engine creates and
+// drops users
+type DatabaseRoleRequest struct {
+	// Role name used to request credentials
+	Name string `json:"name"`
+
+	// Connection the users are created on
+	Connection string `json:"connection"`
+
+	// SQL templates separated by semicolons; {{name}}, {{password}} and
+	// {{expiration}} are replaced for each user
+	CreationStatements   string `json:"creation_statements"`
+	RevocationStatements string `json:"revocation_statements,omitempty"`
+	RenewStatements      string `json:"renew_statements,omitempty"`
+
+	// Lease TTLs in seconds
+	DefaultTTL int `json:"default_ttl,omitempty"`
+	MaxTTL     int `json:"max_ttl,omitempty"`
+}
+
+// DatabaseCredentials represents a generated database user and its lease
+type DatabaseCredentials struct {
+	// Lease dropping the user on expiry or revocation
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+
+	// Generated credentials
+	Data struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+}
@@ -0,0 +1,23 @@
+package types
+
+import "time"
+
+// Lease represents a dynamic or time-limited credential tracked by the server
+type Lease struct {
+	// Lease ID, prefixed with the path the credential was issued from
+	LeaseID string `json:"lease_id"`
+
+	// Engine that releases the credential on revocation
+	Kind string `json:"kind"`
+
+	// Whether the lease can be extended
+	Renewable bool `json:"renewable"`
+
+	// Seconds left before the lease expires
+	LeaseDuration int `json:"lease_duration"`
+
+	// Lease timestamps
+	IssueTime   time.Time  `json:"issue_time"`
+	ExpireTime  time.Time  `json:"expire_time"`
+	LastRenewal *time.Time `json:"last_renewal,omitempty"`
+}
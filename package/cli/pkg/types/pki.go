@@ -0,0 +1,94 @@
+package types
+
+import "time"
+
+// PKIGenerateRootRequest generates the self-signed root CA of the PKI
+// secrets engine
+type PKIGenerateRootRequest struct {
+	// Subject of the CA certificate
+	CommonName   string `json:"common_name"`
+	Organization string `json:"organization,omitempty"`
+
+	// Key type (rsa, ec, ed25519) and size; defaults to RSA 2048
+	KeyType string `json:"key_type,omitempty"`
+	KeyBits int    `json:"key_bits,omitempty"`
+
+	// Certificate lifetime in seconds (default 10 years)
+	TTL int `json:"ttl,omitempty"`
+}
+
+// PKICA represents the CA certificate of the PKI secrets engine
+type PKICA struct {
+	Certificate  string `json:"certificate,omitempty"`
+	CSR          string `json:"csr,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	Expiration   int64  `json:"expiration,omitempty"`
+}
+
+// PKIRoleRequest defines which certificates a PKI role may issue
+type PKIRoleRequest struct {
+	// Role name used to issue and sign certificates
+	Name string `json:"name"`
+
+	// Names allowed in certificates: the allowed domains themselves, names
+	// below them, or any name
+	AllowedDomains   []string `json:"allowed_domains,omitempty"`
+	AllowBareDomains bool     `json:"allow_bare_domains"`
+	AllowSubdomains  *bool    `json:"allow_subdomains,omitempty"`
+	AllowAnyName     bool     `json:"allow_any_name"`
+	AllowIPSANs      bool     `json:"allow_ip_sans"`
+
+	// Extended key usages (default both)
+	ServerFlag *bool `json:"server_flag,omitempty"`
+	ClientFlag *bool `json:"client_flag,omitempty"`
+
+	// Key type (rsa, ec, ed25519, or any for signing CSRs) and size
+	KeyType string `json:"key_type,omitempty"`
+	KeyBits int    `json:"key_bits,omitempty"`
+
+	// Certificate lifetimes in seconds
+	TTL    int `json:"ttl,omitempty"`
+	MaxTTL int `json:"max_ttl,omitempty"`
+}
+
+// PKIIssueRequest issues a certificate with a key generated by the server
+type PKIIssueRequest struct {
+	CommonName string   `json:"common_name"`
+	AltNames   []string `json:"alt_names,omitempty"`
+	IPSANs     []string `json:"ip_sans,omitempty"`
+	TTL        int      `json:"ttl,omitempty"`
+}
+
+// PKISignRequest signs a PEM-encoded CSR; names default to the CSR's
+type PKISignRequest struct {
+	CSR        string   `json:"csr"`
+	CommonName string   `json:"common_name,omitempty"`
+	AltNames   []string `json:"alt_names,omitempty"`
+	IPSANs     []string `json:"ip_sans,omitempty"`
+	TTL        int      `json:"ttl,omitempty"`
+}
+
+// PKICertificate represents an issued or signed certificate
+type PKICertificate struct {
+	Certificate string   `json:"certificate"`
+	IssuingCA   string   `json:"issuing_ca"`
+	CAChain     []string `json:"ca_chain"`
+
+	// Only set when the server generated the key
+	PrivateKey     string `json:"private_key,omitempty"`
+	PrivateKeyType string `json:"private_key_type,omitempty"`
+
+	SerialNumber string `json:"serial_number"`
+	Expiration   int64  `json:"expiration"`
+}
+
+// PKICertificateRecord represents a certificate tracked by the server
+type PKICertificateRecord struct {
+	SerialNumber string     `json:"serial_number"`
+	Role         string     `json:"role,omitempty"`
+	CommonName   string     `json:"common_name"`
+	IsCA         bool       `json:"is_ca"`
+	NotBefore    time.Time  `json:"not_before"`
+	NotAfter     time.Time  `json:"not_after"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
@@ -0,0 +1,37 @@
+package types
+
+// SealStatus represents the seal state reported by the server
+type SealStatus struct {
+	// Whether the vault has been initialized
+	Initialized bool `json:"initialized"`
+
+	// Whether the vault is sealed
+	Sealed bool `json:"sealed"`
+
+	// Number of key shares required to unseal
+	Threshold int `json:"t"`
+
+	// Total number of key shares
+	Shares int `json:"n"`
+
+	// Number of key shares provided so far
+	Progress int `json:"progress"`
+}
+
+// InitRequest represents a vault initialization request
+type InitRequest struct {
+	// Number of key shares to split the root key into
+	SecretShares int `json:"secret_shares"`
+
+	// Number of key shares required to reconstruct the root key
+	SecretThreshold int `json:"secret_threshold"`
+}
+
+// InitResponse represents the result of initializing a vault
+type InitResponse struct {
+	// Unseal key shares, hex encoded
+	Keys []string `json:"keys"`
+
+	// Unseal key shares, base64 encoded
+	KeysBase64 []string `json:"keys_base64"`
+}
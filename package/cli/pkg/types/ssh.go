@@ -0,0 +1,56 @@
+package types
+
+import "time"
+
+// SSHRoleRequest defines which certificates an SSH role may sign
+type SSHRoleRequest struct {
+	// Role name used to sign keys
+	Name string `json:"name"`
+
+	// Certificate type (user or host)
+	CertType string `json:"cert_type,omitempty"`
+
+	// Principals a certificate may name, "*" for any, and those used when a
+	// request names none
+	AllowedPrincipals []string `json:"allowed_principals"`
+	DefaultPrincipals []string `json:"default_principals,omitempty"`
+
+	// Extensions and critical options a certificate may carry, "*" for any,
+	// and those used when a request asks for none
+	AllowedExtensions      []string          `json:"allowed_extensions,omitempty"`
+	DefaultExtensions      map[string]string `json:"default_extensions,omitempty"`
+	AllowedCriticalOptions []string          `json:"allowed_critical_options,omitempty"`
+	DefaultCriticalOptions map[string]string `json:"default_critical_options,omitempty"`
+
+	// Certificate lifetimes in seconds
+	TTL    int `json:"ttl,omitempty"`
+	MaxTTL int `json:"max_ttl,omitempty"`
+}
+
+// SSHSignRequest signs a public key in authorized_keys format
+type SSHSignRequest struct {
+	PublicKey       string            `json:"public_key"`
+	ValidPrincipals []string          `json:"valid_principals,omitempty"`
+	Extensions      map[string]string `json:"extensions,omitempty"`
+	CriticalOptions map[string]string `json:"critical_options,omitempty"`
+	TTL             int               `json:"ttl,omitempty"`
+}
+
+// SSHSignedKey represents a signed OpenSSH certificate
+type SSHSignedKey struct {
+	SerialNumber uint64 `json:"serial_number"`
+	KeyID        string `json:"key_id"`
+
+	// Certificate in authorized_keys format, as written to *-cert.pub
+	SignedKey string `json:"signed_key"`
+
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// SSHRevokeRequest revokes a certificate by serial or all certificates
+// with a key ID
+type SSHRevokeRequest struct {
+	SerialNumber uint64 `json:"serial_number,omitempty"`
+	KeyID        string `json:"key_id,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
@@ -514,3 +514,36 @@ func (u *TOTPUtility) GenerateBackupCodes(count int) ([]*TOTPBackupCode, error)
 
 	return codes, nil
 }
+
+// TOTPRecord represents a TOTP entry stored on the server; its seed never
+// leaves the server except through an export
+type TOTPRecord struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Issuer      string    `json:"issuer,omitempty"`
+	Description string    `json:"description"`
+	Algorithm   string    `json:"algorithm"`
+	Digits      int       `json:"digits"`
+	Period      int       `json:"period"`
+	TeamID      string    `json:"team_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Backup codes, only returned when the entry is created
+	BackupCodes []string `json:"backup_codes,omitempty"`
+}
+
+// TOTPCode is a code generated by the server for an entry
+type TOTPCode struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// TOTPImportRequest imports otpauth:// URIs and Google Authenticator
+// otpauth-migration:// exports
+type TOTPImportRequest struct {
+	URIs   []string `json:"uris"`
+	TeamID string   `json:"team_id,omitempty"`
+
+	// Only parse and validate the URIs
+	DryRun bool `json:"dry_run,omitempty"`
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// WrapInfo represents a response replaced by a single-use wrapping token
+type WrapInfo struct {
+	// Token that unwraps the response exactly once
+	Token string `json:"token"`
+
+	// Accessor identifying the token in audit logs
+	Accessor string `json:"accessor"`
+
+	// Token lifetime in seconds
+	TTL int `json:"ttl"`
+
+	// When and for which request path the token was created
+	CreationTime time.Time `json:"creation_time"`
+	CreationPath string    `json:"creation_path"`
+}
+
+// WrappingLookup describes a wrapping token without consuming it
+type WrappingLookup struct {
+	// Accessor identifying the token in audit logs
+	Accessor string `json:"accessor"`
+
+	// Request path the wrapped response was read from
+	CreationPath string `json:"creation_path"`
+
+	// When the token was created and its lifetime at creation, in seconds
+	CreationTime time.Time `json:"creation_time"`
+	CreationTTL  int       `json:"creation_ttl"`
+
+	// Seconds left before the token expires
+	TTL int `json:"ttl"`
+
+	// When the token expires
+	ExpiresAt time.Time `json:"expires_at"`
+}
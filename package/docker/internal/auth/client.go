@@ -77,6 +77,9 @@ func (c *Client) RenewToken(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("failed to renew token: %w", err)
 	}
+	if authResp.Auth.ClientToken != "" {
+		c.config.Token = authResp.Auth.ClientToken
+	}
 
 	c.logger.WithFields(logrus.Fields{
 		"ttl":       authResp.Auth.LeaseDuration,
@@ -87,6 +90,20 @@ func (c *Client) RenewToken(ctx context.Context) error {
 	return nil
 }
 
+func (c *Client) RenewLease(ctx context.Context, leaseID string, increment int) (*vault.Lease, error) {
+	lease, err := c.vaultClient.RenewLease(ctx, leaseID, increment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew lease %s: %w", leaseID, err)
+	}
+
+	c.logger.WithFields(logrus.Fields{
+		"lease_id": lease.LeaseID,
+		"ttl":      lease.LeaseDuration,
+	}).Debug("Lease renewed successfully")
+
+	return lease, nil
+}
+
 func (c *Client) RevokeToken(ctx context.Context) error {
 	if c.config.Token == "" {
 		c.logger.Warn("No token to revoke")
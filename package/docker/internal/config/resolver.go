@@ -210,7 +210,18 @@ func (r *Resolver) RenewLeases(ctx context.Context, config *Configuration) error
 		return nil
 	}
 
-	// This would require extending the vault client to support lease renewal
-	r.logger.Info("Lease renewal not yet implemented")
+	// A zero increment extends the lease by its original TTL
+	lease, err := r.authClient.RenewLease(ctx, config.LeaseInfo.LeaseID, 0)
+	if err != nil {
+		return err
+	}
+
+	config.LeaseInfo.LeaseDuration = lease.LeaseDuration
+	config.LeaseInfo.Renewable = lease.Renewable
+	r.logger.WithFields(logrus.Fields{
+		"lease_id": lease.LeaseID,
+		"ttl":      lease.LeaseDuration,
+	}).Info("Lease renewed")
+
 	return nil
 }
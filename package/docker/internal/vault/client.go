@@ -36,6 +36,12 @@ type AuthResponse struct {
 	} `json:"auth"`
 }
 
+type Lease struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+}
+
 type HealthResponse struct {
 	Initialized                bool   `json:"initialized"`
 	Sealed                     bool   `json:"sealed"`
@@ -75,6 +81,7 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 
 	if c.token != "" {
 		req.Header.Set("X-Vault-Token", c.token)
+		req.Header.Set("Authorization", "Bearer "+c.token)
 	}
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
@@ -175,9 +182,38 @@ func (c *Client) RenewToken(ctx context.Context, ttl int) (*AuthResponse, error)
 		return nil, fmt.Errorf("failed to decode auth response: %w", err)
 	}
 
+	// Renewal may issue a new token in place of the current one
+	if authResp.Auth.ClientToken != "" {
+		c.token = authResp.Auth.ClientToken
+	}
+
 	return &authResp, nil
 }
 
+func (c *Client) RenewLease(ctx context.Context, leaseID string, increment int) (*Lease, error) {
+	body := map[string]interface{}{
+		"lease_id":  leaseID,
+		"increment": increment,
+	}
+
+	resp, err := c.doRequest(ctx, http.MethodPut, "/sys/leases/renew", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("lease renewal failed with status %d", resp.StatusCode)
+	}
+
+	var lease Lease
+	if err := json.NewDecoder(resp.Body).Decode(&lease); err != nil {
+		return nil, fmt.Errorf("failed to decode lease response: %w", err)
+	}
+
+	return &lease, nil
+}
+
 func (c *Client) RevokeToken(ctx context.Context) error {
 	path := "/auth/token/revoke-self"
 
@@ -27,6 +27,7 @@ package vault
 // - Identity & access management
 // - Policy enforcement
 // - Audit logging
+// - Transit encryption as a service
 // - Transport security (TLS/mTLS)
 // - Retry mechanisms and middleware
 //
@@ -29,24 +29,29 @@ type GenerateResponse struct {
 	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 }
 
+// VerifyRequest checks a TOTP code or a backup code. Window, in time steps
+// either side of now, can only narrow the server's drift window.
 type VerifyRequest struct {
-	Secret string `json:"secret"`
-	Token  string `json:"token"`
-	Window int    `json:"window,omitempty"`
+	Token  string `json:"code"`
+	Window *int   `json:"window,omitempty"`
 }
 
+// VerifyResponse reports the accepted code's time step (Counter) or whether
+// a backup code was used. A code is accepted once; after a failure,
+// LockedUntil is set if the entry got locked.
 type VerifyResponse struct {
-	Valid      bool       `json:"valid"`
-	Counter    int64      `json:"counter,omitempty"`
-	UsedBackup bool       `json:"used_backup,omitempty"`
-	LastUsed   *time.Time `json:"last_used,omitempty"`
-}
-
-type ValidateRequest struct {
-	Token  string `json:"token"`
-	Window int    `json:"window,omitempty"`
-}
-
+	Valid                bool       `json:"valid"`
+	Counter              int64      `json:"counter,omitempty"`
+	UsedBackup           bool       `json:"used_backup,omitempty"`
+	RemainingBackupCodes int        `json:"remaining_backup_codes"`
+	RemainingAttempts    int        `json:"remaining_attempts"`
+	LockedUntil          *time.Time `json:"locked_until,omitempty"`
+	LastUsed             *time.Time `json:"last_used,omitempty"`
+}
+
+// ValidateResponse is the short form of VerifyResponse: Remaining is the
+// number of failed attempts left before the entry is locked, and NextAt the
+// time it unlocks once it is.
 type ValidateResponse struct {
 	Valid     bool       `json:"valid"`
 	Remaining int        `json:"remaining"`
@@ -107,8 +112,10 @@ func (t *TOTPClient) Generate(ctx context.Context, req *GenerateRequest) (*Gener
 	return &generateResp, nil
 }
 
-func (t *TOTPClient) Verify(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
-	resp, err := t.client.Post(ctx, "/api/v1/totp/verify", req)
+// Verify checks a code against the entry id on the server. A wrong code
+// returns Valid false; a locked entry returns an error with status 429.
+func (t *TOTPClient) Verify(ctx context.Context, id string, req *VerifyRequest) (*VerifyResponse, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/totp/"+id+"/verify", req)
 	if err != nil {
 		return nil, err
 	}
@@ -121,25 +128,26 @@ func (t *TOTPClient) Verify(ctx context.Context, req *VerifyRequest) (*VerifyRes
 	return &verifyResp, nil
 }
 
-func (t *TOTPClient) Validate(ctx context.Context, token string, window ...int) (*ValidateResponse, error) {
-	req := &ValidateRequest{
+// Validate verifies token against the entry id, optionally within a
+// narrower window.
+func (t *TOTPClient) Validate(ctx context.Context, id, token string, window ...int) (*ValidateResponse, error) {
+	req := &VerifyRequest{
 		Token: token,
 	}
 	if len(window) > 0 {
-		req.Window = window[0]
+		req.Window = &window[0]
 	}
 
-	resp, err := t.client.Post(ctx, "/api/v1/totp/validate", req)
+	verifyResp, err := t.Verify(ctx, id, req)
 	if err != nil {
 		return nil, err
 	}
 
-	var validateResp ValidateResponse
-	if err := resp.Decode(&validateResp); err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode TOTP validate response")
-	}
-
-	return &validateResp, nil
+	return &ValidateResponse{
+		Valid:     verifyResp.Valid,
+		Remaining: verifyResp.RemainingAttempts,
+		NextAt:    verifyResp.LockedUntil,
+	}, nil
 }
 
 func (t *TOTPClient) Create(ctx context.Context, req *GenerateRequest) (*TOTPSecret, error) {
@@ -203,16 +211,20 @@ func (t *TOTPClient) List(ctx context.Context, req *ListRequest) (*ListResponse,
 	return &listResp, nil
 }
 
-func (t *TOTPClient) RegenerateBackupCodes(ctx context.Context, id string) (*TOTPSecret, error) {
-	resp, err := t.client.Post(ctx, "/api/v1/totp/secrets/"+id+"/regenerate-backup-codes", nil)
+// RegenerateBackupCodes replaces the entry's backup codes. The new codes are
+// only returned here; the server keeps their hashes.
+func (t *TOTPClient) RegenerateBackupCodes(ctx context.Context, id string) ([]string, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/totp/"+id+"/backup-codes", nil)
 	if err != nil {
 		return nil, err
 	}
 
-	var secret TOTPSecret
-	if err := resp.Decode(&secret); err != nil {
-		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode TOTP secret response")
+	var codesResp struct {
+		BackupCodes []string `json:"backup_codes"`
+	}
+	if err := resp.Decode(&codesResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode TOTP backup codes response")
 	}
 
-	return &secret, nil
+	return codesResp.BackupCodes, nil
 }
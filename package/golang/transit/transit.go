@@ -0,0 +1,338 @@
+package transit
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/skygenesisenterprise/aether-vault/package/golang/client"
+	"github.com/skygenesisenterprise/aether-vault/package/golang/errors"
+)
+
+// Key types supported by the transit engine.
+const (
+	KeyTypeAES256GCM96      = "aes256-gcm96"
+	KeyTypeChaCha20Poly1305 = "chacha20-poly1305"
+	KeyTypeEd25519          = "ed25519"
+	KeyTypeECDSAP256        = "ecdsa-p256"
+	KeyTypeECDSAP384        = "ecdsa-p384"
+	KeyTypeRSA2048          = "rsa-2048"
+	KeyTypeRSA3072          = "rsa-3072"
+	KeyTypeRSA4096          = "rsa-4096"
+)
+
+type KeyVersion struct {
+	Version   int       `json:"version"`
+	PublicKey string    `json:"public_key,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Key struct {
+	ID                   string       `json:"id"`
+	Name                 string       `json:"name"`
+	Type                 string       `json:"type"`
+	LatestVersion        int          `json:"latest_version"`
+	MinDecryptionVersion int          `json:"min_decryption_version"`
+	MinEncryptionVersion int          `json:"min_encryption_version"`
+	DeletionAllowed      bool         `json:"deletion_allowed"`
+	SupportsEncryption   bool         `json:"supports_encryption"`
+	SupportsSigning      bool         `json:"supports_signing"`
+	Versions             []KeyVersion `json:"versions,omitempty"`
+	CreatedAt            time.Time    `json:"created_at"`
+	UpdatedAt            time.Time    `json:"updated_at"`
+}
+
+// KeyConfig changes the fields that are set.
+type KeyConfig struct {
+	MinDecryptionVersion *int  `json:"min_decryption_version,omitempty"`
+	MinEncryptionVersion *int  `json:"min_encryption_version,omitempty"`
+	DeletionAllowed      *bool `json:"deletion_allowed,omitempty"`
+}
+
+// EncryptRequest encrypts Plaintext with the latest key version, or
+// KeyVersion. AssociatedData is authenticated but not encrypted, and must
+// be passed again to decrypt.
+type EncryptRequest struct {
+	Plaintext      []byte `json:"plaintext"`
+	AssociatedData []byte `json:"associated_data,omitempty"`
+	KeyVersion     int    `json:"key_version,omitempty"`
+}
+
+type EncryptResponse struct {
+	Ciphertext string `json:"ciphertext"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type DecryptRequest struct {
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData []byte `json:"associated_data,omitempty"`
+}
+
+// RewrapRequest re-encrypts Ciphertext with the latest key version, or
+// KeyVersion, without the plaintext leaving the vault.
+type RewrapRequest struct {
+	Ciphertext     string `json:"ciphertext"`
+	AssociatedData []byte `json:"associated_data,omitempty"`
+	KeyVersion     int    `json:"key_version,omitempty"`
+}
+
+// DataKeyRequest asks for a data key of Bits (128, 256 or 512, default
+// 256).
+type DataKeyRequest struct {
+	Bits           int    `json:"bits,omitempty"`
+	AssociatedData []byte `json:"associated_data,omitempty"`
+}
+
+// DataKey is a generated key. Ciphertext is the key encrypted with the
+// transit key; Plaintext is empty for wrapped data keys.
+type DataKey struct {
+	Plaintext  []byte `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// SignRequest signs Input. HashAlgorithm is sha2-256 (default), sha2-384
+// or sha2-512; SignatureAlgorithm is pss (default) or pkcs1v15 for RSA keys.
+type SignRequest struct {
+	Input              []byte `json:"input"`
+	HashAlgorithm      string `json:"hash_algorithm,omitempty"`
+	SignatureAlgorithm string `json:"signature_algorithm,omitempty"`
+	KeyVersion         int    `json:"key_version,omitempty"`
+}
+
+type SignResponse struct {
+	Signature  string `json:"signature"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type HMACRequest struct {
+	Input      []byte `json:"input"`
+	Algorithm  string `json:"algorithm,omitempty"`
+	KeyVersion int    `json:"key_version,omitempty"`
+}
+
+type HMACResponse struct {
+	HMAC       string `json:"hmac"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// VerifyRequest checks either a Signature or an HMAC of Input.
+type VerifyRequest struct {
+	Input              []byte `json:"input"`
+	Signature          string `json:"signature,omitempty"`
+	HMAC               string `json:"hmac,omitempty"`
+	HashAlgorithm      string `json:"hash_algorithm,omitempty"`
+	SignatureAlgorithm string `json:"signature_algorithm,omitempty"`
+}
+
+// TransitClient encrypts, signs and HMACs data with keys that stay in the
+// vault.
+type TransitClient struct {
+	client *client.Client
+}
+
+func NewTransitClient(client *client.Client) *TransitClient {
+	return &TransitClient{
+		client: client,
+	}
+}
+
+// CreateKey creates a named key; an empty keyType creates an aes256-gcm96
+// key.
+func (t *TransitClient) CreateKey(ctx context.Context, name, keyType string) (*Key, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/keys/"+url.PathEscape(name), map[string]string{"type": keyType})
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := resp.Decode(&key); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit key response")
+	}
+
+	return &key, nil
+}
+
+func (t *TransitClient) GetKey(ctx context.Context, name string) (*Key, error) {
+	resp, err := t.client.Get(ctx, "/api/v1/transit/keys/"+url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := resp.Decode(&key); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit key response")
+	}
+
+	return &key, nil
+}
+
+func (t *TransitClient) ListKeys(ctx context.Context) ([]Key, error) {
+	resp, err := t.client.Get(ctx, "/api/v1/transit/keys")
+	if err != nil {
+		return nil, err
+	}
+
+	var listResp struct {
+		Keys []Key `json:"keys"`
+	}
+	if err := resp.Decode(&listResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit key list response")
+	}
+
+	return listResp.Keys, nil
+}
+
+func (t *TransitClient) ConfigureKey(ctx context.Context, name string, config *KeyConfig) (*Key, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/keys/"+url.PathEscape(name)+"/config", config)
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := resp.Decode(&key); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit key response")
+	}
+
+	return &key, nil
+}
+
+func (t *TransitClient) RotateKey(ctx context.Context, name string) (*Key, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/keys/"+url.PathEscape(name)+"/rotate", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := resp.Decode(&key); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit key response")
+	}
+
+	return &key, nil
+}
+
+// DeleteKey deletes a key whose configuration allows deletion.
+func (t *TransitClient) DeleteKey(ctx context.Context, name string) error {
+	_, err := t.client.Delete(ctx, "/api/v1/transit/keys/"+url.PathEscape(name))
+	return err
+}
+
+func (t *TransitClient) Encrypt(ctx context.Context, name string, req *EncryptRequest) (*EncryptResponse, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/encrypt/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var encryptResp EncryptResponse
+	if err := resp.Decode(&encryptResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit encrypt response")
+	}
+
+	return &encryptResp, nil
+}
+
+func (t *TransitClient) Decrypt(ctx context.Context, name string, req *DecryptRequest) ([]byte, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/decrypt/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var decryptResp struct {
+		Plaintext []byte `json:"plaintext"`
+	}
+	if err := resp.Decode(&decryptResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit decrypt response")
+	}
+
+	return decryptResp.Plaintext, nil
+}
+
+func (t *TransitClient) Rewrap(ctx context.Context, name string, req *RewrapRequest) (*EncryptResponse, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/rewrap/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var rewrapResp EncryptResponse
+	if err := resp.Decode(&rewrapResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit rewrap response")
+	}
+
+	return &rewrapResp, nil
+}
+
+// GenerateDataKey returns a new data key both in plaintext, to encrypt data
+// locally, and encrypted, to store next to that data.
+func (t *TransitClient) GenerateDataKey(ctx context.Context, name string, req *DataKeyRequest) (*DataKey, error) {
+	return t.generateDataKey(ctx, "plaintext", name, req)
+}
+
+// GenerateWrappedDataKey returns a new data key only in encrypted form, to be
+// decrypted with Decrypt when it is needed.
+func (t *TransitClient) GenerateWrappedDataKey(ctx context.Context, name string, req *DataKeyRequest) (*DataKey, error) {
+	return t.generateDataKey(ctx, "wrapped", name, req)
+}
+
+func (t *TransitClient) generateDataKey(ctx context.Context, kind, name string, req *DataKeyRequest) (*DataKey, error) {
+	if req == nil {
+		req = &DataKeyRequest{}
+	}
+
+	resp, err := t.client.Post(ctx, "/api/v1/transit/datakey/"+kind+"/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var dataKey DataKey
+	if err := resp.Decode(&dataKey); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit data key response")
+	}
+
+	return &dataKey, nil
+}
+
+func (t *TransitClient) Sign(ctx context.Context, name string, req *SignRequest) (*SignResponse, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/sign/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var signResp SignResponse
+	if err := resp.Decode(&signResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit sign response")
+	}
+
+	return &signResp, nil
+}
+
+// Verify reports whether a signature or HMAC matches; a mismatch is not an
+// error.
+func (t *TransitClient) Verify(ctx context.Context, name string, req *VerifyRequest) (bool, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/verify/"+url.PathEscape(name), req)
+	if err != nil {
+		return false, err
+	}
+
+	var verifyResp struct {
+		Valid bool `json:"valid"`
+	}
+	if err := resp.Decode(&verifyResp); err != nil {
+		return false, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit verify response")
+	}
+
+	return verifyResp.Valid, nil
+}
+
+func (t *TransitClient) HMAC(ctx context.Context, name string, req *HMACRequest) (*HMACResponse, error) {
+	resp, err := t.client.Post(ctx, "/api/v1/transit/hmac/"+url.PathEscape(name), req)
+	if err != nil {
+		return nil, err
+	}
+
+	var hmacResp HMACResponse
+	if err := resp.Decode(&hmacResp); err != nil {
+		return nil, errors.WrapError(err, errors.ErrCodeInternal, "failed to decode transit HMAC response")
+	}
+
+	return &hmacResp, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/skygenesisenterprise/aether-vault/package/golang/policies"
 	"github.com/skygenesisenterprise/aether-vault/package/golang/secrets"
 	"github.com/skygenesisenterprise/aether-vault/package/golang/totp"
+	"github.com/skygenesisenterprise/aether-vault/package/golang/transit"
 )
 
 type Vault struct {
@@ -25,6 +26,7 @@ type Vault struct {
 	Identity *identity.IdentityClient
 	Policies *policies.PolicyClient
 	Audit    *audit.AuditClient
+	Transit  *transit.TransitClient
 }
 
 type Config struct {
@@ -78,6 +80,7 @@ func New(cfg *Config) (*Vault, error) {
 		Identity: identity.NewIdentityClient(client),
 		Policies: policies.NewPolicyClient(client),
 		Audit:    audit.NewAuditClient(client),
+		Transit:  transit.NewTransitClient(client),
 	}
 
 	return vault, nil
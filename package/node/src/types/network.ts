@@ -82,8 +82,26 @@ export interface Network {
  * Network object that includes protocol configuration.
  */
 export interface NetworkWithConfig extends Network {
-  /** Protocol configuration details */
+  /** Protocol configuration details, without password or private key */
   config?: ProtocolConfig;
+
+  /** Whether credentials are stored for the network */
+  hasCredentials?: boolean;
+
+  /** Seconds between health checks; 0 uses the server default */
+  checkInterval?: number;
+
+  /** Failed checks in a row before the network goes inactive; 0 uses the server default */
+  failureThreshold?: number;
+
+  /** Failed checks in a row so far */
+  consecutiveFailures?: number;
+
+  /** Last health check timestamp (optional) */
+  lastCheckAt?: string | null;
+
+  /** Expiry of the HTTPS certificate seen by the last check (optional) */
+  certificateExpiresAt?: string | null;
 }
 
 /**
@@ -99,6 +117,12 @@ export interface CreateNetworkRequest {
 
   /** Protocol configuration */
   config?: ProtocolConfig;
+
+  /** Seconds between health checks (optional) */
+  checkInterval?: number;
+
+  /** Failed checks in a row before the network goes inactive (optional) */
+  failureThreshold?: number;
 }
 
 /**
@@ -112,8 +136,17 @@ export interface UpdateNetworkRequest {
   /** New protocol type (optional) */
   type?: ProtocolType;
 
-  /** New protocol configuration (optional) */
+  /** New protocol configuration (optional); omitted credentials are kept */
   config?: ProtocolConfig;
+
+  /** Delete the stored credentials (optional) */
+  clearCredentials?: boolean;
+
+  /** Seconds between health checks (optional) */
+  checkInterval?: number;
+
+  /** Failed checks in a row before the network goes inactive (optional) */
+  failureThreshold?: number;
 }
 
 /**
@@ -189,6 +222,9 @@ export interface ProtocolStatus {
 
   /** Connection latency in milliseconds (optional) */
   latency?: number;
+
+  /** HTTPS certificate expiry (optional) */
+  certificateExpiresAt?: string;
 }
 
 /**
@@ -199,8 +235,11 @@ export interface ProtocolTestRequest {
   /** Protocol type to test */
   type: ProtocolType;
 
-  /** Protocol configuration for testing */
-  config: ProtocolConfig;
+  /** Protocol configuration for testing (optional with networkId) */
+  config?: ProtocolConfig;
+
+  /** Stored network to test, with its stored credentials (optional) */
+  networkId?: number;
 }
 
 /**
@@ -219,6 +258,98 @@ export interface ProtocolTestResponse {
 
   /** Additional test details (optional) */
   details?: Record<string, unknown>;
+
+  /** HTTPS certificate expiry (optional) */
+  certificateExpiresAt?: string;
+}
+
+/**
+ * Network check interface.
+ * One recorded health check of a network.
+ */
+export interface NetworkCheck {
+  /** Check identifier */
+  id: number;
+
+  /** Checked network */
+  networkId: number;
+
+  /** Whether the check succeeded */
+  success: boolean;
+
+  /** Status reported by the check */
+  status: string;
+
+  /** Check latency in milliseconds */
+  latency: number;
+
+  /** Check result message (optional) */
+  message?: string;
+
+  /** HTTPS certificate expiry (optional) */
+  certificateExpiresAt?: string;
+
+  /** Check timestamp */
+  checkedAt: string;
+}
+
+/**
+ * Network alert event type.
+ */
+export type NetworkAlertEvent =
+  | "network_down"
+  | "network_recovered"
+  | "certificate_expiring";
+
+/**
+ * Network alert interface.
+ * Raised by the health monitor.
+ */
+export interface NetworkAlert {
+  /** Alert identifier */
+  id: number;
+
+  /** Network the alert is about */
+  networkId: number;
+
+  /** Network name when the alert was raised */
+  networkName: string;
+
+  /** Alert event */
+  event: NetworkAlertEvent;
+
+  /** Alert message */
+  message: string;
+
+  /** Alert timestamp */
+  createdAt: string;
+}
+
+/**
+ * Network uptime response interface.
+ * Summary of a network's checks over a window.
+ */
+export interface NetworkUptimeResponse {
+  /** Network identifier */
+  networkId: number;
+
+  /** Window in seconds */
+  window: number;
+
+  /** Checks in the window */
+  checks: number;
+
+  /** Successful checks in the window */
+  successful: number;
+
+  /** Uptime percentage, null without checks */
+  uptimePercent: number | null;
+
+  /** Average latency of successful checks in milliseconds */
+  averageLatency: number;
+
+  /** Last health check timestamp */
+  lastCheckAt: string | null;
 }
 
 /**
@@ -3,15 +3,14 @@ package main
 import (
 	"fmt"
 	"github.com/skygenesisenterprise/aether-vault/server/src/config"
-	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/routes"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"github.com/skygenesisenterprise/aether-vault/server/src/storage"
 	"log"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -31,12 +30,24 @@ func main() {
 	var secretService *services.SecretService
 	var totpService *services.TOTPService
 	var policyService *services.PolicyService
+	var keyringService *services.KeyringService
+	var sealService *services.SealService
+	var mfaService *services.MFAService
+	var sessionService *services.SessionService
+	var groupService *services.GroupService
+	var teamService *services.TeamService
+	var wrappingService *services.WrappingService
+	var leaseService *services.LeaseService
+	var databaseService *services.DatabaseService
+	var pkiService *services.PKIService
+	var sshService *services.SSHService
+	var transitService *services.TransitService
 	var networkService *services.NetworkService
 	var snmpService *services.SNMPService
 
 	// Initialize database if available (optional in development)
-	if cfg.Server.Environment == "production" || (cfg.Database.Host != "" && cfg.Database.User != "") {
-		db, err = initDatabase(cfg.Database)
+	if cfg.Database.Driver == storage.DriverSQLite || cfg.Server.Environment == "production" || (cfg.Database.Host != "" && cfg.Database.User != "") {
+		db, err = storage.Open(cfg.Database)
 		if err != nil {
 			if cfg.Server.Environment == "production" {
 				log.Fatalf("Failed to initialize database in production: %v", err)
@@ -47,7 +58,7 @@ func main() {
 		}
 
 		if db != nil {
-			if err := migrateDatabase(db); err != nil {
+			if err := storage.Migrate(db); err != nil {
 				if cfg.Server.Environment == "production" {
 					log.Fatalf("Failed to migrate database in production: %v", err)
 				} else {
@@ -62,26 +73,88 @@ func main() {
 	if db != nil {
 		// Full database-backed services
 		userService = services.NewUserService(db)
-		auditService = services.NewAuditService(db)
-		secretService = services.NewSecretService(db, cfg.Security.EncryptionKey, "default-salt", cfg.Security.KDFIterations, auditService)
-		totpService = services.NewTOTPService(db, auditService)
-		policyService = services.NewPolicyService(db)
-		networkService = services.NewNetworkService(db)
+		groupService = services.NewGroupService(db)
+		teamService = services.NewTeamService(db)
+		admin, err := userService.EnsureAdmin(cfg.Bootstrap.AdminEmail, cfg.Bootstrap.AdminPassword, cfg.Bootstrap.PromoteExisting)
+		if err != nil {
+			log.Printf("⚠️  Failed to bootstrap administrator: %v", err)
+		} else if admin != nil {
+			log.Printf("👤 Bootstrapped administrator %s", admin.Email)
+		}
+		// The audit chain key falls back to the encryption key; set a
+		// dedicated one so rotating the encryption key keeps the chain valid.
+		auditKey := cfg.Audit.HMACKey
+		if auditKey == "" {
+			auditKey = cfg.Security.EncryptionKey
+		}
+		auditService = services.NewAuditService(db, auditKey, cfg.Audit.SigningKey, cfg.Audit.RetentionDays)
+		if chained, err := auditService.EnsureChain(); err != nil {
+			log.Printf("⚠️  Failed to chain existing audit logs: %v", err)
+		} else if chained > 0 {
+			log.Printf("🔗 Chained %d existing audit log entries", chained)
+		}
+		auditService.StartCheckpointJob(time.Duration(cfg.Audit.CheckpointInterval) * time.Second)
+		auditService.StartRetentionJob(time.Duration(cfg.Audit.RetentionInterval) * time.Second)
+		keyringService = services.NewKeyringService(db, cfg.Security.KDFIterations, cfg.Security.EncryptionKey, cfg.Security.PreviousEncryptionKey)
+		sealService = services.NewSealService(db, keyringService)
+		if err := sealService.Load(); err != nil {
+			log.Fatalf("Failed to load seal configuration: %v", err)
+		}
+		secretService = services.NewSecretService(db, keyringService, cfg.Security.EncryptionKey, "default-salt", cfg.Security.KDFIterations, cfg.Secrets.MaxVersions, auditService)
+		totpService = services.NewTOTPService(db, keyringService, &cfg.TOTP, auditService)
+		mfaService = services.NewMFAService(db, keyringService, auditService)
+		sessionService = services.NewSessionService(db, time.Duration(cfg.Session.IdleTimeout)*time.Second)
+		sessionService.StartSweeper(time.Duration(cfg.Session.SweepInterval) * time.Second)
+		leaseService = services.NewLeaseService(db, time.Duration(cfg.Lease.MaxTTL)*time.Second, auditService)
+		wrappingService = services.NewWrappingService(db, keyringService, leaseService, time.Duration(cfg.Wrapping.MaxTTL)*time.Second)
+		databaseService = services.NewDatabaseService(db, keyringService, leaseService, cfg.DBEngine.SQLiteDir)
+		pkiService = services.NewPKIService(db, keyringService, cfg.PKI.BaseURL, time.Duration(cfg.PKI.CRLExpiry)*time.Second)
+		sshService = services.NewSSHService(db, keyringService)
+		transitService = services.NewTransitService(db, keyringService)
+		policyService = services.NewPolicyService(db, &cfg.Policy)
+		if admin != nil {
+			// Permissive mode keeps the secrets engines closed, so the first
+			// administrator gets an explicit grant.
+			if _, err := policyService.EnsureAdminPolicy(admin); err != nil {
+				log.Printf("⚠️  Failed to assign the %s policy: %v", services.BootstrapPolicyName, err)
+			}
+		}
+		networkService = services.NewNetworkService(db, secretService, &cfg.Network, auditService)
 		snmpService = services.NewSNMPService()
+		if err := secretService.EnsureVersionHistory(); err != nil {
+			log.Printf("⚠️  Failed to backfill secret version history: %v", err)
+		}
+		var expiryNotifier services.ExpiryNotifier
+		if cfg.Secrets.ExpiryWebhookURL != "" {
+			expiryNotifier = services.NewWebhookNotifier(cfg.Secrets.ExpiryWebhookURL, 10*time.Second)
+		}
+		secretService.StartExpiryJob(time.Duration(cfg.Secrets.ExpiryInterval)*time.Second, time.Duration(cfg.Secrets.ExpiryWarning)*time.Second, cfg.Secrets.ExpiryAction, expiryNotifier)
+		// Every engine registers its lease revoker when created, so expired
+		// leases found on start can be revoked right away.
+		leaseService.StartExpiryJob(time.Duration(cfg.Lease.CheckInterval) * time.Second)
+		var networkNotifier services.NetworkAlertNotifier
+		if cfg.Network.AlertWebhookURL != "" {
+			networkNotifier = services.NewWebhookNotifier(cfg.Network.AlertWebhookURL, 10*time.Second)
+		}
+		networkService.StartMonitor(time.Duration(cfg.Network.MonitorInterval)*time.Second, networkNotifier)
+		keyringService.StartRewrapJob(time.Duration(cfg.Security.RewrapInterval)*time.Second, cfg.Security.RewrapBatchSize, secretService, totpService, mfaService, databaseService, pkiService, sshService, transitService)
 		log.Printf("✅ Database-backed services initialized")
 	} else {
 		// Mock services for development
 		log.Printf("🔧 Initializing mock services for development")
-		networkService = services.NewNetworkService(nil)
+		networkService = services.NewNetworkService(nil, nil, &cfg.Network, nil)
 		snmpService = services.NewSNMPService()
 		// We'll need to create mock services - for now, let's create nil services
 		// and handle this in the routes/controllers
 	}
 
 	// Always initialize auth service (can work with mock user service)
-	authService := services.NewAuthService(userService, &cfg.JWT)
+	authService := services.NewAuthService(db, userService, mfaService, sessionService, &cfg.JWT)
+	if db != nil {
+		authService.StartCleanupJob(time.Hour)
+	}
 
-	router := routes.NewRouter(db, authService, secretService, totpService, userService, policyService, auditService, networkService, snmpService)
+	router := routes.NewRouter(db, authService, secretService, totpService, userService, policyService, auditService, networkService, snmpService, keyringService, sealService, mfaService, sessionService, groupService, teamService, wrappingService, leaseService, databaseService, pkiService, sshService, transitService)
 	router.SetupRoutes()
 
 	server := &http.Server{
@@ -94,50 +167,23 @@ func main() {
 	log.Printf("Aether Vault API server starting on %s:%d", cfg.Server.Host, cfg.Server.Port)
 	log.Printf("Environment: %s", cfg.Server.Environment)
 
-	if db != nil {
+	if db != nil && cfg.Database.Driver == storage.DriverSQLite {
+		log.Printf("Database: sqlite %s (connected)", cfg.Database.Path)
+	} else if db != nil {
 		log.Printf("Database: %s:%d/%s (connected)", cfg.Database.Host, cfg.Database.Port, cfg.Database.DBName)
 	} else {
 		log.Printf("Database: not connected (development mode)")
 	}
 
-	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("Failed to start server: %v", err)
-	}
-}
-
-func initDatabase(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
-		dbConfig.Host,
-		dbConfig.User,
-		dbConfig.Password,
-		dbConfig.DBName,
-		dbConfig.Port,
-		dbConfig.SSLMode,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	if sealService != nil {
+		if sealService.Status().Initialized {
+			log.Printf("🔒 Vault is sealed, provide unseal keys with 'vault operator unseal'")
+		} else {
+			log.Printf("🔒 Vault is not initialized, run 'vault operator init'")
+		}
 	}
 
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		log.Fatalf("Failed to start server: %v", err)
 	}
-
-	sqlDB.SetMaxIdleConns(10)
-	sqlDB.SetMaxOpenConns(100)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-
-	return db, nil
-}
-
-func migrateDatabase(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&model.User{},
-		&model.Secret{},
-		&model.TOTP{},
-		&model.Policy{},
-		&model.AuditLog{},
-	)
 }
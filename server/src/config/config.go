@@ -9,11 +9,21 @@ import (
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Security SecurityConfig `mapstructure:"security"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Audit    AuditConfig    `mapstructure:"audit"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Audit     AuditConfig     `mapstructure:"audit"`
+	Secrets   SecretsConfig   `mapstructure:"secrets"`
+	Policy    PolicyConfig    `mapstructure:"policy"`
+	Session   SessionConfig   `mapstructure:"session"`
+	Wrapping  WrappingConfig  `mapstructure:"wrapping"`
+	Lease     LeaseConfig     `mapstructure:"lease"`
+	DBEngine  DBEngineConfig  `mapstructure:"database_engine"`
+	PKI       PKIConfig       `mapstructure:"pki"`
+	TOTP      TOTPConfig      `mapstructure:"totp"`
+	Network   NetworkConfig   `mapstructure:"network"`
+	Bootstrap BootstrapConfig `mapstructure:"bootstrap"`
 }
 
 type ServerConfig struct {
@@ -24,7 +34,11 @@ type ServerConfig struct {
 	WriteTimeout int    `mapstructure:"write_timeout"`
 }
 
+// DatabaseConfig selects the storage backend: "postgres" uses the connection
+// settings, "sqlite" keeps everything in the file at Path.
 type DatabaseConfig struct {
+	Driver   string `mapstructure:"driver"`
+	Path     string `mapstructure:"path"`
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	User     string `mapstructure:"user"`
@@ -34,20 +48,110 @@ type DatabaseConfig struct {
 }
 
 type SecurityConfig struct {
-	EncryptionKey string `mapstructure:"encryption_key"`
-	KDFIterations int    `mapstructure:"kdf_iterations"`
-	SaltLength    int    `mapstructure:"salt_length"`
+	EncryptionKey         string `mapstructure:"encryption_key"`
+	PreviousEncryptionKey string `mapstructure:"previous_encryption_key"`
+	KDFIterations         int    `mapstructure:"kdf_iterations"`
+	SaltLength            int    `mapstructure:"salt_length"`
+	RewrapInterval        int    `mapstructure:"rewrap_interval"`
+	RewrapBatchSize       int    `mapstructure:"rewrap_batch_size"`
 }
 
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	Expiration int    `mapstructure:"expiration"`
+	Secret            string `mapstructure:"secret"`
+	Expiration        int    `mapstructure:"expiration"`
+	RefreshExpiration int    `mapstructure:"refresh_expiration"`
+	MFAExpiration     int    `mapstructure:"mfa_expiration"`
 }
 
 type AuditConfig struct {
-	Enabled   bool   `mapstructure:"enabled"`
-	LogLevel  string `mapstructure:"log_level"`
-	LogFormat string `mapstructure:"log_format"`
+	Enabled            bool   `mapstructure:"enabled"`
+	LogLevel           string `mapstructure:"log_level"`
+	LogFormat          string `mapstructure:"log_format"`
+	HMACKey            string `mapstructure:"hmac_key"`
+	SigningKey         string `mapstructure:"signing_key"`
+	CheckpointInterval int    `mapstructure:"checkpoint_interval"`
+	RetentionDays      int    `mapstructure:"retention_days"`
+	RetentionInterval  int    `mapstructure:"retention_interval"`
+}
+
+type SecretsConfig struct {
+	MaxVersions      int    `mapstructure:"max_versions"`
+	ExpiryAction     string `mapstructure:"expiry_action"`
+	ExpiryWarning    int    `mapstructure:"expiry_warning"`
+	ExpiryInterval   int    `mapstructure:"expiry_interval"`
+	ExpiryWebhookURL string `mapstructure:"expiry_webhook_url"`
+}
+
+type PolicyConfig struct {
+	Mode string `mapstructure:"mode"`
+}
+
+// BootstrapConfig names the first administrator, created at startup while
+// no active admin exists. An existing account with that email is only
+// promoted when PromoteExisting is set.
+type BootstrapConfig struct {
+	AdminEmail      string `mapstructure:"admin_email"`
+	AdminPassword   string `mapstructure:"admin_password"`
+	PromoteExisting bool   `mapstructure:"promote_existing"`
+}
+
+type SessionConfig struct {
+	IdleTimeout   int `mapstructure:"idle_timeout"`
+	SweepInterval int `mapstructure:"sweep_interval"`
+}
+
+// WrappingConfig bounds response-wrapping tokens; MaxTTL caps the TTL a
+// client may request, in seconds.
+type WrappingConfig struct {
+	MaxTTL int `mapstructure:"max_ttl"`
+}
+
+// LeaseConfig caps the lifetime of every lease, renewals included, and sets
+// the longest the expiry scheduler sleeps between checks, in seconds.
+type LeaseConfig struct {
+	MaxTTL        int `mapstructure:"max_ttl"`
+	CheckInterval int `mapstructure:"check_interval"`
+}
+
+// DBEngineConfig governs the database secrets engine. SQLite connections
+// are files on this host, so they are only allowed inside SQLiteDir and are
+// refused while it is empty.
+type DBEngineConfig struct {
+	SQLiteDir string `mapstructure:"sqlite_dir"`
+}
+
+// PKIConfig sets the external address written into issued certificates as
+// their CA, CRL and OCSP locations, and how long a published CRL is valid,
+// in seconds.
+type PKIConfig struct {
+	BaseURL   string `mapstructure:"base_url"`
+	CRLExpiry int    `mapstructure:"crl_expiry"`
+}
+
+// TOTPConfig governs verification of stored TOTP entries: Skew is the
+// widest drift window in time steps either side of now, and an entry is
+// locked for LockoutDuration seconds after MaxFailedAttempts failures in a
+// row. BackupCodes is how many single-use codes an entry gets.
+type TOTPConfig struct {
+	Skew              int `mapstructure:"skew"`
+	MaxFailedAttempts int `mapstructure:"max_failed_attempts"`
+	LockoutDuration   int `mapstructure:"lockout_duration"`
+	BackupCodes       int `mapstructure:"backup_codes"`
+}
+
+// NetworkConfig drives the network health monitor. It wakes up every
+// MonitorInterval seconds and probes the networks due for a check, every
+// CheckInterval seconds unless a network sets its own. A network goes
+// inactive after FailureThreshold failed checks in a row. Checks and alerts
+// are kept for HistoryRetention seconds, and HTTPS certificates expiring
+// within CertificateWarning seconds raise an alert.
+type NetworkConfig struct {
+	MonitorInterval    int    `mapstructure:"monitor_interval"`
+	CheckInterval      int    `mapstructure:"check_interval"`
+	FailureThreshold   int    `mapstructure:"failure_threshold"`
+	HistoryRetention   int    `mapstructure:"history_retention"`
+	CertificateWarning int    `mapstructure:"certificate_warning"`
+	AlertWebhookURL    string `mapstructure:"alert_webhook_url"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -66,11 +170,56 @@ func LoadConfig() (*Config, error) {
 	viper.AutomaticEnv()
 	viper.SetEnvPrefix("VAULT")
 	// Bind environment variables explicitly to ensure proper mapping
+	viper.BindEnv("database.driver", "VAULT_DATABASE_DRIVER")
+	viper.BindEnv("database.path", "VAULT_DATABASE_PATH")
+	viper.BindEnv("database.host", "VAULT_DATABASE_HOST")
+	viper.BindEnv("database.port", "VAULT_DATABASE_PORT")
+	viper.BindEnv("database.user", "VAULT_DATABASE_USER")
+	viper.BindEnv("database.password", "VAULT_DATABASE_PASSWORD")
+	viper.BindEnv("database.dbname", "VAULT_DATABASE_DBNAME")
+	viper.BindEnv("database.sslmode", "VAULT_DATABASE_SSLMODE")
 	viper.BindEnv("jwt.secret", "VAULT_JWT_SECRET")
 	viper.BindEnv("jwt.expiration", "VAULT_JWT_EXPIRATION")
+	viper.BindEnv("jwt.refresh_expiration", "VAULT_JWT_REFRESH_EXPIRATION")
+	viper.BindEnv("jwt.mfa_expiration", "VAULT_JWT_MFA_EXPIRATION")
 	viper.BindEnv("security.encryption_key", "VAULT_SECURITY_ENCRYPTION_KEY")
 	viper.BindEnv("security.kdf_iterations", "VAULT_SECURITY_KDF_ITERATIONS")
 	viper.BindEnv("security.salt_length", "VAULT_SECURITY_SALT_LENGTH")
+	viper.BindEnv("security.previous_encryption_key", "VAULT_SECURITY_PREVIOUS_ENCRYPTION_KEY")
+	viper.BindEnv("security.rewrap_interval", "VAULT_SECURITY_REWRAP_INTERVAL")
+	viper.BindEnv("security.rewrap_batch_size", "VAULT_SECURITY_REWRAP_BATCH_SIZE")
+	viper.BindEnv("secrets.max_versions", "VAULT_SECRETS_MAX_VERSIONS")
+	viper.BindEnv("secrets.expiry_action", "VAULT_SECRETS_EXPIRY_ACTION")
+	viper.BindEnv("secrets.expiry_warning", "VAULT_SECRETS_EXPIRY_WARNING")
+	viper.BindEnv("secrets.expiry_interval", "VAULT_SECRETS_EXPIRY_INTERVAL")
+	viper.BindEnv("secrets.expiry_webhook_url", "VAULT_SECRETS_EXPIRY_WEBHOOK_URL")
+	viper.BindEnv("audit.hmac_key", "VAULT_AUDIT_HMAC_KEY")
+	viper.BindEnv("audit.signing_key", "VAULT_AUDIT_SIGNING_KEY")
+	viper.BindEnv("audit.checkpoint_interval", "VAULT_AUDIT_CHECKPOINT_INTERVAL")
+	viper.BindEnv("audit.retention_days", "VAULT_AUDIT_RETENTION_DAYS")
+	viper.BindEnv("audit.retention_interval", "VAULT_AUDIT_RETENTION_INTERVAL")
+	viper.BindEnv("policy.mode", "VAULT_POLICY_MODE")
+	viper.BindEnv("session.idle_timeout", "VAULT_SESSION_IDLE_TIMEOUT")
+	viper.BindEnv("session.sweep_interval", "VAULT_SESSION_SWEEP_INTERVAL")
+	viper.BindEnv("wrapping.max_ttl", "VAULT_WRAPPING_MAX_TTL")
+	viper.BindEnv("lease.max_ttl", "VAULT_LEASE_MAX_TTL")
+	viper.BindEnv("lease.check_interval", "VAULT_LEASE_CHECK_INTERVAL")
+	viper.BindEnv("database_engine.sqlite_dir", "VAULT_DATABASE_ENGINE_SQLITE_DIR")
+	viper.BindEnv("pki.base_url", "VAULT_PKI_BASE_URL")
+	viper.BindEnv("pki.crl_expiry", "VAULT_PKI_CRL_EXPIRY")
+	viper.BindEnv("totp.skew", "VAULT_TOTP_SKEW")
+	viper.BindEnv("totp.max_failed_attempts", "VAULT_TOTP_MAX_FAILED_ATTEMPTS")
+	viper.BindEnv("totp.lockout_duration", "VAULT_TOTP_LOCKOUT_DURATION")
+	viper.BindEnv("totp.backup_codes", "VAULT_TOTP_BACKUP_CODES")
+	viper.BindEnv("network.monitor_interval", "VAULT_NETWORK_MONITOR_INTERVAL")
+	viper.BindEnv("network.check_interval", "VAULT_NETWORK_CHECK_INTERVAL")
+	viper.BindEnv("network.failure_threshold", "VAULT_NETWORK_FAILURE_THRESHOLD")
+	viper.BindEnv("network.history_retention", "VAULT_NETWORK_HISTORY_RETENTION")
+	viper.BindEnv("network.certificate_warning", "VAULT_NETWORK_CERTIFICATE_WARNING")
+	viper.BindEnv("network.alert_webhook_url", "VAULT_NETWORK_ALERT_WEBHOOK_URL")
+	viper.BindEnv("bootstrap.admin_email", "VAULT_BOOTSTRAP_ADMIN_EMAIL")
+	viper.BindEnv("bootstrap.admin_password", "VAULT_BOOTSTRAP_ADMIN_PASSWORD")
+	viper.BindEnv("bootstrap.promote_existing", "VAULT_BOOTSTRAP_PROMOTE_EXISTING")
 
 	setDefaults()
 
@@ -104,6 +253,8 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", 30)
 	viper.SetDefault("server.write_timeout", 30)
 
+	viper.SetDefault("database.driver", "postgres")
+	viper.SetDefault("database.path", "vault.db")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "vault")
@@ -112,12 +263,49 @@ func setDefaults() {
 
 	viper.SetDefault("security.kdf_iterations", 100000)
 	viper.SetDefault("security.salt_length", 32)
+	viper.SetDefault("security.rewrap_interval", 300)
+	viper.SetDefault("security.rewrap_batch_size", 100)
 
 	viper.SetDefault("jwt.expiration", 3600)
+	viper.SetDefault("jwt.refresh_expiration", 604800)
+	viper.SetDefault("jwt.mfa_expiration", 300)
 
 	viper.SetDefault("audit.enabled", true)
 	viper.SetDefault("audit.log_level", "info")
 	viper.SetDefault("audit.log_format", "json")
+	viper.SetDefault("audit.checkpoint_interval", 3600)
+	viper.SetDefault("audit.retention_days", 0)
+	viper.SetDefault("audit.retention_interval", 3600)
+
+	viper.SetDefault("secrets.max_versions", 10)
+	viper.SetDefault("secrets.expiry_action", "deactivate")
+	viper.SetDefault("secrets.expiry_warning", 604800)
+	viper.SetDefault("secrets.expiry_interval", 300)
+
+	viper.SetDefault("policy.mode", "permissive")
+
+	viper.SetDefault("session.idle_timeout", 3600)
+	viper.SetDefault("session.sweep_interval", 300)
+
+	viper.SetDefault("wrapping.max_ttl", 86400)
+
+	viper.SetDefault("lease.max_ttl", 2592000)
+	viper.SetDefault("lease.check_interval", 60)
+
+	viper.SetDefault("pki.crl_expiry", 259200)
+
+	viper.SetDefault("totp.skew", 1)
+	viper.SetDefault("totp.max_failed_attempts", 5)
+	viper.SetDefault("totp.lockout_duration", 300)
+	viper.SetDefault("totp.backup_codes", 10)
+
+	viper.SetDefault("network.monitor_interval", 10)
+	viper.SetDefault("network.check_interval", 60)
+	viper.SetDefault("network.failure_threshold", 3)
+	viper.SetDefault("network.history_retention", 604800)
+	viper.SetDefault("network.certificate_warning", 1209600)
+
+	viper.SetDefault("bootstrap.promote_existing", false)
 }
 
 func validateConfig(config *Config) {
@@ -125,8 +313,16 @@ func validateConfig(config *Config) {
 		panic("Invalid server port")
 	}
 
+	if config.Database.Driver != "postgres" && config.Database.Driver != "sqlite" {
+		panic("Database driver must be either postgres or sqlite")
+	}
+
+	if config.Database.Driver == "sqlite" && config.Database.Path == "" {
+		panic("Database path is required for sqlite")
+	}
+
 	// Only require database in production
-	if config.Server.Environment == "production" {
+	if config.Server.Environment == "production" && config.Database.Driver == "postgres" {
 		if config.Database.Host == "" {
 			panic("Database host is required in production")
 		}
@@ -144,9 +340,83 @@ func validateConfig(config *Config) {
 		panic("JWT secret is required")
 	}
 
+	if config.JWT.RefreshExpiration <= 0 || config.JWT.MFAExpiration <= 0 {
+		panic("JWT refresh_expiration and mfa_expiration must be positive")
+	}
+
 	if config.Security.EncryptionKey == "" {
 		panic("Encryption key is required")
 	}
+
+	if config.Security.RewrapInterval <= 0 || config.Security.RewrapBatchSize <= 0 {
+		panic("Security rewrap_interval and rewrap_batch_size must be positive")
+	}
+
+	// Checkpoints must not be signed with a key derivable from the chain key,
+	// or anyone able to rewrite the chain could re-sign it too.
+	if config.Audit.SigningKey == "" {
+		panic("Audit signing key is required")
+	}
+
+	if config.Audit.SigningKey == config.Audit.HMACKey || config.Audit.SigningKey == config.Security.EncryptionKey {
+		panic("Audit signing key must differ from the audit HMAC key and the encryption key")
+	}
+
+	if config.Audit.CheckpointInterval <= 0 || config.Audit.RetentionInterval <= 0 {
+		panic("Audit checkpoint_interval and retention_interval must be positive")
+	}
+
+	if config.Audit.RetentionDays < 0 {
+		panic("Audit retention_days must not be negative")
+	}
+
+	if config.Secrets.MaxVersions < 0 {
+		panic("Secrets max_versions must not be negative")
+	}
+
+	if config.Secrets.ExpiryAction != "deactivate" && config.Secrets.ExpiryAction != "delete" {
+		panic("Secrets expiry_action must be either deactivate or delete")
+	}
+
+	if config.Secrets.ExpiryWarning < 0 || config.Secrets.ExpiryInterval <= 0 {
+		panic("Secrets expiry_warning must not be negative and expiry_interval must be positive")
+	}
+
+	if config.Policy.Mode != "permissive" && config.Policy.Mode != "strict" {
+		panic("Policy mode must be either permissive or strict")
+	}
+
+	if config.Session.IdleTimeout <= 0 || config.Session.SweepInterval <= 0 {
+		panic("Session idle_timeout and sweep_interval must be positive")
+	}
+
+	if config.Wrapping.MaxTTL <= 0 {
+		panic("Wrapping max_ttl must be positive")
+	}
+
+	if config.Lease.MaxTTL <= 0 || config.Lease.CheckInterval <= 0 {
+		panic("Lease max_ttl and check_interval must be positive")
+	}
+
+	if config.PKI.CRLExpiry <= 0 {
+		panic("PKI crl_expiry must be positive")
+	}
+
+	if config.TOTP.Skew < 0 || config.TOTP.BackupCodes < 0 {
+		panic("TOTP skew and backup_codes must not be negative")
+	}
+
+	if config.TOTP.MaxFailedAttempts <= 0 || config.TOTP.LockoutDuration <= 0 {
+		panic("TOTP max_failed_attempts and lockout_duration must be positive")
+	}
+
+	if config.Network.MonitorInterval <= 0 || config.Network.CheckInterval <= 0 || config.Network.FailureThreshold <= 0 {
+		panic("Network monitor_interval, check_interval and failure_threshold must be positive")
+	}
+
+	if config.Network.HistoryRetention <= 0 || config.Network.CertificateWarning < 0 {
+		panic("Network history_retention must be positive and certificate_warning must not be negative")
+	}
 }
 
 func GetEnv(key, defaultValue string) string {
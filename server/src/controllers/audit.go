@@ -1,10 +1,14 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -59,3 +63,232 @@ func (c *AuditController) GetAuditLogs(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"logs": logs, "limit": limit, "offset": offset})
 }
+
+// VerifyChain walks the audit hash chain and reports the first broken link.
+func (c *AuditController) VerifyChain(ctx *gin.Context) {
+	result, err := c.auditService.Verify()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to verify audit log",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (c *AuditController) Search(ctx *gin.Context) {
+	var req model.SearchAuditRequest
+	if !c.bindFilter(ctx, &req) {
+		return
+	}
+	req.Limit, req.Offset = normalizePage(req.Limit, req.Offset)
+
+	logs, total, err := c.auditService.Search(&req)
+	if err != nil {
+		c.respondAuditError(ctx, err, "Failed to search audit logs")
+		return
+	}
+
+	response := model.SearchAuditResponse{
+		Events: make([]model.AuditEvent, 0, len(logs)),
+		Total:  total,
+		Limit:  req.Limit,
+		Offset: req.Offset,
+	}
+	for i := range logs {
+		response.Events = append(response.Events, services.ToAuditEvent(&logs[i]))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *AuditController) GetEvent(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid audit event ID",
+			},
+		})
+		return
+	}
+
+	entry, err := c.auditService.GetEvent(id)
+	if err != nil {
+		c.respondAuditError(ctx, err, "Failed to retrieve audit event")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.ToAuditEvent(entry))
+}
+
+// GetStats aggregates entries; the range defaults to the last 24 hours.
+func (c *AuditController) GetStats(ctx *gin.Context) {
+	var req model.AuditStatsRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid request format",
+				},
+			})
+			return
+		}
+	}
+
+	end := time.Now().UTC()
+	if req.EndTime != nil {
+		end = *req.EndTime
+	}
+	start := end.Add(-24 * time.Hour)
+	if req.StartTime != nil {
+		start = *req.StartTime
+	}
+	if !start.Before(end) {
+		c.respondAuditError(ctx, services.ErrInvalidAuditFilter, "")
+		return
+	}
+
+	stats, err := c.auditService.Stats(start, end)
+	if err != nil {
+		c.respondAuditError(ctx, err, "Failed to compute audit statistics")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// Export streams the matching entries as CSV (default) or JSON lines; the
+// format comes from the body or the "format" query parameter.
+func (c *AuditController) Export(ctx *gin.Context) {
+	var req model.SearchAuditRequest
+	if !c.bindFilter(ctx, &req) {
+		return
+	}
+	if req.Format == "" {
+		req.Format = ctx.DefaultQuery("format", services.AuditExportCSV)
+	}
+
+	contentType := "text/csv"
+	switch req.Format {
+	case services.AuditExportCSV:
+	case services.AuditExportJSONL:
+		contentType = "application/x-ndjson"
+	default:
+		c.respondAuditError(ctx, services.ErrInvalidAuditFilter, "")
+		return
+	}
+
+	// Validate the filter before the response status is committed.
+	if err := c.auditService.ValidateFilter(&req); err != nil {
+		c.respondAuditError(ctx, err, "Failed to export audit logs")
+		return
+	}
+
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=audit-%s.%s", time.Now().UTC().Format("20060102T150405Z"), req.Format))
+	ctx.Status(http.StatusOK)
+
+	if err := c.auditService.Export(&req, req.Format, ctx.Writer); err != nil {
+		log.Printf("⚠️  Audit export failed: %v", err)
+	}
+}
+
+func (c *AuditController) GetRetention(ctx *gin.Context) {
+	retention, err := c.auditService.GetRetention()
+	if err != nil {
+		c.respondAuditError(ctx, err, "Failed to retrieve audit retention")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, retention)
+}
+
+func (c *AuditController) SetRetention(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.SetAuditRetentionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	retention, err := c.auditService.SetRetention(*req.Days, userID.(uuid.UUID))
+	if err != nil {
+		c.respondAuditError(ctx, err, "Failed to update audit retention")
+		return
+	}
+
+	c.auditService.LogAction(userID.(uuid.UUID), "audit_retention_updated", "audit", strconv.Itoa(retention.Days), true, "")
+
+	ctx.JSON(http.StatusOK, retention)
+}
+
+func (c *AuditController) bindFilter(ctx *gin.Context, req *model.SearchAuditRequest) bool {
+	if ctx.Request.ContentLength == 0 {
+		return true
+	}
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *AuditController) respondAuditError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAuditEventNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_AUDIT_EVENT_NOT_FOUND",
+				Message: "Audit event not found",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidAuditFilter):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_FILTER",
+				Message: "Invalid audit filter: status must be success or failure, format csv or jsonl, identity_id a UUID and start_time before end_time",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidRetention):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_RETENTION",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
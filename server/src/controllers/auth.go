@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -33,28 +35,103 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.authService.Login(req.Email, req.Password)
+	email := req.Email
+	if email == "" {
+		email = req.Username
+	}
+
+	response, err := c.authService.Login(email, req.Password, req.TOTP, clientInfo(ctx))
 	if err != nil {
-		if c.auditService != nil {
-			c.auditService.LogAnonymousAction("login_failed", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), false, err.Error())
+		c.respondLoginError(ctx, err)
+		return
+	}
+
+	if c.auditService != nil {
+		if response.MFARequired {
+			c.auditService.LogAnonymousAction("login_mfa_required", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), true, "")
+		} else {
+			c.auditService.LogAnonymousAction("login_success", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), true, "")
 		}
+	}
 
-		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *AuthController) CompleteMFALogin(ctx *gin.Context) {
+	var req model.MFALoginRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
 			Error: model.ErrorDetail{
-				Code:    "VAULT_INVALID_CREDENTIALS",
-				Message: "Invalid email or password",
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
 			},
 		})
 		return
 	}
 
+	response, err := c.authService.CompleteMFALogin(req.MFAToken, req.Code, clientInfo(ctx))
+	if err != nil {
+		c.respondLoginError(ctx, err)
+		return
+	}
+
 	if c.auditService != nil {
-		c.auditService.LogAnonymousAction("login_success", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), true, "")
+		c.auditService.LogAnonymousAction("login_success", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), true, "mfa")
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
 
+func clientInfo(ctx *gin.Context) model.ClientInfo {
+	return model.ClientInfo{
+		IPAddress: ctx.ClientIP(),
+		UserAgent: ctx.GetHeader("User-Agent"),
+	}
+}
+
+func (c *AuthController) respondLoginError(ctx *gin.Context, err error) {
+	if c.auditService != nil {
+		c.auditService.LogAnonymousAction("login_failed", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), false, err.Error())
+	}
+
+	if errors.Is(err, services.ErrMFALocked) {
+		ctx.JSON(http.StatusTooManyRequests, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_MFA_LOCKED",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidMFACode) {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_MFA_CODE",
+				Message: "Invalid MFA code",
+			},
+		})
+		return
+	}
+
+	if errors.Is(err, services.ErrInvalidCredentials) || errors.Is(err, services.ErrMFANotEnrolled) {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_CREDENTIALS",
+				Message: "Invalid email or password",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		Error: model.ErrorDetail{
+			Code:    "VAULT_INTERNAL_ERROR",
+			Message: "Failed to log in",
+		},
+	})
+}
+
 func (c *AuthController) Logout(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
 	if !exists {
@@ -67,6 +144,24 @@ func (c *AuthController) Logout(ctx *gin.Context) {
 		return
 	}
 
+	var req model.LogoutRequest
+	_ = ctx.ShouldBindJSON(&req)
+
+	if claims, ok := ctx.Get("token_claims"); ok {
+		if err := c.authService.EndSession(claims.(*model.TokenClaims)); err != nil {
+			ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INTERNAL_ERROR",
+					Message: "Failed to revoke token",
+				},
+			})
+			return
+		}
+	}
+	if req.RefreshToken != "" {
+		_ = c.authService.RevokeRefreshToken(req.RefreshToken, userID.(uuid.UUID))
+	}
+
 	if c.auditService != nil {
 		c.auditService.LogAction(userID.(uuid.UUID), "logout", "auth", "", true, "")
 	}
@@ -74,6 +169,256 @@ func (c *AuthController) Logout(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
+func (c *AuthController) Refresh(ctx *gin.Context) {
+	var req model.RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	response, err := c.authService.Refresh(req.Token)
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			if c.auditService != nil {
+				c.auditService.LogAnonymousAction("refresh_token_reused", "auth", "", ctx.ClientIP(), ctx.GetHeader("User-Agent"), false, err.Error())
+			}
+			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_REFRESH_TOKEN_REUSED",
+					Message: "Refresh token was already used; all tokens from this login have been revoked",
+				},
+			})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidToken) {
+			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_TOKEN",
+					Message: "Invalid or expired refresh token",
+				},
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to refresh token",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RenewToken exchanges the caller's access token for a fresh one bound to
+// the same session; the old token is revoked.
+func (c *AuthController) RenewToken(ctx *gin.Context) {
+	value, exists := ctx.Get("token_claims")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+	claims := value.(*model.TokenClaims)
+
+	var req model.RenewTokenRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil || req.Increment < 0 {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid request format",
+				},
+			})
+			return
+		}
+	}
+
+	token, renewed, err := c.authService.RenewToken(claims, time.Duration(req.Increment)*time.Second)
+	if c.auditService != nil {
+		details := ""
+		if err != nil {
+			details = err.Error()
+		}
+		c.auditService.LogAction(claims.UserID, "token_renewed", "auth", claims.SessionID.String(), err == nil, details)
+	}
+	if err != nil {
+		if errors.Is(err, services.ErrTokenNotRenewable) {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_TOKEN_NOT_RENEWABLE",
+					Message: "Token is not bound to an active session",
+				},
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to renew token",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.TokenRenewResponse{
+		Auth: model.TokenRenewAuth{
+			ClientToken:   token,
+			LeaseDuration: int(time.Until(renewed.ExpiresAt).Round(time.Second) / time.Second),
+			Renewable:     true,
+			Policies:      []string{},
+			ExpiresAt:     renewed.ExpiresAt,
+		},
+	})
+}
+
+func (c *AuthController) Verify(ctx *gin.Context) {
+	var req model.VerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, c.authService.Introspect(req.Token))
+}
+
+func (c *AuthController) Revoke(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.RevokeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	if err := c.authService.RevokeToken(req.Token, userID.(uuid.UUID)); err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidToken):
+			ctx.JSON(http.StatusOK, model.RevokeResponse{Revoked: false})
+		case errors.Is(err, services.ErrTokenNotOwned):
+			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_ACCESS_DENIED",
+					Message: "Token belongs to another user",
+				},
+			})
+		default:
+			ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INTERNAL_ERROR",
+					Message: "Failed to revoke token",
+				},
+			})
+		}
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "token_revoked", "auth", "", true, "")
+	}
+
+	ctx.JSON(http.StatusOK, model.RevokeResponse{Revoked: true})
+}
+
+// RevokeAll revokes every token of the current user.
+func (c *AuthController) RevokeAll(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	c.revokeAllTokens(ctx, userID.(uuid.UUID), userID.(uuid.UUID))
+}
+
+// RevokeUserTokens revokes every token of another user; admin only.
+func (c *AuthController) RevokeUserTokens(ctx *gin.Context) {
+	adminID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid user ID",
+			},
+		})
+		return
+	}
+
+	c.revokeAllTokens(ctx, adminID.(uuid.UUID), targetID)
+}
+
+func (c *AuthController) revokeAllTokens(ctx *gin.Context, actorID, targetID uuid.UUID) {
+	if err := c.authService.RevokeAllTokens(targetID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_USER_NOT_FOUND",
+					Message: "User not found",
+				},
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to revoke tokens",
+			},
+		})
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(actorID, "tokens_revoked_all", "user", targetID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, model.RevokeResponse{Revoked: true})
+}
+
 func (c *AuthController) GetSession(ctx *gin.Context) {
 	userID, exists := ctx.Get("user_id")
 	if !exists {
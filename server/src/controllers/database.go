@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type DatabaseController struct {
+	databaseService *services.DatabaseService
+	auditService    *services.AuditService
+}
+
+func NewDatabaseController(databaseService *services.DatabaseService, auditService *services.AuditService) *DatabaseController {
+	return &DatabaseController{
+		databaseService: databaseService,
+		auditService:    auditService,
+	}
+}
+
+// WriteConnection creates or replaces a connection, for administrators.
+func (c *DatabaseController) WriteConnection(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.DatabaseConnectionRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	conn, err := c.databaseService.WriteConnection(&req, userID)
+	c.logAction(userID, "database_connection_written", "database_connection", req.Name, err, "type="+string(req.Type))
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to write database connection")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conn)
+}
+
+func (c *DatabaseController) ListConnections(ctx *gin.Context) {
+	conns, err := c.databaseService.ListConnections()
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to list database connections")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"connections": conns,
+		"total":       len(conns),
+	})
+}
+
+func (c *DatabaseController) GetConnection(ctx *gin.Context) {
+	conn, err := c.databaseService.GetConnection(ctx.Param("name"))
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to get database connection")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, conn)
+}
+
+func (c *DatabaseController) DeleteConnection(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	err := c.databaseService.DeleteConnection(name)
+	c.logAction(userID, "database_connection_deleted", "database_connection", name, err, "")
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to delete database connection")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Database connection deleted successfully"})
+}
+
+// WriteRole creates or replaces a role, for administrators.
+func (c *DatabaseController) WriteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.DatabaseRoleRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role, err := c.databaseService.WriteRole(&req)
+	c.logAction(userID, "database_role_written", "database_role", req.Name, err, "connection="+req.Connection)
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to write database role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *DatabaseController) ListRoles(ctx *gin.Context) {
+	roles, err := c.databaseService.ListRoles()
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to list database roles")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+func (c *DatabaseController) GetRole(ctx *gin.Context) {
+	role, err := c.databaseService.GetRole(ctx.Param("name"))
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to get database role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *DatabaseController) DeleteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	err := c.databaseService.DeleteRole(name)
+	c.logAction(userID, "database_role_deleted", "database_role", name, err, "")
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to delete database role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Database role deleted successfully"})
+}
+
+// GenerateCredentials issues a new database user from a role. Only the lease
+// ID is audited, never the credentials.
+func (c *DatabaseController) GenerateCredentials(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	creds, lease, err := c.databaseService.GenerateCredentials(name, userID)
+	details := ""
+	if lease != nil {
+		details = "lease_id=" + lease.ID
+	}
+	c.logAction(userID, "database_credentials_issued", "database_role", name, err, details)
+	if err != nil {
+		c.respondDatabaseError(ctx, err, "Failed to generate database credentials")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.DatabaseCredentialsResponse{
+		LeaseID:       lease.ID,
+		LeaseDuration: lease.TTL,
+		Renewable:     lease.Renewable,
+		Data:          *creds,
+	})
+}
+
+func (c *DatabaseController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *DatabaseController) logAction(userID uuid.UUID, action, resource, name string, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, resource, name, err == nil, details)
+}
+
+func (c *DatabaseController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *DatabaseController) respondDatabaseError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrDatabaseConnectionNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_DATABASE_CONNECTION_NOT_FOUND",
+				Message: "Database connection not found",
+			},
+		})
+	case errors.Is(err, services.ErrDatabaseRoleNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_DATABASE_ROLE_NOT_FOUND",
+				Message: "Database role not found",
+			},
+		})
+	case errors.Is(err, services.ErrDatabaseConnectionInUse):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_DATABASE_CONNECTION_IN_USE",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrUnsupportedDatabaseType),
+		errors.Is(err, services.ErrInvalidDatabaseConnection),
+		errors.Is(err, services.ErrInvalidDatabaseRole),
+		errors.Is(err, services.ErrInvalidDatabaseRoleTTL):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrDatabaseConnectionFailed):
+		ctx.JSON(http.StatusBadGateway, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_DATABASE_CONNECTION_FAILED",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GroupController struct {
+	groupService *services.GroupService
+	auditService *services.AuditService
+}
+
+func NewGroupController(groupService *services.GroupService, auditService *services.AuditService) *GroupController {
+	return &GroupController{
+		groupService: groupService,
+		auditService: auditService,
+	}
+}
+
+func (c *GroupController) ListGroups(ctx *gin.Context) {
+	groups, err := c.groupService.ListGroups()
+	if err != nil {
+		c.respondGroupError(ctx, err, "Failed to retrieve groups")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"groups": groups})
+}
+
+func (c *GroupController) CreateGroup(ctx *gin.Context) {
+	var req model.CreateGroupRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	group := &model.Group{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := c.groupService.CreateGroup(group); err != nil {
+		c.respondGroupError(ctx, err, "Failed to create group")
+		return
+	}
+
+	c.logAction(ctx, "group_created", group.ID.String())
+
+	ctx.JSON(http.StatusCreated, group)
+}
+
+func (c *GroupController) GetGroup(ctx *gin.Context) {
+	id, ok := c.parseID(ctx, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	group, err := c.groupService.GetGroup(id)
+	if err != nil {
+		c.respondGroupError(ctx, err, "Failed to retrieve group")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.GroupResponse{
+		Group:   *group,
+		Members: group.Users,
+	})
+}
+
+func (c *GroupController) DeleteGroup(ctx *gin.Context) {
+	id, ok := c.parseID(ctx, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	if err := c.groupService.DeleteGroup(id); err != nil {
+		c.respondGroupError(ctx, err, "Failed to delete group")
+		return
+	}
+
+	c.logAction(ctx, "group_deleted", id.String())
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Group deleted successfully"})
+}
+
+func (c *GroupController) AddMember(ctx *gin.Context) {
+	id, ok := c.parseID(ctx, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	var req model.GroupMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	if err := c.groupService.AddMember(id, req.UserID); err != nil {
+		c.respondGroupError(ctx, err, "Failed to add group member")
+		return
+	}
+
+	c.logAction(ctx, "group_member_added", id.String())
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member added successfully"})
+}
+
+func (c *GroupController) RemoveMember(ctx *gin.Context) {
+	id, ok := c.parseID(ctx, "id", "Invalid group ID")
+	if !ok {
+		return
+	}
+
+	userID, ok := c.parseID(ctx, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	if err := c.groupService.RemoveMember(id, userID); err != nil {
+		c.respondGroupError(ctx, err, "Failed to remove group member")
+		return
+	}
+
+	c.logAction(ctx, "group_member_removed", id.String())
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+func (c *GroupController) logAction(ctx *gin.Context, action, groupID string) {
+	if c.auditService == nil {
+		return
+	}
+	if userID, exists := ctx.Get("user_id"); exists {
+		c.auditService.LogAction(userID.(uuid.UUID), action, "group", groupID, true, "")
+	}
+}
+
+func (c *GroupController) parseID(ctx *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: message,
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *GroupController) respondGroupError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrGroupNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_GROUP_NOT_FOUND",
+				Message: "Group not found",
+			},
+		})
+	case errors.Is(err, services.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_USER_NOT_FOUND",
+				Message: "User not found",
+			},
+		})
+	case errors.Is(err, services.ErrGroupExists):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_GROUP_EXISTS",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
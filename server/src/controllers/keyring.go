@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type KeyringController struct {
+	keyringService *services.KeyringService
+	auditService   *services.AuditService
+}
+
+func NewKeyringController(keyringService *services.KeyringService, auditService *services.AuditService) *KeyringController {
+	return &KeyringController{
+		keyringService: keyringService,
+		auditService:   auditService,
+	}
+}
+
+func (c *KeyringController) GetStatus(ctx *gin.Context) {
+	status, err := c.keyringService.Status()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to get keyring status",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+func (c *KeyringController) Rotate(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	version, err := c.keyringService.Rotate()
+	if err != nil {
+		if c.auditService != nil {
+			c.auditService.LogAction(userID.(uuid.UUID), "keyring_rotated", "keyring", "", false, err.Error())
+		}
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to rotate keyring",
+			},
+		})
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "keyring_rotated", "keyring", strconv.Itoa(version), true, "")
+	}
+
+	status, err := c.keyringService.Status()
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to get keyring status",
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
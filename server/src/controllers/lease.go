@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LeaseController struct {
+	leaseService *services.LeaseService
+	auditService *services.AuditService
+}
+
+func NewLeaseController(leaseService *services.LeaseService, auditService *services.AuditService) *LeaseController {
+	return &LeaseController{
+		leaseService: leaseService,
+		auditService: auditService,
+	}
+}
+
+// ListLeases returns the caller's active leases, optionally under a prefix.
+func (c *LeaseController) ListLeases(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+	c.list(ctx, &userID)
+}
+
+// ListAllLeases returns the active leases of every user, for administrators.
+func (c *LeaseController) ListAllLeases(ctx *gin.Context) {
+	c.list(ctx, nil)
+}
+
+func (c *LeaseController) LookupLease(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.LeaseRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	lease, err := c.leaseService.Lookup(req.LeaseID, &userID)
+	if err != nil {
+		c.respondLeaseError(ctx, err, "Failed to look up lease")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.ToLeaseResponse(lease))
+}
+
+func (c *LeaseController) RenewLease(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.RenewLeaseRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+	if req.Increment < 0 {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Increment must not be negative",
+			},
+		})
+		return
+	}
+
+	lease, err := c.leaseService.Renew(req.LeaseID, time.Duration(req.Increment)*time.Second, &userID)
+	c.logAction(userID, "lease_renewed", req.LeaseID, err, "increment="+strconv.Itoa(req.Increment))
+	if err != nil {
+		c.respondLeaseError(ctx, err, "Failed to renew lease")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.ToLeaseResponse(lease))
+}
+
+func (c *LeaseController) RevokeLease(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.LeaseRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	err := c.leaseService.Revoke(req.LeaseID, &userID)
+	c.logAction(userID, "lease_revoked", req.LeaseID, err, "")
+	if err != nil {
+		c.respondLeaseError(ctx, err, "Failed to revoke lease")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Lease revoked successfully"})
+}
+
+// RevokePrefix revokes every lease under a prefix regardless of owner, for
+// administrators.
+func (c *LeaseController) RevokePrefix(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.RevokeLeasePrefixRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	revoked, err := c.leaseService.RevokePrefix(req.Prefix)
+	c.logAction(userID, "lease_prefix_revoked", req.Prefix, err, "revoked="+strconv.Itoa(revoked))
+	if err != nil {
+		c.respondLeaseError(ctx, err, "Failed to revoke every lease under the prefix")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.RevokeLeasePrefixResponse{Revoked: revoked})
+}
+
+func (c *LeaseController) list(ctx *gin.Context, userID *uuid.UUID) {
+	leases, err := c.leaseService.List(ctx.Query("prefix"), userID)
+	if err != nil {
+		c.respondLeaseError(ctx, err, "Failed to list leases")
+		return
+	}
+
+	response := model.ListLeasesResponse{
+		Leases: make([]model.LeaseResponse, len(leases)),
+		Total:  len(leases),
+	}
+	for i := range leases {
+		response.Leases[i] = services.ToLeaseResponse(&leases[i])
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *LeaseController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *LeaseController) logAction(userID uuid.UUID, action, leaseID string, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, "lease", leaseID, err == nil, details)
+}
+
+func (c *LeaseController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *LeaseController) respondLeaseError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrLeaseNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_LEASE_NOT_FOUND",
+				Message: "Lease not found",
+			},
+		})
+	case errors.Is(err, services.ErrLeaseExpired):
+		ctx.JSON(http.StatusGone, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_LEASE_EXPIRED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrLeaseNotRenewable):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_LEASE_NOT_RENEWABLE",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
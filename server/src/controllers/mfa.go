@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MFAController struct {
+	mfaService   *services.MFAService
+	userService  *services.UserService
+	auditService *services.AuditService
+}
+
+func NewMFAController(mfaService *services.MFAService, userService *services.UserService, auditService *services.AuditService) *MFAController {
+	return &MFAController{
+		mfaService:   mfaService,
+		userService:  userService,
+		auditService: auditService,
+	}
+}
+
+func (c *MFAController) GetStatus(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	status, err := c.mfaService.Status(userID)
+	if err != nil {
+		c.respondMFAError(ctx, err, "Failed to get MFA status")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+func (c *MFAController) Enroll(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	user, err := c.userService.GetUserByID(userID)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	response, err := c.mfaService.Enroll(userID, user.Email)
+	if err != nil {
+		c.respondMFAError(ctx, err, "Failed to start MFA enrollment")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *MFAController) Confirm(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if !c.bindCode(ctx, &req) {
+		return
+	}
+
+	codes, err := c.mfaService.Confirm(userID, req.Code)
+	if err != nil {
+		c.respondMFAError(ctx, err, "Failed to enable MFA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (c *MFAController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if !c.bindCode(ctx, &req) {
+		return
+	}
+
+	codes, err := c.mfaService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		c.respondMFAError(ctx, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+func (c *MFAController) Disable(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.MFACodeRequest
+	if !c.bindCode(ctx, &req) {
+		return
+	}
+
+	if err := c.mfaService.Disable(userID, req.Code); err != nil {
+		c.respondMFAError(ctx, err, "Failed to disable MFA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "MFA disabled successfully"})
+}
+
+// Reset removes the MFA enrollment of another user; admin only.
+func (c *MFAController) Reset(ctx *gin.Context) {
+	adminID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	targetID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid user ID",
+			},
+		})
+		return
+	}
+
+	if err := c.mfaService.Reset(targetID); err != nil {
+		if c.auditService != nil {
+			c.auditService.LogAction(adminID, "mfa_reset", "user", targetID.String(), false, err.Error())
+		}
+		c.respondMFAError(ctx, err, "Failed to reset MFA")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(adminID, "mfa_reset", "user", targetID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "MFA reset successfully"})
+}
+
+func (c *MFAController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *MFAController) bindCode(ctx *gin.Context, req *model.MFACodeRequest) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *MFAController) respondMFAError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrMFANotEnrolled):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_MFA_NOT_ENROLLED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrMFAAlreadyEnabled):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_MFA_ALREADY_ENABLED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrMFALocked):
+		ctx.JSON(http.StatusTooManyRequests, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_MFA_LOCKED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidMFACode):
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_MFA_CODE",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
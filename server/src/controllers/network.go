@@ -1,10 +1,13 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 )
@@ -27,17 +30,8 @@ func (c *NetworkController) GetNetworks(ctx *gin.Context) {
 	}
 
 	response := make([]model.NetworkResponse, len(networks))
-	for i, network := range networks {
-		config, _ := network.GetProtocolConfig()
-		response[i] = model.NetworkResponse{
-			ID:        network.ID,
-			Name:      network.Name,
-			Type:      network.Type,
-			Status:    network.Status,
-			Config:    config,
-			CreatedAt: network.CreatedAt,
-			UpdatedAt: network.UpdatedAt,
-		}
+	for i := range networks {
+		response[i] = toNetworkResponse(&networks[i])
 	}
 
 	ctx.JSON(http.StatusOK, response)
@@ -53,52 +47,42 @@ func (c *NetworkController) GetNetwork(ctx *gin.Context) {
 
 	network, err := c.networkService.GetNetwork(uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondNetworkError(ctx, err)
 		return
 	}
 
-	config, _ := network.GetProtocolConfig()
-	response := model.NetworkResponse{
-		ID:        network.ID,
-		Name:      network.Name,
-		Type:      network.Type,
-		Status:    network.Status,
-		Config:    config,
-		CreatedAt: network.CreatedAt,
-		UpdatedAt: network.UpdatedAt,
-	}
-
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, toNetworkResponse(network))
 }
 
 func (c *NetworkController) CreateNetwork(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
 	var req model.NetworkRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	network, err := c.networkService.CreateNetwork(&req)
+	network, err := c.networkService.CreateNetwork(&req, userID.(uuid.UUID))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondNetworkError(ctx, err)
 		return
 	}
 
-	config, _ := network.GetProtocolConfig()
-	response := model.NetworkResponse{
-		ID:        network.ID,
-		Name:      network.Name,
-		Type:      network.Type,
-		Status:    network.Status,
-		Config:    config,
-		CreatedAt: network.CreatedAt,
-		UpdatedAt: network.UpdatedAt,
-	}
-
-	ctx.JSON(http.StatusCreated, response)
+	ctx.JSON(http.StatusCreated, toNetworkResponse(network))
 }
 
 func (c *NetworkController) UpdateNetwork(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
 	idParam := ctx.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -112,27 +96,22 @@ func (c *NetworkController) UpdateNetwork(ctx *gin.Context) {
 		return
 	}
 
-	network, err := c.networkService.UpdateNetwork(uint(id), &req)
+	network, err := c.networkService.UpdateNetwork(uint(id), &req, userID.(uuid.UUID))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondNetworkError(ctx, err)
 		return
 	}
 
-	config, _ := network.GetProtocolConfig()
-	response := model.NetworkResponse{
-		ID:        network.ID,
-		Name:      network.Name,
-		Type:      network.Type,
-		Status:    network.Status,
-		Config:    config,
-		CreatedAt: network.CreatedAt,
-		UpdatedAt: network.UpdatedAt,
-	}
-
-	ctx.JSON(http.StatusOK, response)
+	ctx.JSON(http.StatusOK, toNetworkResponse(network))
 }
 
 func (c *NetworkController) DeleteNetwork(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
 	idParam := ctx.Param("id")
 	id, err := strconv.ParseUint(idParam, 10, 32)
 	if err != nil {
@@ -140,8 +119,8 @@ func (c *NetworkController) DeleteNetwork(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.networkService.DeleteNetwork(uint(id)); err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	if err := c.networkService.DeleteNetwork(uint(id), userID.(uuid.UUID)); err != nil {
+		respondNetworkError(ctx, err)
 		return
 	}
 
@@ -157,7 +136,7 @@ func (c *NetworkController) TestProtocol(ctx *gin.Context) {
 
 	response, err := c.networkService.TestProtocol(&req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondNetworkError(ctx, err)
 		return
 	}
 
@@ -174,13 +153,99 @@ func (c *NetworkController) GetProtocolStatus(ctx *gin.Context) {
 
 	status, err := c.networkService.GetProtocolStatus(uint(id))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondNetworkError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, status)
 }
 
+func (c *NetworkController) GetCheckHistory(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+	window, limit, ok := networkHistoryQuery(ctx)
+	if !ok {
+		return
+	}
+
+	checks, err := c.networkService.GetCheckHistory(uint(id), window, limit)
+	if err != nil {
+		respondNetworkError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"checks": checks,
+		"count":  len(checks),
+	})
+}
+
+func (c *NetworkController) GetUptime(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+	window, _, ok := networkHistoryQuery(ctx)
+	if !ok {
+		return
+	}
+
+	uptime, err := c.networkService.GetUptime(uint(id), window)
+	if err != nil {
+		respondNetworkError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, uptime)
+}
+
+// GetAlerts lists the alerts of every network.
+func (c *NetworkController) GetAlerts(ctx *gin.Context) {
+	window, limit, ok := networkHistoryQuery(ctx)
+	if !ok {
+		return
+	}
+
+	alerts, err := c.networkService.GetAlerts(nil, window, limit)
+	if err != nil {
+		respondNetworkError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
+func (c *NetworkController) GetNetworkAlerts(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid network ID"})
+		return
+	}
+	window, limit, ok := networkHistoryQuery(ctx)
+	if !ok {
+		return
+	}
+
+	networkID := uint(id)
+	alerts, err := c.networkService.GetAlerts(&networkID, window, limit)
+	if err != nil {
+		respondNetworkError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"alerts": alerts,
+		"count":  len(alerts),
+	})
+}
+
 func (c *NetworkController) GetSupportedProtocols(ctx *gin.Context) {
 	protocols := c.networkService.GetSupportedProtocols()
 	ctx.JSON(http.StatusOK, gin.H{
@@ -188,3 +253,63 @@ func (c *NetworkController) GetSupportedProtocols(ctx *gin.Context) {
 		"count":     len(protocols),
 	})
 }
+
+// toNetworkResponse returns the network with its config redacted: stored
+// credentials never leave the server.
+func toNetworkResponse(network *model.Network) model.NetworkResponse {
+	config, _ := network.GetProtocolConfig()
+	return model.NetworkResponse{
+		ID:                   network.ID,
+		Name:                 network.Name,
+		Type:                 network.Type,
+		Status:               network.Status,
+		Config:               config.Redacted(),
+		HasCredentials:       network.CredentialsID != nil,
+		CheckInterval:        network.CheckInterval,
+		FailureThreshold:     network.FailureThreshold,
+		ConsecutiveFailures:  network.ConsecutiveFailures,
+		LastCheckAt:          network.LastCheckAt,
+		CertificateExpiresAt: network.CertificateExpiresAt,
+		CreatedAt:            network.CreatedAt,
+		UpdatedAt:            network.UpdatedAt,
+	}
+}
+
+// networkHistoryQuery reads the window (seconds or a Go duration, 24h by
+// default) and limit (1 to 1000, 100 by default) query parameters.
+func networkHistoryQuery(ctx *gin.Context) (time.Duration, int, bool) {
+	window, err := parseNetworkWindow(ctx.DefaultQuery("window", "24h"))
+	if err != nil || window <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "window must be a positive number of seconds or a duration"})
+		return 0, 0, false
+	}
+
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+		return 0, 0, false
+	}
+
+	return window, limit, true
+}
+
+func parseNetworkWindow(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func respondNetworkError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrNetworkNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNetworkConfigRequired),
+		errors.Is(err, services.ErrNetworkConfigMismatch):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNetworkCredentialsNotFound):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,524 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ocsp"
+)
+
+// maxOCSPRequestSize bounds OCSP request bodies; real requests are a few
+// hundred bytes.
+const maxOCSPRequestSize = 64 * 1024
+
+type PKIController struct {
+	pkiService   *services.PKIService
+	auditService *services.AuditService
+}
+
+func NewPKIController(pkiService *services.PKIService, auditService *services.AuditService) *PKIController {
+	return &PKIController{
+		pkiService:   pkiService,
+		auditService: auditService,
+	}
+}
+
+// GenerateRoot creates a self-signed root CA, for administrators.
+func (c *PKIController) GenerateRoot(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIGenerateCARequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.GenerateRoot(&req, userID)
+	c.logAction(userID, "pki_root_generated", "pki_ca", req.CommonName, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to generate root CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GenerateIntermediate creates an intermediate key and returns its CSR, for
+// administrators.
+func (c *PKIController) GenerateIntermediate(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIGenerateCARequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.GenerateIntermediate(&req, userID)
+	c.logAction(userID, "pki_intermediate_generated", "pki_ca", req.CommonName, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to generate intermediate CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *PKIController) SetSignedIntermediate(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKISetSignedRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.SetSignedIntermediate(req.Certificate)
+	serial := ""
+	if resp != nil {
+		serial = resp.SerialNumber
+	}
+	c.logAction(userID, "pki_intermediate_set", "pki_ca", serial, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to set intermediate certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ImportCA takes over an existing CA from a PEM bundle. Only the serial is
+// audited, never the bundle.
+func (c *PKIController) ImportCA(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIImportCARequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.ImportCA(req.PEMBundle, userID)
+	serial := ""
+	if resp != nil {
+		serial = resp.SerialNumber
+	}
+	c.logAction(userID, "pki_ca_imported", "pki_ca", serial, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to import CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *PKIController) DeleteCA(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.pkiService.DeleteCA()
+	c.logAction(userID, "pki_root_deleted", "pki_ca", "", err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to delete CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "CA deleted successfully"})
+}
+
+// SignIntermediate signs the CSR of another CA, for administrators.
+func (c *PKIController) SignIntermediate(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKISignRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.SignIntermediate(&req, userID)
+	serial := ""
+	if resp != nil {
+		serial = resp.SerialNumber
+	}
+	c.logAction(userID, "pki_intermediate_signed", "pki_certificate", serial, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to sign intermediate CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// CA serves the CA certificate in DER, for relying parties following the
+// issuer URL of a certificate.
+func (c *PKIController) CA(ctx *gin.Context) {
+	ca, err := c.pkiService.CA()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to get CA")
+		return
+	}
+
+	block, _ := pem.Decode([]byte(ca.Certificate))
+	if block == nil {
+		c.respondPKIError(ctx, errors.New("invalid CA certificate"), "Failed to get CA")
+		return
+	}
+	ctx.Data(http.StatusOK, "application/pkix-cert", block.Bytes)
+}
+
+func (c *PKIController) CAPEM(ctx *gin.Context) {
+	ca, err := c.pkiService.CA()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to get CA")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/x-pem-file", []byte(ca.Certificate))
+}
+
+// CAChain serves the CA certificate followed by its issuers, in PEM.
+func (c *PKIController) CAChain(ctx *gin.Context) {
+	ca, err := c.pkiService.CA()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to get CA chain")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/x-pem-file", []byte(ca.Certificate+ca.CAChain))
+}
+
+func (c *PKIController) CRL(ctx *gin.Context) {
+	crl, err := c.pkiService.CRL()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to build CRL")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/pkix-crl", crl)
+}
+
+func (c *PKIController) CRLPEM(ctx *gin.Context) {
+	crl, err := c.pkiService.CRL()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to build CRL")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/x-pem-file", pem.EncodeToMemory(&pem.Block{Type: "X509 CRL", Bytes: crl}))
+}
+
+// OCSP answers an OCSP request sent as a POST body, or base64 encoded in
+// the path of a GET as RFC 6960 allows.
+func (c *PKIController) OCSP(ctx *gin.Context) {
+	var request []byte
+	var err error
+	if ctx.Request.Method == http.MethodGet {
+		var raw string
+		raw, err = url.PathUnescape(strings.TrimPrefix(ctx.Param("request"), "/"))
+		if err == nil {
+			request, err = base64.StdEncoding.DecodeString(raw)
+		}
+	} else {
+		request, err = io.ReadAll(io.LimitReader(ctx.Request.Body, maxOCSPRequestSize))
+	}
+	if err != nil {
+		ctx.Data(http.StatusOK, "application/ocsp-response", ocsp.MalformedRequestErrorResponse)
+		return
+	}
+
+	resp, err := c.pkiService.OCSP(request)
+	switch {
+	case errors.Is(err, services.ErrInvalidOCSPRequest):
+		ctx.Data(http.StatusOK, "application/ocsp-response", ocsp.MalformedRequestErrorResponse)
+	case err != nil:
+		ctx.Data(http.StatusOK, "application/ocsp-response", ocsp.InternalErrorErrorResponse)
+	default:
+		ctx.Data(http.StatusOK, "application/ocsp-response", resp)
+	}
+}
+
+// WriteRole creates or replaces a role, for administrators.
+func (c *PKIController) WriteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIRoleRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role, err := c.pkiService.WriteRole(&req)
+	c.logAction(userID, "pki_role_written", "pki_role", req.Name, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to write PKI role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *PKIController) ListRoles(ctx *gin.Context) {
+	roles, err := c.pkiService.ListRoles()
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to list PKI roles")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+func (c *PKIController) GetRole(ctx *gin.Context) {
+	role, err := c.pkiService.GetRole(ctx.Param("name"))
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to get PKI role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *PKIController) DeleteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	err := c.pkiService.DeleteRole(name)
+	c.logAction(userID, "pki_role_deleted", "pki_role", name, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to delete PKI role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "PKI role deleted successfully"})
+}
+
+// Issue generates a key and certificate from a role. Only the serial is
+// audited, never the private key.
+func (c *PKIController) Issue(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIIssueRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role := ctx.Param("role")
+	resp, err := c.pkiService.Issue(role, &req, userID)
+	c.logAction(userID, "pki_certificate_issued", "pki_role", role, err, c.certificateDetails(req.CommonName, resp))
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to issue certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *PKIController) Sign(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKISignRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role := ctx.Param("role")
+	resp, err := c.pkiService.Sign(role, &req, userID)
+	c.logAction(userID, "pki_csr_signed", "pki_role", role, err, c.certificateDetails(req.CommonName, resp))
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to sign certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ListCertificates lists certificate records, optionally of one role or only
+// revoked ones.
+func (c *PKIController) ListCertificates(ctx *gin.Context) {
+	certs, err := c.pkiService.ListCertificates(ctx.Query("role"), ctx.Query("revoked") == "true")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to list certificates")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"certificates": certs,
+		"total":        len(certs),
+	})
+}
+
+func (c *PKIController) GetCertificate(ctx *gin.Context) {
+	cert, err := c.pkiService.GetCertificate(ctx.Param("serial"))
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to get certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cert)
+}
+
+func (c *PKIController) Revoke(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.PKIRevokeRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.pkiService.Revoke(req.SerialNumber)
+	c.logAction(userID, "pki_certificate_revoked", "pki_certificate", req.SerialNumber, err, "")
+	if err != nil {
+		c.respondPKIError(ctx, err, "Failed to revoke certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *PKIController) certificateDetails(commonName string, resp *model.PKICertificateResponse) string {
+	details := "common_name=" + commonName
+	if resp != nil {
+		details += " serial_number=" + resp.SerialNumber
+	}
+	return details
+}
+
+func (c *PKIController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *PKIController) logAction(userID uuid.UUID, action, resource, name string, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, resource, name, err == nil, details)
+}
+
+func (c *PKIController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *PKIController) respondPKIError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPKICANotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_PKI_CA_NOT_FOUND",
+				Message: "No CA is configured",
+			},
+		})
+	case errors.Is(err, services.ErrPKIRoleNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_PKI_ROLE_NOT_FOUND",
+				Message: "PKI role not found",
+			},
+		})
+	case errors.Is(err, services.ErrPKICertificateNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_PKI_CERTIFICATE_NOT_FOUND",
+				Message: "Certificate not found",
+			},
+		})
+	case errors.Is(err, services.ErrPKICAExists),
+		errors.Is(err, services.ErrPKICAPending),
+		errors.Is(err, services.ErrPKINoPendingIntermediate),
+		errors.Is(err, services.ErrPKICannotRevokeCA):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_PKI_CA_CONFLICT",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrPKINameNotAllowed):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_PKI_NAME_NOT_ALLOWED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidPKIRole),
+		errors.Is(err, services.ErrInvalidPKIRoleTTL),
+		errors.Is(err, services.ErrInvalidPKIKeyType),
+		errors.Is(err, services.ErrInvalidPKIRequest),
+		errors.Is(err, services.ErrInvalidPKIBundle),
+		errors.Is(err, services.ErrInvalidPKISerial):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,564 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PolicyController struct {
+	policyService *services.PolicyService
+	auditService  *services.AuditService
+}
+
+func NewPolicyController(policyService *services.PolicyService, auditService *services.AuditService) *PolicyController {
+	return &PolicyController{
+		policyService: policyService,
+		auditService:  auditService,
+	}
+}
+
+func (c *PolicyController) CreatePolicy(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.CreatePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	document := req.Rules
+	if document == nil {
+		var err error
+		document, err = services.NewPolicyDocument(req.Resource, req.Actions, req.Effect, req.Conditions)
+		if err != nil {
+			c.respondPolicyError(ctx, err, "Failed to create policy")
+			return
+		}
+	}
+
+	policy := &model.Policy{
+		Name:        req.Name,
+		Description: req.Description,
+		Priority:    req.Priority,
+		IsActive:    true,
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+	if err := policy.SetDocument(document); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to create policy")
+		return
+	}
+
+	if err := c.policyService.CreatePolicy(policy, userID.(uuid.UUID)); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to create policy")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "policy_created", "policy", policy.ID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusCreated, services.ToPolicyResponse(policy))
+}
+
+func (c *PolicyController) GetPolicy(ctx *gin.Context) {
+	id, ok := c.parseID(ctx, "Invalid policy ID")
+	if !ok {
+		return
+	}
+
+	policy, err := c.policyService.GetPolicy(id)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to retrieve policy")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services.ToPolicyResponse(policy))
+}
+
+func (c *PolicyController) UpdatePolicy(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseID(ctx, "Invalid policy ID")
+	if !ok {
+		return
+	}
+
+	var req model.UpdatePolicyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	policy, err := c.policyService.GetPolicy(id)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to retrieve policy")
+		return
+	}
+
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.Description != nil {
+		policy.Description = *req.Description
+	}
+	if req.Priority != nil {
+		policy.Priority = *req.Priority
+	}
+	if req.IsActive != nil {
+		policy.IsActive = *req.IsActive
+	}
+
+	if req.Rules != nil {
+		if err := policy.SetDocument(req.Rules); err != nil {
+			c.respondPolicyError(ctx, err, "Failed to update policy")
+			return
+		}
+	} else if req.Resource != nil || req.Actions != nil || req.Effect != nil || req.Conditions != nil {
+		current := services.ToPolicyResponse(policy)
+		resource, actions, effect := current.Resource, current.Actions, current.Effect
+		if req.Resource != nil {
+			resource = *req.Resource
+		}
+		if req.Actions != nil {
+			actions = req.Actions
+		}
+		if req.Effect != nil {
+			effect = *req.Effect
+		}
+
+		document, err := services.NewPolicyDocument(resource, actions, effect, req.Conditions)
+		if err != nil {
+			c.respondPolicyError(ctx, err, "Failed to update policy")
+			return
+		}
+		if req.Conditions == nil && current.Conditions != nil {
+			document.Rules[0].Conditions = current.Conditions
+		}
+		if err := policy.SetDocument(document); err != nil {
+			c.respondPolicyError(ctx, err, "Failed to update policy")
+			return
+		}
+	}
+
+	if err := c.policyService.UpdatePolicy(policy); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to update policy")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "policy_updated", "policy", policy.ID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, services.ToPolicyResponse(policy))
+}
+
+func (c *PolicyController) DeletePolicy(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseID(ctx, "Invalid policy ID")
+	if !ok {
+		return
+	}
+
+	if err := c.policyService.DeletePolicy(id); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to delete policy")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "policy_deleted", "policy", id.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Policy deleted successfully"})
+}
+
+func (c *PolicyController) ListPolicies(ctx *gin.Context) {
+	var req model.ListPoliciesRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid request format",
+				},
+			})
+			return
+		}
+	}
+	req.Limit, req.Offset = normalizePage(req.Limit, req.Offset)
+
+	policies, total, err := c.policyService.ListPolicies(&req)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to retrieve policies")
+		return
+	}
+
+	response := model.ListPoliciesResponse{
+		Policies: make([]model.PolicyResponse, 0, len(policies)),
+		Total:    total,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	}
+	for i := range policies {
+		response.Policies = append(response.Policies, services.ToPolicyResponse(&policies[i]))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PolicyController) EnablePolicy(ctx *gin.Context) {
+	c.setPolicyActive(ctx, true)
+}
+
+func (c *PolicyController) DisablePolicy(ctx *gin.Context) {
+	c.setPolicyActive(ctx, false)
+}
+
+func (c *PolicyController) setPolicyActive(ctx *gin.Context, active bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseID(ctx, "Invalid policy ID")
+	if !ok {
+		return
+	}
+
+	if err := c.policyService.SetPolicyActive(id, active); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to update policy")
+		return
+	}
+
+	action := "policy_disabled"
+	if active {
+		action = "policy_enabled"
+	}
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), action, "policy", id.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"id": id, "is_active": active})
+}
+
+func (c *PolicyController) CheckPolicy(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.PolicyCheckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	identityID := userID.(uuid.UUID)
+	if req.IdentityID != "" {
+		parsed, err := uuid.Parse(req.IdentityID)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_ID",
+					Message: "Invalid identity ID",
+				},
+			})
+			return
+		}
+		identityID = parsed
+	}
+
+	accessRequest := &model.AccessRequest{
+		Path:       req.Resource,
+		Capability: model.PolicyCapability(req.Action),
+		Time:       time.Now(),
+	}
+	if clientIP, ok := req.Context["client_ip"].(string); ok {
+		accessRequest.ClientIP = clientIP
+	}
+	if at, ok := req.Context["time"].(string); ok {
+		parsed, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "context.time must be an RFC3339 timestamp",
+				},
+			})
+			return
+		}
+		accessRequest.Time = parsed
+	}
+
+	response, err := c.policyService.Check(identityID, accessRequest)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to evaluate policies")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PolicyController) CreateAssignment(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.CreatePolicyAssignmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	assignment := &model.PolicyAssignment{
+		PolicyID:     req.PolicyID,
+		IdentityType: req.IdentityType,
+		IdentityID:   req.IdentityID,
+		IsActive:     true,
+		CreatedBy:    userID.(uuid.UUID),
+	}
+	if req.IsActive != nil {
+		assignment.IsActive = *req.IsActive
+	}
+
+	if err := c.policyService.CreateAssignment(assignment); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to assign policy")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "policy_assigned", "policy", assignment.PolicyID.String(), true,
+			assignment.IdentityType+":"+assignment.IdentityID)
+	}
+
+	ctx.JSON(http.StatusCreated, assignment)
+}
+
+func (c *PolicyController) DeleteAssignment(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseID(ctx, "Invalid assignment ID")
+	if !ok {
+		return
+	}
+
+	if err := c.policyService.DeleteAssignment(id); err != nil {
+		c.respondPolicyError(ctx, err, "Failed to remove policy assignment")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "policy_unassigned", "policy_assignment", id.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Policy assignment removed successfully"})
+}
+
+func (c *PolicyController) ListAssignments(ctx *gin.Context) {
+	var req model.ListPolicyAssignmentsRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid request format",
+				},
+			})
+			return
+		}
+	}
+	req.Limit, req.Offset = normalizePage(req.Limit, req.Offset)
+
+	assignments, total, err := c.policyService.ListAssignments(&req)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to retrieve policy assignments")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.ListPolicyAssignmentsResponse{
+		Assignments: assignments,
+		Total:       total,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+}
+
+func (c *PolicyController) GetIdentityPolicies(ctx *gin.Context) {
+	identityID, err := uuid.Parse(ctx.Param("identity_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid identity ID",
+			},
+		})
+		return
+	}
+
+	policies, err := c.policyService.GetPoliciesByUserID(identityID)
+	if err != nil {
+		c.respondPolicyError(ctx, err, "Failed to retrieve policies")
+		return
+	}
+
+	response := make([]model.PolicyResponse, 0, len(policies))
+	for i := range policies {
+		response = append(response, services.ToPolicyResponse(&policies[i]))
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PolicyController) parseID(ctx *gin.Context, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: message,
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *PolicyController) respondPolicyError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrPolicyNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_POLICY_NOT_FOUND",
+				Message: "Policy not found",
+			},
+		})
+	case errors.Is(err, services.ErrAssignmentNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ASSIGNMENT_NOT_FOUND",
+				Message: "Policy assignment not found",
+			},
+		})
+	case errors.Is(err, services.ErrGroupNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_GROUP_NOT_FOUND",
+				Message: "Group not found",
+			},
+		})
+	case errors.Is(err, services.ErrAssignmentExists):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ASSIGNMENT_EXISTS",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidPolicy), errors.Is(err, services.ErrInvalidIdentityType):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_POLICY",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
+
+func normalizePage(limit, offset int) (int, int) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
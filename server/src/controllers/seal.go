@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SealController struct {
+	sealService  *services.SealService
+	auditService *services.AuditService
+}
+
+func NewSealController(sealService *services.SealService, auditService *services.AuditService) *SealController {
+	return &SealController{
+		sealService:  sealService,
+		auditService: auditService,
+	}
+}
+
+func (c *SealController) Init(ctx *gin.Context) {
+	var req model.InitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	shares, err := c.sealService.Initialize(req.SecretShares, req.SecretThreshold)
+	if err != nil {
+		c.respondSealError(ctx, err, "Failed to initialize vault")
+		return
+	}
+
+	response := model.InitResponse{
+		Keys:       make([]string, len(shares)),
+		KeysBase64: make([]string, len(shares)),
+	}
+	for i, share := range shares {
+		response.Keys[i] = hex.EncodeToString(share)
+		response.KeysBase64[i] = base64.StdEncoding.EncodeToString(share)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (c *SealController) Status(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.sealService.Status())
+}
+
+func (c *SealController) Unseal(ctx *gin.Context) {
+	var req model.UnsealRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	if req.Reset {
+		ctx.JSON(http.StatusOK, c.sealService.ResetUnseal())
+		return
+	}
+
+	share, err := decodeUnsealKey(req.Key)
+	if err != nil {
+		c.respondSealError(ctx, services.ErrInvalidUnsealKey, "")
+		return
+	}
+
+	status, err := c.sealService.Unseal(share)
+	if err != nil {
+		c.respondSealError(ctx, err, "Failed to unseal vault")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+func (c *SealController) Seal(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	if err := c.sealService.Seal(); err != nil {
+		c.respondSealError(ctx, err, "Failed to seal vault")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(userID.(uuid.UUID), "vault_sealed", "system", "", true, "")
+	}
+
+	ctx.JSON(http.StatusOK, c.sealService.Status())
+}
+
+func (c *SealController) respondSealError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrAlreadyInitialized):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ALREADY_INITIALIZED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrNotInitialized):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_NOT_INITIALIZED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidSealConfig):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidUnsealKey):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_UNSEAL_KEY",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
+
+func decodeUnsealKey(key string) ([]byte, error) {
+	if share, err := hex.DecodeString(key); err == nil {
+		return share, nil
+	}
+	return base64.StdEncoding.DecodeString(key)
+}
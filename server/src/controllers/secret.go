@@ -4,6 +4,7 @@ import (
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -69,8 +70,34 @@ func (c *SecretController) GetSecret(ctx *gin.Context) {
 		return
 	}
 
+	if versionParam := ctx.Query("version"); versionParam != "" {
+		version, err := strconv.Atoi(versionParam)
+		if err != nil || version <= 0 {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_VERSION",
+					Message: "Invalid secret version",
+				},
+			})
+			return
+		}
+
+		secretVersion, err := c.secretService.GetSecretVersion(id, userID.(uuid.UUID), version)
+		if err != nil {
+			c.respondVersionError(ctx, err, "Failed to retrieve secret version")
+			return
+		}
+
+		ctx.JSON(http.StatusOK, secretVersion)
+		return
+	}
+
 	secret, err := c.secretService.GetSecretByID(id, userID.(uuid.UUID))
 	if err != nil {
+		if err == services.ErrSecretVersionDeleted || err == services.ErrSecretVersionDestroyed || err == services.ErrSecretAccessDenied || err == services.ErrSecretExpired {
+			c.respondVersionError(ctx, err, "Failed to retrieve secret")
+			return
+		}
 		if err == services.ErrSecretNotFound {
 			ctx.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: model.ErrorDetail{
@@ -123,9 +150,15 @@ func (c *SecretController) CreateSecret(ctx *gin.Context) {
 		Tags:        req.Tags,
 		ExpiresAt:   req.ExpiresAt,
 		IsActive:    true,
+		MaxVersions: req.MaxVersions,
+		TeamID:      req.TeamID,
 	}
 
 	if err := c.secretService.CreateSecret(secret, userID.(uuid.UUID)); err != nil {
+		if err == services.ErrTeamNotFound || err == services.ErrTeamAccessDenied {
+			c.respondSecretError(ctx, err, "Failed to create secret")
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: model.ErrorDetail{
 				Code:    "VAULT_INTERNAL_ERROR",
@@ -175,6 +208,19 @@ func (c *SecretController) UpdateSecret(ctx *gin.Context) {
 
 	secret, err := c.secretService.UpdateSecret(id, &req, userID.(uuid.UUID))
 	if err != nil {
+		if err == services.ErrInvalidMaxVersions {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "max_versions must not be negative",
+				},
+			})
+			return
+		}
+		if err == services.ErrSecretAccessDenied {
+			c.respondVersionError(ctx, err, "Failed to update secret")
+			return
+		}
 		if err == services.ErrSecretNotFound {
 			ctx.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: model.ErrorDetail{
@@ -221,6 +267,10 @@ func (c *SecretController) DeleteSecret(ctx *gin.Context) {
 	}
 
 	if err := c.secretService.DeleteSecret(id, userID.(uuid.UUID)); err != nil {
+		if err == services.ErrSecretAccessDenied {
+			c.respondVersionError(ctx, err, "Failed to delete secret")
+			return
+		}
 		if err == services.ErrSecretNotFound {
 			ctx.JSON(http.StatusNotFound, model.ErrorResponse{
 				Error: model.ErrorDetail{
@@ -241,3 +291,389 @@ func (c *SecretController) DeleteSecret(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Secret deleted successfully"})
 }
+
+func (c *SecretController) ListSecretVersions(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	versions, err := c.secretService.ListSecretVersions(id, userID.(uuid.UUID))
+	if err != nil {
+		c.respondVersionError(ctx, err, "Failed to retrieve secret versions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, versions)
+}
+
+func (c *SecretController) GetSecretVersion(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(ctx.Param("version"))
+	if err != nil || version <= 0 {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_VERSION",
+				Message: "Invalid secret version",
+			},
+		})
+		return
+	}
+
+	secretVersion, err := c.secretService.GetSecretVersion(id, userID.(uuid.UUID), version)
+	if err != nil {
+		c.respondVersionError(ctx, err, "Failed to retrieve secret version")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, secretVersion)
+}
+
+func (c *SecretController) RestoreSecretVersion(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.RestoreSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	secret, err := c.secretService.RestoreSecretVersion(id, userID.(uuid.UUID), req.Version)
+	if err != nil {
+		c.respondVersionError(ctx, err, "Failed to restore secret version")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, secret)
+}
+
+func (c *SecretController) DeleteSecretVersions(ctx *gin.Context) {
+	c.modifySecretVersions(ctx, c.secretService.DeleteSecretVersions, "Secret versions deleted successfully", "Failed to delete secret versions")
+}
+
+func (c *SecretController) UndeleteSecretVersions(ctx *gin.Context) {
+	c.modifySecretVersions(ctx, c.secretService.UndeleteSecretVersions, "Secret versions restored successfully", "Failed to undelete secret versions")
+}
+
+func (c *SecretController) DestroySecretVersions(ctx *gin.Context) {
+	c.modifySecretVersions(ctx, c.secretService.DestroySecretVersions, "Secret versions destroyed successfully", "Failed to destroy secret versions")
+}
+
+func (c *SecretController) modifySecretVersions(ctx *gin.Context, modify func(uuid.UUID, uuid.UUID, []int) error, successMessage, failureMessage string) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.SecretVersionsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	if err := modify(id, userID.(uuid.UUID), req.Versions); err != nil {
+		c.respondVersionError(ctx, err, failureMessage)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": successMessage, "versions": req.Versions})
+}
+
+// GetTeamSecrets lists the secrets shared with a team the user belongs to.
+func (c *SecretController) GetTeamSecrets(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	teamID, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid team ID",
+			},
+		})
+		return
+	}
+
+	secrets, err := c.secretService.GetTeamSecrets(teamID, userID.(uuid.UUID))
+	if err != nil {
+		c.respondSecretError(ctx, err, "Failed to retrieve team secrets")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"secrets": secrets})
+}
+
+func (c *SecretController) ShareSecret(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.ShareSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	secret, err := c.secretService.ShareSecret(id, userID.(uuid.UUID), req.TeamID)
+	if err != nil {
+		c.respondSecretError(ctx, err, "Failed to share secret")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, secret)
+}
+
+func (c *SecretController) UnshareSecret(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	secret, err := c.secretService.UnshareSecret(id, userID.(uuid.UUID))
+	if err != nil {
+		c.respondSecretError(ctx, err, "Failed to unshare secret")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, secret)
+}
+
+func (c *SecretController) TransferSecret(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	id, ok := c.parseSecretID(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransferSecretRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	secret, err := c.secretService.TransferSecret(id, userID.(uuid.UUID), req.UserID)
+	if err != nil {
+		c.respondSecretError(ctx, err, "Failed to transfer secret")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, secret)
+}
+
+func (c *SecretController) parseSecretID(ctx *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid secret ID",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *SecretController) respondVersionError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrSecretNotFound:
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_NOT_FOUND",
+				Message: "Secret not found",
+			},
+		})
+	case services.ErrSecretAccessDenied:
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ACCESS_DENIED",
+				Message: "Insufficient permission on secret",
+			},
+		})
+	case services.ErrSecretExpired:
+		ctx.JSON(http.StatusGone, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_EXPIRED",
+				Message: "Secret has expired",
+			},
+		})
+	case services.ErrSecretVersionNotFound:
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_VERSION_NOT_FOUND",
+				Message: "Secret version not found",
+			},
+		})
+	case services.ErrSecretVersionDeleted:
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_VERSION_DELETED",
+				Message: "Secret version has been deleted",
+			},
+		})
+	case services.ErrSecretVersionDestroyed:
+		ctx.JSON(http.StatusGone, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_VERSION_DESTROYED",
+				Message: "Secret version has been destroyed",
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
+
+// respondSecretError maps the errors of sharing and ownership changes, and
+// falls back to respondVersionError for the rest.
+func (c *SecretController) respondSecretError(ctx *gin.Context, err error, message string) {
+	switch err {
+	case services.ErrTeamNotFound:
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_NOT_FOUND",
+				Message: "Team not found",
+			},
+		})
+	case services.ErrTeamAccessDenied:
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ACCESS_DENIED",
+				Message: "Insufficient team permission",
+			},
+		})
+	case services.ErrUserNotFound:
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_USER_NOT_FOUND",
+				Message: "User not found",
+			},
+		})
+	case services.ErrSecretNotShared:
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SECRET_NOT_SHARED",
+				Message: err.Error(),
+			},
+		})
+	default:
+		c.respondVersionError(ctx, err, message)
+	}
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SessionController struct {
+	sessionService *services.SessionService
+	auditService   *services.AuditService
+}
+
+func NewSessionController(sessionService *services.SessionService, auditService *services.AuditService) *SessionController {
+	return &SessionController{
+		sessionService: sessionService,
+		auditService:   auditService,
+	}
+}
+
+// ListMySessions returns the active sessions of the current user.
+func (c *SessionController) ListMySessions(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	active := true
+	req := model.ListSessionsRequest{
+		IdentityID: userID.String(),
+		IsActive:   &active,
+	}
+	req.Limit, _ = strconv.Atoi(ctx.Query("limit"))
+	req.Offset, _ = strconv.Atoi(ctx.Query("offset"))
+
+	c.list(ctx, &req)
+}
+
+// RevokeMySession ends one of the current user's sessions.
+func (c *SessionController) RevokeMySession(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := c.parseID(ctx, "id", "Invalid session ID")
+	if !ok {
+		return
+	}
+
+	c.revoke(ctx, userID, sessionID, &userID)
+}
+
+// RevokeMySessions ends every session of the current user, including the
+// one making the request.
+func (c *SessionController) RevokeMySessions(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	c.revokeAll(ctx, userID, userID)
+}
+
+// SearchSessions lists sessions of any identity; admin only.
+func (c *SessionController) SearchSessions(ctx *gin.Context) {
+	var req model.ListSessionsRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid request format",
+				},
+			})
+			return
+		}
+	}
+	if req.IdentityID != "" {
+		if _, err := uuid.Parse(req.IdentityID); err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_ID",
+					Message: "Invalid identity ID",
+				},
+			})
+			return
+		}
+	}
+
+	c.list(ctx, &req)
+}
+
+// RevokeSession ends any session; admin only.
+func (c *SessionController) RevokeSession(ctx *gin.Context) {
+	adminID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	sessionID, ok := c.parseID(ctx, "id", "Invalid session ID")
+	if !ok {
+		return
+	}
+
+	c.revoke(ctx, adminID, sessionID, nil)
+}
+
+// RevokeIdentitySessions ends every session of an identity; admin only.
+func (c *SessionController) RevokeIdentitySessions(ctx *gin.Context) {
+	adminID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	identityID, ok := c.parseID(ctx, "identity_id", "Invalid identity ID")
+	if !ok {
+		return
+	}
+
+	c.revokeAll(ctx, adminID, identityID)
+}
+
+func (c *SessionController) list(ctx *gin.Context, req *model.ListSessionsRequest) {
+	req.Limit, req.Offset = normalizePage(req.Limit, req.Offset)
+
+	sessions, total, err := c.sessionService.List(req)
+	if err != nil {
+		c.respondSessionError(ctx, err, "Failed to retrieve sessions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.ListSessionsResponse{
+		Sessions: sessions,
+		Total:    total,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	})
+}
+
+func (c *SessionController) revoke(ctx *gin.Context, actorID, sessionID uuid.UUID, ownerID *uuid.UUID) {
+	if err := c.sessionService.Revoke(sessionID, ownerID); err != nil {
+		if c.auditService != nil {
+			c.auditService.LogAction(actorID, "session_revoked", "session", sessionID.String(), false, err.Error())
+		}
+		c.respondSessionError(ctx, err, "Failed to revoke session")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(actorID, "session_revoked", "session", sessionID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+func (c *SessionController) revokeAll(ctx *gin.Context, actorID, identityID uuid.UUID) {
+	if err := c.sessionService.RevokeAll(identityID); err != nil {
+		if c.auditService != nil {
+			c.auditService.LogAction(actorID, "sessions_revoked", "user", identityID.String(), false, err.Error())
+		}
+		c.respondSessionError(ctx, err, "Failed to revoke sessions")
+		return
+	}
+
+	if c.auditService != nil {
+		c.auditService.LogAction(actorID, "sessions_revoked", "user", identityID.String(), true, "")
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Sessions revoked successfully"})
+}
+
+func (c *SessionController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *SessionController) parseID(ctx *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: message,
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *SessionController) respondSessionError(ctx *gin.Context, err error, message string) {
+	if errors.Is(err, services.ErrSessionNotFound) {
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SESSION_NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+		Error: model.ErrorDetail{
+			Code:    "VAULT_INTERNAL_ERROR",
+			Message: message,
+		},
+	})
+}
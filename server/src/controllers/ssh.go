@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SSHController struct {
+	sshService   *services.SSHService
+	auditService *services.AuditService
+}
+
+func NewSSHController(sshService *services.SSHService, auditService *services.AuditService) *SSHController {
+	return &SSHController{
+		sshService:   sshService,
+		auditService: auditService,
+	}
+}
+
+// ConfigureCA generates or imports the CA key, for administrators. An
+// imported private key is never audited.
+func (c *SSHController) ConfigureCA(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.SSHConfigCARequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	ca, err := c.sshService.ConfigureCA(&req, userID)
+	details := "generated"
+	if req.PrivateKey != "" {
+		details = "imported"
+	}
+	c.logAction(userID, "ssh_ca_configured", "ssh_ca", "", err, details)
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to configure SSH CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ca)
+}
+
+func (c *SSHController) GetCA(ctx *gin.Context) {
+	ca, err := c.sshService.GetCA()
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to get SSH CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ca)
+}
+
+func (c *SSHController) DeleteCA(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	err := c.sshService.DeleteCA()
+	c.logAction(userID, "ssh_ca_deleted", "ssh_ca", "", err, "")
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to delete SSH CA")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "SSH CA deleted successfully"})
+}
+
+// PublicKey serves the CA public key as a line for TrustedUserCAKeys or
+// @cert-authority entries.
+func (c *SSHController) PublicKey(ctx *gin.Context) {
+	ca, err := c.sshService.GetCA()
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to get SSH CA")
+		return
+	}
+
+	ctx.String(http.StatusOK, ca.PublicKey+"\n")
+}
+
+// KRL serves the key revocation list for RevokedKeys.
+func (c *SSHController) KRL(ctx *gin.Context) {
+	krl, err := c.sshService.KRL()
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to build KRL")
+		return
+	}
+
+	ctx.Data(http.StatusOK, "application/octet-stream", krl)
+}
+
+// WriteRole creates or replaces a role, for administrators.
+func (c *SSHController) WriteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.SSHRoleRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role, err := c.sshService.WriteRole(&req)
+	c.logAction(userID, "ssh_role_written", "ssh_role", req.Name, err, "")
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to write SSH role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *SSHController) ListRoles(ctx *gin.Context) {
+	roles, err := c.sshService.ListRoles()
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to list SSH roles")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"roles": roles,
+		"total": len(roles),
+	})
+}
+
+func (c *SSHController) GetRole(ctx *gin.Context) {
+	role, err := c.sshService.GetRole(ctx.Param("name"))
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to get SSH role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, role)
+}
+
+func (c *SSHController) DeleteRole(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	err := c.sshService.DeleteRole(name)
+	c.logAction(userID, "ssh_role_deleted", "ssh_role", name, err, "")
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to delete SSH role")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "SSH role deleted successfully"})
+}
+
+// Sign signs a public key from a role.
+func (c *SSHController) Sign(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.SSHSignRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	role := ctx.Param("role")
+	resp, err := c.sshService.Sign(role, &req, userID)
+	details := "principals=" + strings.Join(req.ValidPrincipals, ",")
+	if resp != nil {
+		details += fmt.Sprintf(" serial_number=%d key_id=%s", resp.SerialNumber, resp.KeyID)
+	}
+	c.logAction(userID, "ssh_key_signed", "ssh_role", role, err, details)
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to sign SSH key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// ListCertificates lists certificate records, optionally of one role or only
+// revoked ones.
+func (c *SSHController) ListCertificates(ctx *gin.Context) {
+	certs, err := c.sshService.ListCertificates(ctx.Query("role"), ctx.Query("revoked") == "true")
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to list SSH certificates")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"certificates": certs,
+		"total":        len(certs),
+	})
+}
+
+func (c *SSHController) Revoke(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.SSHRevokeRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.sshService.Revoke(&req)
+	target := req.KeyID
+	if target == "" {
+		target = fmt.Sprintf("%d", req.SerialNumber)
+	}
+	details := ""
+	if req.Reason != "" {
+		details = "reason=" + req.Reason
+	}
+	c.logAction(userID, "ssh_certificate_revoked", "ssh_certificate", target, err, details)
+	if err != nil {
+		c.respondSSHError(ctx, err, "Failed to revoke SSH certificate")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *SSHController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *SSHController) logAction(userID uuid.UUID, action, resource, name string, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, resource, name, err == nil, details)
+}
+
+func (c *SSHController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *SSHController) respondSSHError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrSSHCANotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SSH_CA_NOT_FOUND",
+				Message: "No SSH CA is configured",
+			},
+		})
+	case errors.Is(err, services.ErrSSHRoleNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SSH_ROLE_NOT_FOUND",
+				Message: "SSH role not found",
+			},
+		})
+	case errors.Is(err, services.ErrSSHCertificateNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SSH_CERTIFICATE_NOT_FOUND",
+				Message: "SSH certificate not found",
+			},
+		})
+	case errors.Is(err, services.ErrSSHCAExists):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SSH_CA_EXISTS",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrSSHPrincipalNotAllowed),
+		errors.Is(err, services.ErrSSHOptionNotAllowed):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SSH_NOT_ALLOWED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrInvalidSSHRole),
+		errors.Is(err, services.ErrInvalidSSHRoleTTL),
+		errors.Is(err, services.ErrInvalidSSHCAKey),
+		errors.Is(err, services.ErrInvalidSSHPublicKey),
+		errors.Is(err, services.ErrInvalidSSHRequest):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
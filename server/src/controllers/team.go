@@ -0,0 +1,273 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TeamController struct {
+	teamService  *services.TeamService
+	auditService *services.AuditService
+}
+
+func NewTeamController(teamService *services.TeamService, auditService *services.AuditService) *TeamController {
+	return &TeamController{
+		teamService:  teamService,
+		auditService: auditService,
+	}
+}
+
+// ListTeams returns the teams the current user belongs to.
+func (c *TeamController) ListTeams(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	teams, err := c.teamService.ListTeams(userID)
+	if err != nil {
+		c.respondTeamError(ctx, err, "Failed to retrieve teams")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+func (c *TeamController) CreateTeam(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.CreateTeamRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	team := &model.Team{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if err := c.teamService.CreateTeam(team, userID); err != nil {
+		c.respondTeamError(ctx, err, "Failed to create team")
+		return
+	}
+
+	c.logAction(userID, "team_created", team.ID, nil, "")
+
+	ctx.JSON(http.StatusCreated, team)
+}
+
+func (c *TeamController) GetTeam(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := c.parseID(ctx, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	team, err := c.teamService.GetTeam(id, userID)
+	if err != nil {
+		c.respondTeamError(ctx, err, "Failed to retrieve team")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, team)
+}
+
+func (c *TeamController) DeleteTeam(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := c.parseID(ctx, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	err := c.teamService.DeleteTeam(id, userID)
+	c.logAction(userID, "team_deleted", id, err, "")
+	if err != nil {
+		c.respondTeamError(ctx, err, "Failed to delete team")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team deleted successfully"})
+}
+
+// SetMember adds a member or changes the permission of an existing one.
+func (c *TeamController) SetMember(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := c.parseID(ctx, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	var req model.TeamMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	err := c.teamService.SetMember(id, userID, req.UserID, req.Permission)
+	c.logAction(userID, "team_member_set", id, err, "member="+req.UserID.String()+" permission="+req.Permission)
+	if err != nil {
+		c.respondTeamError(ctx, err, "Failed to set team member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team member saved successfully"})
+}
+
+// RemoveMember removes a member; members may also remove themselves.
+func (c *TeamController) RemoveMember(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	id, ok := c.parseID(ctx, "id", "Invalid team ID")
+	if !ok {
+		return
+	}
+
+	memberID, ok := c.parseID(ctx, "user_id", "Invalid user ID")
+	if !ok {
+		return
+	}
+
+	err := c.teamService.RemoveMember(id, userID, memberID)
+	c.logAction(userID, "team_member_removed", id, err, "member="+memberID.String())
+	if err != nil {
+		c.respondTeamError(ctx, err, "Failed to remove team member")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Team member removed successfully"})
+}
+
+func (c *TeamController) logAction(userID uuid.UUID, action string, teamID uuid.UUID, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, "team", teamID.String(), err == nil, details)
+}
+
+func (c *TeamController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *TeamController) parseID(ctx *gin.Context, param, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(ctx.Param(param))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: message,
+			},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (c *TeamController) respondTeamError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTeamNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_NOT_FOUND",
+				Message: "Team not found",
+			},
+		})
+	case errors.Is(err, services.ErrTeamMemberNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_MEMBER_NOT_FOUND",
+				Message: "Team member not found",
+			},
+		})
+	case errors.Is(err, services.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_USER_NOT_FOUND",
+				Message: "User not found",
+			},
+		})
+	case errors.Is(err, services.ErrTeamAccessDenied):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ACCESS_DENIED",
+				Message: "Insufficient team permission",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidTeamPermission):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrTeamExists):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_EXISTS",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrTeamNotEmpty), errors.Is(err, services.ErrLastTeamManager):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_CONFLICT",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -71,14 +74,44 @@ func (c *TOTPController) CreateTOTP(ctx *gin.Context) {
 	totp := &model.TOTP{
 		Name:        req.Name,
 		Description: req.Description,
+		Issuer:      req.Issuer,
 		Secret:      req.Secret,
 		Algorithm:   req.Algorithm,
 		Digits:      req.Digits,
 		Period:      req.Period,
 		IsActive:    true,
+		TeamID:      req.TeamID,
 	}
 
-	if err := c.totpService.CreateTOTP(totp, userID.(uuid.UUID)); err != nil {
+	backupCodes, err := c.totpService.CreateTOTP(totp, userID.(uuid.UUID))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidTOTP) {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: err.Error(),
+				},
+			})
+			return
+		}
+		if err == services.ErrTeamNotFound {
+			ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_TEAM_NOT_FOUND",
+					Message: "Team not found",
+				},
+			})
+			return
+		}
+		if err == services.ErrTeamAccessDenied {
+			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_ACCESS_DENIED",
+					Message: "Insufficient team permission",
+				},
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: model.ErrorDetail{
 				Code:    "VAULT_INTERNAL_ERROR",
@@ -89,7 +122,10 @@ func (c *TOTPController) CreateTOTP(ctx *gin.Context) {
 	}
 
 	totp.Secret = ""
-	ctx.JSON(http.StatusCreated, totp)
+	ctx.JSON(http.StatusCreated, model.CreateTOTPResponse{
+		TOTP:        *totp,
+		BackupCodes: backupCodes,
+	})
 }
 
 func (c *TOTPController) GenerateCode(ctx *gin.Context) {
@@ -127,6 +163,15 @@ func (c *TOTPController) GenerateCode(ctx *gin.Context) {
 			})
 			return
 		}
+		if err == services.ErrTOTPAccessDenied {
+			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_ACCESS_DENIED",
+					Message: "Insufficient permission on TOTP",
+				},
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: model.ErrorDetail{
 				Code:    "VAULT_INTERNAL_ERROR",
@@ -138,3 +183,248 @@ func (c *TOTPController) GenerateCode(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, response)
 }
+
+// Verify checks a TOTP or backup code for an entry, so applications can use
+// the vault as their OTP verifier.
+func (c *TOTPController) Verify(ctx *gin.Context) {
+	userID, id, ok := c.entry(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TOTPVerifyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	response, err := c.totpService.Verify(id, userID, &req)
+	if errors.Is(err, services.ErrTOTPLocked) {
+		retryAfter := int(time.Until(*response.LockedUntil).Seconds()) + 1
+		ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+		ctx.JSON(http.StatusTooManyRequests, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TOTP_LOCKED",
+				Message: err.Error(),
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to verify TOTP code")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// RegenerateBackupCodes replaces an entry's backup codes and returns the new
+// ones.
+func (c *TOTPController) RegenerateBackupCodes(ctx *gin.Context) {
+	userID, id, ok := c.entry(ctx)
+	if !ok {
+		return
+	}
+
+	codes, err := c.totpService.RegenerateBackupCodes(id, userID)
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to regenerate backup codes")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.TOTPBackupCodesResponse{BackupCodes: codes})
+}
+
+// Import creates entries from otpauth:// URIs and Google Authenticator
+// otpauth-migration:// exports.
+func (c *TOTPController) Import(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	var req model.TOTPImportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return
+	}
+
+	imported, err := c.totpService.ImportTOTPs(&req, userID.(uuid.UUID))
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to import TOTPs")
+		return
+	}
+
+	status := http.StatusCreated
+	if req.DryRun {
+		status = http.StatusOK
+	}
+	ctx.JSON(status, model.TOTPImportResponse{
+		Imported: imported,
+		Total:    len(imported),
+	})
+}
+
+// Export returns every readable entry as otpauth:// URIs, or as
+// otpauth-migration:// batches with ?format=migration.
+func (c *TOTPController) Export(ctx *gin.Context) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "otpauth")
+	uris, skipped, err := c.totpService.ExportTOTPs(userID.(uuid.UUID), format)
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to export TOTPs")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.TOTPExportResponse{
+		URIs:    uris,
+		Format:  format,
+		Total:   len(uris),
+		Skipped: skipped,
+	})
+}
+
+func (c *TOTPController) ExportURI(ctx *gin.Context) {
+	userID, id, ok := c.entry(ctx)
+	if !ok {
+		return
+	}
+
+	uri, err := c.totpService.ExportURI(id, userID)
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to export TOTP")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"uri": uri})
+}
+
+// QRCode returns the entry's otpauth:// URI as a PNG QR code; ?size sets
+// its width in pixels.
+func (c *TOTPController) QRCode(ctx *gin.Context) {
+	userID, id, ok := c.entry(ctx)
+	if !ok {
+		return
+	}
+
+	size, err := strconv.Atoi(ctx.DefaultQuery("size", "256"))
+	if err != nil || size < 64 || size > 1024 {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "size must be between 64 and 1024",
+			},
+		})
+		return
+	}
+
+	png, err := c.totpService.QRCode(id, userID, size)
+	if err != nil {
+		c.respondTOTPError(ctx, err, "Failed to render QR code")
+		return
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Data(http.StatusOK, "image/png", png)
+}
+
+// entry returns the caller and the TOTP ID of the route, or responds with an
+// error.
+func (c *TOTPController) entry(ctx *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ID",
+				Message: "Invalid TOTP ID",
+			},
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID.(uuid.UUID), id, true
+}
+
+func (c *TOTPController) respondTOTPError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTOTPNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TOTP_NOT_FOUND",
+				Message: "TOTP not found",
+			},
+		})
+	case errors.Is(err, services.ErrTOTPAccessDenied):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ACCESS_DENIED",
+				Message: "Insufficient permission on TOTP",
+			},
+		})
+	case errors.Is(err, services.ErrTeamNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TEAM_NOT_FOUND",
+				Message: "Team not found",
+			},
+		})
+	case errors.Is(err, services.ErrTeamAccessDenied):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_ACCESS_DENIED",
+				Message: "Insufficient team permission",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidTOTP):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type TransitController struct {
+	transitService *services.TransitService
+	auditService   *services.AuditService
+}
+
+func NewTransitController(transitService *services.TransitService, auditService *services.AuditService) *TransitController {
+	return &TransitController{
+		transitService: transitService,
+		auditService:   auditService,
+	}
+}
+
+// CreateKey creates a named key, for administrators.
+func (c *TransitController) CreateKey(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitCreateKeyRequest
+	if ctx.Request.ContentLength != 0 && !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	key, err := c.transitService.CreateKey(name, &req, userID)
+	c.logAction(userID, "transit_key_created", "transit_key", name, err, "type="+req.Type)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to create transit key")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+func (c *TransitController) ListKeys(ctx *gin.Context) {
+	keys, err := c.transitService.ListKeys()
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to list transit keys")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"keys":  keys,
+		"total": len(keys),
+	})
+}
+
+func (c *TransitController) GetKey(ctx *gin.Context) {
+	key, err := c.transitService.GetKey(ctx.Param("name"))
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to get transit key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+// ConfigureKey sets a key's minimum versions and deletion flag, for
+// administrators.
+func (c *TransitController) ConfigureKey(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitKeyConfigRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	key, err := c.transitService.ConfigureKey(name, &req)
+	details := ""
+	if key != nil {
+		details = fmt.Sprintf("min_decryption_version=%d min_encryption_version=%d deletion_allowed=%t",
+			key.MinDecryptionVersion, key.MinEncryptionVersion, key.DeletionAllowed)
+	}
+	c.logAction(userID, "transit_key_configured", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to configure transit key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+// RotateKey adds a key version, for administrators.
+func (c *TransitController) RotateKey(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	key, err := c.transitService.RotateKey(name)
+	details := ""
+	if key != nil {
+		details = fmt.Sprintf("version=%d", key.LatestVersion)
+	}
+	c.logAction(userID, "transit_key_rotated", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to rotate transit key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, key)
+}
+
+func (c *TransitController) DeleteKey(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	name := ctx.Param("name")
+	err := c.transitService.DeleteKey(name)
+	c.logAction(userID, "transit_key_deleted", "transit_key", name, err, "")
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to delete transit key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Transit key deleted successfully"})
+}
+
+func (c *TransitController) Encrypt(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitEncryptRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	resp, err := c.transitService.Encrypt(name, &req)
+	details := ""
+	if resp != nil {
+		details = fmt.Sprintf("key_version=%d", resp.KeyVersion)
+	}
+	c.logAction(userID, "transit_encrypt", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to encrypt")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TransitController) Decrypt(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitDecryptRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	resp, err := c.transitService.Decrypt(name, &req)
+	c.logAction(userID, "transit_decrypt", "transit_key", name, err, "")
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to decrypt")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TransitController) Rewrap(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitRewrapRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	resp, err := c.transitService.Rewrap(name, &req)
+	details := ""
+	if resp != nil {
+		details = fmt.Sprintf("key_version=%d", resp.KeyVersion)
+	}
+	c.logAction(userID, "transit_rewrap", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to rewrap")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// GenerateDataKey returns a new data key encrypted with the transit key,
+// and also in plaintext when the route's type is "plaintext".
+func (c *TransitController) GenerateDataKey(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitDataKeyRequest
+	if ctx.Request.ContentLength != 0 && !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	kind := ctx.Param("type")
+	resp, err := c.transitService.GenerateDataKey(name, kind, &req)
+	details := "type=" + kind
+	if resp != nil {
+		details += fmt.Sprintf(" key_version=%d", resp.KeyVersion)
+	}
+	c.logAction(userID, "transit_datakey_generated", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to generate data key")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TransitController) Sign(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitSignRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	resp, err := c.transitService.Sign(name, &req)
+	details := ""
+	if resp != nil {
+		details = fmt.Sprintf("key_version=%d", resp.KeyVersion)
+	}
+	c.logAction(userID, "transit_sign", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to sign")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// Verify checks a signature or HMAC; it reveals nothing and is not audited.
+func (c *TransitController) Verify(ctx *gin.Context) {
+	var req model.TransitVerifyRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	resp, err := c.transitService.Verify(ctx.Param("name"), &req)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to verify")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TransitController) HMAC(ctx *gin.Context) {
+	userID, ok := c.currentUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req model.TransitHMACRequest
+	if !c.bind(ctx, &req) {
+		return
+	}
+
+	name := ctx.Param("name")
+	resp, err := c.transitService.HMAC(name, &req)
+	details := ""
+	if resp != nil {
+		details = fmt.Sprintf("key_version=%d", resp.KeyVersion)
+	}
+	c.logAction(userID, "transit_hmac", "transit_key", name, err, details)
+	if err != nil {
+		c.respondTransitError(ctx, err, "Failed to compute HMAC")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (c *TransitController) bind(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *TransitController) logAction(userID uuid.UUID, action, resource, name string, err error, details string) {
+	if c.auditService == nil {
+		return
+	}
+	if err != nil {
+		if details != "" {
+			details += " "
+		}
+		details += err.Error()
+	}
+	c.auditService.LogAction(userID, action, resource, name, err == nil, details)
+}
+
+func (c *TransitController) currentUser(ctx *gin.Context) (uuid.UUID, bool) {
+	userID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		return uuid.Nil, false
+	}
+	return userID.(uuid.UUID), true
+}
+
+func (c *TransitController) respondTransitError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrTransitKeyNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TRANSIT_KEY_NOT_FOUND",
+				Message: "Transit key not found",
+			},
+		})
+	case errors.Is(err, services.ErrTransitKeyExists):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TRANSIT_KEY_EXISTS",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrTransitDeletionNotAllowed),
+		errors.Is(err, services.ErrTransitKeyVersionNotAllowed):
+		ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_TRANSIT_NOT_ALLOWED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrTransitOperationUnsupported),
+		errors.Is(err, services.ErrTransitDecryptionFailed),
+		errors.Is(err, services.ErrInvalidTransitKeyType),
+		errors.Is(err, services.ErrInvalidTransitKeyConfig),
+		errors.Is(err, services.ErrInvalidTransitCiphertext),
+		errors.Is(err, services.ErrInvalidTransitRequest):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
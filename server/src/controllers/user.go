@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"net/http"
@@ -25,8 +26,13 @@ func NewUserController(userService *services.UserService, auditService *services
 }
 
 func (c *UserController) GetUsers(ctx *gin.Context) {
+	query := c.db.Preload("Groups").Where("is_active = ?", true)
+	if role := ctx.Query("role"); role != "" {
+		query = query.Where("role = ?", role)
+	}
+
 	var users []model.User
-	if err := c.db.Where("is_active = ?", true).Find(&users).Error; err != nil {
+	if err := query.Find(&users).Error; err != nil {
 		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
 			Error: model.ErrorDetail{
 				Code:    "VAULT_INTERNAL_ERROR",
@@ -78,6 +84,16 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 		return
 	}
 
+	if err := c.db.Model(user).Association("Groups").Find(&user.Groups); err != nil {
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to retrieve user",
+			},
+		})
+		return
+	}
+
 	user.Password = ""
 	user.Secrets = nil
 	user.TOTPs = nil
@@ -91,6 +107,7 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 		Password  string `json:"password" binding:"required,min=8"`
 		FirstName string `json:"first_name" binding:"required"`
 		LastName  string `json:"last_name" binding:"required"`
+		Role      string `json:"role"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -103,28 +120,38 @@ func (c *UserController) CreateUser(ctx *gin.Context) {
 		return
 	}
 
+	if req.Role == "" {
+		req.Role = model.RoleUser
+	}
+	if !model.IsValidRole(req.Role) {
+		c.respondUserError(ctx, services.ErrInvalidRole, "Failed to create user")
+		return
+	}
+
+	actorID, actorRole := c.actor(ctx)
+	if !canManageRole(actorRole, req.Role) {
+		c.respondForbidden(ctx)
+		return
+	}
+
 	user := &model.User{
 		Email:     req.Email,
 		Password:  req.Password,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
 		IsActive:  true,
+		Role:      req.Role,
 	}
 
 	if err := c.userService.CreateUser(user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "VAULT_INTERNAL_ERROR",
-				Message: "Failed to create user",
-			},
-		})
+		c.respondUserError(ctx, err, "Failed to create user")
 		return
 	}
 
 	user.Password = ""
 
 	if c.auditService != nil {
-		c.auditService.LogAction(user.ID, "user_created", "user", user.ID.String(), true, "")
+		c.auditService.LogAction(actorID, "user_created", "user", user.ID.String(), true, "")
 	}
 
 	ctx.JSON(http.StatusCreated, user)
@@ -147,6 +174,7 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		FirstName *string `json:"first_name"`
 		LastName  *string `json:"last_name"`
 		IsActive  *bool   `json:"is_active"`
+		Role      *string `json:"role"`
 	}
 
 	if err := ctx.ShouldBindJSON(&req); err != nil {
@@ -179,6 +207,30 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
+	// Users may edit their own profile; changing a role or the active flag,
+	// and editing other accounts, needs a role allowed to manage the account.
+	actorID, actorRole := c.actor(ctx)
+	if (req.IsActive != nil || req.Role != nil || actorID != id) && !canManageRole(actorRole, user.Role) {
+		c.respondForbidden(ctx)
+		return
+	}
+	if req.Role != nil {
+		if !model.IsValidRole(*req.Role) {
+			c.respondUserError(ctx, services.ErrInvalidRole, "Failed to update user")
+			return
+		}
+		if !canManageRole(actorRole, *req.Role) {
+			c.respondForbidden(ctx)
+			return
+		}
+	}
+	if (req.Role != nil && *req.Role != user.Role) || (req.IsActive != nil && !*req.IsActive) {
+		if err := c.userService.CheckAdminRemoval(user); err != nil {
+			c.respondUserError(ctx, err, "Failed to update user")
+			return
+		}
+	}
+
 	if req.FirstName != nil {
 		user.FirstName = *req.FirstName
 	}
@@ -188,14 +240,12 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 	if req.IsActive != nil {
 		user.IsActive = *req.IsActive
 	}
+	if req.Role != nil {
+		user.Role = *req.Role
+	}
 
 	if err := c.userService.UpdateUser(user); err != nil {
-		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "VAULT_INTERNAL_ERROR",
-				Message: "Failed to update user",
-			},
-		})
+		c.respondUserError(ctx, err, "Failed to update user")
 		return
 	}
 
@@ -204,7 +254,7 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 	user.TOTPs = nil
 
 	if c.auditService != nil {
-		c.auditService.LogAction(id, "user_updated", "user", user.ID.String(), true, "")
+		c.auditService.LogAction(actorID, "user_updated", "user", user.ID.String(), true, "")
 	}
 
 	ctx.JSON(http.StatusOK, user)
@@ -223,19 +273,90 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
+	user, err := c.userService.GetUserByID(id)
+	if err != nil {
+		c.respondUserError(ctx, err, "Failed to delete user")
+		return
+	}
+
+	if err := c.userService.CheckAdminRemoval(user); err != nil {
+		c.respondUserError(ctx, err, "Failed to delete user")
+		return
+	}
+
 	if err := c.userService.DeleteUser(id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
-			Error: model.ErrorDetail{
-				Code:    "VAULT_INTERNAL_ERROR",
-				Message: "Failed to delete user",
-			},
-		})
+		c.respondUserError(ctx, err, "Failed to delete user")
 		return
 	}
 
+	actorID, _ := c.actor(ctx)
 	if c.auditService != nil {
-		c.auditService.LogAction(id, "user_deleted", "user", id.String(), true, "")
+		c.auditService.LogAction(actorID, "user_deleted", "user", id.String(), true, "")
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
+
+// actor returns the calling user and the role stored by the user middleware.
+func (c *UserController) actor(ctx *gin.Context) (uuid.UUID, string) {
+	var actorID uuid.UUID
+	if userID, exists := ctx.Get("user_id"); exists {
+		actorID = userID.(uuid.UUID)
+	}
+	return actorID, ctx.GetString("user_role")
+}
+
+// canManageRole reports whether a user holding actorRole may manage accounts
+// with the target role. Operators only manage regular users.
+func canManageRole(actorRole, targetRole string) bool {
+	switch actorRole {
+	case model.RoleAdmin:
+		return true
+	case model.RoleOperator:
+		return targetRole == model.RoleUser
+	default:
+		return false
+	}
+}
+
+func (c *UserController) respondForbidden(ctx *gin.Context) {
+	ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+		Error: model.ErrorDetail{
+			Code:    "VAULT_ACCESS_DENIED",
+			Message: "Access denied: insufficient permissions",
+		},
+	})
+}
+
+func (c *UserController) respondUserError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_USER_NOT_FOUND",
+				Message: "User not found",
+			},
+		})
+	case errors.Is(err, services.ErrInvalidRole):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_ROLE",
+				Message: "Role must be one of admin, operator, user or auditor",
+			},
+		})
+	case errors.Is(err, services.ErrLastAdmin):
+		ctx.JSON(http.StatusConflict, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_LAST_ADMIN",
+				Message: err.Error(),
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
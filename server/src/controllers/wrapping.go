@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WrappingController struct {
+	wrappingService *services.WrappingService
+	auditService    *services.AuditService
+}
+
+func NewWrappingController(wrappingService *services.WrappingService, auditService *services.AuditService) *WrappingController {
+	return &WrappingController{
+		wrappingService: wrappingService,
+		auditService:    auditService,
+	}
+}
+
+// Unwrap returns the response behind a wrapping token and consumes the
+// token. The token is its own credential, so no login is required.
+func (c *WrappingController) Unwrap(ctx *gin.Context) {
+	var req model.WrappingTokenRequest
+	if !c.bindToken(ctx, &req) {
+		return
+	}
+
+	payload, entry, err := c.wrappingService.Unwrap(req.Token)
+	if c.auditService != nil {
+		// Replayed or unknown tokens are logged too, without an accessor.
+		var accessor, details string
+		if entry != nil {
+			accessor, details = entry.ID.String(), "path="+entry.CreationPath
+		}
+		if err != nil {
+			if details != "" {
+				details += " "
+			}
+			details += err.Error()
+		}
+		c.auditService.LogAnonymousAction("response_unwrapped", "wrapping", accessor, ctx.ClientIP(), ctx.GetHeader("User-Agent"), err == nil, details)
+	}
+	if err != nil {
+		c.respondWrappingError(ctx, err, "Failed to unwrap token")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.UnwrapResponse{Data: payload})
+}
+
+// Lookup reports where and when a wrapping token was created and how long it
+// remains valid, without consuming it.
+func (c *WrappingController) Lookup(ctx *gin.Context) {
+	var req model.WrappingTokenRequest
+	if !c.bindToken(ctx, &req) {
+		return
+	}
+
+	info, err := c.wrappingService.Lookup(req.Token)
+	if err != nil {
+		c.respondWrappingError(ctx, err, "Failed to look up token")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+func (c *WrappingController) bindToken(ctx *gin.Context, req *model.WrappingTokenRequest) bool {
+	if c.wrappingService == nil {
+		ctx.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_WRAPPING_UNAVAILABLE",
+				Message: "Response wrapping requires a database",
+			},
+		})
+		return false
+	}
+
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: "Invalid request format",
+			},
+		})
+		return false
+	}
+	return true
+}
+
+func (c *WrappingController) respondWrappingError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrWrappingTokenNotFound):
+		ctx.JSON(http.StatusNotFound, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_WRAPPING_TOKEN_NOT_FOUND",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrWrappingTokenExpired):
+		ctx.JSON(http.StatusGone, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_WRAPPING_TOKEN_EXPIRED",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrKeyringLocked):
+		ctx.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SEALED",
+				Message: "Vault is sealed",
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: message,
+			},
+		})
+	}
+}
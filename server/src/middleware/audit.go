@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"io"
@@ -99,10 +100,14 @@ func (m *AuditMiddleware) getResourceFromRequest(ctx *gin.Context) string {
 		return "secret"
 	case strings.Contains(path, "/totp"):
 		return "totp"
+	case strings.Contains(path, "/policies"):
+		return "policy"
 	case strings.Contains(path, "/identity"):
 		return "identity"
 	case strings.Contains(path, "/audit"):
 		return "audit"
+	case strings.Contains(path, "/sys/"):
+		return "system"
 	case strings.Contains(path, "/health"):
 		return "system"
 	case strings.Contains(path, "/version"):
@@ -131,23 +136,42 @@ func (m *AuditMiddleware) getAuditDetails(ctx *gin.Context, body []byte, duratio
 		"status_code": statusCode,
 	}
 
-	if len(body) > 0 && !m.isSensitiveEndpoint(ctx) {
+	if len(body) > 0 && m.logsRequestBody(ctx) {
 		details["request_body"] = string(body)
 	}
 
-	return fmt.Sprintf("%+v", details)
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Sprintf("%+v", details)
+	}
+	return string(data)
 }
 
-func (m *AuditMiddleware) isSensitiveEndpoint(ctx *gin.Context) bool {
-	path := ctx.Request.URL.Path
-	method := ctx.Request.Method
-
-	sensitiveEndpoints := map[string]bool{
-		"POST:/api/v1/auth/login": true,
-		"POST:/api/v1/secrets":    true,
-		"PUT:/api/v1/secrets":     true,
+// logsRequestBody reports whether the request body goes into the audit
+// entry. Entries are chained and cannot be edited later, so bodies are only
+// kept for endpoints known to carry no secrets; every other route, including
+// credentials, engine configuration and transit data, is logged without it.
+func (m *AuditMiddleware) logsRequestBody(ctx *gin.Context) bool {
+	loggedEndpoints := map[string]bool{
+		"POST:/api/v1/secrets/:id/restore":           true,
+		"POST:/api/v1/secrets/:id/versions/delete":   true,
+		"POST:/api/v1/secrets/:id/versions/undelete": true,
+		"POST:/api/v1/secrets/:id/versions/destroy":  true,
+		"PUT:/api/v1/secrets/:id/share":              true,
+		"POST:/api/v1/secrets/:id/transfer":          true,
+		"POST:/api/v1/policies":                      true,
+		"PUT:/api/v1/policies/:id":                   true,
+		"POST:/api/v1/policies/assignments":          true,
+		"POST:/api/v1/groups":                        true,
+		"POST:/api/v1/groups/:id/members":            true,
+		"POST:/api/v1/teams":                         true,
+		"POST:/api/v1/teams/:id/members":             true,
+		"POST:/api/v1/identities/sessions/search":    true,
+		"POST:/api/v1/audit/search":                  true,
+		"POST:/api/v1/audit/stats":                   true,
+		"PUT:/api/v1/audit/retention":                true,
 	}
 
-	key := method + ":" + path
-	return sensitiveEndpoints[key]
+	key := ctx.Request.Method + ":" + ctx.FullPath()
+	return loggedEndpoints[key]
 }
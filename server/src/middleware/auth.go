@@ -7,15 +7,18 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthMiddleware struct {
-	authService *services.AuthService
+	authService    *services.AuthService
+	sessionService *services.SessionService
 }
 
-func NewAuthMiddleware(authService *services.AuthService) *AuthMiddleware {
+func NewAuthMiddleware(authService *services.AuthService, sessionService *services.SessionService) *AuthMiddleware {
 	return &AuthMiddleware{
-		authService: authService,
+		authService:    authService,
+		sessionService: sessionService,
 	}
 }
 
@@ -45,7 +48,7 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		userID, err := m.authService.ValidateToken(tokenParts[1])
+		claims, err := m.authService.ValidateToken(tokenParts[1])
 		if err != nil {
 			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
 				Error: model.ErrorDetail{
@@ -57,7 +60,12 @@ func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 			return
 		}
 
-		ctx.Set("user_id", *userID)
+		if m.sessionService != nil && claims.SessionID != uuid.Nil {
+			m.sessionService.Touch(claims.SessionID)
+		}
+
+		ctx.Set("user_id", claims.UserID)
+		ctx.Set("token_claims", claims)
 		ctx.Next()
 	}
 }
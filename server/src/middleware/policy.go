@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const apiPrefix = "/api/v1/"
+
+// routeCapabilities overrides the method-derived capability for collection
+// listings and for action endpoints that are not plain CRUD.
+var routeCapabilities = map[string]model.PolicyCapability{
+	"GET /api/v1/secrets":                        model.CapabilityList,
+	"GET /api/v1/secrets/:id/versions":           model.CapabilityList,
+	"POST /api/v1/secrets/:id/restore":           model.CapabilityUpdate,
+	"POST /api/v1/secrets/:id/versions/delete":   model.CapabilityDelete,
+	"POST /api/v1/secrets/:id/versions/undelete": model.CapabilityUpdate,
+	"POST /api/v1/secrets/:id/versions/destroy":  model.CapabilityDelete,
+	"GET /api/v1/totp":                           model.CapabilityList,
+	"POST /api/v1/totp/:id/generate":             model.CapabilityRead,
+	"POST /api/v1/totp/:id/verify":               model.CapabilityRead,
+	"POST /api/v1/totp/:id/backup-codes":         model.CapabilityUpdate,
+	"GET /api/v1/network":                        model.CapabilityList,
+	"POST /api/v1/network/test":                  model.CapabilityRead,
+	"POST /api/v1/snmp/get":                      model.CapabilityRead,
+	"POST /api/v1/snmp/walk":                     model.CapabilityRead,
+	"POST /api/v1/snmp/test":                     model.CapabilityRead,
+	"GET /api/v1/database/config":                model.CapabilityList,
+	"GET /api/v1/database/roles":                 model.CapabilityList,
+	"GET /api/v1/pki/roles":                      model.CapabilityList,
+	"GET /api/v1/pki/certs":                      model.CapabilityList,
+	"GET /api/v1/ssh/roles":                      model.CapabilityList,
+	"GET /api/v1/ssh/certs":                      model.CapabilityList,
+	"GET /api/v1/transit/keys":                   model.CapabilityList,
+	"POST /api/v1/transit/encrypt/:name":         model.CapabilityUpdate,
+	"POST /api/v1/transit/decrypt/:name":         model.CapabilityUpdate,
+	"POST /api/v1/transit/rewrap/:name":          model.CapabilityUpdate,
+	"POST /api/v1/transit/datakey/:type/:name":   model.CapabilityUpdate,
+	"POST /api/v1/transit/sign/:name":            model.CapabilityUpdate,
+	"POST /api/v1/transit/verify/:name":          model.CapabilityUpdate,
+	"POST /api/v1/transit/hmac/:name":            model.CapabilityUpdate,
+}
+
+type PolicyMiddleware struct {
+	policyService *services.PolicyService
+}
+
+func NewPolicyMiddleware(policyService *services.PolicyService) *PolicyMiddleware {
+	return &PolicyMiddleware{
+		policyService: policyService,
+	}
+}
+
+func (m *PolicyMiddleware) Enforce() gin.HandlerFunc {
+	return m.enforce(false)
+}
+
+// RequireGrant enforces policies like Enforce, but only admits requests a
+// policy rule grants: the permissive-mode fallback does not count. The
+// secrets engines use it, as they mint credentials and use shared keys.
+func (m *PolicyMiddleware) RequireGrant() gin.HandlerFunc {
+	return m.enforce(true)
+}
+
+func (m *PolicyMiddleware) enforce(requireGrant bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if m.policyService == nil {
+			ctx.Next()
+			return
+		}
+
+		userID, exists := ctx.Get("user_id")
+		if !exists {
+			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_UNAUTHORIZED",
+					Message: "Unauthorized",
+				},
+			})
+			ctx.Abort()
+			return
+		}
+
+		req := &model.AccessRequest{
+			Path:       RequestPolicyPath(ctx),
+			Capability: RequestCapability(ctx),
+			ClientIP:   ctx.ClientIP(),
+			Time:       time.Now(),
+		}
+
+		decision, err := m.policyService.Evaluate(userID.(uuid.UUID), req)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INTERNAL_ERROR",
+					Message: "Failed to evaluate policies",
+				},
+			})
+			ctx.Abort()
+			return
+		}
+
+		if requireGrant && decision.Allowed && decision.PolicyID == nil {
+			decision = &model.PolicyDecision{
+				Allowed: false,
+				Reason:  "an explicit policy grant is required",
+			}
+		}
+
+		if !decision.Allowed {
+			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_PERMISSION_DENIED",
+					Message: "Permission denied: " + decision.Reason,
+				},
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Set("policy_decision", decision)
+		ctx.Next()
+	}
+}
+
+func RequestPolicyPath(ctx *gin.Context) string {
+	return strings.Trim(strings.TrimPrefix(ctx.Request.URL.Path, apiPrefix), "/")
+}
+
+func RequestCapability(ctx *gin.Context) model.PolicyCapability {
+	if capability, ok := routeCapabilities[ctx.Request.Method+" "+ctx.FullPath()]; ok {
+		return capability
+	}
+
+	switch ctx.Request.Method {
+	case http.MethodPost:
+		return model.CapabilityCreate
+	case http.MethodPut, http.MethodPatch:
+		return model.CapabilityUpdate
+	case http.MethodDelete:
+		return model.CapabilityDelete
+	default:
+		return model.CapabilityRead
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SealMiddleware struct {
+	sealService *services.SealService
+}
+
+func NewSealMiddleware(sealService *services.SealService) *SealMiddleware {
+	return &SealMiddleware{
+		sealService: sealService,
+	}
+}
+
+func (m *SealMiddleware) RequireUnsealed() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if m.sealService == nil || !m.sealService.IsSealed() {
+			ctx.Next()
+			return
+		}
+
+		ctx.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SEALED",
+				Message: "Vault is sealed",
+			},
+		})
+		ctx.Abort()
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -73,46 +74,87 @@ func (m *UserMiddleware) RequireOwnership() gin.HandlerFunc {
 }
 
 func (m *UserMiddleware) RequireAdmin() gin.HandlerFunc {
+	return m.RequireRole(model.RoleAdmin)
+}
+
+// RequireRole only lets users holding one of the given roles through and
+// stores the caller's role as "user_role".
+func (m *UserMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		currentUserID, exists := ctx.Get("user_id")
-		if !exists {
-			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
-				Error: model.ErrorDetail{
-					Code:    "VAULT_UNAUTHORIZED",
-					Message: "Unauthorized",
-				},
-			})
-			ctx.Abort()
+		user, ok := m.currentUser(ctx)
+		if !ok {
 			return
 		}
 
-		user, err := m.userService.GetUserByID(currentUserID.(uuid.UUID))
-		if err != nil {
-			ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+		if !user.HasRole(roles...) {
+			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
 				Error: model.ErrorDetail{
-					Code:    "VAULT_UNAUTHORIZED",
-					Message: "Unauthorized",
+					Code:    "VAULT_ACCESS_DENIED",
+					Message: "Access denied: " + strings.Join(roles, " or ") + " role required",
 				},
 			})
 			ctx.Abort()
 			return
 		}
 
-		if !m.isAdmin(user) {
+		ctx.Set("user_role", user.Role)
+		ctx.Next()
+	}
+}
+
+// RequireSelfOrRole lets users act on their own account, identified by the
+// "id" path parameter, and otherwise requires one of the given roles.
+func (m *UserMiddleware) RequireSelfOrRole(roles ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user, ok := m.currentUser(ctx)
+		if !ok {
+			return
+		}
+
+		if ctx.Param("id") != user.ID.String() && !user.HasRole(roles...) {
 			ctx.JSON(http.StatusForbidden, model.ErrorResponse{
 				Error: model.ErrorDetail{
 					Code:    "VAULT_ACCESS_DENIED",
-					Message: "Access denied: admin privileges required",
+					Message: "Access denied: insufficient permissions",
 				},
 			})
 			ctx.Abort()
 			return
 		}
 
+		ctx.Set("user_role", user.Role)
 		ctx.Next()
 	}
 }
 
+func (m *UserMiddleware) currentUser(ctx *gin.Context) (*model.User, bool) {
+	currentUserID, exists := ctx.Get("user_id")
+	if !exists {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		ctx.Abort()
+		return nil, false
+	}
+
+	user, err := m.userService.GetUserByID(currentUserID.(uuid.UUID))
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_UNAUTHORIZED",
+				Message: "Unauthorized",
+			},
+		})
+		ctx.Abort()
+		return nil, false
+	}
+
+	return user, true
+}
+
 func (m *UserMiddleware) RequireActiveUser() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		currentUserID, exists := ctx.Get("user_id")
@@ -203,7 +245,7 @@ func (m *UserMiddleware) ValidateUserExists() gin.HandlerFunc {
 }
 
 func (m *UserMiddleware) isAdmin(user *model.User) bool {
-	return user.Email == "admin@aether-vault.local"
+	return user.HasRole(model.RoleAdmin)
 }
 
 func (m *UserMiddleware) CanAccessResource(ctx *gin.Context, resourceType string, resourceID uuid.UUID) bool {
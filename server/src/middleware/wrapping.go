@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// WrapTTLHeader requests a wrapped response; its value is the token TTL as
+// seconds or a Go duration such as "15m".
+const WrapTTLHeader = "X-Vault-Wrap-TTL"
+
+type WrappingMiddleware struct {
+	wrappingService *services.WrappingService
+	auditService    *services.AuditService
+}
+
+func NewWrappingMiddleware(wrappingService *services.WrappingService, auditService *services.AuditService) *WrappingMiddleware {
+	return &WrappingMiddleware{
+		wrappingService: wrappingService,
+		auditService:    auditService,
+	}
+}
+
+// Wrap replaces the response of a successful read carrying WrapTTLHeader
+// with a single-use wrapping token; the original response is only returned
+// by unwrapping it. Failed reads are passed through unchanged.
+func (m *WrappingMiddleware) Wrap() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader(WrapTTLHeader)
+		if header == "" || ctx.Request.Method != http.MethodGet {
+			ctx.Next()
+			return
+		}
+
+		ttl, err := parseWrapTTL(header)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_INVALID_REQUEST",
+					Message: "Invalid " + WrapTTLHeader + " header",
+				},
+			})
+			ctx.Abort()
+			return
+		}
+		if m.wrappingService == nil {
+			ctx.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+				Error: model.ErrorDetail{
+					Code:    "VAULT_WRAPPING_UNAVAILABLE",
+					Message: "Response wrapping requires a database",
+				},
+			})
+			ctx.Abort()
+			return
+		}
+
+		original := ctx.Writer
+		recorder := &wrapRecorder{ResponseWriter: original, status: http.StatusOK}
+		ctx.Writer = recorder
+		ctx.Next()
+		ctx.Writer = original
+
+		if recorder.status != http.StatusOK {
+			original.WriteHeader(recorder.status)
+			original.Write(recorder.body.Bytes())
+			return
+		}
+
+		var createdBy *uuid.UUID
+		if userID, ok := ctx.Get("user_id"); ok {
+			if uid, ok := userID.(uuid.UUID); ok {
+				createdBy = &uid
+			}
+		}
+
+		path := ctx.Request.URL.Path
+		info, err := m.wrappingService.Wrap(recorder.body.Bytes(), path, ttl, createdBy)
+		if err != nil {
+			respondWrapError(ctx, err)
+			return
+		}
+
+		if m.auditService != nil {
+			if createdBy != nil {
+				m.auditService.LogAction(*createdBy, "response_wrapped", "wrapping", info.Accessor.String(), true, "path="+path)
+			} else {
+				m.auditService.LogAnonymousAction("response_wrapped", "wrapping", info.Accessor.String(), ctx.ClientIP(), ctx.GetHeader("User-Agent"), true, "path="+path)
+			}
+		}
+
+		ctx.JSON(http.StatusOK, model.WrapResponse{WrapInfo: *info})
+	}
+}
+
+func parseWrapTTL(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func respondWrapError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidWrapTTL):
+		ctx.JSON(http.StatusBadRequest, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INVALID_REQUEST",
+				Message: err.Error(),
+			},
+		})
+	case errors.Is(err, services.ErrKeyringLocked):
+		ctx.JSON(http.StatusServiceUnavailable, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_SEALED",
+				Message: "Vault is sealed",
+			},
+		})
+	default:
+		ctx.JSON(http.StatusInternalServerError, model.ErrorResponse{
+			Error: model.ErrorDetail{
+				Code:    "VAULT_INTERNAL_ERROR",
+				Message: "Failed to wrap response",
+			},
+		})
+	}
+}
+
+// wrapRecorder holds back the response of the wrapped handler so it can be
+// sealed instead of sent.
+type wrapRecorder struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (w *wrapRecorder) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *wrapRecorder) WriteHeaderNow() {}
+
+func (w *wrapRecorder) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *wrapRecorder) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *wrapRecorder) Status() int {
+	return w.status
+}
+
+func (w *wrapRecorder) Size() int {
+	return w.body.Len()
+}
+
+func (w *wrapRecorder) Written() bool {
+	return w.body.Len() > 0
+}
@@ -15,16 +15,67 @@ type AuditLog struct {
 	ResourceID *string    `json:"resource_id"`
 	IPAddress  string     `gorm:"not null" json:"ip_address"`
 	UserAgent  string     `gorm:"type:text" json:"user_agent"`
-	Success    bool       `gorm:"default:true" json:"success"`
+	Success    bool       `json:"success"`
 	Details    string     `gorm:"type:text" json:"details"`
 	CreatedAt  time.Time  `json:"created_at"`
 
+	// Sequence, PrevHash and Hash chain every entry to the one before it.
+	Sequence int64  `gorm:"uniqueIndex" json:"sequence"`
+	PrevHash string `json:"prev_hash"`
+	Hash     string `gorm:"index" json:"hash"`
+
 	User *User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+const (
+	CheckpointKindPeriodic = "periodic"
+	// CheckpointKindArchive anchors the chain after retention removed the
+	// entries up to and including Sequence.
+	CheckpointKindArchive = "archive"
+)
+
+// AuditCheckpoint is a signed statement of the chain hash at a sequence.
+type AuditCheckpoint struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Sequence  int64     `gorm:"not null;index" json:"sequence"`
+	Hash      string    `gorm:"not null" json:"hash"`
+	Kind      string    `gorm:"not null" json:"kind"`
+	Signature string    `gorm:"not null" json:"signature"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditRetention is the single row holding the audit retention policy.
+type AuditRetention struct {
+	ID          int        `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	Days        int        `gorm:"not null" json:"days"`
+	UpdatedBy   *uuid.UUID `gorm:"type:uuid" json:"updated_by,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty"`
+	LastRemoved int64      `json:"last_removed"`
+}
+
+type AuditVerifyResponse struct {
+	Valid              bool       `json:"valid"`
+	EntriesChecked     int64      `json:"entries_checked"`
+	FirstSequence      int64      `json:"first_sequence"`
+	LastSequence       int64      `json:"last_sequence"`
+	CheckpointsChecked int        `json:"checkpoints_checked"`
+	BrokenSequence     *int64     `json:"broken_sequence,omitempty"`
+	BrokenEntryID      *uuid.UUID `json:"broken_entry_id,omitempty"`
+	Reason             string     `json:"reason,omitempty"`
+	PublicKey          string     `json:"public_key"`
+}
+
 func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
 	if a.ID == uuid.Nil {
 		a.ID = uuid.New()
 	}
 	return nil
 }
+
+func (c *AuditCheckpoint) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
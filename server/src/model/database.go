@@ -0,0 +1,118 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DatabaseType names a database engine; the values match the CLI's database
+// types so the same flags configure both.
+type DatabaseType string
+
+const (
+	DatabaseTypeSQLite     DatabaseType = "sqlite"
+	DatabaseTypePostgreSQL DatabaseType = "postgresql"
+	DatabaseTypeMySQL      DatabaseType = "mysql"
+	DatabaseTypeMariaDB    DatabaseType = "mariadb"
+)
+
+// IsValidDatabaseType reports whether t can issue credentials; only SQL
+// databases can.
+func IsValidDatabaseType(t DatabaseType) bool {
+	switch t {
+	case DatabaseTypeSQLite, DatabaseTypePostgreSQL, DatabaseTypeMySQL, DatabaseTypeMariaDB:
+		return true
+	default:
+		return false
+	}
+}
+
+// DatabaseConnection is a privileged connection the database engine uses to
+// create and drop users. The password and URL are sealed in Credentials with
+// a per-connection data key. For SQLite, Database is the file path.
+type DatabaseConnection struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	Name        string       `gorm:"uniqueIndex;not null" json:"name"`
+	Type        DatabaseType `gorm:"not null" json:"type"`
+	Host        string       `json:"host,omitempty"`
+	Port        int          `json:"port,omitempty"`
+	Username    string       `json:"username,omitempty"`
+	Database    string       `json:"database,omitempty"`
+	Credentials string       `gorm:"type:text;not null" json:"-"`
+	DataKey     string       `gorm:"type:text;not null" json:"-"`
+	KeyVersion  int          `gorm:"not null" json:"-"`
+	CreatedBy   *uuid.UUID   `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// DatabaseRole issues users on a connection. Statements are SQL templates
+// separated by semicolons; {{name}}, {{password}} and {{expiration}} are
+// replaced with the generated username, password and lease expiry.
+type DatabaseRole struct {
+	ID                   uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name                 string    `gorm:"uniqueIndex;not null" json:"name"`
+	Connection           string    `gorm:"not null;index" json:"connection"`
+	CreationStatements   string    `gorm:"type:text;not null" json:"creation_statements"`
+	RevocationStatements string    `gorm:"type:text" json:"revocation_statements,omitempty"`
+	RenewStatements      string    `gorm:"type:text" json:"renew_statements,omitempty"`
+	DefaultTTL           int       `gorm:"not null" json:"default_ttl"`
+	MaxTTL               int       `gorm:"not null" json:"max_ttl"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+func (c *DatabaseConnection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (r *DatabaseRole) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// DatabaseConnectionRequest creates or replaces a connection. URL overrides
+// the individual settings; VerifyConnection defaults to true.
+type DatabaseConnectionRequest struct {
+	Name             string       `json:"name" binding:"required"`
+	Type             DatabaseType `json:"type" binding:"required"`
+	Host             string       `json:"host"`
+	Port             int          `json:"port"`
+	Username         string       `json:"username"`
+	Password         string       `json:"password"`
+	Database         string       `json:"database"`
+	URL              string       `json:"url"`
+	VerifyConnection *bool        `json:"verify_connection"`
+}
+
+// DatabaseRoleRequest creates or replaces a role; TTLs are in seconds.
+type DatabaseRoleRequest struct {
+	Name                 string `json:"name" binding:"required"`
+	Connection           string `json:"connection" binding:"required"`
+	CreationStatements   string `json:"creation_statements" binding:"required"`
+	RevocationStatements string `json:"revocation_statements"`
+	RenewStatements      string `json:"renew_statements"`
+	DefaultTTL           int    `json:"default_ttl"`
+	MaxTTL               int    `json:"max_ttl"`
+}
+
+type DatabaseCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// DatabaseCredentialsResponse returns generated credentials with the lease
+// that drops them.
+type DatabaseCredentialsResponse struct {
+	LeaseID       string              `json:"lease_id"`
+	LeaseDuration int                 `json:"lease_duration"`
+	Renewable     bool                `json:"renewable"`
+	Data          DatabaseCredentials `json:"data"`
+}
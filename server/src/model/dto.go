@@ -30,14 +30,56 @@ type VersionResponse struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
+	Email    string `json:"email" binding:"required_without=Username,omitempty,email"`
+	Username string `json:"username" binding:"omitempty,email"`
 	Password string `json:"password" binding:"required,min=8"`
+	TOTP     string `json:"totp"`
 }
 
 type LoginResponse struct {
-	Token     string    `json:"token"`
-	ExpiresAt time.Time `json:"expires_at"`
-	User      User      `json:"user"`
+	Token            string     `json:"token,omitempty"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RefreshToken     string     `json:"refresh_token,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+	User             User       `json:"user"`
+	MFARequired      bool       `json:"mfa_required,omitempty"`
+	MFAToken         string     `json:"mfa_token,omitempty"`
+}
+
+type RefreshRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RefreshResponse struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+type VerifyRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type VerifyResponse struct {
+	Valid     bool       `json:"valid"`
+	UserID    string     `json:"user_id,omitempty"`
+	Username  string     `json:"username,omitempty"`
+	TokenID   string     `json:"token_id,omitempty"`
+	IssuedAt  *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type RevokeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type RevokeResponse struct {
+	Revoked bool `json:"revoked"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type SessionResponse struct {
@@ -52,6 +94,8 @@ type CreateSecretRequest struct {
 	Type        SecretType `json:"type" binding:"required"`
 	Tags        string     `json:"tags"`
 	ExpiresAt   *time.Time `json:"expires_at"`
+	MaxVersions int        `json:"max_versions"`
+	TeamID      *uuid.UUID `json:"team_id"`
 }
 
 type UpdateSecretRequest struct {
@@ -62,15 +106,56 @@ type UpdateSecretRequest struct {
 	Tags        *string     `json:"tags"`
 	ExpiresAt   *time.Time  `json:"expires_at"`
 	IsActive    *bool       `json:"is_active"`
+	MaxVersions *int        `json:"max_versions"`
+}
+
+// SecretExpiryEvent is sent to the expiry notification hook.
+type SecretExpiryEvent struct {
+	Event     string     `json:"event"`
+	SecretID  uuid.UUID  `json:"secret_id"`
+	Name      string     `json:"name"`
+	OwnerID   uuid.UUID  `json:"owner_id"`
+	TeamID    *uuid.UUID `json:"team_id,omitempty"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Action    string     `json:"action,omitempty"`
+	Timestamp time.Time  `json:"timestamp"`
+}
+
+type SecretVersionResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	SecretID  uuid.UUID  `json:"secret_id"`
+	Version   int        `json:"version"`
+	Value     string     `json:"value,omitempty"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	Destroyed bool       `json:"destroyed"`
+}
+
+type ListSecretVersionsResponse struct {
+	Versions       []SecretVersionResponse `json:"versions"`
+	Total          int                     `json:"total"`
+	CurrentVersion int                     `json:"current_version"`
+	MaxVersions    int                     `json:"max_versions"`
+}
+
+type SecretVersionsRequest struct {
+	Versions []int `json:"versions" binding:"required,min=1"`
+}
+
+type RestoreSecretRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
 }
 
 type CreateTOTPRequest struct {
-	Name        string `json:"name" binding:"required"`
-	Description string `json:"description"`
-	Secret      string `json:"secret"`
-	Algorithm   string `json:"algorithm"`
-	Digits      int    `json:"digits"`
-	Period      int    `json:"period"`
+	Name        string     `json:"name" binding:"required"`
+	Description string     `json:"description"`
+	Issuer      string     `json:"issuer"`
+	Secret      string     `json:"secret"`
+	Algorithm   string     `json:"algorithm"`
+	Digits      int        `json:"digits"`
+	Period      int        `json:"period"`
+	TeamID      *uuid.UUID `json:"team_id"`
 }
 
 type TOTPGenerateRequest struct {
@@ -81,3 +166,305 @@ type TOTPGenerateResponse struct {
 	Code      string    `json:"code"`
 	ExpiresAt time.Time `json:"expires_at"`
 }
+
+// CreateTOTPResponse returns the backup codes of a new entry; they are only
+// shown this once.
+type CreateTOTPResponse struct {
+	TOTP
+	BackupCodes []string `json:"backup_codes,omitempty"`
+}
+
+// TOTPVerifyRequest checks a TOTP or backup code. Window narrows the drift
+// window, in time steps either side of now; it is capped at the configured
+// skew.
+type TOTPVerifyRequest struct {
+	Code   string `json:"code" binding:"required"`
+	Window *int   `json:"window"`
+}
+
+// TOTPVerifyResponse reports an accepted code's time step, or whether a
+// backup code was used. After a failure, LockedUntil is set once the entry
+// is locked.
+type TOTPVerifyResponse struct {
+	Valid                bool       `json:"valid"`
+	Counter              int64      `json:"counter,omitempty"`
+	UsedBackup           bool       `json:"used_backup,omitempty"`
+	RemainingBackupCodes int        `json:"remaining_backup_codes"`
+	RemainingAttempts    int        `json:"remaining_attempts"`
+	LockedUntil          *time.Time `json:"locked_until,omitempty"`
+	LastUsed             *time.Time `json:"last_used,omitempty"`
+}
+
+type TOTPBackupCodesResponse struct {
+	BackupCodes []string `json:"backup_codes"`
+}
+
+// TOTPImportRequest creates entries from otpauth:// URIs and
+// otpauth-migration:// exports of Google Authenticator; one migration URI
+// may hold several entries. DryRun only parses and validates them.
+type TOTPImportRequest struct {
+	URIs   []string   `json:"uris" binding:"required,min=1"`
+	TeamID *uuid.UUID `json:"team_id"`
+	DryRun bool       `json:"dry_run"`
+}
+
+type TOTPImportResponse struct {
+	Imported []CreateTOTPResponse `json:"imported"`
+	Total    int                  `json:"total"`
+}
+
+// TOTPExportResponse carries entries as otpauth:// URIs, or as
+// otpauth-migration:// batches when that format was asked for. Skipped
+// names the entries the migration format cannot hold.
+type TOTPExportResponse struct {
+	URIs    []string `json:"uris"`
+	Format  string   `json:"format"`
+	Total   int      `json:"total"`
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+type CreatePolicyRequest struct {
+	Name        string                 `json:"name" binding:"required"`
+	Description string                 `json:"description"`
+	Rules       *PolicyDocument        `json:"rules"`
+	Resource    string                 `json:"resource"`
+	Actions     []string               `json:"actions"`
+	Effect      string                 `json:"effect"`
+	Priority    int                    `json:"priority"`
+	IsActive    *bool                  `json:"is_active"`
+	Conditions  map[string]interface{} `json:"conditions"`
+}
+
+type UpdatePolicyRequest struct {
+	Name        *string                `json:"name"`
+	Description *string                `json:"description"`
+	Rules       *PolicyDocument        `json:"rules"`
+	Resource    *string                `json:"resource"`
+	Actions     []string               `json:"actions"`
+	Effect      *string                `json:"effect"`
+	Priority    *int                   `json:"priority"`
+	IsActive    *bool                  `json:"is_active"`
+	Conditions  map[string]interface{} `json:"conditions"`
+}
+
+type PolicyResponse struct {
+	ID          uuid.UUID         `json:"id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Rules       *PolicyDocument   `json:"rules"`
+	Resource    string            `json:"resource,omitempty"`
+	Actions     []string          `json:"actions,omitempty"`
+	Effect      string            `json:"effect,omitempty"`
+	Conditions  *PolicyConditions `json:"conditions,omitempty"`
+	Priority    int               `json:"priority"`
+	IsActive    bool              `json:"is_active"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+type ListPoliciesRequest struct {
+	Name     string `json:"name"`
+	IsActive *bool  `json:"is_active"`
+	Limit    int    `json:"limit"`
+	Offset   int    `json:"offset"`
+}
+
+type ListPoliciesResponse struct {
+	Policies []PolicyResponse `json:"policies"`
+	Total    int64            `json:"total"`
+	Limit    int              `json:"limit"`
+	Offset   int              `json:"offset"`
+}
+
+type PolicyCheckRequest struct {
+	IdentityID string                 `json:"identity_id"`
+	Resource   string                 `json:"resource" binding:"required"`
+	Action     string                 `json:"action" binding:"required"`
+	Context    map[string]interface{} `json:"context"`
+}
+
+type PolicyCheckResponse struct {
+	Allowed    bool        `json:"allowed"`
+	Reason     string      `json:"reason"`
+	Policies   []string    `json:"policies"`
+	PolicyID   *uuid.UUID  `json:"policy_id,omitempty"`
+	PolicyName string      `json:"policy_name,omitempty"`
+	Rule       *PolicyRule `json:"rule,omitempty"`
+	Evaluated  time.Time   `json:"evaluated"`
+}
+
+type CreatePolicyAssignmentRequest struct {
+	IdentityType string    `json:"identity_type"`
+	IdentityID   string    `json:"identity_id" binding:"required"`
+	PolicyID     uuid.UUID `json:"policy_id" binding:"required"`
+	IsActive     *bool     `json:"is_active"`
+}
+
+type ListPolicyAssignmentsRequest struct {
+	IdentityType string `json:"identity_type"`
+	IdentityID   string `json:"identity_id"`
+	PolicyID     string `json:"policy_id"`
+	IsActive     *bool  `json:"is_active"`
+	Limit        int    `json:"limit"`
+	Offset       int    `json:"offset"`
+}
+
+type ListPolicyAssignmentsResponse struct {
+	Assignments []PolicyAssignment `json:"assignments"`
+	Total       int64              `json:"total"`
+	Limit       int                `json:"limit"`
+	Offset      int                `json:"offset"`
+}
+
+type InitRequest struct {
+	SecretShares    int `json:"secret_shares" binding:"required"`
+	SecretThreshold int `json:"secret_threshold" binding:"required"`
+}
+
+type InitResponse struct {
+	Keys       []string `json:"keys"`
+	KeysBase64 []string `json:"keys_base64"`
+}
+
+type UnsealRequest struct {
+	Key   string `json:"key"`
+	Reset bool   `json:"reset"`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	Pending                bool       `json:"pending"`
+	ConfirmedAt            *time.Time `json:"confirmed_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	LockedUntil            *time.Time `json:"locked_until,omitempty"`
+}
+
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type ListSessionsRequest struct {
+	IdentityID string `json:"identity_id"`
+	IsActive   *bool  `json:"is_active"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+}
+
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+	Total    int64     `json:"total"`
+	Limit    int       `json:"limit"`
+	Offset   int       `json:"offset"`
+}
+
+type CreateGroupRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type GroupMemberRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+type GroupResponse struct {
+	Group
+	Members []User `json:"members,omitempty"`
+}
+
+type CreateTeamRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type TeamMemberRequest struct {
+	UserID     uuid.UUID `json:"user_id" binding:"required"`
+	Permission string    `json:"permission" binding:"required"`
+}
+
+type ShareSecretRequest struct {
+	TeamID uuid.UUID `json:"team_id" binding:"required"`
+}
+
+type TransferSecretRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+type SearchAuditRequest struct {
+	IdentityID *string    `json:"identity_id"`
+	Action     string     `json:"action"`
+	Resource   string     `json:"resource"`
+	ResourceID *string    `json:"resource_id"`
+	IPAddress  string     `json:"ip_address"`
+	Status     string     `json:"status"`
+	Query      string     `json:"query"`
+	StartTime  *time.Time `json:"start_time"`
+	EndTime    *time.Time `json:"end_time"`
+	Format     string     `json:"format"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+}
+
+type AuditEvent struct {
+	ID         uuid.UUID              `json:"id"`
+	Sequence   int64                  `json:"sequence"`
+	IdentityID *uuid.UUID             `json:"identity_id,omitempty"`
+	Action     string                 `json:"action"`
+	Resource   string                 `json:"resource"`
+	ResourceID *string                `json:"resource_id,omitempty"`
+	IPAddress  string                 `json:"ip_address"`
+	UserAgent  string                 `json:"user_agent"`
+	Status     string                 `json:"status"`
+	Message    string                 `json:"message"`
+	Details    map[string]interface{} `json:"details,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Hash       string                 `json:"hash"`
+}
+
+type SearchAuditResponse struct {
+	Events []AuditEvent `json:"events"`
+	Total  int64        `json:"total"`
+	Limit  int          `json:"limit"`
+	Offset int          `json:"offset"`
+}
+
+type AuditStatsRequest struct {
+	StartTime *time.Time `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+}
+
+type AuditHourCount struct {
+	Hour  time.Time `json:"hour"`
+	Count int64     `json:"count"`
+}
+
+type AuditTimeRange struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+type AuditStats struct {
+	TotalEvents    int64            `json:"total_events"`
+	EventsByAction map[string]int64 `json:"events_by_action"`
+	EventsByStatus map[string]int64 `json:"events_by_status"`
+	EventsByHour   []AuditHourCount `json:"events_by_hour"`
+	TimeRange      AuditTimeRange   `json:"time_range"`
+}
+
+type SetAuditRetentionRequest struct {
+	Days *int `json:"days" binding:"required"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+)
+
+type KeyringKey struct {
+	Version    int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	WrappedKey string    `gorm:"type:text;not null" json:"-"`
+	Salt       string    `gorm:"not null" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type KeyringStatus struct {
+	ActiveVersion int            `json:"active_version"`
+	Keys          []KeyringKey   `json:"keys"`
+	PendingRewrap map[string]int `json:"pending_rewrap"`
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Lease tracks a dynamic or time-limited credential. Its ID starts with the
+// path the credential was issued from, so related leases can be revoked by
+// prefix. Kind names the engine that releases the credential on revocation;
+// Data holds whatever that engine needs to do so.
+type Lease struct {
+	ID            string     `gorm:"primaryKey" json:"lease_id"`
+	Kind          string     `gorm:"not null;index" json:"kind"`
+	ResourceID    string     `gorm:"index" json:"resource_id,omitempty"`
+	UserID        *uuid.UUID `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Renewable     bool       `gorm:"not null" json:"renewable"`
+	TTL           int        `gorm:"not null" json:"ttl"`
+	MaxTTL        int        `gorm:"not null" json:"max_ttl"`
+	IssueTime     time.Time  `gorm:"not null" json:"issue_time"`
+	ExpireTime    time.Time  `gorm:"not null;index" json:"expire_time"`
+	MaxExpireTime time.Time  `gorm:"not null" json:"max_expire_time"`
+	LastRenewal   *time.Time `json:"last_renewal,omitempty"`
+	RevokedAt     *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	RevokeErrors  int        `gorm:"not null" json:"-"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	Data          string     `gorm:"type:text" json:"-"`
+}
+
+// RemainingTTL returns the whole seconds left before the lease expires.
+func (l *Lease) RemainingTTL(now time.Time) int {
+	if !l.ExpireTime.After(now) {
+		return 0
+	}
+	return int(l.ExpireTime.Sub(now).Round(time.Second) / time.Second)
+}
+
+type LeaseRequest struct {
+	LeaseID string `json:"lease_id" binding:"required"`
+}
+
+type RenewLeaseRequest struct {
+	LeaseID   string `json:"lease_id" binding:"required"`
+	Increment int    `json:"increment"`
+}
+
+type RevokeLeasePrefixRequest struct {
+	Prefix string `json:"prefix" binding:"required"`
+}
+
+// LeaseResponse is a lease as returned by the API; LeaseDuration is the
+// number of seconds left.
+type LeaseResponse struct {
+	LeaseID       string     `json:"lease_id"`
+	Kind          string     `json:"kind"`
+	Renewable     bool       `json:"renewable"`
+	LeaseDuration int        `json:"lease_duration"`
+	IssueTime     time.Time  `json:"issue_time"`
+	ExpireTime    time.Time  `json:"expire_time"`
+	LastRenewal   *time.Time `json:"last_renewal,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+}
+
+type ListLeasesResponse struct {
+	Leases []LeaseResponse `json:"leases"`
+	Total  int             `json:"total"`
+}
+
+type RevokeLeasePrefixResponse struct {
+	Revoked int `json:"revoked"`
+}
+
+// TokenRenewResponse mirrors the auth block returned on login so token
+// renewal works with clients that expect it.
+type TokenRenewResponse struct {
+	Auth TokenRenewAuth `json:"auth"`
+}
+
+type TokenRenewAuth struct {
+	ClientToken   string    `json:"client_token"`
+	LeaseDuration int       `json:"lease_duration"`
+	Renewable     bool      `json:"renewable"`
+	Policies      []string  `json:"policies"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+type RenewTokenRequest struct {
+	Increment int `json:"increment"`
+}
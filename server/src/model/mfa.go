@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserMFA holds a user's login TOTP seed. It is pending until the first code
+// has been confirmed.
+type UserMFA struct {
+	UserID       uuid.UUID  `gorm:"type:uuid;primary_key" json:"user_id"`
+	Secret       string     `gorm:"type:text;not null" json:"-"`
+	DataKey      string     `gorm:"type:text" json:"-"`
+	KeyVersion   int        `gorm:"not null;default:0;index" json:"-"`
+	Enabled      bool       `gorm:"not null;default:false" json:"enabled"`
+	LastUsedStep int64      `gorm:"not null;default:0" json:"-"`
+	ConfirmedAt  *time.Time `json:"confirmed_at,omitempty"`
+	// FailedAttempts counts failed verifications in a row until the
+	// second factor is locked until LockedUntil.
+	FailedAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil    *time.Time `json:"locked_until,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+
+	User User `gorm:"foreignKey:UserID" json:"-"`
+}
+
+type MFARecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (c *MFARecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
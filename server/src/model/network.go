@@ -2,8 +2,10 @@ package model
 
 import (
 	"encoding/json"
-	"gorm.io/gorm"
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type ProtocolType string
@@ -22,15 +24,77 @@ const (
 	ProtocolCustom ProtocolType = "custom"
 )
 
+const (
+	NetworkStatusActive   = "active"
+	NetworkStatusInactive = "inactive"
+)
+
+// Network is a remote endpoint the vault can test. Config holds the
+// ProtocolConfig without its credentials, which are kept in the vault secret
+// CredentialsID.
+//
+// The health monitor checks it every CheckInterval seconds and marks it
+// inactive after FailureThreshold failed checks in a row; zero means the
+// configured default for either. CertificateNotifiedAt records the
+// certificate_expiring alert for the current CertificateExpiresAt.
 type Network struct {
-	ID        uint           `json:"id" gorm:"primaryKey"`
-	Name      string         `json:"name" gorm:"not null;unique"`
-	Type      ProtocolType   `json:"type" gorm:"not null"`
-	Status    string         `json:"status" gorm:"default:'active'"`
-	Config    string         `json:"config" gorm:"type:text"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+	ID                    uint           `json:"id" gorm:"primaryKey"`
+	Name                  string         `json:"name" gorm:"not null;unique"`
+	Type                  ProtocolType   `json:"type" gorm:"not null"`
+	Status                string         `json:"status" gorm:"default:'active'"`
+	Config                string         `json:"config" gorm:"type:text"`
+	CredentialsID         *uuid.UUID     `json:"-" gorm:"type:uuid;index"`
+	CheckInterval         int            `json:"check_interval" gorm:"default:0"`
+	FailureThreshold      int            `json:"failure_threshold" gorm:"default:0"`
+	ConsecutiveFailures   int            `json:"consecutive_failures" gorm:"default:0"`
+	LastCheckAt           *time.Time     `json:"last_check_at"`
+	CertificateExpiresAt  *time.Time     `json:"certificate_expires_at"`
+	CertificateNotifiedAt *time.Time     `json:"-"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+	DeletedAt             gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// NetworkCheck is one health check of a network, recorded by the monitor or
+// by a status request.
+type NetworkCheck struct {
+	ID                   uint       `json:"id" gorm:"primaryKey"`
+	NetworkID            uint       `json:"network_id" gorm:"not null;index"`
+	Success              bool       `json:"success"`
+	Status               string     `json:"status"`
+	Latency              int64      `json:"latency"`
+	Message              string     `json:"message,omitempty" gorm:"type:text"`
+	CertificateExpiresAt *time.Time `json:"certificate_expires_at,omitempty"`
+	CheckedAt            time.Time  `json:"checked_at" gorm:"index"`
+}
+
+const (
+	NetworkAlertDown                = "network_down"
+	NetworkAlertRecovered           = "network_recovered"
+	NetworkAlertCertificateExpiring = "certificate_expiring"
+)
+
+// NetworkAlert is raised when a network goes down, recovers, or its HTTPS
+// certificate nears expiry. NetworkName keeps the name the network had then.
+type NetworkAlert struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	NetworkID   uint      `json:"network_id" gorm:"not null;index"`
+	NetworkName string    `json:"network_name"`
+	Event       string    `json:"event" gorm:"not null"`
+	Message     string    `json:"message" gorm:"type:text"`
+	CreatedAt   time.Time `json:"created_at" gorm:"index"`
+}
+
+// NetworkUptimeResponse summarizes the checks of a network over Window
+// seconds. UptimePercent is nil when no check ran in the window.
+type NetworkUptimeResponse struct {
+	NetworkID      uint       `json:"network_id"`
+	Window         int64      `json:"window"`
+	Checks         int64      `json:"checks"`
+	Successful     int64      `json:"successful"`
+	UptimePercent  *float64   `json:"uptime_percent"`
+	AverageLatency int64      `json:"average_latency"`
+	LastCheckAt    *time.Time `json:"last_check_at"`
 }
 
 type ProtocolConfig struct {
@@ -45,20 +109,40 @@ type ProtocolConfig struct {
 	Options     map[string]interface{} `json:"options,omitempty"`
 }
 
+// NetworkCredentials are the secret parts of a ProtocolConfig, stored as the
+// JSON value of a vault secret.
+type NetworkCredentials struct {
+	Password   string `json:"password,omitempty"`
+	PrivateKey string `json:"private_key,omitempty"`
+}
+
+// NetworkRequest creates or updates a network. On update, a config without
+// credentials keeps the stored ones unless ClearCredentials is set.
 type NetworkRequest struct {
-	Name   string          `json:"name" binding:"required"`
-	Type   ProtocolType    `json:"type" binding:"required"`
-	Config *ProtocolConfig `json:"config"`
+	Name             string          `json:"name" binding:"required"`
+	Type             ProtocolType    `json:"type" binding:"required"`
+	Config           *ProtocolConfig `json:"config"`
+	ClearCredentials bool            `json:"clear_credentials"`
+	CheckInterval    int             `json:"check_interval" binding:"min=0"`
+	FailureThreshold int             `json:"failure_threshold" binding:"min=0"`
 }
 
+// NetworkResponse never carries credentials; HasCredentials tells whether
+// the network has some stored.
 type NetworkResponse struct {
-	ID        uint            `json:"id"`
-	Name      string          `json:"name"`
-	Type      ProtocolType    `json:"type"`
-	Status    string          `json:"status"`
-	Config    *ProtocolConfig `json:"config"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID                   uint            `json:"id"`
+	Name                 string          `json:"name"`
+	Type                 ProtocolType    `json:"type"`
+	Status               string          `json:"status"`
+	Config               *ProtocolConfig `json:"config"`
+	HasCredentials       bool            `json:"has_credentials"`
+	CheckInterval        int             `json:"check_interval"`
+	FailureThreshold     int             `json:"failure_threshold"`
+	ConsecutiveFailures  int             `json:"consecutive_failures"`
+	LastCheckAt          *time.Time      `json:"last_check_at"`
+	CertificateExpiresAt *time.Time      `json:"certificate_expires_at"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
 }
 
 type ProtocolStatus struct {
@@ -67,11 +151,17 @@ type ProtocolStatus struct {
 	LastCheck time.Time    `json:"last_check"`
 	Message   string       `json:"message,omitempty"`
 	Latency   int64        `json:"latency,omitempty"`
+
+	CertificateExpiresAt *time.Time `json:"certificate_expires_at,omitempty"`
 }
 
+// ProtocolTestRequest tests Config, or the stored network NetworkID. With
+// both, Type and Config must match the stored network, though Config may
+// leave out the credentials.
 type ProtocolTestRequest struct {
-	Type   ProtocolType    `json:"type" binding:"required"`
-	Config *ProtocolConfig `json:"config" binding:"required"`
+	Type      ProtocolType    `json:"type" binding:"required"`
+	Config    *ProtocolConfig `json:"config"`
+	NetworkID *uint           `json:"network_id,omitempty"`
 }
 
 type ProtocolTestResponse struct {
@@ -79,6 +169,8 @@ type ProtocolTestResponse struct {
 	Message string                 `json:"message"`
 	Latency int64                  `json:"latency,omitempty"`
 	Details map[string]interface{} `json:"details,omitempty"`
+
+	CertificateExpiresAt *time.Time `json:"certificate_expires_at,omitempty"`
 }
 
 func (n *Network) GetProtocolConfig() (*ProtocolConfig, error) {
@@ -106,3 +198,37 @@ func (n *Network) SetProtocolConfig(config *ProtocolConfig) error {
 	n.Config = string(data)
 	return nil
 }
+
+// Credentials returns the secret parts of the config.
+func (c *ProtocolConfig) Credentials() NetworkCredentials {
+	return NetworkCredentials{
+		Password:   c.Password,
+		PrivateKey: c.PrivateKey,
+	}
+}
+
+// SetCredentials fills the credentials the config lacks from creds.
+func (c *ProtocolConfig) SetCredentials(creds NetworkCredentials) {
+	if c.Password == "" {
+		c.Password = creds.Password
+	}
+	if c.PrivateKey == "" {
+		c.PrivateKey = creds.PrivateKey
+	}
+}
+
+// Redacted returns a copy of the config without its credentials.
+func (c *ProtocolConfig) Redacted() *ProtocolConfig {
+	if c == nil {
+		return nil
+	}
+	redacted := *c
+	redacted.Password = ""
+	redacted.PrivateKey = ""
+	return &redacted
+}
+
+// IsEmpty reports whether the credentials hold nothing.
+func (c NetworkCredentials) IsEmpty() bool {
+	return c.Password == "" && c.PrivateKey == ""
+}
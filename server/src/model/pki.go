@@ -0,0 +1,177 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PKI CA types: a root signs itself, an intermediate is signed by another CA.
+const (
+	PKICATypeRoot         = "root"
+	PKICATypeIntermediate = "intermediate"
+)
+
+// PKI key types; roles may allow any key type when signing CSRs.
+const (
+	PKIKeyTypeRSA     = "rsa"
+	PKIKeyTypeEC      = "ec"
+	PKIKeyTypeEd25519 = "ed25519"
+	PKIKeyTypeAny     = "any"
+)
+
+// PKICA is the engine's signing CA. The private key is sealed with a per-CA
+// data key. An intermediate generated here stays pending, with only its CSR,
+// until the signed certificate is set.
+type PKICA struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Type        string     `gorm:"not null" json:"type"`
+	CommonName  string     `gorm:"not null" json:"common_name"`
+	Certificate string     `gorm:"type:text" json:"certificate,omitempty"`
+	CAChain     string     `gorm:"type:text" json:"ca_chain,omitempty"`
+	CSR         string     `gorm:"type:text" json:"csr,omitempty"`
+	PrivateKey  string     `gorm:"type:text;not null" json:"-"`
+	DataKey     string     `gorm:"type:text;not null" json:"-"`
+	KeyVersion  int        `gorm:"not null" json:"-"`
+	KeyType     string     `gorm:"not null" json:"key_type"`
+	Serial      string     `json:"serial_number,omitempty"`
+	NotAfter    *time.Time `json:"not_after,omitempty"`
+	Pending     bool       `gorm:"not null" json:"pending"`
+	CreatedBy   *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// PKIRole constrains the certificates issued or signed through it. A name
+// is allowed when it equals an allowed domain and bare domains are allowed,
+// or is below one and subdomains are allowed. TTLs are in seconds.
+type PKIRole struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name             string    `gorm:"uniqueIndex;not null" json:"name"`
+	AllowedDomains   string    `gorm:"type:text" json:"allowed_domains"`
+	AllowBareDomains bool      `gorm:"not null" json:"allow_bare_domains"`
+	AllowSubdomains  bool      `gorm:"not null" json:"allow_subdomains"`
+	AllowAnyName     bool      `gorm:"not null" json:"allow_any_name"`
+	AllowIPSANs      bool      `gorm:"not null" json:"allow_ip_sans"`
+	ServerFlag       bool      `gorm:"not null" json:"server_flag"`
+	ClientFlag       bool      `gorm:"not null" json:"client_flag"`
+	KeyType          string    `gorm:"not null" json:"key_type"`
+	KeyBits          int       `gorm:"not null" json:"key_bits"`
+	TTL              int       `gorm:"not null" json:"ttl"`
+	MaxTTL           int       `gorm:"not null" json:"max_ttl"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// PKICertificate records every certificate the engine issued or signed, so
+// it can be looked up, revoked and listed on the CRL by serial.
+type PKICertificate struct {
+	Serial      string     `gorm:"primaryKey" json:"serial_number"`
+	Role        string     `gorm:"index" json:"role,omitempty"`
+	CommonName  string     `json:"common_name"`
+	Certificate string     `gorm:"type:text;not null" json:"certificate"`
+	IsCA        bool       `gorm:"not null" json:"is_ca"`
+	NotBefore   time.Time  `gorm:"not null" json:"not_before"`
+	NotAfter    time.Time  `gorm:"not null;index" json:"not_after"`
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	IssuedBy    *uuid.UUID `gorm:"type:uuid;index" json:"issued_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (c *PKICA) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (r *PKIRole) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// PKIGenerateCARequest generates a root, or the key and CSR of an
+// intermediate. TTL is in seconds and ignored for intermediates.
+type PKIGenerateCARequest struct {
+	CommonName   string `json:"common_name" binding:"required"`
+	Organization string `json:"organization"`
+	KeyType      string `json:"key_type"`
+	KeyBits      int    `json:"key_bits"`
+	TTL          int    `json:"ttl"`
+}
+
+// PKIImportCARequest imports a CA certificate and its private key, followed
+// by any issuer certificates, as one PEM bundle.
+type PKIImportCARequest struct {
+	PEMBundle string `json:"pem_bundle" binding:"required"`
+}
+
+// PKISetSignedRequest completes a pending intermediate with its signed
+// certificate, optionally followed by the issuer chain.
+type PKISetSignedRequest struct {
+	Certificate string `json:"certificate" binding:"required"`
+}
+
+// PKIRoleRequest creates or replaces a role. Unset flags default to
+// allowing subdomains and both server and client use.
+type PKIRoleRequest struct {
+	Name             string   `json:"name" binding:"required"`
+	AllowedDomains   []string `json:"allowed_domains"`
+	AllowBareDomains bool     `json:"allow_bare_domains"`
+	AllowSubdomains  *bool    `json:"allow_subdomains"`
+	AllowAnyName     bool     `json:"allow_any_name"`
+	AllowIPSANs      bool     `json:"allow_ip_sans"`
+	ServerFlag       *bool    `json:"server_flag"`
+	ClientFlag       *bool    `json:"client_flag"`
+	KeyType          string   `json:"key_type"`
+	KeyBits          int      `json:"key_bits"`
+	TTL              int      `json:"ttl"`
+	MaxTTL           int      `json:"max_ttl"`
+}
+
+// PKIIssueRequest issues a certificate with a new key; PKISignRequest signs
+// a CSR, taking its names unless they are given here. TTL is in seconds.
+type PKIIssueRequest struct {
+	CommonName string   `json:"common_name" binding:"required"`
+	AltNames   []string `json:"alt_names"`
+	IPSANs     []string `json:"ip_sans"`
+	TTL        int      `json:"ttl"`
+}
+
+type PKISignRequest struct {
+	CSR        string   `json:"csr" binding:"required"`
+	CommonName string   `json:"common_name"`
+	AltNames   []string `json:"alt_names"`
+	IPSANs     []string `json:"ip_sans"`
+	TTL        int      `json:"ttl"`
+}
+
+type PKIRevokeRequest struct {
+	SerialNumber string `json:"serial_number" binding:"required"`
+}
+
+// PKICertificateResponse returns an issued or signed certificate; the
+// private key is only set when the engine generated it.
+type PKICertificateResponse struct {
+	Certificate    string   `json:"certificate"`
+	IssuingCA      string   `json:"issuing_ca"`
+	CAChain        []string `json:"ca_chain"`
+	PrivateKey     string   `json:"private_key,omitempty"`
+	PrivateKeyType string   `json:"private_key_type,omitempty"`
+	SerialNumber   string   `json:"serial_number"`
+	Expiration     int64    `json:"expiration"`
+}
+
+type PKICAResponse struct {
+	Certificate  string `json:"certificate,omitempty"`
+	CSR          string `json:"csr,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	Expiration   int64  `json:"expiration,omitempty"`
+}
+
+type PKIRevokeResponse struct {
+	RevocationTime time.Time `json:"revocation_time"`
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ type Policy struct {
 	Name        string         `gorm:"not null" json:"name"`
 	Description string         `json:"description"`
 	Rules       string         `gorm:"type:text;not null" json:"rules"`
+	Priority    int            `gorm:"default:0" json:"priority"`
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
@@ -21,9 +23,108 @@ type Policy struct {
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+const (
+	IdentityTypeUser  = "user"
+	IdentityTypeGroup = "group"
+)
+
+type PolicyAssignment struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	PolicyID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_policy_identity" json:"policy_id"`
+	IdentityType string    `gorm:"not null;default:'user';uniqueIndex:idx_policy_identity" json:"identity_type"`
+	IdentityID   string    `gorm:"not null;uniqueIndex:idx_policy_identity" json:"identity_id"`
+	IsActive     bool      `gorm:"default:true" json:"is_active"`
+	CreatedBy    uuid.UUID `gorm:"type:uuid" json:"created_by"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	Policy Policy `gorm:"foreignKey:PolicyID" json:"-"`
+}
+
+type PolicyCapability string
+
+const (
+	CapabilityRead   PolicyCapability = "read"
+	CapabilityCreate PolicyCapability = "create"
+	CapabilityUpdate PolicyCapability = "update"
+	CapabilityDelete PolicyCapability = "delete"
+	CapabilityList   PolicyCapability = "list"
+	CapabilityDeny   PolicyCapability = "deny"
+)
+
+// PolicyDocument is the JSON form stored in Policy.Rules, e.g.
+//
+//	{"rules": [{"path": "secrets/**", "capabilities": ["read", "list"]}]}
+//
+// Paths are slash-separated globs relative to /api/v1: "*" matches within a
+// single segment and "**" matches any number of segments.
+type PolicyDocument struct {
+	Rules []PolicyRule `json:"rules"`
+}
+
+type PolicyRule struct {
+	Path         string             `json:"path"`
+	Capabilities []PolicyCapability `json:"capabilities"`
+	Conditions   *PolicyConditions  `json:"conditions,omitempty"`
+}
+
+type PolicyConditions struct {
+	SourceIPs []string   `json:"source_ips,omitempty"`
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	NotAfter  *time.Time `json:"not_after,omitempty"`
+}
+
+type AccessRequest struct {
+	Path       string           `json:"path"`
+	Capability PolicyCapability `json:"capability"`
+	ClientIP   string           `json:"client_ip,omitempty"`
+	Time       time.Time        `json:"time"`
+}
+
+type PolicyDecision struct {
+	Allowed    bool        `json:"allowed"`
+	Reason     string      `json:"reason"`
+	PolicyID   *uuid.UUID  `json:"policy_id,omitempty"`
+	PolicyName string      `json:"policy_name,omitempty"`
+	Rule       *PolicyRule `json:"rule,omitempty"`
+}
+
 func (p *Policy) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
 		p.ID = uuid.New()
 	}
 	return nil
 }
+
+func (p *Policy) GetDocument() (*PolicyDocument, error) {
+	var document PolicyDocument
+	if err := json.Unmarshal([]byte(p.Rules), &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (p *Policy) SetDocument(document *PolicyDocument) error {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	p.Rules = string(data)
+	return nil
+}
+
+func (a *PolicyAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+func (r *PolicyRule) HasCapability(capability PolicyCapability) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
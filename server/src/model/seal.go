@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+type SealConfig struct {
+	ID              int       `gorm:"primaryKey;autoIncrement:false" json:"-"`
+	SecretShares    int       `gorm:"not null" json:"secret_shares"`
+	SecretThreshold int       `gorm:"not null" json:"secret_threshold"`
+	RootKeyCheck    string    `gorm:"type:text;not null" json:"-"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type SealStatus struct {
+	Initialized bool `json:"initialized"`
+	Sealed      bool `json:"sealed"`
+	Threshold   int  `json:"t"`
+	Shares      int  `json:"n"`
+	Progress    int  `json:"progress"`
+}
@@ -10,6 +10,7 @@ import (
 type Secret struct {
 	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
 	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	TeamID      *uuid.UUID     `gorm:"type:uuid;index" json:"team_id,omitempty"`
 	Name        string         `gorm:"not null" json:"name"`
 	Description string         `json:"description"`
 	Value       string         `gorm:"type:text;not null" json:"-"`
@@ -17,14 +18,37 @@ type Secret struct {
 	Type        SecretType     `gorm:"not null" json:"type"`
 	Tags        string         `gorm:"type:text" json:"tags"`
 	ExpiresAt   *time.Time     `json:"expires_at"`
+	NotifiedAt  *time.Time     `json:"-"`
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
+	Version     int            `gorm:"not null;default:0" json:"version"`
+	MaxVersions int            `gorm:"not null;default:0" json:"max_versions"`
+	DataKey     string         `gorm:"type:text" json:"-"`
+	KeyVersion  int            `gorm:"not null;default:0;index" json:"key_version"`
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// Destroyed is set on listed secrets whose current version was
+	// destroyed, leaving no value to return.
+	Destroyed bool `gorm:"-" json:"destroyed,omitempty"`
+
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+type SecretVersion struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	SecretID  uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_secret_version" json:"secret_id"`
+	Version   int        `gorm:"not null;uniqueIndex:idx_secret_version" json:"version"`
+	Value     string     `gorm:"type:text" json:"-"`
+	ValueHash string     `json:"-"`
+	CreatedBy uuid.UUID  `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Destroyed bool       `gorm:"default:false" json:"destroyed"`
+
+	Secret Secret `gorm:"foreignKey:SecretID" json:"-"`
+}
+
 type SecretType string
 
 const (
@@ -41,3 +65,19 @@ func (s *Secret) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func (v *SecretVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired reports whether the secret's expiry has passed at now.
+func (s *Secret) IsExpired(now time.Time) bool {
+	return s.ExpiresAt != nil && !s.ExpiresAt.After(now)
+}
+
+func (v *SecretVersion) IsReadable() bool {
+	return v.DeletedAt == nil && !v.Destroyed
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of a user. Its ID is shared by the refresh token
+// family and carried in every access token issued for it.
+type Session struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"identity_id"`
+	TokenID      string     `gorm:"index" json:"token_id"`
+	IPAddress    string     `json:"ip_address"`
+	UserAgent    string     `json:"user_agent"`
+	IsActive     bool       `gorm:"not null;default:true;index" json:"is_active"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastActivity time.Time  `gorm:"not null;index" json:"last_activity"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+}
+
+// ClientInfo identifies the client a session was opened from.
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
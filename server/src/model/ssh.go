@@ -0,0 +1,127 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SSH certificate types, as in OpenSSH.
+const (
+	SSHCertTypeUser = "user"
+	SSHCertTypeHost = "host"
+)
+
+// SSHCA is the engine's signing key. The private key is sealed with a
+// per-CA data key; the public key is published for TrustedUserCAKeys.
+type SSHCA struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	PublicKey  string     `gorm:"type:text;not null" json:"public_key"`
+	PrivateKey string     `gorm:"type:text;not null" json:"-"`
+	DataKey    string     `gorm:"type:text;not null" json:"-"`
+	KeyVersion int        `gorm:"not null" json:"-"`
+	KeyType    string     `gorm:"not null" json:"key_type"`
+	CreatedBy  *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SSHRole constrains the certificates signed through it. Lists are comma
+// separated and "*" allows anything; default extensions and critical options
+// are JSON objects. TTLs are in seconds.
+type SSHRole struct {
+	ID                     uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name                   string    `gorm:"uniqueIndex;not null" json:"name"`
+	CertType               string    `gorm:"not null" json:"cert_type"`
+	AllowedPrincipals      string    `gorm:"type:text" json:"allowed_principals"`
+	DefaultPrincipals      string    `gorm:"type:text" json:"default_principals"`
+	AllowedExtensions      string    `gorm:"type:text" json:"allowed_extensions"`
+	DefaultExtensions      string    `gorm:"type:text" json:"default_extensions"`
+	AllowedCriticalOptions string    `gorm:"type:text" json:"allowed_critical_options"`
+	DefaultCriticalOptions string    `gorm:"type:text" json:"default_critical_options"`
+	TTL                    int       `gorm:"not null" json:"ttl"`
+	MaxTTL                 int       `gorm:"not null" json:"max_ttl"`
+	CreatedAt              time.Time `json:"created_at"`
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// SSHCertificate records every certificate the engine signed, so it can be
+// revoked by serial or key ID and listed in the KRL.
+type SSHCertificate struct {
+	Serial      uint64     `gorm:"primaryKey;autoIncrement:false" json:"serial_number"`
+	KeyID       string     `gorm:"index;not null" json:"key_id"`
+	Role        string     `gorm:"index" json:"role"`
+	CertType    string     `gorm:"not null" json:"cert_type"`
+	Principals  string     `gorm:"type:text" json:"principals"`
+	Fingerprint string     `gorm:"not null" json:"fingerprint"`
+	ValidAfter  time.Time  `gorm:"not null" json:"valid_after"`
+	ValidBefore time.Time  `gorm:"not null;index" json:"valid_before"`
+	RevokedAt   *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+	IssuedBy    *uuid.UUID `gorm:"type:uuid;index" json:"issued_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func (c *SSHCA) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+func (r *SSHRole) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// SSHConfigCARequest generates a CA key, or imports one when PrivateKey is
+// set (OpenSSH or PEM format).
+type SSHConfigCARequest struct {
+	KeyType    string `json:"key_type"`
+	PrivateKey string `json:"private_key"`
+}
+
+// SSHRoleRequest creates or replaces a role. Without allowed extensions, a
+// user role allows and defaults to permit-pty.
+type SSHRoleRequest struct {
+	Name                   string            `json:"name" binding:"required"`
+	CertType               string            `json:"cert_type"`
+	AllowedPrincipals      []string          `json:"allowed_principals"`
+	DefaultPrincipals      []string          `json:"default_principals"`
+	AllowedExtensions      []string          `json:"allowed_extensions"`
+	DefaultExtensions      map[string]string `json:"default_extensions"`
+	AllowedCriticalOptions []string          `json:"allowed_critical_options"`
+	DefaultCriticalOptions map[string]string `json:"default_critical_options"`
+	TTL                    int               `json:"ttl"`
+	MaxTTL                 int               `json:"max_ttl"`
+}
+
+// SSHSignRequest signs a public key in authorized_keys format. Extensions
+// and critical options given here replace the role's defaults.
+type SSHSignRequest struct {
+	PublicKey       string            `json:"public_key" binding:"required"`
+	ValidPrincipals []string          `json:"valid_principals"`
+	Extensions      map[string]string `json:"extensions"`
+	CriticalOptions map[string]string `json:"critical_options"`
+	TTL             int               `json:"ttl"`
+}
+
+type SSHSignResponse struct {
+	SerialNumber uint64    `json:"serial_number"`
+	KeyID        string    `json:"key_id"`
+	SignedKey    string    `json:"signed_key"`
+	ValidBefore  time.Time `json:"valid_before"`
+}
+
+// SSHRevokeRequest revokes one certificate by serial, or every certificate
+// with a key ID.
+type SSHRevokeRequest struct {
+	SerialNumber uint64 `json:"serial_number"`
+	KeyID        string `json:"key_id"`
+	Reason       string `json:"reason"`
+}
+
+type SSHRevokeResponse struct {
+	Revoked []uint64 `json:"revoked"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Team owns a shared collection of secrets and TOTP seeds. Unlike groups,
+// which admins use to assign policies, teams are run by their own members.
+type Team struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name        string    `gorm:"uniqueIndex;not null" json:"name"`
+	Description string    `json:"description"`
+	CreatedBy   uuid.UUID `gorm:"type:uuid;not null" json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Members []TeamMember `gorm:"foreignKey:TeamID" json:"members,omitempty"`
+}
+
+// TeamMember grants a user one permission on everything in a team's
+// collection.
+type TeamMember struct {
+	TeamID     uuid.UUID `gorm:"type:uuid;primary_key" json:"team_id"`
+	UserID     uuid.UUID `gorm:"type:uuid;primary_key;index" json:"user_id"`
+	Permission string    `gorm:"not null" json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Team permissions, each including the ones before it: read opens items,
+// write creates and changes them, manage deletes, shares and transfers them
+// and administers the team.
+const (
+	TeamPermissionRead   = "read"
+	TeamPermissionWrite  = "write"
+	TeamPermissionManage = "manage"
+)
+
+var teamPermissionRank = map[string]int{
+	TeamPermissionRead:   1,
+	TeamPermissionWrite:  2,
+	TeamPermissionManage: 3,
+}
+
+func IsValidTeamPermission(permission string) bool {
+	_, ok := teamPermissionRank[permission]
+	return ok
+}
+
+// TeamPermissionAllows reports whether holding permission have grants want.
+func TeamPermissionAllows(have, want string) bool {
+	return teamPermissionRank[have] > 0 && teamPermissionRank[have] >= teamPermissionRank[want]
+}
+
+func (t *Team) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
@@ -7,26 +7,54 @@ import (
 	"gorm.io/gorm"
 )
 
+// TOTP is a stored authenticator entry. When the vault verifies codes for
+// it, LastUsedStep rejects a code whose time step was already accepted, and
+// FailedAttempts counts failures in a row until the entry is locked.
 type TOTP struct {
-	ID          uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
-	UserID      uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
-	Name        string         `gorm:"not null" json:"name"`
-	Description string         `json:"description"`
-	Secret      string         `gorm:"type:text;not null" json:"-"`
-	Algorithm   string         `gorm:"default:SHA1" json:"algorithm"`
-	Digits      int            `gorm:"default:6" json:"digits"`
-	Period      int            `gorm:"default:30" json:"period"`
-	IsActive    bool           `gorm:"default:true" json:"is_active"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	UserID         uuid.UUID      `gorm:"type:uuid;not null" json:"user_id"`
+	TeamID         *uuid.UUID     `gorm:"type:uuid;index" json:"team_id,omitempty"`
+	Name           string         `gorm:"not null" json:"name"`
+	Description    string         `json:"description"`
+	Issuer         string         `json:"issuer,omitempty"`
+	Secret         string         `gorm:"type:text;not null" json:"-"`
+	Algorithm      string         `gorm:"default:SHA1" json:"algorithm"`
+	Digits         int            `gorm:"default:6" json:"digits"`
+	Period         int            `gorm:"default:30" json:"period"`
+	DataKey        string         `gorm:"type:text" json:"-"`
+	KeyVersion     int            `gorm:"not null;default:0;index" json:"-"`
+	IsActive       bool           `gorm:"default:true" json:"is_active"`
+	LastUsedStep   int64          `gorm:"not null;default:0" json:"-"`
+	LastUsedAt     *time.Time     `json:"last_used_at,omitempty"`
+	FailedAttempts int            `gorm:"not null;default:0" json:"failed_attempts"`
+	LockedUntil    *time.Time     `json:"locked_until,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
 
 	User User `gorm:"foreignKey:UserID" json:"-"`
 }
 
+// TOTPBackupCode is a single-use code accepted in place of a TOTP code. Only
+// its hash is stored.
+type TOTPBackupCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	TOTPID    uuid.UUID  `gorm:"type:uuid;not null;index" json:"totp_id"`
+	CodeHash  string     `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
 func (t *TOTP) BeforeCreate(tx *gorm.DB) error {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
 	return nil
 }
+
+func (c *TOTPBackupCode) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
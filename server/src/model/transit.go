@@ -0,0 +1,170 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Transit key types.
+const (
+	TransitKeyAES256GCM96      = "aes256-gcm96"
+	TransitKeyChaCha20Poly1305 = "chacha20-poly1305"
+	TransitKeyEd25519          = "ed25519"
+	TransitKeyECDSAP256        = "ecdsa-p256"
+	TransitKeyECDSAP384        = "ecdsa-p384"
+	TransitKeyRSA2048          = "rsa-2048"
+	TransitKeyRSA3072          = "rsa-3072"
+	TransitKeyRSA4096          = "rsa-4096"
+)
+
+// TransitKey is a named key of the transit engine. Its material lives in
+// TransitKeyVersion rows; LatestVersion grows with every rotation.
+// MinDecryptionVersion keeps older versions from decrypting, verifying or
+// rewrapping, and MinEncryptionVersion (0 for the latest) bounds the version
+// callers may pick for new ciphertexts.
+type TransitKey struct {
+	ID                   uuid.UUID  `gorm:"type:uuid;primary_key" json:"id"`
+	Name                 string     `gorm:"uniqueIndex;not null" json:"name"`
+	Type                 string     `gorm:"not null" json:"type"`
+	LatestVersion        int        `gorm:"not null" json:"latest_version"`
+	MinDecryptionVersion int        `gorm:"not null;default:1" json:"min_decryption_version"`
+	MinEncryptionVersion int        `gorm:"not null;default:0" json:"min_encryption_version"`
+	DeletionAllowed      bool       `gorm:"not null;default:false" json:"deletion_allowed"`
+	CreatedBy            *uuid.UUID `gorm:"type:uuid" json:"created_by,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// TransitKeyVersion holds one version of a key's material, sealed with its
+// own data key. Every version also carries an HMAC key.
+type TransitKeyVersion struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key" json:"-"`
+	KeyID      uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_transit_key_version" json:"-"`
+	Version    int       `gorm:"not null;uniqueIndex:idx_transit_key_version" json:"version"`
+	Material   string    `gorm:"type:text;not null" json:"-"`
+	DataKey    string    `gorm:"type:text;not null" json:"-"`
+	KeyVersion int       `gorm:"not null" json:"-"`
+	PublicKey  string    `gorm:"type:text" json:"public_key,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (k *TransitKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
+
+func (v *TransitKeyVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+type TransitCreateKeyRequest struct {
+	Type string `json:"type"`
+}
+
+// TransitKeyConfigRequest updates the fields that are set.
+type TransitKeyConfigRequest struct {
+	MinDecryptionVersion *int  `json:"min_decryption_version"`
+	MinEncryptionVersion *int  `json:"min_encryption_version"`
+	DeletionAllowed      *bool `json:"deletion_allowed"`
+}
+
+type TransitKeyResponse struct {
+	TransitKey
+	SupportsEncryption bool                `json:"supports_encryption"`
+	SupportsSigning    bool                `json:"supports_signing"`
+	Versions           []TransitKeyVersion `json:"versions"`
+}
+
+// TransitEncryptRequest takes base64 plaintext. AssociatedData is base64
+// too and must be given again to decrypt; RSA keys use it as OAEP label.
+type TransitEncryptRequest struct {
+	Plaintext      string `json:"plaintext" binding:"required"`
+	AssociatedData string `json:"associated_data"`
+	KeyVersion     int    `json:"key_version"`
+}
+
+type TransitEncryptResponse struct {
+	Ciphertext string `json:"ciphertext"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type TransitDecryptRequest struct {
+	Ciphertext     string `json:"ciphertext" binding:"required"`
+	AssociatedData string `json:"associated_data"`
+}
+
+type TransitDecryptResponse struct {
+	Plaintext string `json:"plaintext"`
+}
+
+// TransitRewrapRequest re-encrypts a ciphertext with the latest key version,
+// or KeyVersion, without revealing the plaintext.
+type TransitRewrapRequest struct {
+	Ciphertext     string `json:"ciphertext" binding:"required"`
+	AssociatedData string `json:"associated_data"`
+	KeyVersion     int    `json:"key_version"`
+}
+
+// TransitDataKeyRequest generates a random key of Bits (128, 256 or 512)
+// for the caller to encrypt data locally.
+type TransitDataKeyRequest struct {
+	Bits           int    `json:"bits"`
+	AssociatedData string `json:"associated_data"`
+}
+
+// TransitDataKeyResponse carries the key encrypted under the transit key,
+// and in base64 unless only the wrapped form was asked for.
+type TransitDataKeyResponse struct {
+	Plaintext  string `json:"plaintext,omitempty"`
+	Ciphertext string `json:"ciphertext"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// TransitSignRequest signs base64 input. HashAlgorithm is sha2-256
+// (default), sha2-384 or sha2-512 and is ignored by Ed25519;
+// SignatureAlgorithm is pss (default) or pkcs1v15 for RSA keys.
+type TransitSignRequest struct {
+	Input              string `json:"input" binding:"required"`
+	HashAlgorithm      string `json:"hash_algorithm"`
+	SignatureAlgorithm string `json:"signature_algorithm"`
+	KeyVersion         int    `json:"key_version"`
+}
+
+type TransitSignResponse struct {
+	Signature  string `json:"signature"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// TransitHMACRequest computes an HMAC of base64 input with the key version's
+// HMAC key. Algorithm is sha2-256 (default), sha2-384 or sha2-512.
+type TransitHMACRequest struct {
+	Input      string `json:"input" binding:"required"`
+	Algorithm  string `json:"algorithm"`
+	KeyVersion int    `json:"key_version"`
+}
+
+type TransitHMACResponse struct {
+	HMAC       string `json:"hmac"`
+	KeyVersion int    `json:"key_version"`
+}
+
+// TransitVerifyRequest checks either a Signature or an HMAC of the input;
+// HashAlgorithm applies to both.
+type TransitVerifyRequest struct {
+	Input              string `json:"input" binding:"required"`
+	Signature          string `json:"signature"`
+	HMAC               string `json:"hmac"`
+	HashAlgorithm      string `json:"hash_algorithm"`
+	SignatureAlgorithm string `json:"signature_algorithm"`
+}
+
+type TransitVerifyResponse struct {
+	Valid bool `json:"valid"`
+}
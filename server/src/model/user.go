@@ -14,17 +14,54 @@ type User struct {
 	FirstName string         `json:"first_name"`
 	LastName  string         `json:"last_name"`
 	IsActive  bool           `gorm:"default:true" json:"is_active"`
+	Role      string         `gorm:"not null;default:'user';index" json:"role"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// TokensRevokedAt invalidates every token issued up to that second.
+	TokensRevokedAt *time.Time `json:"-"`
+
+	Groups  []Group  `gorm:"many2many:user_groups" json:"groups,omitempty"`
 	Secrets []Secret `gorm:"foreignKey:UserID" json:"-"`
 	TOTPs   []TOTP   `gorm:"foreignKey:UserID" json:"-"`
 }
 
+const (
+	// RoleAdmin has full access, including role and group administration.
+	RoleAdmin = "admin"
+	// RoleOperator manages regular user accounts.
+	RoleOperator = "operator"
+	// RoleUser only manages its own account.
+	RoleUser = "user"
+	// RoleAuditor reads audit logs.
+	RoleAuditor = "auditor"
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleOperator, RoleUser, RoleAuditor:
+		return true
+	default:
+		return false
+	}
+}
+
+func (u *User) HasRole(roles ...string) bool {
+	for _, role := range roles {
+		if u.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
 	}
+	if u.Role == "" {
+		u.Role = RoleUser
+	}
 	return nil
 }
@@ -0,0 +1,58 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WrappingToken is a single-use token standing in for a wrapped response.
+// Its cubbyhole holds the response sealed with a per-token data key and is
+// deleted together with the token on unwrap. Only the token hash is stored;
+// the ID doubles as an accessor that can be logged safely.
+type WrappingToken struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"accessor"`
+	LeaseID      string     `gorm:"index" json:"-"`
+	TokenHash    string     `gorm:"uniqueIndex;not null" json:"-"`
+	CreationPath string     `gorm:"not null" json:"creation_path"`
+	CreatedBy    *uuid.UUID `gorm:"type:uuid;index" json:"created_by,omitempty"`
+	Cubbyhole    string     `gorm:"type:text;not null" json:"-"`
+	DataKey      string     `gorm:"type:text;not null" json:"-"`
+	KeyVersion   int        `gorm:"not null" json:"-"`
+	TTL          int        `gorm:"not null" json:"ttl"`
+	ExpiresAt    time.Time  `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time  `json:"creation_time"`
+}
+
+// WrapInfo is returned in place of a wrapped response.
+type WrapInfo struct {
+	Token        string    `json:"token"`
+	Accessor     uuid.UUID `json:"accessor"`
+	TTL          int       `json:"ttl"`
+	CreationTime time.Time `json:"creation_time"`
+	CreationPath string    `json:"creation_path"`
+}
+
+type WrapResponse struct {
+	WrapInfo WrapInfo `json:"wrap_info"`
+}
+
+type WrappingTokenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// WrappingLookupResponse describes a wrapping token without consuming it;
+// TTL is the number of seconds left before it expires.
+type WrappingLookupResponse struct {
+	Accessor     uuid.UUID `json:"accessor"`
+	CreationPath string    `json:"creation_path"`
+	CreationTime time.Time `json:"creation_time"`
+	CreationTTL  int       `json:"creation_ttl"`
+	TTL          int       `json:"ttl"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type UnwrapResponse struct {
+	Data json.RawMessage `json:"data"`
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/skygenesisenterprise/aether-vault/server/src/controllers"
 	"github.com/skygenesisenterprise/aether-vault/server/src/middleware"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"github.com/skygenesisenterprise/aether-vault/server/src/services"
 
 	"github.com/gin-gonic/gin"
@@ -20,11 +21,28 @@ type Router struct {
 	userController      *controllers.UserController
 	networkController   *controllers.NetworkController
 	snmpController      *controllers.SNMPController
+	policyController    *controllers.PolicyController
+	keyringController   *controllers.KeyringController
+	sealController      *controllers.SealController
+	mfaController       *controllers.MFAController
+	sessionController   *controllers.SessionController
+	groupController     *controllers.GroupController
+	teamController      *controllers.TeamController
+	wrappingController  *controllers.WrappingController
+	leaseController     *controllers.LeaseController
+	databaseController  *controllers.DatabaseController
+	pkiController       *controllers.PKIController
+	sshController       *controllers.SSHController
+	transitController   *controllers.TransitController
 	authMiddleware      *middleware.AuthMiddleware
+	policyMiddleware    *middleware.PolicyMiddleware
+	sealMiddleware      *middleware.SealMiddleware
+	userMiddleware      *middleware.UserMiddleware
 	auditMiddleware     *middleware.AuditMiddleware
 	rateLimitMiddleware *middleware.RateLimitMiddleware
 	networkMiddleware   *middleware.NetworkMiddleware
 	snmpMiddleware      *middleware.SNMPMiddleware
+	wrappingMiddleware  *middleware.WrappingMiddleware
 }
 
 func NewRouter(
@@ -37,6 +55,18 @@ func NewRouter(
 	auditService *services.AuditService,
 	networkService *services.NetworkService,
 	snmpService *services.SNMPService,
+	keyringService *services.KeyringService,
+	sealService *services.SealService,
+	mfaService *services.MFAService,
+	sessionService *services.SessionService,
+	groupService *services.GroupService,
+	teamService *services.TeamService,
+	wrappingService *services.WrappingService,
+	leaseService *services.LeaseService,
+	databaseService *services.DatabaseService,
+	pkiService *services.PKIService,
+	sshService *services.SSHService,
+	transitService *services.TransitService,
 ) *Router {
 	authController := controllers.NewAuthController(authService, auditService)
 	secretController := controllers.NewSecretController(secretService)
@@ -47,9 +77,26 @@ func NewRouter(
 	userController := controllers.NewUserController(userService, auditService)
 	networkController := controllers.NewNetworkController(networkService)
 	snmpController := controllers.NewSNMPController(snmpService)
+	policyController := controllers.NewPolicyController(policyService, auditService)
+	keyringController := controllers.NewKeyringController(keyringService, auditService)
+	sealController := controllers.NewSealController(sealService, auditService)
+	mfaController := controllers.NewMFAController(mfaService, userService, auditService)
+	sessionController := controllers.NewSessionController(sessionService, auditService)
+	groupController := controllers.NewGroupController(groupService, auditService)
+	teamController := controllers.NewTeamController(teamService, auditService)
+	wrappingController := controllers.NewWrappingController(wrappingService, auditService)
+	leaseController := controllers.NewLeaseController(leaseService, auditService)
+	databaseController := controllers.NewDatabaseController(databaseService, auditService)
+	pkiController := controllers.NewPKIController(pkiService, auditService)
+	sshController := controllers.NewSSHController(sshService, auditService)
+	transitController := controllers.NewTransitController(transitService, auditService)
 
-	authMiddleware := middleware.NewAuthMiddleware(authService)
+	authMiddleware := middleware.NewAuthMiddleware(authService, sessionService)
+	policyMiddleware := middleware.NewPolicyMiddleware(policyService)
+	sealMiddleware := middleware.NewSealMiddleware(sealService)
+	userMiddleware := middleware.NewUserMiddleware(userService)
 	auditMiddleware := middleware.NewAuditMiddleware(auditService)
+	wrappingMiddleware := middleware.NewWrappingMiddleware(wrappingService, auditService)
 	rateLimitMiddleware := middleware.NewRateLimitMiddleware(100, 60) // 100 requests per minute
 
 	networkConfig := &middleware.NetworkConfig{
@@ -74,6 +121,7 @@ func NewRouter(
 	engine.Use(middleware.RequestIDMiddleware())
 	engine.Use(rateLimitMiddleware.Limit())
 	engine.Use(auditMiddleware.Audit())
+	engine.Use(wrappingMiddleware.Wrap())
 
 	return &Router{
 		engine:              engine,
@@ -86,11 +134,28 @@ func NewRouter(
 		userController:      userController,
 		networkController:   networkController,
 		snmpController:      snmpController,
+		policyController:    policyController,
+		keyringController:   keyringController,
+		sealController:      sealController,
+		mfaController:       mfaController,
+		sessionController:   sessionController,
+		groupController:     groupController,
+		teamController:      teamController,
+		wrappingController:  wrappingController,
+		leaseController:     leaseController,
+		databaseController:  databaseController,
+		pkiController:       pkiController,
+		sshController:       sshController,
+		transitController:   transitController,
 		authMiddleware:      authMiddleware,
+		policyMiddleware:    policyMiddleware,
+		sealMiddleware:      sealMiddleware,
+		userMiddleware:      userMiddleware,
 		auditMiddleware:     auditMiddleware,
 		rateLimitMiddleware: rateLimitMiddleware,
 		networkMiddleware:   networkMiddleware,
 		snmpMiddleware:      snmpMiddleware,
+		wrappingMiddleware:  wrappingMiddleware,
 	}
 }
 
@@ -98,55 +163,277 @@ func (r *Router) SetupRoutes() {
 	v1 := r.engine.Group("/api/v1")
 
 	auth := v1.Group("/auth")
+	auth.Use(r.sealMiddleware.RequireUnsealed())
 	{
 		auth.POST("/login", r.authController.Login)
+		auth.POST("/login/mfa", r.authController.CompleteMFALogin)
+		auth.POST("/refresh", r.authController.Refresh)
+		auth.POST("/verify", r.authController.Verify)
+		auth.POST("/revoke", r.authMiddleware.RequireAuth(), r.authController.Revoke)
+		auth.POST("/revoke-all", r.authMiddleware.RequireAuth(), r.authController.RevokeAll)
 		auth.POST("/logout", r.authMiddleware.RequireAuth(), r.authController.Logout)
 		auth.GET("/session", r.authMiddleware.RequireAuth(), r.authController.GetSession)
+		auth.POST("/token/renew-self", r.authMiddleware.RequireAuth(), r.authController.RenewToken)
+	}
+
+	mfa := auth.Group("/mfa")
+	mfa.Use(r.authMiddleware.RequireAuth())
+	{
+		mfa.GET("", r.mfaController.GetStatus)
+		mfa.POST("/enroll", r.mfaController.Enroll)
+		mfa.POST("/confirm", r.mfaController.Confirm)
+		mfa.POST("/recovery-codes", r.mfaController.RegenerateRecoveryCodes)
+		mfa.POST("/disable", r.mfaController.Disable)
+	}
+
+	sessions := auth.Group("/sessions")
+	sessions.Use(r.authMiddleware.RequireAuth())
+	{
+		sessions.GET("", r.sessionController.ListMySessions)
+		sessions.DELETE("", r.sessionController.RevokeMySessions)
+		sessions.DELETE("/:id", r.sessionController.RevokeMySession)
 	}
 
 	secrets := v1.Group("/secrets")
+	secrets.Use(r.sealMiddleware.RequireUnsealed())
 	secrets.Use(r.authMiddleware.RequireAuth())
+	secrets.Use(r.policyMiddleware.Enforce())
 	{
 		secrets.GET("", r.secretController.GetSecrets)
 		secrets.POST("", r.secretController.CreateSecret)
 		secrets.GET("/:id", r.secretController.GetSecret)
 		secrets.PUT("/:id", r.secretController.UpdateSecret)
 		secrets.DELETE("/:id", r.secretController.DeleteSecret)
+
+		secrets.GET("/:id/versions", r.secretController.ListSecretVersions)
+		secrets.GET("/:id/versions/:version", r.secretController.GetSecretVersion)
+		secrets.POST("/:id/restore", r.secretController.RestoreSecretVersion)
+		secrets.POST("/:id/versions/delete", r.secretController.DeleteSecretVersions)
+		secrets.POST("/:id/versions/undelete", r.secretController.UndeleteSecretVersions)
+		secrets.POST("/:id/versions/destroy", r.secretController.DestroySecretVersions)
+
+		secrets.PUT("/:id/share", r.secretController.ShareSecret)
+		secrets.DELETE("/:id/share", r.secretController.UnshareSecret)
+		secrets.POST("/:id/transfer", r.secretController.TransferSecret)
 	}
 
 	totp := v1.Group("/totp")
+	totp.Use(r.sealMiddleware.RequireUnsealed())
 	totp.Use(r.authMiddleware.RequireAuth())
+	totp.Use(r.policyMiddleware.Enforce())
 	{
 		totp.GET("", r.totpController.GetTOTPs)
 		totp.POST("", r.totpController.CreateTOTP)
+		totp.POST("/import", r.totpController.Import)
+		totp.GET("/export", r.totpController.Export)
+		totp.GET("/:id/uri", r.totpController.ExportURI)
+		totp.GET("/:id/qr", r.totpController.QRCode)
 		totp.POST("/:id/generate", r.totpController.GenerateCode)
+		totp.POST("/:id/verify", r.totpController.Verify)
+		totp.POST("/:id/backup-codes", r.totpController.RegenerateBackupCodes)
 	}
 
 	identity := v1.Group("/identity")
+	identity.Use(r.sealMiddleware.RequireUnsealed())
 	identity.Use(r.authMiddleware.RequireAuth())
 	{
 		identity.GET("/me", r.identityController.GetMe)
 		identity.GET("/policies", r.identityController.GetPolicies)
 	}
 
+	identities := v1.Group("/identities")
+	identities.Use(r.sealMiddleware.RequireUnsealed())
+	identities.Use(r.authMiddleware.RequireAuth())
+	identities.Use(r.userMiddleware.RequireAdmin())
+	{
+		identities.POST("/sessions/search", r.sessionController.SearchSessions)
+		identities.DELETE("/sessions/:id", r.sessionController.RevokeSession)
+		identities.DELETE("/:identity_id/sessions", r.sessionController.RevokeIdentitySessions)
+	}
+
+	policies := v1.Group("/policies")
+	policies.Use(r.sealMiddleware.RequireUnsealed())
+	policies.Use(r.authMiddleware.RequireAuth())
+	policies.Use(r.userMiddleware.RequireAdmin())
+	{
+		policies.POST("", r.policyController.CreatePolicy)
+		policies.POST("/search", r.policyController.ListPolicies)
+		policies.POST("/check", r.policyController.CheckPolicy)
+		policies.GET("/:id", r.policyController.GetPolicy)
+		policies.PUT("/:id", r.policyController.UpdatePolicy)
+		policies.DELETE("/:id", r.policyController.DeletePolicy)
+		policies.POST("/:id/enable", r.policyController.EnablePolicy)
+		policies.POST("/:id/disable", r.policyController.DisablePolicy)
+
+		policies.POST("/assignments", r.policyController.CreateAssignment)
+		policies.POST("/assignments/search", r.policyController.ListAssignments)
+		policies.DELETE("/assignments/:id", r.policyController.DeleteAssignment)
+
+		policies.GET("/identity/:identity_id", r.policyController.GetIdentityPolicies)
+	}
+
 	users := v1.Group("/users")
+	users.Use(r.sealMiddleware.RequireUnsealed())
 	users.Use(r.authMiddleware.RequireAuth())
 	{
-		users.GET("", r.userController.GetUsers)
-		users.GET("/:id", r.userController.GetUser)
-		users.POST("", r.userController.CreateUser)
-		users.PUT("/:id", r.userController.UpdateUser)
-		users.DELETE("/:id", r.userController.DeleteUser)
+		users.GET("", r.userMiddleware.RequireRole(model.RoleAdmin, model.RoleOperator), r.userController.GetUsers)
+		users.GET("/:id", r.userMiddleware.RequireSelfOrRole(model.RoleAdmin, model.RoleOperator), r.userController.GetUser)
+		users.POST("", r.userMiddleware.RequireRole(model.RoleAdmin, model.RoleOperator), r.userController.CreateUser)
+		users.PUT("/:id", r.userMiddleware.RequireSelfOrRole(model.RoleAdmin, model.RoleOperator), r.userController.UpdateUser)
+		users.DELETE("/:id", r.userMiddleware.RequireAdmin(), r.userController.DeleteUser)
+		users.DELETE("/:id/mfa", r.userMiddleware.RequireAdmin(), r.mfaController.Reset)
+		users.POST("/:id/revoke-tokens", r.userMiddleware.RequireAdmin(), r.authController.RevokeUserTokens)
+	}
+
+	groups := v1.Group("/groups")
+	groups.Use(r.sealMiddleware.RequireUnsealed())
+	groups.Use(r.authMiddleware.RequireAuth())
+	groups.Use(r.userMiddleware.RequireAdmin())
+	{
+		groups.GET("", r.groupController.ListGroups)
+		groups.POST("", r.groupController.CreateGroup)
+		groups.GET("/:id", r.groupController.GetGroup)
+		groups.DELETE("/:id", r.groupController.DeleteGroup)
+		groups.POST("/:id/members", r.groupController.AddMember)
+		groups.DELETE("/:id/members/:user_id", r.groupController.RemoveMember)
+	}
+
+	teams := v1.Group("/teams")
+	teams.Use(r.sealMiddleware.RequireUnsealed())
+	teams.Use(r.authMiddleware.RequireAuth())
+	{
+		teams.GET("", r.teamController.ListTeams)
+		teams.POST("", r.teamController.CreateTeam)
+		teams.GET("/:id", r.teamController.GetTeam)
+		teams.DELETE("/:id", r.teamController.DeleteTeam)
+		teams.GET("/:id/secrets", r.secretController.GetTeamSecrets)
+		teams.POST("/:id/members", r.teamController.SetMember)
+		teams.DELETE("/:id/members/:user_id", r.teamController.RemoveMember)
+	}
+
+	database := v1.Group("/database")
+	database.Use(r.sealMiddleware.RequireUnsealed())
+	database.Use(r.authMiddleware.RequireAuth())
+	database.Use(r.policyMiddleware.RequireGrant())
+	{
+		database.GET("/config", r.userMiddleware.RequireAdmin(), r.databaseController.ListConnections)
+		database.POST("/config", r.userMiddleware.RequireAdmin(), r.databaseController.WriteConnection)
+		database.GET("/config/:name", r.userMiddleware.RequireAdmin(), r.databaseController.GetConnection)
+		database.DELETE("/config/:name", r.userMiddleware.RequireAdmin(), r.databaseController.DeleteConnection)
+
+		database.GET("/roles", r.userMiddleware.RequireAdmin(), r.databaseController.ListRoles)
+		database.POST("/roles", r.userMiddleware.RequireAdmin(), r.databaseController.WriteRole)
+		database.GET("/roles/:name", r.userMiddleware.RequireAdmin(), r.databaseController.GetRole)
+		database.DELETE("/roles/:name", r.userMiddleware.RequireAdmin(), r.databaseController.DeleteRole)
+
+		database.GET("/creds/:name", r.databaseController.GenerateCredentials)
+	}
+
+	// CA certificates, the CRL and OCSP are fetched by relying parties
+	// without a token.
+	pkiPublic := v1.Group("/pki")
+	pkiPublic.Use(r.sealMiddleware.RequireUnsealed())
+	{
+		pkiPublic.GET("/ca", r.pkiController.CA)
+		pkiPublic.GET("/ca/pem", r.pkiController.CAPEM)
+		pkiPublic.GET("/ca_chain", r.pkiController.CAChain)
+		pkiPublic.GET("/crl", r.pkiController.CRL)
+		pkiPublic.GET("/crl/pem", r.pkiController.CRLPEM)
+		pkiPublic.POST("/ocsp", r.pkiController.OCSP)
+		pkiPublic.GET("/ocsp/*request", r.pkiController.OCSP)
+	}
+
+	pki := v1.Group("/pki")
+	pki.Use(r.sealMiddleware.RequireUnsealed())
+	pki.Use(r.authMiddleware.RequireAuth())
+	pki.Use(r.policyMiddleware.RequireGrant())
+	{
+		pki.POST("/root/generate", r.userMiddleware.RequireAdmin(), r.pkiController.GenerateRoot)
+		pki.POST("/root/sign-intermediate", r.userMiddleware.RequireAdmin(), r.pkiController.SignIntermediate)
+		pki.DELETE("/root", r.userMiddleware.RequireAdmin(), r.pkiController.DeleteCA)
+		pki.POST("/intermediate/generate", r.userMiddleware.RequireAdmin(), r.pkiController.GenerateIntermediate)
+		pki.POST("/intermediate/set-signed", r.userMiddleware.RequireAdmin(), r.pkiController.SetSignedIntermediate)
+		pki.POST("/config/ca", r.userMiddleware.RequireAdmin(), r.pkiController.ImportCA)
+
+		pki.GET("/roles", r.userMiddleware.RequireAdmin(), r.pkiController.ListRoles)
+		pki.POST("/roles", r.userMiddleware.RequireAdmin(), r.pkiController.WriteRole)
+		pki.GET("/roles/:name", r.userMiddleware.RequireAdmin(), r.pkiController.GetRole)
+		pki.DELETE("/roles/:name", r.userMiddleware.RequireAdmin(), r.pkiController.DeleteRole)
+
+		pki.POST("/issue/:role", r.pkiController.Issue)
+		pki.POST("/sign/:role", r.pkiController.Sign)
+		pki.GET("/certs", r.pkiController.ListCertificates)
+		pki.GET("/cert/:serial", r.pkiController.GetCertificate)
+		pki.POST("/revoke", r.userMiddleware.RequireAdmin(), r.pkiController.Revoke)
+	}
+
+	// The CA public key and KRL are fetched by SSH servers without a token.
+	sshPublic := v1.Group("/ssh")
+	sshPublic.Use(r.sealMiddleware.RequireUnsealed())
+	{
+		sshPublic.GET("/public_key", r.sshController.PublicKey)
+		sshPublic.GET("/krl", r.sshController.KRL)
+	}
+
+	ssh := v1.Group("/ssh")
+	ssh.Use(r.sealMiddleware.RequireUnsealed())
+	ssh.Use(r.authMiddleware.RequireAuth())
+	ssh.Use(r.policyMiddleware.RequireGrant())
+	{
+		ssh.GET("/config/ca", r.sshController.GetCA)
+		ssh.POST("/config/ca", r.userMiddleware.RequireAdmin(), r.sshController.ConfigureCA)
+		ssh.DELETE("/config/ca", r.userMiddleware.RequireAdmin(), r.sshController.DeleteCA)
+
+		ssh.GET("/roles", r.userMiddleware.RequireAdmin(), r.sshController.ListRoles)
+		ssh.POST("/roles", r.userMiddleware.RequireAdmin(), r.sshController.WriteRole)
+		ssh.GET("/roles/:name", r.userMiddleware.RequireAdmin(), r.sshController.GetRole)
+		ssh.DELETE("/roles/:name", r.userMiddleware.RequireAdmin(), r.sshController.DeleteRole)
+
+		ssh.POST("/sign/:role", r.sshController.Sign)
+		ssh.GET("/certs", r.sshController.ListCertificates)
+		ssh.POST("/revoke", r.userMiddleware.RequireAdmin(), r.sshController.Revoke)
+	}
+
+	transit := v1.Group("/transit")
+	transit.Use(r.sealMiddleware.RequireUnsealed())
+	transit.Use(r.authMiddleware.RequireAuth())
+	transit.Use(r.policyMiddleware.RequireGrant())
+	{
+		transit.GET("/keys", r.transitController.ListKeys)
+		transit.GET("/keys/:name", r.transitController.GetKey)
+		transit.POST("/keys/:name", r.userMiddleware.RequireAdmin(), r.transitController.CreateKey)
+		transit.POST("/keys/:name/config", r.userMiddleware.RequireAdmin(), r.transitController.ConfigureKey)
+		transit.POST("/keys/:name/rotate", r.userMiddleware.RequireAdmin(), r.transitController.RotateKey)
+		transit.DELETE("/keys/:name", r.userMiddleware.RequireAdmin(), r.transitController.DeleteKey)
+
+		transit.POST("/encrypt/:name", r.transitController.Encrypt)
+		transit.POST("/decrypt/:name", r.transitController.Decrypt)
+		transit.POST("/rewrap/:name", r.transitController.Rewrap)
+		transit.POST("/datakey/:type/:name", r.transitController.GenerateDataKey)
+		transit.POST("/sign/:name", r.transitController.Sign)
+		transit.POST("/verify/:name", r.transitController.Verify)
+		transit.POST("/hmac/:name", r.transitController.HMAC)
 	}
 
 	audit := v1.Group("/audit")
+	audit.Use(r.sealMiddleware.RequireUnsealed())
 	audit.Use(r.authMiddleware.RequireAuth())
+	audit.Use(r.userMiddleware.RequireRole(model.RoleAdmin, model.RoleAuditor))
 	{
 		audit.GET("/logs", r.auditController.GetAuditLogs)
+		audit.GET("/verify", r.auditController.VerifyChain)
+		audit.POST("/search", r.auditController.Search)
+		audit.GET("/events/:id", r.auditController.GetEvent)
+		audit.POST("/stats", r.auditController.GetStats)
+		audit.POST("/export", r.auditController.Export)
+		audit.GET("/retention", r.auditController.GetRetention)
+		audit.PUT("/retention", r.userMiddleware.RequireAdmin(), r.auditController.SetRetention)
 	}
 
 	network := v1.Group("/network")
+	network.Use(r.sealMiddleware.RequireUnsealed())
 	network.Use(r.authMiddleware.RequireAuth())
+	network.Use(r.policyMiddleware.Enforce())
 	network.Use(r.networkMiddleware.ValidateProtocol())
 	network.Use(r.networkMiddleware.NetworkRateLimit())
 	network.Use(r.networkMiddleware.ProtocolSecurity())
@@ -159,12 +446,18 @@ func (r *Router) SetupRoutes() {
 		network.DELETE("/:id", r.networkController.DeleteNetwork)
 
 		network.GET("/protocols", r.networkController.GetSupportedProtocols)
+		network.GET("/alerts", r.networkController.GetAlerts)
 		network.POST("/test", r.networkController.TestProtocol)
 		network.GET("/:id/status", r.networkController.GetProtocolStatus)
+		network.GET("/:id/history", r.networkController.GetCheckHistory)
+		network.GET("/:id/uptime", r.networkController.GetUptime)
+		network.GET("/:id/alerts", r.networkController.GetNetworkAlerts)
 	}
 
 	snmp := v1.Group("/snmp")
+	snmp.Use(r.sealMiddleware.RequireUnsealed())
 	snmp.Use(r.authMiddleware.RequireAuth())
+	snmp.Use(r.policyMiddleware.Enforce())
 	snmp.Use(r.snmpMiddleware.RateLimit())
 	snmp.Use(r.snmpMiddleware.SecurityHeaders())
 	snmp.Use(r.snmpMiddleware.ValidateTarget())
@@ -175,6 +468,42 @@ func (r *Router) SetupRoutes() {
 		snmp.POST("/test", r.snmpController.TestConnection)
 	}
 
+	sys := v1.Group("/sys")
+	{
+		sys.POST("/init", r.sealController.Init)
+		sys.GET("/seal-status", r.sealController.Status)
+		sys.POST("/unseal", r.sealController.Unseal)
+		sys.POST("/seal", r.authMiddleware.RequireAuth(), r.userMiddleware.RequireAdmin(), r.sealController.Seal)
+	}
+
+	keyring := sys.Group("/keyring")
+	keyring.Use(r.sealMiddleware.RequireUnsealed())
+	keyring.Use(r.authMiddleware.RequireAuth())
+	keyring.Use(r.userMiddleware.RequireAdmin())
+	{
+		keyring.GET("", r.keyringController.GetStatus)
+		keyring.POST("/rotate", r.keyringController.Rotate)
+	}
+
+	wrapping := sys.Group("/wrapping")
+	wrapping.Use(r.sealMiddleware.RequireUnsealed())
+	{
+		wrapping.POST("/unwrap", r.wrappingController.Unwrap)
+		wrapping.POST("/lookup", r.wrappingController.Lookup)
+	}
+
+	leases := sys.Group("/leases")
+	leases.Use(r.sealMiddleware.RequireUnsealed())
+	leases.Use(r.authMiddleware.RequireAuth())
+	{
+		leases.GET("", r.leaseController.ListLeases)
+		leases.GET("/all", r.userMiddleware.RequireAdmin(), r.leaseController.ListAllLeases)
+		leases.POST("/lookup", r.leaseController.LookupLease)
+		leases.PUT("/renew", r.leaseController.RenewLease)
+		leases.PUT("/revoke", r.leaseController.RevokeLease)
+		leases.PUT("/revoke-prefix", r.userMiddleware.RequireAdmin(), r.leaseController.RevokePrefix)
+	}
+
 	system := v1.Group("/system")
 	{
 		system.GET("/health", r.systemController.Health)
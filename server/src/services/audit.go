@@ -1,20 +1,63 @@
 package services
 
 import (
-	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+// verifyBatchSize is the number of entries loaded at a time by Verify and
+// Export.
+const verifyBatchSize = 1000
+
+const (
+	AuditStatusSuccess = "success"
+	AuditStatusFailure = "failure"
+
+	AuditExportCSV   = "csv"
+	AuditExportJSONL = "jsonl"
+)
+
+// AuditService writes audit entries as a hash chain: every entry carries an
+// HMAC over its fields and the hash of the entry before it, so editing or
+// deleting a row breaks every later link. Checkpoints of the chain head are
+// signed with an Ed25519 key derived from a separate signing secret, so the
+// chain key alone cannot forge them.
 type AuditService struct {
-	db *gorm.DB
+	db            *gorm.DB
+	chainKey      []byte
+	signingKey    ed25519.PrivateKey
+	retentionDays int
+	mu            sync.Mutex
 }
 
-func NewAuditService(db *gorm.DB) *AuditService {
-	return &AuditService{db: db}
+// NewAuditService creates the audit service. retentionDays is used until a
+// retention policy is stored; zero keeps entries forever.
+func NewAuditService(db *gorm.DB, chainSecret, signingSecret string, retentionDays int) *AuditService {
+	return &AuditService{
+		db:            db,
+		chainKey:      deriveAuditKey(chainSecret, "audit-chain"),
+		signingKey:    ed25519.NewKeyFromSeed(deriveAuditKey(signingSecret, "audit-checkpoint")),
+		retentionDays: retentionDays,
+	}
 }
 
 func (s *AuditService) LogAction(userID uuid.UUID, action, resource, resourceID string, success bool, details string) error {
@@ -28,7 +71,7 @@ func (s *AuditService) LogAction(userID uuid.UUID, action, resource, resourceID
 		CreatedAt:  time.Now(),
 	}
 
-	if err := s.db.Create(auditLog).Error; err != nil {
+	if err := s.append(auditLog); err != nil {
 		return fmt.Errorf("failed to create audit log: %w", err)
 	}
 
@@ -48,7 +91,7 @@ func (s *AuditService) LogAnonymousAction(action, resource, resourceID, ipAddres
 		CreatedAt:  time.Now(),
 	}
 
-	if err := s.db.Create(auditLog).Error; err != nil {
+	if err := s.append(auditLog); err != nil {
 		return fmt.Errorf("failed to create audit log: %w", err)
 	}
 
@@ -83,12 +126,647 @@ func (s *AuditService) GetAuditLogsByResource(resource, resourceID string, limit
 	return logs, nil
 }
 
-func (s *AuditService) CleanupOldLogs(retentionDays int) error {
+// Search returns the entries matching the filter, newest first.
+func (s *AuditService) Search(filter *model.SearchAuditRequest) ([]model.AuditLog, int64, error) {
+	query, err := s.filterQuery(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var logs []model.AuditLog
+	if err := query.Order("sequence DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&logs).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
+
+func (s *AuditService) GetEvent(id uuid.UUID) (*model.AuditLog, error) {
+	var auditLog model.AuditLog
+	if err := s.db.Where("id = ?", id).First(&auditLog).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuditEventNotFound
+		}
+		return nil, fmt.Errorf("failed to get audit log: %w", err)
+	}
+	return &auditLog, nil
+}
+
+// Stats aggregates the entries created within [start, end).
+func (s *AuditService) Stats(start, end time.Time) (*model.AuditStats, error) {
+	stats := &model.AuditStats{
+		EventsByAction: make(map[string]int64),
+		EventsByStatus: make(map[string]int64),
+		EventsByHour:   []model.AuditHourCount{},
+		TimeRange:      model.AuditTimeRange{StartTime: start, EndTime: end},
+	}
+	query := func() *gorm.DB {
+		return s.db.Model(&model.AuditLog{}).Where("created_at >= ? AND created_at < ?", start, end)
+	}
+
+	if err := query().Count(&stats.TotalEvents).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	var byAction []struct {
+		Action string
+		Count  int64
+	}
+	if err := query().Select("action, COUNT(*) AS count").Group("action").Scan(&byAction).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs by action: %w", err)
+	}
+	for _, row := range byAction {
+		stats.EventsByAction[row.Action] = row.Count
+	}
+
+	var byStatus []struct {
+		Success bool
+		Count   int64
+	}
+	if err := query().Select("success, COUNT(*) AS count").Group("success").Scan(&byStatus).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs by status: %w", err)
+	}
+	for _, row := range byStatus {
+		stats.EventsByStatus[auditStatus(row.Success)] = row.Count
+	}
+
+	bucket := s.hourBucket()
+	var byHour []struct {
+		Hour  string
+		Count int64
+	}
+	if err := query().Select(bucket + " AS hour, COUNT(*) AS count").Group(bucket).Order("hour").Scan(&byHour).Error; err != nil {
+		return nil, fmt.Errorf("failed to count audit logs by hour: %w", err)
+	}
+	for _, row := range byHour {
+		hour, err := time.Parse(time.RFC3339, row.Hour)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse audit hour %q: %w", row.Hour, err)
+		}
+		stats.EventsByHour = append(stats.EventsByHour, model.AuditHourCount{Hour: hour, Count: row.Count})
+	}
+
+	return stats, nil
+}
+
+// ValidateFilter checks a search filter without running it.
+func (s *AuditService) ValidateFilter(filter *model.SearchAuditRequest) error {
+	_, err := s.filterQuery(filter)
+	return err
+}
+
+// Export writes every entry matching the filter to w, oldest first, as CSV
+// or JSON lines. Entries are loaded in batches so large exports stream.
+func (s *AuditService) Export(filter *model.SearchAuditRequest, format string, w io.Writer) error {
+	if format != AuditExportCSV && format != AuditExportJSONL {
+		return ErrInvalidAuditFilter
+	}
+	if err := s.ValidateFilter(filter); err != nil {
+		return err
+	}
+
+	var csvWriter *csv.Writer
+	encoder := json.NewEncoder(w)
+	if format == AuditExportCSV {
+		csvWriter = csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"id", "sequence", "timestamp", "identity_id", "action", "resource", "resource_id", "ip_address", "user_agent", "status", "details", "hash"}); err != nil {
+			return err
+		}
+	}
+
+	var last int64
+	for {
+		query, _ := s.filterQuery(filter)
+		var logs []model.AuditLog
+		if err := query.Where("sequence > ?", last).Order("sequence ASC").Limit(verifyBatchSize).Find(&logs).Error; err != nil {
+			return fmt.Errorf("failed to export audit logs: %w", err)
+		}
+
+		for i := range logs {
+			entry := &logs[i]
+			if csvWriter != nil {
+				var identityID, resourceID string
+				if entry.UserID != nil {
+					identityID = entry.UserID.String()
+				}
+				if entry.ResourceID != nil {
+					resourceID = *entry.ResourceID
+				}
+				if err := csvWriter.Write([]string{
+					entry.ID.String(),
+					strconv.FormatInt(entry.Sequence, 10),
+					entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+					identityID,
+					entry.Action,
+					entry.Resource,
+					resourceID,
+					entry.IPAddress,
+					entry.UserAgent,
+					auditStatus(entry.Success),
+					entry.Details,
+					entry.Hash,
+				}); err != nil {
+					return err
+				}
+			} else if err := encoder.Encode(ToAuditEvent(entry)); err != nil {
+				return err
+			}
+			last = entry.Sequence
+		}
+
+		if csvWriter != nil {
+			csvWriter.Flush()
+			if err := csvWriter.Error(); err != nil {
+				return err
+			}
+		}
+		if flusher, ok := w.(http.Flusher); ok {
+			flusher.Flush()
+		}
+
+		if len(logs) < verifyBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *AuditService) GetRetention() (*model.AuditRetention, error) {
+	var retention model.AuditRetention
+	if err := s.db.Where("id = ?", 1).First(&retention).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &model.AuditRetention{ID: 1, Days: s.retentionDays}, nil
+		}
+		return nil, fmt.Errorf("failed to get audit retention: %w", err)
+	}
+	return &retention, nil
+}
+
+// SetRetention stores the number of days entries are kept; zero keeps them
+// forever.
+func (s *AuditService) SetRetention(days int, userID uuid.UUID) (*model.AuditRetention, error) {
+	if days < 0 {
+		return nil, ErrInvalidRetention
+	}
+
+	retention, err := s.GetRetention()
+	if err != nil {
+		return nil, err
+	}
+	retention.Days = days
+	retention.UpdatedBy = &userID
+
+	if err := s.db.Save(retention).Error; err != nil {
+		return nil, fmt.Errorf("failed to save audit retention: %w", err)
+	}
+	return retention, nil
+}
+
+// ApplyRetention removes the entries older than the retention policy.
+func (s *AuditService) ApplyRetention() (int64, error) {
+	retention, err := s.GetRetention()
+	if err != nil {
+		return 0, err
+	}
+	if retention.Days == 0 {
+		return 0, nil
+	}
+
+	removed, err := s.CleanupOldLogs(retention.Days)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	retention.LastRunAt = &now
+	retention.LastRemoved = removed
+	if err := s.db.Save(retention).Error; err != nil {
+		return removed, fmt.Errorf("failed to save audit retention: %w", err)
+	}
+	return removed, nil
+}
+
+func (s *AuditService) StartRetentionJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			removed, err := s.ApplyRetention()
+			if err != nil {
+				log.Printf("⚠️  Audit retention failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("🧹 Archived %d audit log entries past retention", removed)
+			}
+		}
+	}()
+}
+
+// CleanupOldLogs removes entries older than the retention period. A signed
+// archive checkpoint of the last removed entry is stored first so the
+// remaining chain still verifies.
+func (s *AuditService) CleanupOldLogs(retentionDays int) (int64, error) {
 	cutoffDate := time.Now().AddDate(0, 0, -retentionDays)
 
-	if err := s.db.Where("created_at < ?", cutoffDate).Delete(&model.AuditLog{}).Error; err != nil {
-		return fmt.Errorf("failed to cleanup old audit logs: %w", err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var removed int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var last model.AuditLog
+		if err := tx.Where("created_at < ?", cutoffDate).Order("sequence DESC").First(&last).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to find old audit logs: %w", err)
+		}
+
+		if err := s.createCheckpoint(tx, last.Sequence, last.Hash, model.CheckpointKindArchive); err != nil {
+			return err
+		}
+
+		result := tx.Where("sequence <= ?", last.Sequence).Delete(&model.AuditLog{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to cleanup old audit logs: %w", result.Error)
+		}
+		removed = result.RowsAffected
+		return nil
+	})
+	return removed, err
+}
+
+// EnsureChain links entries written before the chain existed, in the order
+// they were created.
+func (s *AuditService) EnsureChain() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var unchained []model.AuditLog
+	if err := s.db.Where("hash IS NULL OR hash = ''").Order("created_at ASC, id ASC").Find(&unchained).Error; err != nil {
+		return 0, fmt.Errorf("failed to find unchained audit logs: %w", err)
+	}
+	if len(unchained) == 0 {
+		return 0, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		sequence, prevHash, err := chainHead(tx)
+		if err != nil {
+			return err
+		}
+
+		for i := range unchained {
+			entry := &unchained[i]
+			sequence++
+			entry.Sequence = sequence
+			entry.PrevHash = prevHash
+			entry.Hash = s.entryHash(entry)
+			if err := tx.Model(entry).Updates(map[string]interface{}{
+				"sequence":  entry.Sequence,
+				"prev_hash": entry.PrevHash,
+				"hash":      entry.Hash,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to chain audit log: %w", err)
+			}
+			prevHash = entry.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(unchained), nil
+}
+
+// Checkpoint signs the current chain head unless it is already covered by
+// the latest checkpoint.
+func (s *AuditService) Checkpoint() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sequence, hash, err := chainHead(s.db)
+	if err != nil {
+		return false, err
+	}
+
+	var latest model.AuditCheckpoint
+	err = s.db.Order("sequence DESC").First(&latest).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, fmt.Errorf("failed to get audit checkpoint: %w", err)
+	}
+	if sequence == 0 || (err == nil && latest.Sequence >= sequence) {
+		return false, nil
+	}
+
+	if err := s.createCheckpoint(s.db, sequence, hash, model.CheckpointKindPeriodic); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *AuditService) StartCheckpointJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := s.Checkpoint(); err != nil {
+				log.Printf("⚠️  Audit checkpoint failed: %v", err)
+			}
+		}
+	}()
+}
+
+// Verify walks the chain from its anchor, the latest archive checkpoint or
+// the first entry, and reports the first broken link.
+func (s *AuditService) Verify() (*model.AuditVerifyResponse, error) {
+	result := &model.AuditVerifyResponse{
+		Valid:     true,
+		PublicKey: base64.StdEncoding.EncodeToString(s.signingKey.Public().(ed25519.PublicKey)),
+	}
+
+	var checkpoints []model.AuditCheckpoint
+	if err := s.db.Order("sequence ASC, created_at ASC").Find(&checkpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to get audit checkpoints: %w", err)
+	}
+
+	var anchor *model.AuditCheckpoint
+	var lastCheckpoint int64
+	for i := range checkpoints {
+		checkpoint := &checkpoints[i]
+		if !s.checkpointValid(checkpoint) {
+			return chainBroken(result, checkpoint.Sequence, nil, fmt.Sprintf("checkpoint at sequence %d has an invalid signature", checkpoint.Sequence)), nil
+		}
+		if checkpoint.Kind == model.CheckpointKindArchive {
+			anchor = checkpoint
+		}
+		if checkpoint.Sequence > lastCheckpoint {
+			lastCheckpoint = checkpoint.Sequence
+		}
+	}
+	result.CheckpointsChecked = len(checkpoints)
+
+	expected, prevHash := int64(1), ""
+	if anchor != nil {
+		expected, prevHash = anchor.Sequence+1, anchor.Hash
+	}
+	checkpointHashes := make(map[int64]string)
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Sequence >= expected {
+			checkpointHashes[checkpoint.Sequence] = checkpoint.Hash
+		}
+	}
+	result.FirstSequence = expected
+
+	for {
+		var entries []model.AuditLog
+		if err := s.db.Where("sequence >= ?", expected).Order("sequence ASC").Limit(verifyBatchSize).Find(&entries).Error; err != nil {
+			return nil, fmt.Errorf("failed to get audit logs: %w", err)
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			switch {
+			case entry.Sequence != expected:
+				return chainBroken(result, expected, nil, fmt.Sprintf("entry %d is missing", expected)), nil
+			case entry.PrevHash != prevHash:
+				return chainBroken(result, entry.Sequence, &entry.ID, "previous hash does not match the preceding entry"), nil
+			case !hmac.Equal([]byte(entry.Hash), []byte(s.entryHash(entry))):
+				return chainBroken(result, entry.Sequence, &entry.ID, "entry has been modified"), nil
+			}
+			if hash, ok := checkpointHashes[entry.Sequence]; ok && hash != entry.Hash {
+				return chainBroken(result, entry.Sequence, &entry.ID, "entry does not match its signed checkpoint"), nil
+			}
+
+			result.EntriesChecked++
+			result.LastSequence = entry.Sequence
+			prevHash = entry.Hash
+			expected++
+		}
+
+		if len(entries) < verifyBatchSize {
+			break
+		}
+	}
+
+	if lastCheckpoint >= expected {
+		return chainBroken(result, expected, nil, fmt.Sprintf("entries %d to %d covered by a checkpoint are missing", expected, lastCheckpoint)), nil
+	}
+
+	return result, nil
+}
+
+func (s *AuditService) filterQuery(filter *model.SearchAuditRequest) (*gorm.DB, error) {
+	query := s.db.Model(&model.AuditLog{})
+	if filter.IdentityID != nil && *filter.IdentityID != "" {
+		identityID, err := uuid.Parse(*filter.IdentityID)
+		if err != nil {
+			return nil, ErrInvalidAuditFilter
+		}
+		query = query.Where("user_id = ?", identityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Resource != "" {
+		query = query.Where("resource = ?", filter.Resource)
+	}
+	if filter.ResourceID != nil && *filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", *filter.ResourceID)
+	}
+	if filter.IPAddress != "" {
+		query = query.Where("ip_address = ?", filter.IPAddress)
+	}
+	switch filter.Status {
+	case "":
+	case AuditStatusSuccess:
+		query = query.Where("success = ?", true)
+	case AuditStatusFailure:
+		query = query.Where("success = ?", false)
+	default:
+		return nil, ErrInvalidAuditFilter
+	}
+	if filter.StartTime != nil {
+		query = query.Where("created_at >= ?", filter.StartTime.UTC())
+	}
+	if filter.EndTime != nil {
+		query = query.Where("created_at < ?", filter.EndTime.UTC())
+	}
+	if filter.Query != "" {
+		pattern := "%" + strings.ToLower(filter.Query) + "%"
+		query = query.Where("(LOWER(action) LIKE ? OR LOWER(resource) LIKE ? OR LOWER(details) LIKE ? OR LOWER(user_agent) LIKE ? OR ip_address LIKE ?)",
+			pattern, pattern, pattern, pattern, pattern)
+	}
+	return query, nil
+}
+
+// hourBucket returns the SQL expression truncating created_at to the hour as
+// an RFC 3339 UTC string.
+func (s *AuditService) hourBucket() string {
+	if s.db.Dialector.Name() == "sqlite" {
+		return `strftime('%Y-%m-%dT%H:00:00Z', created_at)`
+	}
+	return `to_char(date_trunc('hour', created_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD"T"HH24:00:00"Z"')`
+}
+
+func (s *AuditService) append(entry *model.AuditLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	// Stored timestamps keep microseconds; hash the value that is read back.
+	entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		sequence, prevHash, err := chainHead(tx)
+		if err != nil {
+			return err
+		}
+
+		entry.Sequence = sequence + 1
+		entry.PrevHash = prevHash
+		entry.Hash = s.entryHash(entry)
+		return tx.Create(entry).Error
+	})
+}
+
+func (s *AuditService) entryHash(entry *model.AuditLog) string {
+	var userID, resourceID string
+	if entry.UserID != nil {
+		userID = entry.UserID.String()
+	}
+	if entry.ResourceID != nil {
+		resourceID = *entry.ResourceID
 	}
 
+	mac := hmac.New(sha256.New, s.chainKey)
+	for _, field := range []string{
+		strconv.FormatInt(entry.Sequence, 10),
+		entry.PrevHash,
+		entry.ID.String(),
+		userID,
+		entry.Action,
+		entry.Resource,
+		resourceID,
+		entry.IPAddress,
+		entry.UserAgent,
+		strconv.FormatBool(entry.Success),
+		entry.Details,
+		entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		var length [8]byte
+		binary.BigEndian.PutUint64(length[:], uint64(len(field)))
+		mac.Write(length[:])
+		mac.Write([]byte(field))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *AuditService) createCheckpoint(tx *gorm.DB, sequence int64, hash, kind string) error {
+	checkpoint := &model.AuditCheckpoint{
+		Sequence:  sequence,
+		Hash:      hash,
+		Kind:      kind,
+		CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+	}
+	checkpoint.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.signingKey, checkpointMessage(checkpoint)))
+
+	if err := tx.Create(checkpoint).Error; err != nil {
+		return fmt.Errorf("failed to create audit checkpoint: %w", err)
+	}
 	return nil
 }
+
+func (s *AuditService) checkpointValid(checkpoint *model.AuditCheckpoint) bool {
+	signature, err := base64.StdEncoding.DecodeString(checkpoint.Signature)
+	if err != nil {
+		return false
+	}
+	return ed25519.Verify(s.signingKey.Public().(ed25519.PublicKey), checkpointMessage(checkpoint), signature)
+}
+
+// ToAuditEvent converts an entry to its API form. Details stored as a JSON
+// object are returned as a map, anything else as the message.
+func ToAuditEvent(entry *model.AuditLog) model.AuditEvent {
+	event := model.AuditEvent{
+		ID:         entry.ID,
+		Sequence:   entry.Sequence,
+		IdentityID: entry.UserID,
+		Action:     entry.Action,
+		Resource:   entry.Resource,
+		ResourceID: entry.ResourceID,
+		IPAddress:  entry.IPAddress,
+		UserAgent:  entry.UserAgent,
+		Status:     auditStatus(entry.Success),
+		Timestamp:  entry.CreatedAt,
+		Hash:       entry.Hash,
+	}
+	if err := json.Unmarshal([]byte(entry.Details), &event.Details); err != nil {
+		event.Details = nil
+		event.Message = entry.Details
+	}
+	return event
+}
+
+func auditStatus(success bool) string {
+	if success {
+		return AuditStatusSuccess
+	}
+	return AuditStatusFailure
+}
+
+func chainBroken(result *model.AuditVerifyResponse, sequence int64, entryID *uuid.UUID, reason string) *model.AuditVerifyResponse {
+	result.Valid = false
+	result.BrokenSequence = &sequence
+	result.BrokenEntryID = entryID
+	result.Reason = reason
+	return result
+}
+
+// chainHead returns the sequence and hash of the latest entry, falling back
+// to the latest checkpoint when retention removed every entry.
+func chainHead(tx *gorm.DB) (int64, string, error) {
+	var last model.AuditLog
+	err := tx.Where("hash <> ''").Order("sequence DESC").First(&last).Error
+	if err == nil {
+		return last.Sequence, last.Hash, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", fmt.Errorf("failed to get audit chain head: %w", err)
+	}
+
+	var checkpoint model.AuditCheckpoint
+	err = tx.Order("sequence DESC").First(&checkpoint).Error
+	if err == nil {
+		return checkpoint.Sequence, checkpoint.Hash, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, "", fmt.Errorf("failed to get audit checkpoint: %w", err)
+	}
+	return 0, "", nil
+}
+
+func checkpointMessage(checkpoint *model.AuditCheckpoint) []byte {
+	return []byte(fmt.Sprintf("%d:%s:%s:%s", checkpoint.Sequence, checkpoint.Hash, checkpoint.Kind, checkpoint.CreatedAt.UTC().Format(time.RFC3339Nano)))
+}
+
+func deriveAuditKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+var (
+	ErrAuditEventNotFound = errors.New("audit event not found")
+	ErrInvalidAuditFilter = errors.New("invalid audit filter")
+	ErrInvalidRetention   = errors.New("retention days must not be negative")
+)
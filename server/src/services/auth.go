@@ -1,28 +1,49 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/skygenesisenterprise/aether-vault/server/src/config"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"log"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
+const mfaTokenPurpose = "mfa"
+
+// AuthService issues short-lived JWT access tokens with a unique token ID and
+// opaque, rotating refresh tokens, both bound to a session. Revoked access
+// tokens are kept in a server-side list until they expire.
 type AuthService struct {
-	userService *UserService
-	config      *config.JWTConfig
+	db             *gorm.DB
+	userService    *UserService
+	mfaService     *MFAService
+	sessionService *SessionService
+	config         *config.JWTConfig
 }
 
-func NewAuthService(userService *UserService, config *config.JWTConfig) *AuthService {
+func NewAuthService(db *gorm.DB, userService *UserService, mfaService *MFAService, sessionService *SessionService, config *config.JWTConfig) *AuthService {
 	return &AuthService{
-		userService: userService,
-		config:      config,
+		db:             db,
+		userService:    userService,
+		mfaService:     mfaService,
+		sessionService: sessionService,
+		config:         config,
 	}
 }
 
-func (s *AuthService) Login(email, password string) (*model.LoginResponse, error) {
+// Login checks the password and, for users with MFA enabled, the TOTP or
+// recovery code. Without a code it returns a short-lived MFA token to be
+// completed with CompleteMFALogin.
+func (s *AuthService) Login(email, password, totp string, client model.ClientInfo) (*model.LoginResponse, error) {
 	user, err := s.userService.GetUserByEmail(email)
 	if err != nil {
 		return nil, ErrInvalidCredentials
@@ -32,21 +53,400 @@ func (s *AuthService) Login(email, password string) (*model.LoginResponse, error
 		return nil, ErrInvalidCredentials
 	}
 
-	token, expiresAt, err := s.generateToken(user.ID)
+	if s.mfaService != nil {
+		enabled, err := s.mfaService.IsEnabled(user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if enabled && totp == "" {
+			mfaToken, claims, err := s.signToken(user.ID, uuid.Nil, mfaTokenPurpose, time.Duration(s.config.MFAExpiration)*time.Second)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate MFA token: %w", err)
+			}
+			return &model.LoginResponse{
+				ExpiresAt:   claims.ExpiresAt,
+				User:        *user,
+				MFARequired: true,
+				MFAToken:    mfaToken,
+			}, nil
+		}
+
+		if enabled {
+			if err := s.mfaService.Verify(user.ID, totp); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.issueLogin(user, client)
+}
+
+// CompleteMFALogin exchanges an MFA token from Login and a TOTP or recovery
+// code for an access token. The MFA token is spent on success, and on the
+// failure that locks the user's second factor or any attempt while locked.
+func (s *AuthService) CompleteMFALogin(mfaToken, code string, client model.ClientInfo) (*model.LoginResponse, error) {
+	if s.mfaService == nil {
+		return nil, ErrMFANotEnrolled
+	}
+
+	claims, err := s.validate(mfaToken, mfaTokenPurpose)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	user, err := s.userService.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := s.mfaService.Verify(user.ID, code); err != nil {
+		if errors.Is(err, ErrMFALocked) {
+			if revokeErr := s.RevokeAccessToken(claims); revokeErr != nil {
+				return nil, revokeErr
+			}
+		}
+		return nil, err
+	}
+
+	if err := s.RevokeAccessToken(claims); err != nil {
+		return nil, err
+	}
+
+	return s.issueLogin(user, client)
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already
+// rotated revokes every token descended from the same login.
+func (s *AuthService) Refresh(refreshToken string) (*model.RefreshResponse, error) {
+	var stored model.RefreshToken
+	if err := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if stored.RevokedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+	if err := s.sessionService.CheckActive(stored.FamilyID); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	result := s.db.Model(&model.RefreshToken{}).
+		Where("id = ? AND used_at IS NULL", stored.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		if err := s.sessionService.Revoke(stored.FamilyID, nil); err != nil {
+			return nil, err
+		}
+		log.Printf("⚠️  Refresh token reuse detected for user %s, session %s revoked", stored.UserID, stored.FamilyID)
+		return nil, ErrRefreshTokenReused
+	}
+
+	user, err := s.userService.GetUserByID(stored.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	token, claims, err := s.signToken(user.ID, stored.FamilyID, "", time.Duration(s.config.Expiration)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	newRefreshToken, refreshExpiresAt, err := s.createRefreshToken(user.ID, stored.FamilyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.Refresh(stored.FamilyID, claims.TokenID, refreshExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &model.RefreshResponse{
+		Token:            token,
+		ExpiresAt:        claims.ExpiresAt,
+		RefreshToken:     newRefreshToken,
+		RefreshExpiresAt: refreshExpiresAt,
+	}, nil
+}
+
+// ValidateToken validates an access token, including the revocation list,
+// its session and the user's revoke-all cutoff.
+func (s *AuthService) ValidateToken(tokenString string) (*model.TokenClaims, error) {
+	return s.validate(tokenString, "")
+}
+
+// Introspect reports whether an access token is currently valid.
+func (s *AuthService) Introspect(tokenString string) *model.VerifyResponse {
+	claims, err := s.ValidateToken(tokenString)
+	if err != nil {
+		return &model.VerifyResponse{Valid: false}
+	}
+
+	response := &model.VerifyResponse{
+		Valid:     true,
+		UserID:    claims.UserID.String(),
+		TokenID:   claims.TokenID,
+		IssuedAt:  &claims.IssuedAt,
+		ExpiresAt: &claims.ExpiresAt,
+	}
+	if user, err := s.userService.GetUserByID(claims.UserID); err == nil {
+		response.Username = user.Email
+	}
+
+	return response
+}
+
+// RevokeToken revokes an access or refresh token held by userID.
+func (s *AuthService) RevokeToken(tokenString string, userID uuid.UUID) error {
+	if claims, err := s.parseToken(tokenString, ""); err == nil {
+		if claims.UserID != userID {
+			return ErrTokenNotOwned
+		}
+		return s.RevokeAccessToken(claims)
+	}
+
+	return s.RevokeRefreshToken(tokenString, userID)
+}
+
+func (s *AuthService) RevokeAccessToken(claims *model.TokenClaims) error {
+	revoked := &model.RevokedToken{
+		TokenID:   claims.TokenID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt,
+		RevokedAt: time.Now(),
+	}
+	if err := s.db.Save(revoked).Error; err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	return nil
+}
+
+// RevokeRefreshToken ends the session a refresh token belongs to.
+func (s *AuthService) RevokeRefreshToken(refreshToken string, userID uuid.UUID) error {
+	var stored model.RefreshToken
+	if err := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&stored).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidToken
+		}
+		return fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if stored.UserID != userID {
+		return ErrTokenNotOwned
+	}
+
+	return s.sessionService.Revoke(stored.FamilyID, &userID)
+}
+
+// RevokeAllTokens invalidates every access and refresh token issued to the
+// user so far and ends all their sessions.
+func (s *AuthService) RevokeAllTokens(userID uuid.UUID) error {
+	now := time.Now()
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&model.User{}).Where("id = ?", userID).Update("tokens_revoked_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to revoke tokens: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", now).Error; err != nil {
+			return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+		}
+		return revokeUserSessions(tx, userID)
+	})
+}
+
+// PurgeExpiredTokens removes revocation entries and refresh tokens that can
+// no longer be used.
+func (s *AuthService) PurgeExpiredTokens() error {
+	now := time.Now()
+	if err := s.db.Where("expires_at < ?", now).Delete(&model.RevokedToken{}).Error; err != nil {
+		return fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+	if err := s.db.Where("expires_at < ?", now).Delete(&model.RefreshToken{}).Error; err != nil {
+		return fmt.Errorf("failed to purge refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func (s *AuthService) StartCleanupJob(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if err := s.PurgeExpiredTokens(); err != nil {
+				log.Printf("⚠️  Token cleanup failed: %v", err)
+			}
+		}
+	}()
+}
+
+// RenewToken replaces an access token with a new one for the same session
+// and revokes the old one. The new token lives for increment, or the
+// configured lifetime when increment is zero, but never past the session.
+func (s *AuthService) RenewToken(claims *model.TokenClaims, increment time.Duration) (string, *model.TokenClaims, error) {
+	if claims.SessionID == uuid.Nil {
+		return "", nil, ErrTokenNotRenewable
+	}
+	session, err := s.sessionService.GetActive(claims.SessionID)
+	if err != nil {
+		return "", nil, ErrTokenNotRenewable
+	}
+
+	lifetime := time.Duration(s.config.Expiration) * time.Second
+	if increment > 0 && increment < lifetime {
+		lifetime = increment
+	}
+	if remaining := time.Until(session.ExpiresAt); remaining < lifetime {
+		lifetime = remaining
+	}
+
+	token, renewed, err := s.signToken(claims.UserID, claims.SessionID, "", lifetime)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	if err := s.sessionService.Refresh(session.ID, renewed.TokenID, session.ExpiresAt); err != nil {
+		return "", nil, err
+	}
+	if err := s.RevokeAccessToken(claims); err != nil {
+		return "", nil, err
+	}
+
+	return token, renewed, nil
+}
+
+// EndSession revokes an access token and ends the session it was issued for.
+func (s *AuthService) EndSession(claims *model.TokenClaims) error {
+	if err := s.RevokeAccessToken(claims); err != nil {
+		return err
+	}
+	if claims.SessionID == uuid.Nil {
+		return nil
+	}
+	if err := s.sessionService.Revoke(claims.SessionID, &claims.UserID); err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (s *AuthService) GetSession(userID uuid.UUID) (*model.SessionResponse, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return &model.SessionResponse{
+			Valid: false,
+		}, nil
+	}
+
+	return &model.SessionResponse{
+		User:  *user,
+		Valid: true,
+	}, nil
+}
+
+func (s *AuthService) issueLogin(user *model.User, client model.ClientInfo) (*model.LoginResponse, error) {
+	sessionID := uuid.New()
+	token, claims, err := s.signToken(user.ID, sessionID, "", time.Duration(s.config.Expiration)*time.Second)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	refreshToken, refreshExpiresAt, err := s.createRefreshToken(user.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionService.Create(&model.Session{
+		ID:        sessionID,
+		UserID:    user.ID,
+		TokenID:   claims.TokenID,
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		ExpiresAt: refreshExpiresAt,
+	}); err != nil {
+		return nil, err
+	}
+
 	response := &model.LoginResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      *user,
+		Token:            token,
+		ExpiresAt:        claims.ExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: &refreshExpiresAt,
+		User:             *user,
 	}
 
 	return response, nil
 }
 
-func (s *AuthService) ValidateToken(tokenString string) (*uuid.UUID, error) {
+func (s *AuthService) createRefreshToken(userID, familyID uuid.UUID) (string, time.Time, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	stored := &model.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(time.Duration(s.config.RefreshExpiration) * time.Second),
+	}
+	if err := s.db.Create(stored).Error; err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return token, stored.ExpiresAt, nil
+}
+
+func (s *AuthService) validate(tokenString, purpose string) (*model.TokenClaims, error) {
+	claims, err := s.parseToken(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.db == nil {
+		return claims, nil
+	}
+
+	var count int64
+	if err := s.db.Model(&model.RevokedToken{}).Where("token_id = ?", claims.TokenID).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrTokenRevoked
+	}
+
+	if purpose == "" {
+		if err := s.sessionService.CheckActive(claims.SessionID); err != nil {
+			if errors.Is(err, ErrSessionNotFound) {
+				return nil, ErrTokenRevoked
+			}
+			return nil, err
+		}
+	}
+
+	user, err := s.userService.GetUserByID(claims.UserID)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if user.TokensRevokedAt != nil && !claims.IssuedAt.After(user.TokensRevokedAt.Truncate(time.Second)) {
+		return nil, ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+func (s *AuthService) parseToken(tokenString, purpose string) (*model.TokenClaims, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
@@ -59,6 +459,10 @@ func (s *AuthService) ValidateToken(tokenString string) (*uuid.UUID, error) {
 	}
 
 	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
+		if tokenPurpose, _ := claims["purpose"].(string); tokenPurpose != purpose {
+			return nil, fmt.Errorf("invalid token purpose")
+		}
+
 		userIDStr, ok := claims["user_id"].(string)
 		if !ok {
 			return nil, fmt.Errorf("invalid user ID in token")
@@ -69,40 +473,80 @@ func (s *AuthService) ValidateToken(tokenString string) (*uuid.UUID, error) {
 			return nil, fmt.Errorf("invalid user ID format: %w", err)
 		}
 
-		return &userID, nil
-	}
+		tokenID, _ := claims["jti"].(string)
+		if tokenID == "" {
+			return nil, fmt.Errorf("missing token ID")
+		}
 
-	return nil, fmt.Errorf("invalid token")
-}
+		var sessionID uuid.UUID
+		if sid, ok := claims["sid"].(string); ok {
+			if sessionID, err = uuid.Parse(sid); err != nil {
+				return nil, fmt.Errorf("invalid session ID format: %w", err)
+			}
+		}
 
-func (s *AuthService) GetSession(userID uuid.UUID) (*model.SessionResponse, error) {
-	user, err := s.userService.GetUserByID(userID)
-	if err != nil {
-		return &model.SessionResponse{
-			Valid: false,
+		issuedAt, err := claims.GetIssuedAt()
+		if err != nil || issuedAt == nil {
+			return nil, fmt.Errorf("invalid issued at claim")
+		}
+		expiresAt, err := claims.GetExpirationTime()
+		if err != nil || expiresAt == nil {
+			return nil, fmt.Errorf("invalid expiration claim")
+		}
+
+		return &model.TokenClaims{
+			UserID:    userID,
+			TokenID:   tokenID,
+			SessionID: sessionID,
+			IssuedAt:  issuedAt.Time,
+			ExpiresAt: expiresAt.Time,
 		}, nil
 	}
 
-	return &model.SessionResponse{
-		User:  *user,
-		Valid: true,
-	}, nil
+	return nil, fmt.Errorf("invalid token")
 }
 
-func (s *AuthService) generateToken(userID uuid.UUID) (string, time.Time, error) {
-	expiresAt := time.Now().Add(time.Duration(s.config.Expiration) * time.Second)
+func (s *AuthService) signToken(userID, sessionID uuid.UUID, purpose string, lifetime time.Duration) (string, *model.TokenClaims, error) {
+	now := time.Now()
+	tokenClaims := &model.TokenClaims{
+		UserID:    userID,
+		TokenID:   uuid.New().String(),
+		SessionID: sessionID,
+		IssuedAt:  now.Truncate(time.Second),
+		ExpiresAt: now.Add(lifetime).Truncate(time.Second),
+	}
 
 	claims := jwt.MapClaims{
 		"user_id": userID.String(),
-		"exp":     expiresAt.Unix(),
-		"iat":     time.Now().Unix(),
+		"jti":     tokenClaims.TokenID,
+		"exp":     tokenClaims.ExpiresAt.Unix(),
+		"iat":     tokenClaims.IssuedAt.Unix(),
+	}
+	if sessionID != uuid.Nil {
+		claims["sid"] = sessionID.String()
+	}
+	if purpose != "" {
+		claims["purpose"] = purpose
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenString, err := token.SignedString([]byte(s.config.Secret))
 	if err != nil {
-		return "", time.Time{}, err
+		return "", nil, err
 	}
 
-	return tokenString, expiresAt, nil
+	return tokenString, tokenClaims, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
+
+var (
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrTokenRevoked       = errors.New("token has been revoked")
+	ErrTokenNotOwned      = errors.New("token belongs to another user")
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	ErrTokenNotRenewable  = errors.New("token is not renewable")
+)
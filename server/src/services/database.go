@@ -0,0 +1,772 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	LeaseKindDatabase = "database"
+
+	defaultDatabaseRoleTTL = time.Hour
+
+	databasePasswordLength = 24
+	databaseRandomAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	databasePasswordExtra  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// databaseNameSanitizer keeps generated usernames valid unquoted identifiers
+// on every supported database.
+var databaseNameSanitizer = regexp.MustCompile(`[^a-z0-9_]`)
+
+// defaultRevocationStatements drop a generated user when its role defines no
+// revocation statements. SQLite has no users, so its roles must define them.
+var defaultRevocationStatements = map[model.DatabaseType]string{
+	model.DatabaseTypePostgreSQL: `REVOKE ALL PRIVILEGES ON ALL TABLES IN SCHEMA public FROM "{{name}}"; DROP ROLE IF EXISTS "{{name}}";`,
+	model.DatabaseTypeMySQL:      `DROP USER IF EXISTS '{{name}}'@'%';`,
+	model.DatabaseTypeMariaDB:    `DROP USER IF EXISTS '{{name}}'@'%';`,
+}
+
+// databaseConnectionSecrets is sealed in DatabaseConnection.Credentials.
+type databaseConnectionSecrets struct {
+	Password string `json:"password,omitempty"`
+	URL      string `json:"url,omitempty"`
+}
+
+// databaseLeaseData is stored on each credential lease. The statements are
+// copied from the role so revocation keeps working after the role changes
+// or is deleted.
+type databaseLeaseData struct {
+	Connection           string `json:"connection"`
+	Role                 string `json:"role"`
+	Username             string `json:"username"`
+	RevocationStatements string `json:"revocation_statements"`
+	RenewStatements      string `json:"renew_statements,omitempty"`
+}
+
+// DatabaseService is the database secrets engine. It keeps privileged
+// connections, defines roles on them and issues short-lived users whose
+// leases drop them again on expiry or revocation.
+type DatabaseService struct {
+	db        *gorm.DB
+	keyring   *KeyringService
+	leases    *LeaseService
+	sqliteDir string
+
+	mutex sync.Mutex
+	conns map[string]*gorm.DB
+}
+
+// NewDatabaseService creates the engine. SQLite connections are only
+// accepted for files inside sqliteDir, and refused when it is empty.
+func NewDatabaseService(db *gorm.DB, keyring *KeyringService, leases *LeaseService, sqliteDir string) *DatabaseService {
+	s := &DatabaseService{
+		db:        db,
+		keyring:   keyring,
+		leases:    leases,
+		sqliteDir: sqliteDir,
+		conns:     make(map[string]*gorm.DB),
+	}
+	leases.RegisterRevoker(LeaseKindDatabase, s)
+	return s
+}
+
+// WriteConnection creates or replaces a connection. Unless verify is false
+// the connection is opened first, so a bad configuration is never stored.
+func (s *DatabaseService) WriteConnection(req *model.DatabaseConnectionRequest, createdBy uuid.UUID) (*model.DatabaseConnection, error) {
+	if !model.IsValidDatabaseType(req.Type) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatabaseType, req.Type)
+	}
+	if req.URL == "" && req.Type == model.DatabaseTypeSQLite && req.Database == "" {
+		return nil, fmt.Errorf("%w: sqlite needs a database file path", ErrInvalidDatabaseConnection)
+	}
+	if req.URL == "" && req.Type != model.DatabaseTypeSQLite && req.Host == "" {
+		return nil, fmt.Errorf("%w: host is required", ErrInvalidDatabaseConnection)
+	}
+
+	conn := &model.DatabaseConnection{
+		Name:     req.Name,
+		Type:     req.Type,
+		Host:     req.Host,
+		Port:     req.Port,
+		Username: req.Username,
+		Database: req.Database,
+	}
+	if conn.Port == 0 {
+		conn.Port = defaultDatabasePort(conn.Type)
+	}
+	secrets := databaseConnectionSecrets{Password: req.Password, URL: req.URL}
+
+	if conn.Type == model.DatabaseTypeSQLite {
+		if _, err := sqliteTargetPath(s.sqliteDir, conn, &secrets); err != nil {
+			return nil, err
+		}
+	}
+	if req.VerifyConnection == nil || *req.VerifyConnection {
+		target, err := openDatabaseTarget(conn, &secrets, s.sqliteDir)
+		if err != nil {
+			return nil, err
+		}
+		closeDatabaseTarget(target)
+	}
+
+	if err := s.sealConnection(conn, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to seal connection credentials: %w", err)
+	}
+
+	var existing model.DatabaseConnection
+	err := s.db.Where("name = ?", req.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		conn.ID = existing.ID
+		conn.CreatedBy = existing.CreatedBy
+		conn.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(conn).Error; err != nil {
+			return nil, fmt.Errorf("failed to update connection: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		conn.CreatedBy = &createdBy
+		if err := s.db.Create(conn).Error; err != nil {
+			return nil, fmt.Errorf("failed to create connection: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	s.dropCachedConnection(conn.Name)
+	return conn, nil
+}
+
+func (s *DatabaseService) ListConnections() ([]model.DatabaseConnection, error) {
+	var conns []model.DatabaseConnection
+	if err := s.db.Order("name ASC").Find(&conns).Error; err != nil {
+		return nil, fmt.Errorf("failed to list connections: %w", err)
+	}
+	return conns, nil
+}
+
+func (s *DatabaseService) GetConnection(name string) (*model.DatabaseConnection, error) {
+	var conn model.DatabaseConnection
+	if err := s.db.Where("name = ?", name).First(&conn).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDatabaseConnectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	return &conn, nil
+}
+
+// DeleteConnection removes a connection no role uses any more, revoking the
+// credentials still leased on it first.
+func (s *DatabaseService) DeleteConnection(name string) error {
+	if _, err := s.GetConnection(name); err != nil {
+		return err
+	}
+
+	var roles int64
+	if err := s.db.Model(&model.DatabaseRole{}).Where("connection = ?", name).Count(&roles).Error; err != nil {
+		return fmt.Errorf("failed to check roles: %w", err)
+	}
+	if roles > 0 {
+		return ErrDatabaseConnectionInUse
+	}
+
+	if _, err := s.leases.RevokeResource(LeaseKindDatabase, name); err != nil {
+		return fmt.Errorf("failed to revoke credentials of connection: %w", err)
+	}
+
+	if err := s.db.Where("name = ?", name).Delete(&model.DatabaseConnection{}).Error; err != nil {
+		return fmt.Errorf("failed to delete connection: %w", err)
+	}
+	s.dropCachedConnection(name)
+	return nil
+}
+
+// WriteRole creates or replaces a role. A zero default TTL means one hour
+// and a zero max TTL leaves the system lease maximum.
+func (s *DatabaseService) WriteRole(req *model.DatabaseRoleRequest) (*model.DatabaseRole, error) {
+	conn, err := s.GetConnection(req.Connection)
+	if err != nil {
+		return nil, err
+	}
+	if req.DefaultTTL < 0 || req.MaxTTL < 0 || (req.MaxTTL > 0 && req.DefaultTTL > req.MaxTTL) {
+		return nil, ErrInvalidDatabaseRoleTTL
+	}
+	if len(splitStatements(req.CreationStatements, false)) == 0 {
+		return nil, fmt.Errorf("%w: creation statements are required", ErrInvalidDatabaseRole)
+	}
+	if len(splitStatements(req.RevocationStatements, false)) == 0 && defaultRevocationStatements[conn.Type] == "" {
+		return nil, fmt.Errorf("%w: %s roles need revocation statements", ErrInvalidDatabaseRole, conn.Type)
+	}
+
+	role := &model.DatabaseRole{
+		Name:                 req.Name,
+		Connection:           req.Connection,
+		CreationStatements:   req.CreationStatements,
+		RevocationStatements: req.RevocationStatements,
+		RenewStatements:      req.RenewStatements,
+		DefaultTTL:           req.DefaultTTL,
+		MaxTTL:               req.MaxTTL,
+	}
+	if role.DefaultTTL == 0 {
+		role.DefaultTTL = int(defaultDatabaseRoleTTL / time.Second)
+		if role.MaxTTL > 0 && role.DefaultTTL > role.MaxTTL {
+			role.DefaultTTL = role.MaxTTL
+		}
+	}
+
+	var existing model.DatabaseRole
+	err = s.db.Where("name = ?", req.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		role.ID = existing.ID
+		role.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to update role: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.db.Create(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to create role: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+func (s *DatabaseService) ListRoles() ([]model.DatabaseRole, error) {
+	var roles []model.DatabaseRole
+	if err := s.db.Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *DatabaseService) GetRole(name string) (*model.DatabaseRole, error) {
+	var role model.DatabaseRole
+	if err := s.db.Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDatabaseRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &role, nil
+}
+
+// DeleteRole stops a role from issuing credentials; credentials already
+// issued keep their leases.
+func (s *DatabaseService) DeleteRole(name string) error {
+	result := s.db.Where("name = ?", name).Delete(&model.DatabaseRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrDatabaseRoleNotFound
+	}
+	return nil
+}
+
+// GenerateCredentials creates a user from a role's creation statements. The
+// lease is issued first so a user created just before a crash is still
+// dropped when the lease expires. When a creation statement fails the lease
+// is revoked at once, since statements that commit implicitly, like CREATE
+// USER on MySQL, survive the rollback.
+func (s *DatabaseService) GenerateCredentials(roleName string, userID uuid.UUID) (*model.DatabaseCredentials, *model.Lease, error) {
+	role, err := s.GetRole(roleName)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn, err := s.GetConnection(role.Connection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	creds := &model.DatabaseCredentials{}
+	if creds.Username, err = generateDatabaseUsername(role.Name); err != nil {
+		return nil, nil, err
+	}
+	if creds.Password, err = generateDatabasePassword(); err != nil {
+		return nil, nil, err
+	}
+
+	revocation := role.RevocationStatements
+	if len(splitStatements(revocation, false)) == 0 {
+		revocation = defaultRevocationStatements[conn.Type]
+	}
+	data, err := json.Marshal(databaseLeaseData{
+		Connection:           conn.Name,
+		Role:                 role.Name,
+		Username:             creds.Username,
+		RevocationStatements: revocation,
+		RenewStatements:      role.RenewStatements,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode lease data: %w", err)
+	}
+
+	lease, err := s.leases.Issue(LeaseKindDatabase, "database/creds/"+role.Name, conn.Name, &userID,
+		time.Duration(role.DefaultTTL)*time.Second, time.Duration(role.MaxTTL)*time.Second, true, string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	target, err := s.connection(conn.Name)
+	if err != nil {
+		if releaseErr := s.leases.Release(lease.ID); releaseErr != nil {
+			return nil, nil, fmt.Errorf("failed to create database user: %w (and failed to release lease: %v)", err, releaseErr)
+		}
+		return nil, nil, fmt.Errorf("failed to create database user: %w", err)
+	}
+	if err := executeStatements(target, role.CreationStatements, creds.Username, creds.Password, lease.ExpireTime); err != nil {
+		if revokeErr := s.leases.Revoke(lease.ID, nil); revokeErr != nil {
+			return nil, nil, fmt.Errorf("failed to create database user: %w (cleanup is retried when the lease expires: %v)", err, revokeErr)
+		}
+		return nil, nil, fmt.Errorf("failed to create database user: %w", err)
+	}
+
+	return creds, lease, nil
+}
+
+// RevokeLease drops the user behind a lease; the revocation statements must
+// succeed when the user is already gone.
+func (s *DatabaseService) RevokeLease(lease *model.Lease) error {
+	var data databaseLeaseData
+	if err := json.Unmarshal([]byte(lease.Data), &data); err != nil {
+		return fmt.Errorf("failed to decode lease data: %w", err)
+	}
+
+	target, err := s.connection(data.Connection)
+	if err != nil {
+		return err
+	}
+	return executeStatements(target, data.RevocationStatements, data.Username, "", lease.ExpireTime)
+}
+
+// RenewLease runs the role's renew statements with the new expiry, so
+// credentials that expire on the database side stay valid with the lease.
+func (s *DatabaseService) RenewLease(lease *model.Lease) error {
+	var data databaseLeaseData
+	if err := json.Unmarshal([]byte(lease.Data), &data); err != nil {
+		return fmt.Errorf("failed to decode lease data: %w", err)
+	}
+	if len(splitStatements(data.RenewStatements, false)) == 0 {
+		return nil
+	}
+
+	target, err := s.connection(data.Connection)
+	if err != nil {
+		return err
+	}
+	return executeStatements(target, data.RenewStatements, data.Username, "", lease.ExpireTime)
+}
+
+func (s *DatabaseService) Name() string {
+	return "database"
+}
+
+func (s *DatabaseService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.DatabaseConnection{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count database connections pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *DatabaseService) RewrapKeys(batchSize int) (int, error) {
+	var conns []model.DatabaseConnection
+	if err := s.db.Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&conns).Error; err != nil {
+		return 0, fmt.Errorf("failed to find database connections pending rewrap: %w", err)
+	}
+
+	for i := range conns {
+		conn := &conns[i]
+		dataKey, err := s.keyring.UnwrapDataKey(conn.DataKey, conn.KeyVersion)
+		if err != nil {
+			return i, fmt.Errorf("failed to rewrap database connection %s: %w", conn.Name, err)
+		}
+		if conn.DataKey, conn.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+			return i, fmt.Errorf("failed to rewrap database connection %s: %w", conn.Name, err)
+		}
+
+		if err := s.db.Model(conn).UpdateColumns(map[string]interface{}{
+			"data_key":    conn.DataKey,
+			"key_version": conn.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update database connection %s: %w", conn.Name, err)
+		}
+	}
+
+	return len(conns), nil
+}
+
+// connection returns the open connection with the given name, opening it on
+// first use.
+func (s *DatabaseService) connection(name string) (*gorm.DB, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if target, ok := s.conns[name]; ok {
+		return target, nil
+	}
+
+	conn, err := s.GetConnection(name)
+	if err != nil {
+		return nil, err
+	}
+	secrets, err := s.openConnection(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection credentials: %w", err)
+	}
+	target, err := openDatabaseTarget(conn, secrets, s.sqliteDir)
+	if err != nil {
+		return nil, err
+	}
+
+	s.conns[name] = target
+	return target, nil
+}
+
+func (s *DatabaseService) dropCachedConnection(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if target, ok := s.conns[name]; ok {
+		closeDatabaseTarget(target)
+		delete(s.conns, name)
+	}
+}
+
+func (s *DatabaseService) sealConnection(conn *model.DatabaseConnection, secrets *databaseConnectionSecrets) error {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sealGCM(dataKey, plaintext)
+	if err != nil {
+		return err
+	}
+
+	conn.Credentials, conn.DataKey, conn.KeyVersion = sealed, wrapped, version
+	return nil
+}
+
+func (s *DatabaseService) openConnection(conn *model.DatabaseConnection) (*databaseConnectionSecrets, error) {
+	dataKey, err := s.keyring.UnwrapDataKey(conn.DataKey, conn.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := openGCM(dataKey, conn.Credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets databaseConnectionSecrets
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, err
+	}
+	return &secrets, nil
+}
+
+// openDatabaseTarget connects to the database a connection points at. The
+// logger is silenced so statements carrying generated passwords are never
+// written to the server log.
+func openDatabaseTarget(conn *model.DatabaseConnection, secrets *databaseConnectionSecrets, sqliteDir string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch conn.Type {
+	case model.DatabaseTypePostgreSQL:
+		dsn := secrets.URL
+		if dsn == "" {
+			u := url.URL{
+				Scheme:   "postgres",
+				User:     url.UserPassword(conn.Username, secrets.Password),
+				Host:     net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port)),
+				Path:     "/" + conn.Database,
+				RawQuery: "sslmode=prefer",
+			}
+			dsn = u.String()
+		}
+		dialector = postgres.Open(dsn)
+	case model.DatabaseTypeMySQL, model.DatabaseTypeMariaDB:
+		dsn := secrets.URL
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s)/%s", conn.Username, secrets.Password,
+				net.JoinHostPort(conn.Host, strconv.Itoa(conn.Port)), conn.Database)
+		}
+		dialector = mysql.Open(dsn)
+	case model.DatabaseTypeSQLite:
+		path, err := sqliteTargetPath(sqliteDir, conn, secrets)
+		if err != nil {
+			return nil, err
+		}
+		dialector = sqlite.Open(path)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDatabaseType, conn.Type)
+	}
+
+	target, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseConnectionFailed, err)
+	}
+
+	sqlDB, err := target.DB()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseConnectionFailed, err)
+	}
+	if err := sqlDB.Ping(); err != nil {
+		sqlDB.Close()
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseConnectionFailed, err)
+	}
+	sqlDB.SetMaxOpenConns(4)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+
+	return target, nil
+}
+
+func closeDatabaseTarget(target *gorm.DB) {
+	if sqlDB, err := target.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// sqliteTargetPath resolves the file of a SQLite connection. SQLite targets
+// are files on the vault host itself, so they must live inside dir: paths
+// leaving it, through ".." or a symlink, and driver options are refused.
+func sqliteTargetPath(dir string, conn *model.DatabaseConnection, secrets *databaseConnectionSecrets) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("%w: sqlite connections are disabled", ErrUnsupportedDatabaseType)
+	}
+
+	path := conn.Database
+	if secrets.URL != "" {
+		u, err := url.Parse(secrets.URL)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidDatabaseConnection, err)
+		}
+		if u.RawQuery != "" || u.Fragment != "" {
+			return "", fmt.Errorf("%w: sqlite URLs take no options", ErrInvalidDatabaseConnection)
+		}
+		path = u.Host + u.Path
+	}
+	if path == "" || strings.ContainsAny(path, "?#") || strings.HasPrefix(path, "file:") || strings.HasPrefix(path, ":memory:") {
+		return "", fmt.Errorf("%w: invalid sqlite path", ErrInvalidDatabaseConnection)
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDatabaseConnection, err)
+	}
+	if root, err = filepath.EvalSymlinks(root); err != nil {
+		return "", fmt.Errorf("%w: sqlite directory: %v", ErrInvalidDatabaseConnection, err)
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(filepath.Clean(path)))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidDatabaseConnection, err)
+	}
+	path = filepath.Join(parent, filepath.Base(path))
+
+	if rel, err := filepath.Rel(root, path); err != nil || rel == "." || rel == ".." ||
+		strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: sqlite path must be inside the configured directory", ErrInvalidDatabaseConnection)
+	}
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		return "", fmt.Errorf("%w: sqlite path must not be a symlink", ErrInvalidDatabaseConnection)
+	}
+	return path, nil
+}
+
+// executeStatements renders the statement templates and runs them in one
+// transaction. Database errors may quote the failing statement, so the
+// password is masked in the returned error.
+func executeStatements(target *gorm.DB, statements, username, password string, expiration time.Time) error {
+	replacer := strings.NewReplacer(
+		"{{name}}", username,
+		"{{username}}", username,
+		"{{password}}", password,
+		"{{expiration}}", expiration.UTC().Format("2006-01-02 15:04:05-0700"),
+	)
+
+	return target.Transaction(func(tx *gorm.DB) error {
+		for i, statement := range splitStatements(statements, target.Dialector.Name() == "mysql") {
+			if err := tx.Exec(replacer.Replace(statement)).Error; err != nil {
+				message := err.Error()
+				if password != "" {
+					message = strings.ReplaceAll(message, password, "[redacted]")
+				}
+				return fmt.Errorf("statement %d failed: %s", i+1, message)
+			}
+		}
+		return nil
+	})
+}
+
+// splitStatements cuts a template at the semicolons ending its statements.
+// Semicolons inside quoted strings and identifiers, comments and
+// dollar-quoted bodies such as DO $$ ... $$ blocks do not end a statement.
+// backslashEscapes follows MySQL, where \' does not close a string.
+func splitStatements(statements string, backslashEscapes bool) []string {
+	var result []string
+	start := 0
+	for i := 0; i < len(statements); i++ {
+		switch c := statements[i]; {
+		case c == ';':
+			if statement := strings.TrimSpace(statements[start:i]); statement != "" {
+				result = append(result, statement)
+			}
+			start = i + 1
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(statements, i, backslashEscapes && c != '`')
+		case strings.HasPrefix(statements[i:], "--"):
+			if end := strings.IndexByte(statements[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(statements)
+			}
+		case strings.HasPrefix(statements[i:], "/*"):
+			if end := strings.Index(statements[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				i = len(statements)
+			}
+		case c == '$':
+			if tag := dollarQuoteTag(statements[i:]); tag != "" {
+				if end := strings.Index(statements[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(statements)
+				}
+			}
+		}
+	}
+	if statement := strings.TrimSpace(statements[start:]); statement != "" {
+		result = append(result, statement)
+	}
+	return result
+}
+
+// skipQuoted returns the index of the quote closing the string opened at
+// start; a doubled quote is an escaped one. Unterminated strings run to the
+// end of the template.
+func skipQuoted(statements string, start int, backslashEscapes bool) int {
+	quote := statements[start]
+	for i := start + 1; i < len(statements); i++ {
+		switch {
+		case backslashEscapes && statements[i] == '\\':
+			i++
+		case statements[i] == quote:
+			if i+1 < len(statements) && statements[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(statements)
+}
+
+// dollarQuoteTag returns the $tag$ opening a PostgreSQL dollar-quoted string
+// at the start of s, or "" when s starts with a parameter such as $1.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 1:
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// generateDatabaseUsername returns v_<role>_<random>, short enough for
+// MySQL's 32 character limit.
+func generateDatabaseUsername(role string) (string, error) {
+	role = databaseNameSanitizer.ReplaceAllString(strings.ToLower(role), "_")
+	if len(role) > 10 {
+		role = role[:10]
+	}
+
+	suffix, err := randomDatabaseString(databaseRandomAlphabet, 12)
+	if err != nil {
+		return "", err
+	}
+	return "v_" + role + "_" + suffix, nil
+}
+
+// generateDatabasePassword returns a password with no characters that need
+// quoting inside SQL string literals.
+func generateDatabasePassword() (string, error) {
+	password, err := randomDatabaseString(databaseRandomAlphabet+databasePasswordExtra, databasePasswordLength)
+	if err != nil {
+		return "", err
+	}
+	// Prefix one character of each class for password policies requiring them
+	return "A1a-" + password, nil
+}
+
+func randomDatabaseString(alphabet string, length int) (string, error) {
+	max := big.NewInt(int64(len(alphabet)))
+	result := make([]byte, length)
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random string: %w", err)
+		}
+		result[i] = alphabet[n.Int64()]
+	}
+	return string(result), nil
+}
+
+func defaultDatabasePort(t model.DatabaseType) int {
+	switch t {
+	case model.DatabaseTypePostgreSQL:
+		return 5432
+	case model.DatabaseTypeMySQL, model.DatabaseTypeMariaDB:
+		return 3306
+	default:
+		return 0
+	}
+}
+
+var (
+	ErrDatabaseConnectionNotFound = errors.New("database connection not found")
+	ErrDatabaseConnectionInUse    = errors.New("database connection is still used by roles")
+	ErrDatabaseConnectionFailed   = errors.New("failed to connect to database")
+	ErrInvalidDatabaseConnection  = errors.New("invalid database connection")
+	ErrUnsupportedDatabaseType    = errors.New("unsupported database type")
+	ErrDatabaseRoleNotFound       = errors.New("database role not found")
+	ErrInvalidDatabaseRole        = errors.New("invalid database role")
+	ErrInvalidDatabaseRoleTTL     = errors.New("default TTL must not be negative or exceed the max TTL")
+)
@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/google/uuid"
+	"github.com/skygenesisenterprise/aether-vault/server/src/config"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/src/storage"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestVaultDB returns a migrated SQLite vault database, removed with the
+// test.
+func newTestVaultDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := storage.Open(config.DatabaseConfig{Driver: storage.DriverSQLite, Path: filepath.Join(t.TempDir(), "vault.db")})
+	if err != nil {
+		t.Fatalf("open vault database: %v", err)
+	}
+	if err := storage.Migrate(db); err != nil {
+		t.Fatalf("migrate vault database: %v", err)
+	}
+	t.Cleanup(func() { closeDatabaseTarget(db) })
+	return db
+}
+
+// newTestKeyring returns a keyring on db unlocked with a random root key.
+func newTestKeyring(t *testing.T, db *gorm.DB) *KeyringService {
+	t.Helper()
+
+	rootKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(rootKey); err != nil {
+		t.Fatal(err)
+	}
+	keyring := NewKeyringService(db, 1000)
+	if err := keyring.Unlock(rootKey); err != nil {
+		t.Fatalf("unlock keyring: %v", err)
+	}
+	return keyring
+}
+
+// newTestDatabaseService returns a database engine on a migrated SQLite
+// vault with an unlocked keyring, and the directory its SQLite targets live
+// in.
+func newTestDatabaseService(t *testing.T) (*DatabaseService, *LeaseService, string) {
+	t.Helper()
+
+	db := newTestVaultDB(t)
+	dir := t.TempDir()
+	leases := NewLeaseService(db, time.Hour, nil)
+	return NewDatabaseService(db, newTestKeyring(t, db), leases, dir), leases, dir
+}
+
+func openTestTarget(t *testing.T, path string) *gorm.DB {
+	t.Helper()
+
+	target, err := gorm.Open(sqlite.Open(path), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("open target database: %v", err)
+	}
+	t.Cleanup(func() { closeDatabaseTarget(target) })
+	return target
+}
+
+func countTestUsers(t *testing.T, target *gorm.DB, name string) int64 {
+	t.Helper()
+
+	var count int64
+	if err := target.Table("app_users").Where("name = ?", name).Count(&count).Error; err != nil {
+		t.Fatalf("count users: %v", err)
+	}
+	return count
+}
+
+func TestDatabaseCredentialsIssueAndRevoke(t *testing.T) {
+	s, leases, dir := newTestDatabaseService(t)
+	target := openTestTarget(t, filepath.Join(dir, "app.db"))
+	if err := target.Exec("CREATE TABLE app_users (name TEXT PRIMARY KEY, password TEXT NOT NULL, note TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.WriteConnection(&model.DatabaseConnectionRequest{
+		Name:     "app",
+		Type:     model.DatabaseTypeSQLite,
+		Database: "app.db",
+	}, uuid.New()); err != nil {
+		t.Fatalf("write connection: %v", err)
+	}
+	if _, err := s.WriteRole(&model.DatabaseRoleRequest{
+		Name:                 "writer",
+		Connection:           "app",
+		CreationStatements:   "INSERT INTO app_users (name, password, note) VALUES ('{{name}}', '{{password}}', 'expires; {{expiration}}');",
+		RevocationStatements: "DELETE FROM app_users WHERE name = '{{name}}';",
+	}); err != nil {
+		t.Fatalf("write role: %v", err)
+	}
+
+	creds, lease, err := s.GenerateCredentials("writer", uuid.New())
+	if err != nil {
+		t.Fatalf("generate credentials: %v", err)
+	}
+	if creds.Username == "" || creds.Password == "" {
+		t.Fatalf("empty credentials: %+v", creds)
+	}
+
+	var password string
+	if err := target.Table("app_users").Select("password").Where("name = ?", creds.Username).Scan(&password).Error; err != nil {
+		t.Fatal(err)
+	}
+	if password != creds.Password {
+		t.Fatalf("stored password %q, want %q", password, creds.Password)
+	}
+
+	if err := leases.Revoke(lease.ID, nil); err != nil {
+		t.Fatalf("revoke lease: %v", err)
+	}
+	if n := countTestUsers(t, target, creds.Username); n != 0 {
+		t.Fatalf("user still present after revocation (%d rows)", n)
+	}
+	if _, err := leases.Lookup(lease.ID, nil); !errors.Is(err, ErrLeaseNotFound) {
+		t.Fatalf("lease still active after revocation: %v", err)
+	}
+}
+
+func TestDatabaseCredentialsFailedCreationIsRevoked(t *testing.T) {
+	s, leases, dir := newTestDatabaseService(t)
+	target := openTestTarget(t, filepath.Join(dir, "app.db"))
+	if err := target.Exec("CREATE TABLE app_users (name TEXT PRIMARY KEY, password TEXT NOT NULL, note TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.WriteConnection(&model.DatabaseConnectionRequest{
+		Name:     "app",
+		Type:     model.DatabaseTypeSQLite,
+		Database: "app.db",
+	}, uuid.New()); err != nil {
+		t.Fatalf("write connection: %v", err)
+	}
+	if _, err := s.WriteRole(&model.DatabaseRoleRequest{
+		Name:                 "broken",
+		Connection:           "app",
+		CreationStatements:   "INSERT INTO app_users (name, password) VALUES ('{{name}}', '{{password}}'); INSERT INTO missing_table VALUES (1);",
+		RevocationStatements: "DELETE FROM app_users WHERE name = '{{name}}';",
+	}); err != nil {
+		t.Fatalf("write role: %v", err)
+	}
+
+	_, _, err := s.GenerateCredentials("broken", uuid.New())
+	if err == nil {
+		t.Fatal("expected creation to fail")
+	}
+
+	var users int64
+	if err := target.Table("app_users").Count(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if users != 0 {
+		t.Fatalf("failed creation left %d users behind", users)
+	}
+	active, err := leases.List("database/creds/broken", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(active) != 0 {
+		t.Fatalf("failed creation left %d active leases", len(active))
+	}
+}
+
+func TestSQLiteTargetPath(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		dir     string
+		conn    model.DatabaseConnection
+		secrets databaseConnectionSecrets
+		want    error
+	}{
+		{name: "relative file", dir: dir, conn: model.DatabaseConnection{Database: "app.db"}},
+		{name: "absolute file inside", dir: dir, conn: model.DatabaseConnection{Database: filepath.Join(dir, "app.db")}},
+		{name: "url inside", dir: dir, secrets: databaseConnectionSecrets{URL: "sqlite://" + filepath.Join(dir, "app.db")}},
+		{name: "disabled", dir: "", conn: model.DatabaseConnection{Database: "app.db"}, want: ErrUnsupportedDatabaseType},
+		{name: "parent escape", dir: dir, conn: model.DatabaseConnection{Database: "../app.db"}, want: ErrInvalidDatabaseConnection},
+		{name: "absolute outside", dir: dir, conn: model.DatabaseConnection{Database: "/etc/vault.db"}, want: ErrInvalidDatabaseConnection},
+		{name: "directory itself", dir: dir, conn: model.DatabaseConnection{Database: "."}, want: ErrInvalidDatabaseConnection},
+		{name: "driver options", dir: dir, conn: model.DatabaseConnection{Database: "app.db?_pragma=foo"}, want: ErrInvalidDatabaseConnection},
+		{name: "memory", dir: dir, conn: model.DatabaseConnection{Database: ":memory:"}, want: ErrInvalidDatabaseConnection},
+		{name: "url options", dir: dir, secrets: databaseConnectionSecrets{URL: "sqlite://" + filepath.Join(dir, "app.db") + "?mode=ro"}, want: ErrInvalidDatabaseConnection},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := sqliteTargetPath(tt.dir, &tt.conn, &tt.secrets)
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Fatalf("got %q, %v; want %v", path, err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if filepath.Base(path) != "app.db" {
+				t.Fatalf("resolved to %q", path)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name             string
+		statements       string
+		backslashEscapes bool
+		want             []string
+	}{
+		{
+			name:       "plain",
+			statements: " CREATE USER a; GRANT x TO a ;; ",
+			want:       []string{"CREATE USER a", "GRANT x TO a"},
+		},
+		{
+			name:       "quoted semicolons",
+			statements: `CREATE ROLE "a;b" PASSWORD 'p;w''d'; SELECT 1`,
+			want:       []string{`CREATE ROLE "a;b" PASSWORD 'p;w''d'`, "SELECT 1"},
+		},
+		{
+			name:       "do block",
+			statements: "DO $$ BEGIN CREATE ROLE a; EXCEPTION WHEN others THEN NULL; END $$; GRANT x TO a;",
+			want:       []string{"DO $$ BEGIN CREATE ROLE a; EXCEPTION WHEN others THEN NULL; END $$", "GRANT x TO a"},
+		},
+		{
+			name:       "tagged dollar quote",
+			statements: "DO $body$ BEGIN PERFORM 1; END $body$; SELECT $1",
+			want:       []string{"DO $body$ BEGIN PERFORM 1; END $body$", "SELECT $1"},
+		},
+		{
+			name:       "comments",
+			statements: "-- drop; later\nSELECT 1; /* a; b */ SELECT 2",
+			want:       []string{"-- drop; later\nSELECT 1", "/* a; b */ SELECT 2"},
+		},
+		{
+			name:             "mysql backslash",
+			statements:       `CREATE USER 'a'@'%' IDENTIFIED BY 'p\';w'; SELECT 1`,
+			backslashEscapes: true,
+			want:             []string{`CREATE USER 'a'@'%' IDENTIFIED BY 'p\';w'`, "SELECT 1"},
+		},
+		{
+			name:       "backtick identifier",
+			statements: "GRANT SELECT ON `a;b`.* TO 'u'@'%'; SELECT 1",
+			want:       []string{"GRANT SELECT ON `a;b`.* TO 'u'@'%'", "SELECT 1"},
+		},
+		{
+			name:       "empty",
+			statements: " ; \n ",
+			want:       nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.statements, tt.backslashEscapes); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type GroupService struct {
+	db *gorm.DB
+}
+
+func NewGroupService(db *gorm.DB) *GroupService {
+	return &GroupService{db: db}
+}
+
+func (s *GroupService) CreateGroup(group *model.Group) error {
+	var existing int64
+	if err := s.db.Model(&model.Group{}).Where("name = ?", group.Name).Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check group: %w", err)
+	}
+	if existing > 0 {
+		return ErrGroupExists
+	}
+
+	if err := s.db.Create(group).Error; err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+	return nil
+}
+
+func (s *GroupService) GetGroup(id uuid.UUID) (*model.Group, error) {
+	var group model.Group
+	if err := s.db.Preload("Users").Where("id = ?", id).First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+// ResolveGroup finds a group by ID or by name.
+func (s *GroupService) ResolveGroup(idOrName string) (*model.Group, error) {
+	return resolveGroup(s.db, idOrName)
+}
+
+func (s *GroupService) ListGroups() ([]model.Group, error) {
+	var groups []model.Group
+	if err := s.db.Order("name ASC").Find(&groups).Error; err != nil {
+		return nil, fmt.Errorf("failed to list groups: %w", err)
+	}
+	return groups, nil
+}
+
+// DeleteGroup removes a group together with its memberships and policy
+// assignments.
+func (s *GroupService) DeleteGroup(id uuid.UUID) error {
+	group, err := s.GetGroup(id)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(group).Association("Users").Clear(); err != nil {
+			return fmt.Errorf("failed to remove group members: %w", err)
+		}
+		if err := tx.Where("identity_type = ? AND identity_id = ?", model.IdentityTypeGroup, group.ID.String()).
+			Delete(&model.PolicyAssignment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete group policy assignments: %w", err)
+		}
+		if err := tx.Delete(group).Error; err != nil {
+			return fmt.Errorf("failed to delete group: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *GroupService) AddMember(groupID, userID uuid.UUID) error {
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	var user model.User
+	if err := s.db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if err := s.db.Model(group).Association("Users").Append(&user); err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+	return nil
+}
+
+func (s *GroupService) RemoveMember(groupID, userID uuid.UUID) error {
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(group).Association("Users").Delete(&model.User{ID: userID}); err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	return nil
+}
+
+func resolveGroup(db *gorm.DB, idOrName string) (*model.Group, error) {
+	query := db.Where("name = ?", idOrName)
+	if id, err := uuid.Parse(idOrName); err == nil {
+		query = db.Where("id = ?", id)
+	}
+
+	var group model.Group
+	if err := query.First(&group).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrGroupNotFound
+		}
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+	return &group, nil
+}
+
+var (
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+)
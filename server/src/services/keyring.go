@@ -0,0 +1,382 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/pbkdf2"
+	"gorm.io/gorm"
+)
+
+const dataKeySize = 32
+
+// KeyRewrapper is implemented by services that store data keys wrapped by the
+// keyring, so the background job can move them to the active key version.
+type KeyRewrapper interface {
+	Name() string
+	RewrapKeys(batchSize int) (int, error)
+	PendingRewrap() (int64, error)
+}
+
+// KeyringService holds the versioned key-encryption keys (KEKs). Each KEK is
+// stored wrapped by a master key derived from the vault root key with a
+// per-key random salt; data keys are wrapped by the active KEK. The keyring
+// holds no keys while the vault is sealed.
+type KeyringService struct {
+	db                *gorm.DB
+	kdfIter           int
+	legacyPassphrases [][]byte
+
+	mutex   sync.RWMutex
+	rootKey []byte
+	keys    map[int][]byte
+	active  int
+	targets []KeyRewrapper
+	trigger chan struct{}
+}
+
+// NewKeyringService creates a locked keyring. legacyPassphrases are the
+// configured encryption keys that wrapped the keyring before the vault was
+// initialized; keys still wrapped by them are rewrapped on unlock.
+func NewKeyringService(db *gorm.DB, kdfIter int, legacyPassphrases ...string) *KeyringService {
+	service := &KeyringService{
+		db:      db,
+		kdfIter: kdfIter,
+		keys:    make(map[int][]byte),
+		trigger: make(chan struct{}, 1),
+	}
+	for _, passphrase := range legacyPassphrases {
+		if passphrase != "" {
+			service.legacyPassphrases = append(service.legacyPassphrases, []byte(passphrase))
+		}
+	}
+	return service
+}
+
+func (s *KeyringService) Unlock(rootKey []byte) error {
+	var entries []model.KeyringKey
+	if err := s.db.Order("version ASC").Find(&entries).Error; err != nil {
+		return fmt.Errorf("failed to load keyring: %w", err)
+	}
+
+	keys := make(map[int][]byte)
+	active := 0
+	var legacy []int
+	for i := range entries {
+		entry := &entries[i]
+		key, err := s.unwrapKeyringKey(entry, rootKey)
+		if err != nil {
+			key, err = s.unwrapLegacyKey(entry)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt keyring key %d: %w", entry.Version, err)
+			}
+			legacy = append(legacy, entry.Version)
+		}
+
+		keys[entry.Version] = key
+		if entry.Version > active {
+			active = entry.Version
+		}
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, version := range legacy {
+			if err := s.persistKeyringKey(tx, rootKey, version, keys[version]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to rewrap keyring with the root key: %w", err)
+	}
+	for _, version := range legacy {
+		log.Printf("🔑 Keyring key v%d rewrapped with the root key", version)
+	}
+
+	s.mutex.Lock()
+	s.rootKey, s.keys, s.active = rootKey, keys, active
+	s.mutex.Unlock()
+
+	if active == 0 {
+		if _, err := s.Rotate(); err != nil {
+			s.Lock()
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *KeyringService) Lock() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, key := range s.keys {
+		for i := range key {
+			key[i] = 0
+		}
+	}
+	s.rootKey = nil
+	s.keys = make(map[int][]byte)
+	s.active = 0
+}
+
+func (s *KeyringService) Rotate() (int, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return 0, fmt.Errorf("failed to generate keyring key: %w", err)
+	}
+
+	s.mutex.Lock()
+	if s.rootKey == nil {
+		s.mutex.Unlock()
+		return 0, ErrKeyringLocked
+	}
+	version := s.active + 1
+	if err := s.persistKeyringKey(s.db, s.rootKey, version, key); err != nil {
+		s.mutex.Unlock()
+		return 0, fmt.Errorf("failed to store keyring key: %w", err)
+	}
+	s.keys[version] = key
+	s.active = version
+	s.mutex.Unlock()
+
+	s.TriggerRewrap()
+
+	return version, nil
+}
+
+func (s *KeyringService) ActiveVersion() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.active
+}
+
+func (s *KeyringService) GenerateDataKey() ([]byte, string, int, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, "", 0, fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	wrapped, version, err := s.WrapDataKey(dataKey)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	return dataKey, wrapped, version, nil
+}
+
+// WrapDataKey and UnwrapDataKey hold the read lock while using the key, as
+// Lock zeroes the keys in place.
+func (s *KeyringService) WrapDataKey(dataKey []byte) (string, int, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	version := s.active
+	kek, ok := s.keys[version]
+
+	if !ok {
+		return "", 0, ErrKeyringLocked
+	}
+
+	wrapped, err := sealGCM(kek, dataKey)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	return wrapped, version, nil
+}
+
+func (s *KeyringService) UnwrapDataKey(wrapped string, version int) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	kek, ok := s.keys[version]
+
+	if !ok {
+		return nil, fmt.Errorf("%w: version %d", ErrKeyVersionNotFound, version)
+	}
+
+	dataKey, err := openGCM(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return dataKey, nil
+}
+
+func (s *KeyringService) Status() (*model.KeyringStatus, error) {
+	var entries []model.KeyringKey
+	if err := s.db.Order("version DESC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load keyring: %w", err)
+	}
+
+	status := &model.KeyringStatus{
+		ActiveVersion: s.ActiveVersion(),
+		Keys:          entries,
+		PendingRewrap: make(map[string]int),
+	}
+
+	for _, target := range s.rewrapTargets() {
+		pending, err := target.PendingRewrap()
+		if err != nil {
+			return nil, err
+		}
+		status.PendingRewrap[target.Name()] = int(pending)
+	}
+
+	return status, nil
+}
+
+func (s *KeyringService) StartRewrapJob(interval time.Duration, batchSize int, targets ...KeyRewrapper) {
+	s.mutex.Lock()
+	s.targets = append(s.targets, targets...)
+	s.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		s.RewrapAll(batchSize)
+		for {
+			select {
+			case <-ticker.C:
+			case <-s.trigger:
+			}
+			s.RewrapAll(batchSize)
+		}
+	}()
+}
+
+func (s *KeyringService) TriggerRewrap() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+func (s *KeyringService) RewrapAll(batchSize int) {
+	for _, target := range s.rewrapTargets() {
+		for {
+			count, err := target.RewrapKeys(batchSize)
+			if err != nil {
+				log.Printf("⚠️  Keyring rewrap of %s failed: %v", target.Name(), err)
+				break
+			}
+			if count > 0 {
+				log.Printf("🔑 Rewrapped %d %s to keyring v%d", count, target.Name(), s.ActiveVersion())
+			}
+			if count < batchSize {
+				break
+			}
+		}
+	}
+}
+
+func (s *KeyringService) rewrapTargets() []KeyRewrapper {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return append([]KeyRewrapper(nil), s.targets...)
+}
+
+func (s *KeyringService) persistKeyringKey(db *gorm.DB, rootKey []byte, version int, key []byte) error {
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return err
+	}
+
+	wrapped, err := sealGCM(s.masterKey(rootKey, salt), key)
+	if err != nil {
+		return err
+	}
+
+	entry := &model.KeyringKey{
+		Version:    version,
+		WrappedKey: wrapped,
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+	}
+	return db.Save(entry).Error
+}
+
+func (s *KeyringService) unwrapKeyringKey(entry *model.KeyringKey, passphrase []byte) ([]byte, error) {
+	salt, err := base64.StdEncoding.DecodeString(entry.Salt)
+	if err != nil {
+		return nil, err
+	}
+	return openGCM(s.masterKey(passphrase, salt), entry.WrappedKey)
+}
+
+func (s *KeyringService) unwrapLegacyKey(entry *model.KeyringKey) ([]byte, error) {
+	err := errors.New("no legacy encryption key configured")
+	for _, passphrase := range s.legacyPassphrases {
+		var key []byte
+		if key, err = s.unwrapKeyringKey(entry, passphrase); err == nil {
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+func (s *KeyringService) masterKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, s.kdfIter, 32, sha256.New)
+}
+
+func sealGCM(key, plaintext []byte) (string, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+func openGCM(key []byte, ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+	if len(data) < nonceSize {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := data[:nonceSize], data[nonceSize:]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+var (
+	ErrKeyringLocked      = errors.New("keyring is locked")
+	ErrKeyVersionNotFound = errors.New("keyring key version not found")
+)
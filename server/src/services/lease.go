@@ -0,0 +1,358 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// leaseRevokeBatchSize bounds the expired leases revoked in one pass.
+const leaseRevokeBatchSize = 100
+
+// LeaseRevoker is implemented by engines issuing leased credentials. It
+// releases the credential behind a lease and must treat one that is already
+// gone as revoked.
+type LeaseRevoker interface {
+	RevokeLease(lease *model.Lease) error
+}
+
+// LeaseRenewer is optionally implemented by revokers whose credential carries
+// its own expiry; it is called with the new ExpireTime before a renewal is
+// stored.
+type LeaseRenewer interface {
+	RenewLease(lease *model.Lease) error
+}
+
+// LeaseService issues, renews and revokes leases. Leases live in the
+// database, so expiry survives restarts: the scheduler revokes everything
+// overdue on start and then wakes for the next expiry.
+type LeaseService struct {
+	db           *gorm.DB
+	maxTTL       time.Duration
+	auditService *AuditService
+
+	mutex    sync.RWMutex
+	revokers map[string]LeaseRevoker
+	wake     chan struct{}
+}
+
+func NewLeaseService(db *gorm.DB, maxTTL time.Duration, auditService *AuditService) *LeaseService {
+	return &LeaseService{
+		db:           db,
+		maxTTL:       maxTTL,
+		auditService: auditService,
+		revokers:     make(map[string]LeaseRevoker),
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// RegisterRevoker routes the revocation of leases of the given kind.
+func (s *LeaseService) RegisterRevoker(kind string, revoker LeaseRevoker) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.revokers[kind] = revoker
+}
+
+// Issue creates a lease under path. A zero maxTTL uses the system maximum,
+// which also caps both TTLs.
+func (s *LeaseService) Issue(kind, path, resourceID string, userID *uuid.UUID, ttl, maxTTL time.Duration, renewable bool, data string) (*model.Lease, error) {
+	if maxTTL <= 0 || maxTTL > s.maxTTL {
+		maxTTL = s.maxTTL
+	}
+	if ttl <= 0 || ttl > maxTTL {
+		ttl = maxTTL
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	lease := &model.Lease{
+		ID:            strings.Trim(path, "/") + "/" + uuid.New().String(),
+		Kind:          kind,
+		ResourceID:    resourceID,
+		UserID:        userID,
+		Renewable:     renewable,
+		TTL:           int(ttl / time.Second),
+		MaxTTL:        int(maxTTL / time.Second),
+		IssueTime:     now,
+		ExpireTime:    now.Add(ttl),
+		MaxExpireTime: now.Add(maxTTL),
+		Data:          data,
+	}
+	if err := s.db.Create(lease).Error; err != nil {
+		return nil, fmt.Errorf("failed to create lease: %w", err)
+	}
+
+	s.Wake()
+	return lease, nil
+}
+
+// Lookup returns an active lease. When userID is not nil the lease must
+// belong to that user.
+func (s *LeaseService) Lookup(id string, userID *uuid.UUID) (*model.Lease, error) {
+	query := s.db.Where("id = ? AND revoked_at IS NULL", id)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var lease model.Lease
+	if err := query.First(&lease).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLeaseNotFound
+		}
+		return nil, fmt.Errorf("failed to get lease: %w", err)
+	}
+	return &lease, nil
+}
+
+// List returns the active leases whose ID starts with prefix, soonest expiry
+// first. When userID is not nil only that user's leases are returned.
+func (s *LeaseService) List(prefix string, userID *uuid.UUID) ([]model.Lease, error) {
+	query := s.db.Where("revoked_at IS NULL")
+	if prefix != "" {
+		query = query.Where("SUBSTR(id, 1, ?) = ?", len(prefix), prefix)
+	}
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var leases []model.Lease
+	if err := query.Order("expire_time ASC").Find(&leases).Error; err != nil {
+		return nil, fmt.Errorf("failed to list leases: %w", err)
+	}
+	return leases, nil
+}
+
+// Renew extends a lease by increment, or by its original TTL when increment
+// is zero, never past its max TTL.
+func (s *LeaseService) Renew(id string, increment time.Duration, userID *uuid.UUID) (*model.Lease, error) {
+	lease, err := s.Lookup(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !lease.Renewable {
+		return nil, ErrLeaseNotRenewable
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if !lease.ExpireTime.After(now) {
+		return nil, ErrLeaseExpired
+	}
+	if increment <= 0 {
+		increment = time.Duration(lease.TTL) * time.Second
+	}
+	expireTime := now.Add(increment)
+	if expireTime.After(lease.MaxExpireTime) {
+		expireTime = lease.MaxExpireTime
+	}
+
+	lease.ExpireTime = expireTime
+	lease.LastRenewal = &now
+	if renewer, ok := s.revoker(lease.Kind).(LeaseRenewer); ok {
+		if err := renewer.RenewLease(lease); err != nil {
+			return nil, fmt.Errorf("failed to renew credential: %w", err)
+		}
+	}
+
+	if err := s.db.Model(&model.Lease{}).Where("id = ?", lease.ID).Updates(map[string]interface{}{
+		"expire_time":  lease.ExpireTime,
+		"last_renewal": lease.LastRenewal,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to renew lease: %w", err)
+	}
+	return lease, nil
+}
+
+// Revoke releases the credential behind a lease and ends the lease.
+func (s *LeaseService) Revoke(id string, userID *uuid.UUID) error {
+	lease, err := s.Lookup(id, userID)
+	if err != nil {
+		return err
+	}
+	return s.revoke(lease)
+}
+
+// RevokePrefix revokes every active lease whose ID starts with prefix.
+func (s *LeaseService) RevokePrefix(prefix string) (int, error) {
+	leases, err := s.List(prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	return s.revokeAll(leases)
+}
+
+// RevokeResource revokes every active lease of the given kind on a resource,
+// such as all credentials issued on one database connection.
+func (s *LeaseService) RevokeResource(kind, resourceID string) (int, error) {
+	var leases []model.Lease
+	if err := s.db.Where("revoked_at IS NULL AND kind = ? AND resource_id = ?", kind, resourceID).
+		Find(&leases).Error; err != nil {
+		return 0, fmt.Errorf("failed to list leases: %w", err)
+	}
+	return s.revokeAll(leases)
+}
+
+// revokeAll keeps going past failures and returns the number revoked with
+// the first error.
+func (s *LeaseService) revokeAll(leases []model.Lease) (int, error) {
+	revoked := 0
+	var firstErr error
+	for i := range leases {
+		if err := s.revoke(&leases[i]); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		revoked++
+	}
+	return revoked, firstErr
+}
+
+// Release ends a lease whose credential was already consumed, without
+// calling its revoker.
+func (s *LeaseService) Release(id string) error {
+	if err := s.db.Model(&model.Lease{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to release lease: %w", err)
+	}
+	return nil
+}
+
+// RevokeExpired revokes leases past their expiry. Failed revocations are
+// recorded on the lease and retried on the next pass.
+func (s *LeaseService) RevokeExpired() (int, error) {
+	var leases []model.Lease
+	if err := s.db.Where("revoked_at IS NULL AND expire_time <= ?", time.Now()).
+		Order("revoke_errors ASC, expire_time ASC").Limit(leaseRevokeBatchSize).Find(&leases).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expired leases: %w", err)
+	}
+
+	revoked := 0
+	for i := range leases {
+		lease := &leases[i]
+		err := s.revoke(lease)
+		if s.auditService != nil {
+			details := "kind=" + lease.Kind
+			if err != nil {
+				details += " " + err.Error()
+			}
+			s.auditService.LogAnonymousAction("lease_expired", "lease", lease.ID, "", "", err == nil, details)
+		}
+		if err != nil {
+			log.Printf("⚠️  Failed to revoke expired lease %s: %v", lease.ID, err)
+			continue
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// StartExpiryJob revokes expired leases now and then whenever the next lease
+// expires, checking at least every interval.
+func (s *LeaseService) StartExpiryJob(interval time.Duration) {
+	go func() {
+		for {
+			revoked, err := s.RevokeExpired()
+			if err != nil {
+				log.Printf("⚠️  Lease expiry failed: %v", err)
+			} else if revoked > 0 {
+				log.Printf("🧹 Revoked %d expired leases", revoked)
+			}
+			if revoked == leaseRevokeBatchSize {
+				continue
+			}
+
+			timer := time.NewTimer(s.nextExpiry(interval))
+			select {
+			case <-timer.C:
+			case <-s.wake:
+				timer.Stop()
+			}
+		}
+	}()
+}
+
+// Wake makes the expiry job recompute its next run.
+func (s *LeaseService) Wake() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *LeaseService) nextExpiry(interval time.Duration) time.Duration {
+	var lease model.Lease
+	err := s.db.Where("revoked_at IS NULL AND expire_time > ?", time.Now()).
+		Order("expire_time ASC").First(&lease).Error
+	if err != nil {
+		return interval
+	}
+	if wait := time.Until(lease.ExpireTime); wait < interval {
+		return wait
+	}
+	return interval
+}
+
+func (s *LeaseService) revoke(lease *model.Lease) error {
+	revoker := s.revoker(lease.Kind)
+	if revoker == nil {
+		return s.recordRevokeError(lease, fmt.Errorf("%w: %s", ErrNoLeaseRevoker, lease.Kind))
+	}
+	if err := revoker.RevokeLease(lease); err != nil {
+		return s.recordRevokeError(lease, err)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(&model.Lease{}).Where("id = ?", lease.ID).Updates(map[string]interface{}{
+		"revoked_at": now,
+		"last_error": "",
+	}).Error; err != nil {
+		return fmt.Errorf("failed to revoke lease: %w", err)
+	}
+	lease.RevokedAt = &now
+	return nil
+}
+
+func (s *LeaseService) recordRevokeError(lease *model.Lease, err error) error {
+	if updateErr := s.db.Model(&model.Lease{}).Where("id = ?", lease.ID).Updates(map[string]interface{}{
+		"revoke_errors": gorm.Expr("revoke_errors + 1"),
+		"last_error":    err.Error(),
+	}).Error; updateErr != nil {
+		log.Printf("⚠️  Failed to record revocation error of lease %s: %v", lease.ID, updateErr)
+	}
+	return err
+}
+
+func (s *LeaseService) revoker(kind string) LeaseRevoker {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.revokers[kind]
+}
+
+// ToLeaseResponse converts a lease for the API.
+func ToLeaseResponse(lease *model.Lease) model.LeaseResponse {
+	return model.LeaseResponse{
+		LeaseID:       lease.ID,
+		Kind:          lease.Kind,
+		Renewable:     lease.Renewable,
+		LeaseDuration: lease.RemainingTTL(time.Now()),
+		IssueTime:     lease.IssueTime,
+		ExpireTime:    lease.ExpireTime,
+		LastRenewal:   lease.LastRenewal,
+		RevokedAt:     lease.RevokedAt,
+	}
+}
+
+var (
+	ErrLeaseNotFound     = errors.New("lease not found")
+	ErrLeaseExpired      = errors.New("lease has expired")
+	ErrLeaseNotRenewable = errors.New("lease is not renewable")
+	ErrNoLeaseRevoker    = errors.New("no revoker registered for lease kind")
+)
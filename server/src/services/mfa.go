@@ -0,0 +1,431 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/utils"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	mfaIssuer            = "Aether Vault"
+	mfaDigits            = 6
+	mfaPeriod            = 30
+	mfaSkew              = 1
+	mfaRecoveryCodeCount = 10
+	mfaMaxFailedAttempts = 5
+	mfaLockoutDuration   = 5 * time.Minute
+)
+
+// MFAService manages the TOTP second factor used at login. Seeds are
+// envelope-encrypted with the keyring like stored TOTP entries.
+type MFAService struct {
+	db           *gorm.DB
+	keyring      *KeyringService
+	auditService *AuditService
+}
+
+func NewMFAService(db *gorm.DB, keyring *KeyringService, auditService *AuditService) *MFAService {
+	return &MFAService{
+		db:           db,
+		keyring:      keyring,
+		auditService: auditService,
+	}
+}
+
+// Enroll generates a new pending seed for the user, replacing any earlier
+// unconfirmed enrollment.
+func (s *MFAService) Enroll(userID uuid.UUID, accountName string) (*model.MFAEnrollResponse, error) {
+	existing, err := s.find(userID)
+	if err != nil && !errors.Is(err, ErrMFANotEnrolled) {
+		return nil, err
+	}
+	if existing != nil && existing.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate MFA secret: %w", err)
+	}
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw)
+
+	mfa := &model.UserMFA{UserID: userID}
+	if err := s.sealSecret(mfa, secret); err != nil {
+		return nil, fmt.Errorf("failed to encrypt MFA secret: %w", err)
+	}
+	if err := s.db.Save(mfa).Error; err != nil {
+		return nil, fmt.Errorf("failed to store MFA enrollment: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "mfa_enrollment_started", "mfa", userID.String(), true, "")
+	}
+
+	key := &utils.OTPAuthKey{
+		Issuer:    mfaIssuer,
+		Account:   accountName,
+		Secret:    secret,
+		Algorithm: "SHA1",
+		Digits:    mfaDigits,
+		Period:    mfaPeriod,
+	}
+
+	return &model.MFAEnrollResponse{
+		Secret:     secret,
+		OTPAuthURL: key.URI(),
+	}, nil
+}
+
+// Confirm enables a pending enrollment once the user proves possession of the
+// seed, and issues a fresh set of recovery codes.
+func (s *MFAService) Confirm(userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.find(userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, err := s.matchCode(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(mfa).Updates(map[string]interface{}{
+			"enabled":        true,
+			"confirmed_at":   &now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable MFA: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "mfa_enabled", "mfa", userID.String(), true, "")
+	}
+
+	return codes, nil
+}
+
+// Verify checks a TOTP code or an unused recovery code for a user with MFA
+// enabled. Each TOTP time step and each recovery code is accepted only once.
+// After mfaMaxFailedAttempts failures in a row the second factor is locked
+// for mfaLockoutDuration, and the failure that locks it returns ErrMFALocked.
+func (s *MFAService) Verify(userID uuid.UUID, code string) error {
+	mfa, err := s.find(userID)
+	if err != nil {
+		return err
+	}
+	if !mfa.Enabled {
+		return ErrMFANotEnrolled
+	}
+
+	now := time.Now()
+	if mfa.LockedUntil != nil && now.Before(*mfa.LockedUntil) {
+		return ErrMFALocked
+	}
+
+	err = s.verifyCode(mfa, code)
+	if errors.Is(err, ErrInvalidMFACode) {
+		return s.recordFailure(mfa, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if mfa.FailedAttempts > 0 || mfa.LockedUntil != nil {
+		if err := s.db.Model(&model.UserMFA{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"failed_attempts": 0,
+			"locked_until":    nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to reset MFA failures: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *MFAService) verifyCode(mfa *model.UserMFA, code string) error {
+	userID := mfa.UserID
+	code = strings.TrimSpace(code)
+	if len(code) == mfaDigits {
+		step, err := s.matchCode(mfa, code)
+		if err != nil {
+			return err
+		}
+
+		result := s.db.Model(&model.UserMFA{}).
+			Where("user_id = ? AND last_used_step < ?", userID, step).
+			Update("last_used_step", step)
+		if result.Error != nil {
+			return fmt.Errorf("failed to record MFA code use: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	result := s.db.Model(&model.MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to record recovery code use: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMFACode
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "mfa_recovery_code_used", "mfa", userID.String(), true, "")
+	}
+
+	return nil
+}
+
+// recordFailure counts a failed verification and locks the second factor
+// once the failures in a row reach the limit. The count is incremented and
+// checked in one UPDATE, so concurrent failures cannot all read the same
+// count and slip past the limit.
+func (s *MFAService) recordFailure(mfa *model.UserMFA, now time.Time) error {
+	var updated model.UserMFA
+	result := s.db.Model(&updated).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_attempts"}}}).
+		Where("user_id = ?", mfa.UserID).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", mfaMaxFailedAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", mfaMaxFailedAttempts, now.Add(mfaLockoutDuration)),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record MFA failure: %w", result.Error)
+	}
+
+	// The lock restarts the count, so only the failure that set it reads
+	// back zero.
+	if result.RowsAffected == 0 || updated.FailedAttempts > 0 {
+		return ErrInvalidMFACode
+	}
+	if s.auditService != nil {
+		s.auditService.LogAction(mfa.UserID, "mfa_locked", "mfa", mfa.UserID.String(), true, fmt.Sprintf("failed_attempts=%d", mfaMaxFailedAttempts))
+	}
+	return ErrMFALocked
+}
+
+func (s *MFAService) IsEnabled(userID uuid.UUID) (bool, error) {
+	mfa, err := s.find(userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnrolled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return mfa.Enabled, nil
+}
+
+func (s *MFAService) Status(userID uuid.UUID) (*model.MFAStatusResponse, error) {
+	mfa, err := s.find(userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnrolled) {
+			return &model.MFAStatusResponse{}, nil
+		}
+		return nil, err
+	}
+
+	var remaining int64
+	if err := s.db.Model(&model.MFARecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&remaining).Error; err != nil {
+		return nil, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+
+	return &model.MFAStatusResponse{
+		Enabled:                mfa.Enabled,
+		Pending:                !mfa.Enabled,
+		ConfirmedAt:            mfa.ConfirmedAt,
+		RecoveryCodesRemaining: int(remaining),
+		LockedUntil:            mfa.LockedUntil,
+	}, nil
+}
+
+// RegenerateRecoveryCodes invalidates all previous recovery codes.
+func (s *MFAService) RegenerateRecoveryCodes(userID uuid.UUID, code string) ([]string, error) {
+	if err := s.Verify(userID, code); err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(tx, userID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate recovery codes: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "mfa_recovery_codes_regenerated", "mfa", userID.String(), true, "")
+	}
+
+	return codes, nil
+}
+
+// Disable removes the user's own second factor after verifying a code.
+func (s *MFAService) Disable(userID uuid.UUID, code string) error {
+	if err := s.Verify(userID, code); err != nil {
+		return err
+	}
+
+	if err := s.remove(userID); err != nil {
+		return err
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "mfa_disabled", "mfa", userID.String(), true, "")
+	}
+
+	return nil
+}
+
+// Reset removes a user's second factor without a code, for administrators
+// recovering locked-out accounts.
+func (s *MFAService) Reset(userID uuid.UUID) error {
+	if _, err := s.find(userID); err != nil {
+		return err
+	}
+	return s.remove(userID)
+}
+
+func (s *MFAService) Name() string {
+	return "mfa"
+}
+
+func (s *MFAService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.UserMFA{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count MFA seeds pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *MFAService) RewrapKeys(batchSize int) (int, error) {
+	var entries []model.UserMFA
+	if err := s.db.Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&entries).Error; err != nil {
+		return 0, fmt.Errorf("failed to find MFA seeds pending rewrap: %w", err)
+	}
+
+	for i := range entries {
+		mfa := &entries[i]
+		dataKey, err := s.keyring.UnwrapDataKey(mfa.DataKey, mfa.KeyVersion)
+		if err != nil {
+			return i, fmt.Errorf("failed to rewrap MFA seed of user %s: %w", mfa.UserID, err)
+		}
+		if mfa.DataKey, mfa.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+			return i, fmt.Errorf("failed to rewrap MFA seed of user %s: %w", mfa.UserID, err)
+		}
+
+		if err := s.db.Model(mfa).UpdateColumns(map[string]interface{}{
+			"data_key":    mfa.DataKey,
+			"key_version": mfa.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update MFA seed of user %s: %w", mfa.UserID, err)
+		}
+	}
+
+	return len(entries), nil
+}
+
+func (s *MFAService) find(userID uuid.UUID) (*model.UserMFA, error) {
+	var mfa model.UserMFA
+	if err := s.db.Where("user_id = ?", userID).First(&mfa).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, fmt.Errorf("failed to get MFA enrollment: %w", err)
+	}
+	return &mfa, nil
+}
+
+func (s *MFAService) remove(userID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+			return fmt.Errorf("failed to delete recovery codes: %w", err)
+		}
+		if err := tx.Where("user_id = ?", userID).Delete(&model.UserMFA{}).Error; err != nil {
+			return fmt.Errorf("failed to delete MFA enrollment: %w", err)
+		}
+		return nil
+	})
+}
+
+// matchCode returns the time step matching code within the allowed clock
+// skew. It does not check for replay.
+func (s *MFAService) matchCode(mfa *model.UserMFA, code string) (int64, error) {
+	secret, err := openOTPSeed(s.keyring, mfa.Secret, mfa.DataKey, mfa.KeyVersion)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt MFA secret: %w", err)
+	}
+
+	step, err := matchOTPStep(secret, "SHA1", mfaDigits, mfaPeriod, strings.TrimSpace(code), mfaSkew, mfa.LastUsedStep, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	if step == 0 {
+		return 0, ErrInvalidMFACode
+	}
+	return step, nil
+}
+
+func (s *MFAService) replaceRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&model.MFARecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes, err := generateRecoveryCodes(mfaRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := tx.Create(&model.MFARecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
+}
+
+func (s *MFAService) sealSecret(mfa *model.UserMFA, secret string) error {
+	sealed, wrapped, version, err := sealOTPSeed(s.keyring, secret)
+	if err != nil {
+		return err
+	}
+
+	mfa.Secret, mfa.DataKey, mfa.KeyVersion = sealed, wrapped, version
+	return nil
+}
+
+var (
+	ErrMFANotEnrolled    = errors.New("MFA is not enrolled")
+	ErrMFAAlreadyEnabled = errors.New("MFA is already enabled")
+	ErrInvalidMFACode    = errors.New("invalid MFA code")
+	ErrMFALocked         = errors.New("MFA is locked after too many failed attempts")
+)
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/utils"
+)
+
+func TestMFALocksAfterMaxFailedAttempts(t *testing.T) {
+	db := newTestVaultDB(t)
+	s := NewMFAService(db, newTestKeyring(t, db), nil)
+
+	user := &model.User{Email: "user@example.com", Password: "Passw0rd!", IsActive: true, Role: model.RoleUser}
+	if err := NewUserService(db).CreateUser(user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	userID := user.ID
+	enrollment, err := s.Enroll(userID, user.Email)
+	if err != nil {
+		t.Fatalf("enroll: %v", err)
+	}
+	code, err := utils.HOTPCode(enrollment.Secret, "SHA1", mfaDigits, uint64(time.Now().Unix()/mfaPeriod))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Confirm(userID, code); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+
+	for i := 1; i < mfaMaxFailedAttempts; i++ {
+		if err := s.Verify(userID, "aaaaa-bbbbb"); !errors.Is(err, ErrInvalidMFACode) {
+			t.Fatalf("failure %d: got %v, want %v", i, err, ErrInvalidMFACode)
+		}
+	}
+	if err := s.Verify(userID, "aaaaa-bbbbb"); !errors.Is(err, ErrMFALocked) {
+		t.Fatalf("failure %d: got %v, want %v", mfaMaxFailedAttempts, err, ErrMFALocked)
+	}
+
+	mfa, err := s.find(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mfa.FailedAttempts != 0 || mfa.LockedUntil == nil || !mfa.LockedUntil.After(time.Now()) {
+		t.Fatalf("not locked: failed_attempts=%d locked_until=%v", mfa.FailedAttempts, mfa.LockedUntil)
+	}
+	if err := s.Verify(userID, code); !errors.Is(err, ErrMFALocked) {
+		t.Fatalf("verify while locked: got %v, want %v", err, ErrMFALocked)
+	}
+}
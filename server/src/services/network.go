@@ -2,19 +2,44 @@ package services
 
 import (
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
 	"net"
 	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/skygenesisenterprise/aether-vault/server/src/config"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"gorm.io/gorm"
 )
 
+// networkMonitorConcurrency bounds the networks the monitor probes at once.
+const networkMonitorConcurrency = 8
+
+// networkMonitorColumns are written by the monitor only, so saving a network
+// from a request must leave them alone.
+var networkMonitorColumns = []string{"consecutive_failures", "last_check_at", "certificate_expires_at", "certificate_notified_at"}
+
+// NetworkService stores networks and tests their endpoints. Connection
+// credentials are kept in a vault secret per network, sealed like every
+// other secret, and only read back to run a test or a status check.
+//
+// Every status check is recorded as a model.NetworkCheck; mu serializes the
+// bookkeeping so concurrent checks of a network count its failures right.
 type NetworkService struct {
-	db      *gorm.DB
-	clients map[model.ProtocolType]ProtocolClient
+	db            *gorm.DB
+	secretService *SecretService
+	config        *config.NetworkConfig
+	auditService  *AuditService
+	notifier      NetworkAlertNotifier
+	clients       map[model.ProtocolType]ProtocolClient
+	mu            sync.Mutex
 }
 
 type ProtocolClient interface {
@@ -28,13 +53,13 @@ func (c *HTTPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResp
 	start := time.Now()
 
 	client := &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
+		Timeout: protocolTimeout(config),
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig: protocolTLSConfig(config),
 		},
 	}
 
-	url := fmt.Sprintf("http://%s:%d", config.Host, config.Port)
+	url := "http://" + protocolAddress(config, 80)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -70,23 +95,7 @@ func (c *HTTPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResp
 }
 
 func (c *HTTPClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
-	test, err := c.Test(config)
-	if err != nil {
-		return nil, err
-	}
-
-	status := "active"
-	if !test.Success {
-		status = "inactive"
-	}
-
-	return &model.ProtocolStatus{
-		Protocol:  model.ProtocolHTTP,
-		Status:    status,
-		LastCheck: time.Now(),
-		Message:   test.Message,
-		Latency:   test.Latency,
-	}, nil
+	return protocolStatus(model.ProtocolHTTP, c, config)
 }
 
 type HTTPSClient struct{}
@@ -95,13 +104,13 @@ func (c *HTTPSClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestRes
 	start := time.Now()
 
 	client := &http.Client{
-		Timeout: time.Duration(config.Timeout) * time.Second,
+		Timeout: protocolTimeout(config),
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			TLSClientConfig: protocolTLSConfig(config),
 		},
 	}
 
-	url := fmt.Sprintf("https://%s:%d", config.Host, config.Port)
+	url := "https://" + protocolAddress(config, 443)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, err
@@ -125,36 +134,34 @@ func (c *HTTPSClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestRes
 	}
 	defer resp.Body.Close()
 
-	return &model.ProtocolTestResponse{
+	response := &model.ProtocolTestResponse{
 		Success: resp.StatusCode >= 200 && resp.StatusCode < 300,
 		Message: fmt.Sprintf("HTTPS %d %s", resp.StatusCode, resp.Status),
 		Latency: time.Since(start).Milliseconds(),
 		Details: map[string]interface{}{
 			"status_code": resp.StatusCode,
-			"tls_version": resp.TLS,
 			"headers":     resp.Header,
 		},
-	}, nil
-}
-
-func (c *HTTPSClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
-	test, err := c.Test(config)
-	if err != nil {
-		return nil, err
 	}
 
-	status := "active"
-	if !test.Success {
-		status = "inactive"
+	if resp.TLS != nil {
+		response.Details["tls_version"] = tls.VersionName(resp.TLS.Version)
+		if len(resp.TLS.PeerCertificates) > 0 {
+			cert := resp.TLS.PeerCertificates[0]
+			expiresAt := cert.NotAfter
+			response.CertificateExpiresAt = &expiresAt
+			response.Details["certificate_subject"] = cert.Subject.String()
+			response.Details["certificate_issuer"] = cert.Issuer.String()
+			response.Details["certificate_expires_at"] = expiresAt
+			response.Details["certificate_days_remaining"] = int(time.Until(expiresAt).Hours() / 24)
+		}
 	}
 
-	return &model.ProtocolStatus{
-		Protocol:  model.ProtocolHTTPS,
-		Status:    status,
-		LastCheck: time.Now(),
-		Message:   test.Message,
-		Latency:   test.Latency,
-	}, nil
+	return response, nil
+}
+
+func (c *HTTPSClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolHTTPS, c, config)
 }
 
 type TCPClient struct{}
@@ -162,8 +169,7 @@ type TCPClient struct{}
 func (c *TCPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
 	start := time.Now()
 
-	address := fmt.Sprintf("%s:%d", config.Host, config.Port)
-	conn, err := net.DialTimeout("tcp", address, time.Duration(config.Timeout)*time.Second)
+	conn, err := net.DialTimeout("tcp", protocolAddress(config, 0), protocolTimeout(config))
 	if err != nil {
 		return &model.ProtocolTestResponse{
 			Success: false,
@@ -185,42 +191,29 @@ func (c *TCPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestRespo
 }
 
 func (c *TCPClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
-	test, err := c.Test(config)
-	if err != nil {
-		return nil, err
-	}
-
-	status := "active"
-	if !test.Success {
-		status = "inactive"
-	}
-
-	return &model.ProtocolStatus{
-		Protocol:  model.ProtocolSSH,
-		Status:    status,
-		LastCheck: time.Now(),
-		Message:   test.Message,
-		Latency:   test.Latency,
-	}, nil
+	return protocolStatus(model.ProtocolCustom, c, config)
 }
 
-func NewNetworkService(db *gorm.DB) *NetworkService {
+func NewNetworkService(db *gorm.DB, secretService *SecretService, config *config.NetworkConfig, auditService *AuditService) *NetworkService {
 	clients := make(map[model.ProtocolType]ProtocolClient)
 	clients[model.ProtocolHTTP] = &HTTPClient{}
 	clients[model.ProtocolHTTPS] = &HTTPSClient{}
-	clients[model.ProtocolSSH] = &TCPClient{}
-	clients[model.ProtocolFTP] = &TCPClient{}
-	clients[model.ProtocolSFTP] = &TCPClient{}
-	clients[model.ProtocolSMB] = &TCPClient{}
+	clients[model.ProtocolSSH] = &SSHClient{}
+	clients[model.ProtocolFTP] = &FTPClient{}
+	clients[model.ProtocolSFTP] = &SFTPClient{}
+	clients[model.ProtocolSMB] = &SMBClient{}
 	clients[model.ProtocolNFS] = &TCPClient{}
-	clients[model.ProtocolRsync] = &TCPClient{}
-	clients[model.ProtocolGit] = &TCPClient{}
-	clients[model.ProtocolWebDAV] = &HTTPClient{}
+	clients[model.ProtocolRsync] = &RsyncClient{}
+	clients[model.ProtocolGit] = &GitClient{}
+	clients[model.ProtocolWebDAV] = &WebDAVClient{}
 	clients[model.ProtocolCustom] = &TCPClient{}
 
 	return &NetworkService{
-		db:      db,
-		clients: clients,
+		db:            db,
+		secretService: secretService,
+		config:        config,
+		auditService:  auditService,
+		clients:       clients,
 	}
 }
 
@@ -236,55 +229,93 @@ func (s *NetworkService) GetNetwork(id uint) (*model.Network, error) {
 	var network model.Network
 	if err := s.db.First(&network, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("network not found")
+			return nil, ErrNetworkNotFound
 		}
 		return nil, fmt.Errorf("failed to get network: %w", err)
 	}
 	return &network, nil
 }
 
-func (s *NetworkService) CreateNetwork(req *model.NetworkRequest) (*model.Network, error) {
+// CreateNetwork stores the network; its credentials go to a new secret owned
+// by userID.
+func (s *NetworkService) CreateNetwork(req *model.NetworkRequest, userID uuid.UUID) (*model.Network, error) {
 	network := &model.Network{
-		Name:   req.Name,
-		Type:   req.Type,
-		Status: "active",
+		Name:             req.Name,
+		Type:             req.Type,
+		Status:           model.NetworkStatusActive,
+		CheckInterval:    req.CheckInterval,
+		FailureThreshold: req.FailureThreshold,
 	}
 
-	if err := network.SetProtocolConfig(req.Config); err != nil {
-		return nil, fmt.Errorf("failed to set protocol config: %w", err)
-	}
-
-	if err := s.db.Create(network).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.setConfig(tx, network, req.Config, false, userID); err != nil {
+			return err
+		}
+		return tx.Create(network).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to create network: %w", err)
 	}
 
+	s.logAction(userID, "network_created", network)
+
 	return network, nil
 }
 
-func (s *NetworkService) UpdateNetwork(id uint, req *model.NetworkRequest) (*model.Network, error) {
+// UpdateNetwork replaces the network's settings. Credentials given in the
+// config replace the stored ones as a new secret version; see
+// model.NetworkRequest for the ones left out.
+func (s *NetworkService) UpdateNetwork(id uint, req *model.NetworkRequest, userID uuid.UUID) (*model.Network, error) {
 	network, err := s.GetNetwork(id)
 	if err != nil {
 		return nil, err
 	}
+	if previous, err := network.GetProtocolConfig(); err == nil && network.Type == req.Type {
+		keepHostKeyPin(previous, req.Config)
+	}
 
 	network.Name = req.Name
 	network.Type = req.Type
+	network.CheckInterval = req.CheckInterval
+	network.FailureThreshold = req.FailureThreshold
 
-	if err := network.SetProtocolConfig(req.Config); err != nil {
-		return nil, fmt.Errorf("failed to set protocol config: %w", err)
-	}
-
-	if err := s.db.Save(network).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.setConfig(tx, network, req.Config, req.ClearCredentials, userID); err != nil {
+			return err
+		}
+		return tx.Omit(networkMonitorColumns...).Save(network).Error
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update network: %w", err)
 	}
 
+	s.logAction(userID, "network_updated", network)
+
 	return network, nil
 }
 
-func (s *NetworkService) DeleteNetwork(id uint) error {
-	if err := s.db.Delete(&model.Network{}, id).Error; err != nil {
+// DeleteNetwork deletes the network and its credentials secret.
+func (s *NetworkService) DeleteNetwork(id uint, userID uuid.UUID) error {
+	network, err := s.GetNetwork(id)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(network).Error; err != nil {
+			return err
+		}
+		if network.CredentialsID != nil && s.secretService != nil {
+			return s.secretService.deleteInternal(tx, *network.CredentialsID)
+		}
+		return nil
+	})
+	if err != nil {
 		return fmt.Errorf("failed to delete network: %w", err)
 	}
+
+	s.logAction(userID, "network_deleted", network)
+
 	return nil
 }
 
@@ -292,13 +323,17 @@ func (s *NetworkService) GetNetworkByName(name string) (*model.Network, error) {
 	var network model.Network
 	if err := s.db.Where("name = ?", name).First(&network).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, fmt.Errorf("network not found")
+			return nil, ErrNetworkNotFound
 		}
 		return nil, fmt.Errorf("failed to get network by name: %w", err)
 	}
 	return &network, nil
 }
 
+// TestProtocol tests the config of the request or of the stored network it
+// names. A stored network is tested exactly as stored: its stored
+// credentials must not be sent over a connection the caller weakened, so a
+// request type or config differing from the stored one is refused.
 func (s *NetworkService) TestProtocol(req *model.ProtocolTestRequest) (*model.ProtocolTestResponse, error) {
 	client, exists := s.clients[req.Type]
 	if !exists {
@@ -308,17 +343,39 @@ func (s *NetworkService) TestProtocol(req *model.ProtocolTestRequest) (*model.Pr
 		}, nil
 	}
 
-	return client.Test(req.Config)
+	config := req.Config
+	if req.NetworkID != nil {
+		network, err := s.GetNetwork(*req.NetworkID)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := s.protocolConfig(network)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Type != network.Type || (config != nil && !sameProtocolConfig(config, stored)) {
+			return nil, ErrNetworkConfigMismatch
+		}
+		config = stored
+		s.pinHostKey(network, config)
+	}
+	if config == nil {
+		return nil, ErrNetworkConfigRequired
+	}
+
+	return client.Test(config)
 }
 
+// GetProtocolStatus checks the network now and records the check in its
+// history, like a scheduled one.
 func (s *NetworkService) GetProtocolStatus(id uint) (*model.ProtocolStatus, error) {
 	network, err := s.GetNetwork(id)
 	if err != nil {
 		return nil, err
 	}
 
-	client, exists := s.clients[network.Type]
-	if !exists {
+	if _, exists := s.clients[network.Type]; !exists {
 		return &model.ProtocolStatus{
 			Protocol: network.Type,
 			Status:   "unsupported",
@@ -326,12 +383,180 @@ func (s *NetworkService) GetProtocolStatus(id uint) (*model.ProtocolStatus, erro
 		}, nil
 	}
 
-	config, err := network.GetProtocolConfig()
+	status, err := s.probe(network)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get protocol config: %w", err)
+		return nil, err
+	}
+	if err := s.recordCheck(network.ID, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// StartMonitor checks every network when its check interval has elapsed,
+// looking for due ones every interval, and prunes history past the
+// retention. notifier may be nil, in which case alerts only go to the audit
+// log.
+func (s *NetworkService) StartMonitor(interval time.Duration, notifier NetworkAlertNotifier) {
+	s.notifier = notifier
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if _, err := s.RunChecks(); err != nil {
+				log.Printf("⚠️  Network health checks failed: %v", err)
+			}
+
+			pruned, err := s.PruneHistory()
+			if err != nil {
+				log.Printf("⚠️  Network history pruning failed: %v", err)
+				continue
+			}
+			if pruned > 0 {
+				log.Printf("🧹 Pruned %d network checks and alerts", pruned)
+			}
+		}
+	}()
+}
+
+// RunChecks probes the networks due for a check and records the results. A
+// network that cannot be probed, for instance because its credentials are
+// gone, counts as a failed check; one whose credentials are sealed away is
+// skipped until the vault is unsealed.
+func (s *NetworkService) RunChecks() (int, error) {
+	var networks []model.Network
+	if err := s.db.Find(&networks).Error; err != nil {
+		return 0, fmt.Errorf("failed to find networks: %w", err)
+	}
+
+	now := time.Now()
+	due := make([]model.Network, 0, len(networks))
+	for _, network := range networks {
+		if _, exists := s.clients[network.Type]; !exists {
+			continue
+		}
+		if network.LastCheckAt == nil || now.Sub(*network.LastCheckAt) >= s.checkInterval(&network) {
+			due = append(due, network)
+		}
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, networkMonitorConcurrency)
+	for i := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(network *model.Network) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			status, err := s.probe(network)
+			if errors.Is(err, ErrKeyringLocked) {
+				return
+			}
+			if err != nil {
+				status = &model.ProtocolStatus{
+					Protocol:  network.Type,
+					Status:    model.NetworkStatusInactive,
+					LastCheck: time.Now(),
+					Message:   err.Error(),
+				}
+			}
+			if err := s.recordCheck(network.ID, status); err != nil && !errors.Is(err, ErrNetworkNotFound) {
+				log.Printf("⚠️  Failed to record check of network %q: %v", network.Name, err)
+			}
+		}(&due[i])
+	}
+	wg.Wait()
+
+	return len(due), nil
+}
+
+// PruneHistory deletes the checks and alerts older than the history
+// retention.
+func (s *NetworkService) PruneHistory() (int64, error) {
+	cutoff := time.Now().Add(-time.Duration(s.config.HistoryRetention) * time.Second)
+
+	checks := s.db.Where("checked_at < ?", cutoff).Delete(&model.NetworkCheck{})
+	if checks.Error != nil {
+		return 0, fmt.Errorf("failed to prune network checks: %w", checks.Error)
+	}
+	alerts := s.db.Where("created_at < ?", cutoff).Delete(&model.NetworkAlert{})
+	if alerts.Error != nil {
+		return checks.RowsAffected, fmt.Errorf("failed to prune network alerts: %w", alerts.Error)
+	}
+	return checks.RowsAffected + alerts.RowsAffected, nil
+}
+
+// GetCheckHistory returns the network's checks within window, newest first.
+func (s *NetworkService) GetCheckHistory(id uint, window time.Duration, limit int) ([]model.NetworkCheck, error) {
+	if _, err := s.GetNetwork(id); err != nil {
+		return nil, err
 	}
 
-	return client.GetStatus(config)
+	var checks []model.NetworkCheck
+	if err := s.db.Where("network_id = ? AND checked_at >= ?", id, time.Now().Add(-window)).
+		Order("checked_at DESC").Limit(limit).Find(&checks).Error; err != nil {
+		return nil, fmt.Errorf("failed to get network checks: %w", err)
+	}
+	return checks, nil
+}
+
+// GetUptime summarizes the network's checks within window. The average
+// latency only counts successful checks, as failed ones mostly measure
+// timeouts.
+func (s *NetworkService) GetUptime(id uint, window time.Duration) (*model.NetworkUptimeResponse, error) {
+	network, err := s.GetNetwork(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var row struct {
+		Checks         int64
+		Successful     int64
+		AverageLatency float64
+	}
+	if err := s.db.Model(&model.NetworkCheck{}).
+		Select("COUNT(*) AS checks, "+
+			"COALESCE(SUM(CASE WHEN success THEN 1 ELSE 0 END), 0) AS successful, "+
+			"COALESCE(AVG(CASE WHEN success THEN latency END), 0) AS average_latency").
+		Where("network_id = ? AND checked_at >= ?", id, time.Now().Add(-window)).
+		Scan(&row).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute network uptime: %w", err)
+	}
+
+	response := &model.NetworkUptimeResponse{
+		NetworkID:      id,
+		Window:         int64(window / time.Second),
+		Checks:         row.Checks,
+		Successful:     row.Successful,
+		AverageLatency: int64(row.AverageLatency),
+		LastCheckAt:    network.LastCheckAt,
+	}
+	if row.Checks > 0 {
+		uptime := float64(row.Successful) * 100 / float64(row.Checks)
+		response.UptimePercent = &uptime
+	}
+	return response, nil
+}
+
+// GetAlerts returns the alerts raised within window, newest first, for one
+// network or for all of them when networkID is nil.
+func (s *NetworkService) GetAlerts(networkID *uint, window time.Duration, limit int) ([]model.NetworkAlert, error) {
+	query := s.db.Where("created_at >= ?", time.Now().Add(-window))
+	if networkID != nil {
+		if _, err := s.GetNetwork(*networkID); err != nil {
+			return nil, err
+		}
+		query = query.Where("network_id = ?", *networkID)
+	}
+
+	var alerts []model.NetworkAlert
+	if err := query.Order("created_at DESC").Limit(limit).Find(&alerts).Error; err != nil {
+		return nil, fmt.Errorf("failed to get network alerts: %w", err)
+	}
+	return alerts, nil
 }
 
 func (s *NetworkService) GetSupportedProtocols() []model.ProtocolType {
@@ -341,3 +566,328 @@ func (s *NetworkService) GetSupportedProtocols() []model.ProtocolType {
 	}
 	return protocols
 }
+
+// setConfig stores config on the network without its credentials, which are
+// written to the network's credentials secret. Credentials the config leaves
+// out are kept, unless clear is set.
+func (s *NetworkService) setConfig(tx *gorm.DB, network *model.Network, config *model.ProtocolConfig, clear bool, userID uuid.UUID) error {
+	if err := network.SetProtocolConfig(config.Redacted()); err != nil {
+		return fmt.Errorf("failed to set protocol config: %w", err)
+	}
+
+	var creds model.NetworkCredentials
+	if config != nil {
+		creds = config.Credentials()
+	}
+	if clear && network.CredentialsID != nil {
+		if err := s.secretService.deleteInternal(tx, *network.CredentialsID); err != nil {
+			return err
+		}
+		network.CredentialsID = nil
+	}
+	if creds.IsEmpty() {
+		return nil
+	}
+	if s.secretService == nil {
+		return ErrNetworkCredentialsUnavailable
+	}
+
+	if network.CredentialsID != nil {
+		stored, err := s.credentials(network)
+		if err != nil {
+			return err
+		}
+		if creds.Password == "" {
+			creds.Password = stored.Password
+		}
+		if creds.PrivateKey == "" {
+			creds.PrivateKey = stored.PrivateKey
+		}
+	}
+
+	value, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	secretID, err := s.secretService.storeInternal(tx, network.CredentialsID, "network/"+network.Name, string(value), userID)
+	if err != nil {
+		return fmt.Errorf("failed to store network credentials: %w", err)
+	}
+	network.CredentialsID = &secretID
+	return nil
+}
+
+// protocolConfig returns the network's config with its credentials read back
+// from the vault.
+func (s *NetworkService) protocolConfig(network *model.Network) (*model.ProtocolConfig, error) {
+	config, err := network.GetProtocolConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get protocol config: %w", err)
+	}
+	if config == nil {
+		return nil, ErrNetworkConfigRequired
+	}
+
+	creds, err := s.credentials(network)
+	if err != nil {
+		return nil, err
+	}
+	config.SetCredentials(creds)
+	return config, nil
+}
+
+// pinHostKey trusts the SSH host key a stored network presents on first
+// use: before credentials go out to an unpinned host, its fingerprint is
+// read and saved as the host_key_fingerprint option, so every later check
+// refuses a different key. Nothing is pinned when the key cannot be read;
+// the check then fails on its own.
+func (s *NetworkService) pinHostKey(network *model.Network, config *model.ProtocolConfig) {
+	if !usesSSH(network.Type, config) || protocolOption(config, "host_key_fingerprint", "") != "" {
+		return
+	}
+	fingerprint, err := readSSHHostKey(config)
+	if err != nil {
+		return
+	}
+
+	stored, err := network.GetProtocolConfig()
+	if err != nil || stored == nil {
+		return
+	}
+	previous := network.Config
+	if stored.Options == nil {
+		stored.Options = make(map[string]interface{})
+	}
+	stored.Options["host_key_fingerprint"] = fingerprint
+	if err := network.SetProtocolConfig(stored); err != nil {
+		return
+	}
+	// Only pin the config the key was read for, not one updated meanwhile
+	result := s.db.Model(&model.Network{}).Where("id = ? AND config = ?", network.ID, previous).Update("config", network.Config)
+	if result.Error != nil {
+		log.Printf("⚠️  Failed to pin SSH host key of network %q: %v", network.Name, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		return
+	}
+
+	if config.Options == nil {
+		config.Options = make(map[string]interface{})
+	}
+	config.Options["host_key_fingerprint"] = fingerprint
+	log.Printf("🔑 Pinned SSH host key %s of network %q", fingerprint, network.Name)
+}
+
+// keepHostKeyPin carries the pinned host key over to an updated config for
+// the same host and port that leaves it out, so editing a network does not
+// open trust on first use again. A config naming a fingerprint replaces it.
+func keepHostKeyPin(previous, config *model.ProtocolConfig) {
+	if previous == nil || config == nil || previous.Host != config.Host || previous.Port != config.Port {
+		return
+	}
+	pinned := protocolOption(previous, "host_key_fingerprint", "")
+	if pinned == "" || protocolOption(config, "host_key_fingerprint", "") != "" {
+		return
+	}
+	if config.Options == nil {
+		config.Options = make(map[string]interface{})
+	}
+	config.Options["host_key_fingerprint"] = pinned
+}
+
+// sameProtocolConfig reports whether config describes stored, counting
+// credentials config leaves out as the stored ones.
+func sameProtocolConfig(config, stored *model.ProtocolConfig) bool {
+	a, b := *config, *stored
+	a.SetCredentials(b.Credentials())
+	for _, c := range []*model.ProtocolConfig{&a, &b} {
+		if len(c.Headers) == 0 {
+			c.Headers = nil
+		}
+		if len(c.Options) == 0 {
+			c.Options = nil
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func (s *NetworkService) credentials(network *model.Network) (model.NetworkCredentials, error) {
+	var creds model.NetworkCredentials
+	if network.CredentialsID == nil {
+		return creds, nil
+	}
+	if s.secretService == nil {
+		return creds, ErrNetworkCredentialsUnavailable
+	}
+
+	value, err := s.secretService.readInternal(*network.CredentialsID)
+	if err != nil {
+		if errors.Is(err, ErrSecretNotFound) {
+			return creds, ErrNetworkCredentialsNotFound
+		}
+		return creds, fmt.Errorf("failed to read network credentials: %w", err)
+	}
+	if err := json.Unmarshal([]byte(value), &creds); err != nil {
+		return creds, fmt.Errorf("failed to read network credentials: %w", err)
+	}
+	return creds, nil
+}
+
+// probe runs the status check of the network's protocol with its stored
+// config and credentials.
+func (s *NetworkService) probe(network *model.Network) (*model.ProtocolStatus, error) {
+	client, exists := s.clients[network.Type]
+	if !exists {
+		return nil, fmt.Errorf("protocol %s not supported", network.Type)
+	}
+
+	config, err := s.protocolConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	s.pinHostKey(network, config)
+
+	status, err := client.GetStatus(config)
+	if err != nil {
+		return nil, err
+	}
+	// TCPClient serves several protocols and reports them as custom
+	status.Protocol = network.Type
+	return status, nil
+}
+
+// recordCheck stores status as a check of the network and updates its
+// health: the network goes inactive once its failures in a row reach the
+// threshold and active again on the next success, raising an alert each
+// way. A certificate expiring within the warning raises one alert, re-armed
+// when the certificate changes.
+func (s *NetworkService) recordCheck(id uint, status *model.ProtocolStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	network, err := s.GetNetwork(id)
+	if err != nil {
+		return err
+	}
+
+	checkedAt := status.LastCheck
+	success := status.Status == model.NetworkStatusActive
+	check := &model.NetworkCheck{
+		NetworkID:            network.ID,
+		Success:              success,
+		Status:               status.Status,
+		Latency:              status.Latency,
+		Message:              status.Message,
+		CertificateExpiresAt: status.CertificateExpiresAt,
+		CheckedAt:            checkedAt,
+	}
+
+	updates := map[string]interface{}{"last_check_at": checkedAt}
+	var alerts []model.NetworkAlert
+	alert := func(event, message string) {
+		alerts = append(alerts, model.NetworkAlert{
+			NetworkID:   network.ID,
+			NetworkName: network.Name,
+			Event:       event,
+			Message:     message,
+			CreatedAt:   checkedAt,
+		})
+	}
+
+	if success {
+		if network.Status == model.NetworkStatusInactive {
+			alert(model.NetworkAlertRecovered, fmt.Sprintf("%s is back up: %s", network.Name, status.Message))
+		}
+		updates["status"] = model.NetworkStatusActive
+		updates["consecutive_failures"] = 0
+	} else {
+		failures := network.ConsecutiveFailures + 1
+		if failures >= s.failureThreshold(network) && network.Status != model.NetworkStatusInactive {
+			alert(model.NetworkAlertDown, fmt.Sprintf("%s is down: %s", network.Name, status.Message))
+			updates["status"] = model.NetworkStatusInactive
+		}
+		updates["consecutive_failures"] = failures
+	}
+
+	if expiresAt := status.CertificateExpiresAt; expiresAt != nil {
+		notifiedAt := network.CertificateNotifiedAt
+		if network.CertificateExpiresAt == nil || !network.CertificateExpiresAt.Equal(*expiresAt) {
+			updates["certificate_expires_at"] = *expiresAt
+			updates["certificate_notified_at"] = nil
+			notifiedAt = nil
+		}
+
+		warning := time.Duration(s.config.CertificateWarning) * time.Second
+		if warning > 0 && notifiedAt == nil && expiresAt.Sub(checkedAt) <= warning {
+			alert(model.NetworkAlertCertificateExpiring, fmt.Sprintf("certificate of %s expires at %s", network.Name, expiresAt.Format(time.RFC3339)))
+			updates["certificate_notified_at"] = checkedAt
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(network).UpdateColumns(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(check).Error; err != nil {
+			return err
+		}
+		if len(alerts) > 0 {
+			return tx.Create(&alerts).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record network check: %w", err)
+	}
+
+	for i := range alerts {
+		s.raiseAlert(&alerts[i])
+	}
+	return nil
+}
+
+// raiseAlert sends a stored alert to the audit log and the notifier.
+func (s *NetworkService) raiseAlert(alert *model.NetworkAlert) {
+	if s.auditService != nil {
+		s.auditService.LogAnonymousAction(alert.Event, "network", strconv.FormatUint(uint64(alert.NetworkID), 10), "", "", true, alert.Message)
+	}
+	if s.notifier != nil {
+		if err := s.notifier.NotifyNetworkAlert(alert); err != nil {
+			log.Printf("⚠️  Network alert notification failed for %q: %v", alert.NetworkName, err)
+		}
+	}
+}
+
+func (s *NetworkService) checkInterval(network *model.Network) time.Duration {
+	if network.CheckInterval > 0 {
+		return time.Duration(network.CheckInterval) * time.Second
+	}
+	return time.Duration(s.config.CheckInterval) * time.Second
+}
+
+func (s *NetworkService) failureThreshold(network *model.Network) int {
+	if network.FailureThreshold > 0 {
+		return network.FailureThreshold
+	}
+	return s.config.FailureThreshold
+}
+
+func (s *NetworkService) logAction(userID uuid.UUID, action string, network *model.Network) {
+	if s.auditService == nil {
+		return
+	}
+	details := ""
+	if network.CredentialsID != nil {
+		details = "credentials=" + network.CredentialsID.String()
+	}
+	s.auditService.LogAction(userID, action, "network", strconv.FormatUint(uint64(network.ID), 10), true, details)
+}
+
+var (
+	ErrNetworkNotFound               = errors.New("network not found")
+	ErrNetworkConfigRequired         = errors.New("protocol config is required")
+	ErrNetworkConfigMismatch         = errors.New("protocol type and config must match the stored network")
+	ErrNetworkCredentialsNotFound    = errors.New("network credentials secret not found")
+	ErrNetworkCredentialsUnavailable = errors.New("network credentials cannot be stored without a database")
+)
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/skygenesisenterprise/aether-vault/server/src/config"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNetworkPinsSSHHostKeyOnFirstUse(t *testing.T) {
+	_, userKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSigner, err := ssh.NewSignerFromKey(userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, fingerprint := testSSHServer(t, userSigner.PublicKey())
+
+	s := NewNetworkService(newTestVaultDB(t), nil, &config.NetworkConfig{}, nil)
+	network, err := s.CreateNetwork(&model.NetworkRequest{
+		Name:   "bastion",
+		Type:   model.ProtocolSSH,
+		Config: &model.ProtocolConfig{Host: "127.0.0.1", Port: port, Username: "alice", Timeout: 5},
+	}, uuid.New())
+	if err != nil {
+		t.Fatalf("create network: %v", err)
+	}
+
+	storedPin := func() string {
+		t.Helper()
+		stored, err := s.GetNetwork(network.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := stored.GetProtocolConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return protocolOption(config, "host_key_fingerprint", "")
+	}
+
+	if _, err := s.TestProtocol(&model.ProtocolTestRequest{Type: model.ProtocolSSH, NetworkID: &network.ID}); err != nil {
+		t.Fatalf("test network: %v", err)
+	}
+	if pin := storedPin(); pin != fingerprint {
+		t.Fatalf("pinned %q after first use, want %q", pin, fingerprint)
+	}
+
+	// An edit leaving the fingerprint out keeps the pin
+	if _, err := s.UpdateNetwork(network.ID, &model.NetworkRequest{
+		Name:   "bastion",
+		Type:   model.ProtocolSSH,
+		Config: &model.ProtocolConfig{Host: "127.0.0.1", Port: port, Username: "root", Timeout: 5},
+	}, uuid.New()); err != nil {
+		t.Fatalf("update network: %v", err)
+	}
+	if pin := storedPin(); pin != fingerprint {
+		t.Fatalf("pin %q after update, want %q", pin, fingerprint)
+	}
+
+	if _, err := s.TestProtocol(&model.ProtocolTestRequest{
+		Type:      model.ProtocolSSH,
+		NetworkID: &network.ID,
+		Config:    &model.ProtocolConfig{Host: "127.0.0.1", Port: port, Username: "root", Timeout: 5},
+	}); err != ErrNetworkConfigMismatch {
+		t.Fatalf("config without the pin: got %v, want %v", err, ErrNetworkConfigMismatch)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"net/http"
+	"time"
+)
+
+// ExpiryNotifier is told when secrets are about to expire or have expired,
+// so owners can rotate them in time.
+type ExpiryNotifier interface {
+	NotifySecretExpiry(event *model.SecretExpiryEvent) error
+}
+
+// NetworkAlertNotifier is told when a monitored network goes down, recovers
+// or serves a certificate close to expiry.
+type NetworkAlertNotifier interface {
+	NotifyNetworkAlert(alert *model.NetworkAlert) error
+}
+
+// WebhookNotifier posts expiry events and network alerts as JSON to a
+// configured URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (n *WebhookNotifier) NotifySecretExpiry(event *model.SecretExpiryEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode expiry event: %w", err)
+	}
+	if err := n.post(body); err != nil {
+		return fmt.Errorf("expiry webhook: %w", err)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) NotifyNetworkAlert(alert *model.NetworkAlert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode network alert: %w", err)
+	}
+	if err := n.post(body); err != nil {
+		return fmt.Errorf("network alert webhook: %w", err)
+	}
+	return nil
+}
+
+func (n *WebhookNotifier) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "aether-vault")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,1196 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ocsp"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPKIRootTTL         = 10 * 365 * 24 * time.Hour
+	defaultPKIIntermediateTTL = 5 * 365 * 24 * time.Hour
+	defaultPKIRoleTTL         = 24 * time.Hour
+	defaultPKIRoleMaxTTL      = 30 * 24 * time.Hour
+
+	// pkiBackdate covers clock skew between the vault and relying parties.
+	pkiBackdate = 30 * time.Second
+
+	pkiOCSPValidity = time.Hour
+)
+
+// PKIService is the PKI secrets engine. It holds one signing CA, either
+// generated here or imported, issues certificates and signs CSRs within the
+// limits of a role, and publishes revocations as a CRL and over OCSP.
+type PKIService struct {
+	db        *gorm.DB
+	keyring   *KeyringService
+	baseURL   string
+	crlExpiry time.Duration
+
+	mutex         sync.Mutex
+	crl           []byte
+	crlNextUpdate time.Time
+}
+
+// NewPKIService creates the engine; when baseURL is set, issued certificates
+// point relying parties at its CA, CRL and OCSP endpoints.
+func NewPKIService(db *gorm.DB, keyring *KeyringService, baseURL string, crlExpiry time.Duration) *PKIService {
+	return &PKIService{
+		db:        db,
+		keyring:   keyring,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		crlExpiry: crlExpiry,
+	}
+}
+
+// GenerateRoot creates a self-signed root CA.
+func (s *PKIService) GenerateRoot(req *model.PKIGenerateCARequest, userID uuid.UUID) (*model.PKICAResponse, error) {
+	if err := s.ensureNoCA(); err != nil {
+		return nil, err
+	}
+
+	keyType, key, err := generatePKIKey(req.KeyType, req.KeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(req.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultPKIRootTTL
+	}
+	serial, err := newPKISerial()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkiSubject(req.CommonName, req.Organization),
+		NotBefore:             now.Add(-pkiBackdate),
+		NotAfter:              now.Add(ttl),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create root certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse root certificate: %w", err)
+	}
+
+	ca := &model.PKICA{
+		Type:       model.PKICATypeRoot,
+		CommonName: req.CommonName,
+		KeyType:    keyType,
+		CreatedBy:  &userID,
+	}
+	setPKICACertificate(ca, cert, "")
+	if err := s.sealCAKey(ca, key); err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ca).Error; err != nil {
+			return err
+		}
+		return tx.Create(pkiCertificateRecord(cert, "", &userID)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store root CA: %w", err)
+	}
+
+	s.invalidateCRL()
+	return &model.PKICAResponse{
+		Certificate:  ca.Certificate,
+		SerialNumber: ca.Serial,
+		Expiration:   cert.NotAfter.Unix(),
+	}, nil
+}
+
+// GenerateIntermediate creates the key of an intermediate CA and returns its
+// CSR; the CA stays pending until SetSignedIntermediate.
+func (s *PKIService) GenerateIntermediate(req *model.PKIGenerateCARequest, userID uuid.UUID) (*model.PKICAResponse, error) {
+	if err := s.ensureNoCA(); err != nil {
+		return nil, err
+	}
+
+	keyType, key, err := generatePKIKey(req.KeyType, req.KeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkiSubject(req.CommonName, req.Organization),
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create intermediate CSR: %w", err)
+	}
+
+	ca := &model.PKICA{
+		Type:       model.PKICATypeIntermediate,
+		CommonName: req.CommonName,
+		CSR:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: der})),
+		KeyType:    keyType,
+		Pending:    true,
+		CreatedBy:  &userID,
+	}
+	if err := s.sealCAKey(ca, key); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(ca).Error; err != nil {
+		return nil, fmt.Errorf("failed to store intermediate CA: %w", err)
+	}
+
+	return &model.PKICAResponse{CSR: ca.CSR}, nil
+}
+
+// SetSignedIntermediate completes the pending intermediate with the
+// certificate its parent CA signed.
+func (s *PKIService) SetSignedIntermediate(bundle string) (*model.PKICAResponse, error) {
+	ca, err := s.getCA()
+	if err != nil {
+		return nil, err
+	}
+	if !ca.Pending {
+		return nil, ErrPKINoPendingIntermediate
+	}
+
+	certs, _, err := parsePEMBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%w: no certificate found", ErrInvalidPKIBundle)
+	}
+
+	key, err := s.openCAKey(ca)
+	if err != nil {
+		return nil, err
+	}
+	cert := certs[0]
+	if !cert.IsCA || !pkiPublicKeysEqual(cert.PublicKey, key.Public()) {
+		return nil, fmt.Errorf("%w: certificate is not a CA for the pending key", ErrInvalidPKIBundle)
+	}
+
+	setPKICACertificate(ca, cert, encodeCertificates(certs[1:]))
+	ca.Pending = false
+	ca.CSR = ""
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(ca).Error; err != nil {
+			return err
+		}
+		return tx.Create(pkiCertificateRecord(cert, "", ca.CreatedBy)).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store intermediate certificate: %w", err)
+	}
+
+	s.invalidateCRL()
+	return &model.PKICAResponse{
+		Certificate:  ca.Certificate,
+		SerialNumber: ca.Serial,
+		Expiration:   cert.NotAfter.Unix(),
+	}, nil
+}
+
+// ImportCA takes over an existing CA from a PEM bundle holding its
+// certificate, its private key and optionally its issuer chain.
+func (s *PKIService) ImportCA(bundle string, userID uuid.UUID) (*model.PKICAResponse, error) {
+	if err := s.ensureNoCA(); err != nil {
+		return nil, err
+	}
+
+	certs, key, err := parsePEMBundle(bundle)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("%w: no private key found", ErrInvalidPKIBundle)
+	}
+
+	var cert *x509.Certificate
+	var chain []*x509.Certificate
+	for _, c := range certs {
+		if cert == nil && pkiPublicKeysEqual(c.PublicKey, key.Public()) {
+			cert = c
+			continue
+		}
+		chain = append(chain, c)
+	}
+	if cert == nil || !cert.IsCA {
+		return nil, fmt.Errorf("%w: no CA certificate matches the private key", ErrInvalidPKIBundle)
+	}
+
+	ca := &model.PKICA{
+		Type:       model.PKICATypeIntermediate,
+		CommonName: cert.Subject.CommonName,
+		KeyType:    pkiKeyType(key),
+		CreatedBy:  &userID,
+	}
+	if cert.CheckSignatureFrom(cert) == nil {
+		ca.Type = model.PKICATypeRoot
+	}
+	setPKICACertificate(ca, cert, encodeCertificates(chain))
+	if err := s.sealCAKey(ca, key); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(ca).Error; err != nil {
+		return nil, fmt.Errorf("failed to store CA: %w", err)
+	}
+
+	s.invalidateCRL()
+	return &model.PKICAResponse{
+		Certificate:  ca.Certificate,
+		SerialNumber: ca.Serial,
+		Expiration:   cert.NotAfter.Unix(),
+	}, nil
+}
+
+// DeleteCA removes the CA and its key so a new one can be generated or
+// imported. Certificate records are kept.
+func (s *PKIService) DeleteCA() error {
+	result := s.db.Where("1 = 1").Delete(&model.PKICA{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete CA: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPKICANotFound
+	}
+	s.invalidateCRL()
+	return nil
+}
+
+// CA returns the CA certificate and the chain above it.
+func (s *PKIService) CA() (*model.PKICA, error) {
+	ca, err := s.getCA()
+	if err != nil {
+		return nil, err
+	}
+	if ca.Pending {
+		return nil, ErrPKICAPending
+	}
+	return ca, nil
+}
+
+// SignIntermediate signs the CSR of another CA, making this CA its parent.
+func (s *PKIService) SignIntermediate(req *model.PKISignRequest, userID uuid.UUID) (*model.PKICertificateResponse, error) {
+	csr, err := parsePKICSR(req.CSR)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := csr.Subject
+	if req.CommonName != "" {
+		subject.CommonName = req.CommonName
+	}
+	if subject.CommonName == "" {
+		return nil, fmt.Errorf("%w: common name is required", ErrInvalidPKIRequest)
+	}
+
+	ttl := time.Duration(req.TTL) * time.Second
+	if ttl <= 0 {
+		ttl = defaultPKIIntermediateTTL
+	}
+
+	template := &x509.Certificate{
+		Subject:               subject,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return s.sign(template, csr.PublicKey, ttl, "", userID, nil)
+}
+
+// WriteRole creates or replaces a role.
+func (s *PKIService) WriteRole(req *model.PKIRoleRequest) (*model.PKIRole, error) {
+	role := &model.PKIRole{
+		Name:             req.Name,
+		AllowedDomains:   strings.Join(normalizeDomains(req.AllowedDomains), ","),
+		AllowBareDomains: req.AllowBareDomains,
+		AllowSubdomains:  req.AllowSubdomains == nil || *req.AllowSubdomains,
+		AllowAnyName:     req.AllowAnyName,
+		AllowIPSANs:      req.AllowIPSANs,
+		ServerFlag:       req.ServerFlag == nil || *req.ServerFlag,
+		ClientFlag:       req.ClientFlag == nil || *req.ClientFlag,
+		KeyType:          req.KeyType,
+		KeyBits:          req.KeyBits,
+		TTL:              req.TTL,
+		MaxTTL:           req.MaxTTL,
+	}
+	if role.KeyType == "" {
+		role.KeyType = model.PKIKeyTypeRSA
+	}
+	if role.KeyType != model.PKIKeyTypeAny {
+		bits, err := pkiKeyBits(role.KeyType, role.KeyBits)
+		if err != nil {
+			return nil, err
+		}
+		role.KeyBits = bits
+	}
+	if role.TTL < 0 || role.MaxTTL < 0 {
+		return nil, ErrInvalidPKIRoleTTL
+	}
+	if role.MaxTTL == 0 {
+		role.MaxTTL = int(defaultPKIRoleMaxTTL / time.Second)
+	}
+	if role.TTL == 0 {
+		role.TTL = int(defaultPKIRoleTTL / time.Second)
+	}
+	if role.TTL > role.MaxTTL {
+		return nil, ErrInvalidPKIRoleTTL
+	}
+	if !role.AllowAnyName && role.AllowedDomains == "" {
+		return nil, fmt.Errorf("%w: allowed domains are required unless any name is allowed", ErrInvalidPKIRole)
+	}
+
+	var existing model.PKIRole
+	err := s.db.Where("name = ?", req.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		role.ID = existing.ID
+		role.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to update role: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.db.Create(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to create role: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+func (s *PKIService) ListRoles() ([]model.PKIRole, error) {
+	var roles []model.PKIRole
+	if err := s.db.Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *PKIService) GetRole(name string) (*model.PKIRole, error) {
+	var role model.PKIRole
+	if err := s.db.Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPKIRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return &role, nil
+}
+
+func (s *PKIService) DeleteRole(name string) error {
+	result := s.db.Where("name = ?", name).Delete(&model.PKIRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPKIRoleNotFound
+	}
+	return nil
+}
+
+// Issue generates a key and a certificate for it within the role's limits.
+func (s *PKIService) Issue(roleName string, req *model.PKIIssueRequest, userID uuid.UUID) (*model.PKICertificateResponse, error) {
+	role, err := s.GetRole(roleName)
+	if err != nil {
+		return nil, err
+	}
+	if role.KeyType == model.PKIKeyTypeAny {
+		return nil, fmt.Errorf("%w: roles allowing any key type can only sign CSRs", ErrInvalidPKIRequest)
+	}
+
+	template, err := pkiLeafTemplate(role, req.CommonName, req.AltNames, req.IPSANs)
+	if err != nil {
+		return nil, err
+	}
+
+	keyType, key, err := generatePKIKey(role.KeyType, role.KeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.sign(template, key.Public(), roleTTL(role, req.TTL), role.Name, userID, role)
+	if err != nil {
+		return nil, err
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode private key: %w", err)
+	}
+	resp.PrivateKey = string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	resp.PrivateKeyType = keyType
+	return resp, nil
+}
+
+// Sign signs a CSR within the role's limits. Names given in the request
+// replace the ones in the CSR.
+func (s *PKIService) Sign(roleName string, req *model.PKISignRequest, userID uuid.UUID) (*model.PKICertificateResponse, error) {
+	role, err := s.GetRole(roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	csr, err := parsePKICSR(req.CSR)
+	if err != nil {
+		return nil, err
+	}
+	if role.KeyType != model.PKIKeyTypeAny && pkiKeyType(csr.PublicKey) != role.KeyType {
+		return nil, fmt.Errorf("%w: role requires %s keys", ErrInvalidPKIRequest, role.KeyType)
+	}
+
+	commonName, altNames, ipSANs := req.CommonName, req.AltNames, req.IPSANs
+	if commonName == "" {
+		commonName = csr.Subject.CommonName
+	}
+	if len(altNames) == 0 {
+		altNames = csr.DNSNames
+	}
+	if len(ipSANs) == 0 {
+		for _, ip := range csr.IPAddresses {
+			ipSANs = append(ipSANs, ip.String())
+		}
+	}
+
+	template, err := pkiLeafTemplate(role, commonName, altNames, ipSANs)
+	if err != nil {
+		return nil, err
+	}
+	return s.sign(template, csr.PublicKey, roleTTL(role, req.TTL), role.Name, userID, role)
+}
+
+// Revoke marks a certificate revoked and refreshes the CRL. Revoking a
+// certificate twice returns the original revocation time.
+func (s *PKIService) Revoke(serial string) (*model.PKIRevokeResponse, error) {
+	record, err := s.GetCertificate(serial)
+	if err != nil {
+		return nil, err
+	}
+	if record.RevokedAt != nil {
+		return &model.PKIRevokeResponse{RevocationTime: *record.RevokedAt}, nil
+	}
+
+	if ca, err := s.getCA(); err == nil && ca.Serial == record.Serial {
+		return nil, ErrPKICannotRevokeCA
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.db.Model(&model.PKICertificate{}).Where("serial = ?", record.Serial).
+		Update("revoked_at", now).Error; err != nil {
+		return nil, fmt.Errorf("failed to revoke certificate: %w", err)
+	}
+
+	s.invalidateCRL()
+	return &model.PKIRevokeResponse{RevocationTime: now}, nil
+}
+
+// ListCertificates returns certificate records, newest first, optionally
+// only those of one role or only revoked ones.
+func (s *PKIService) ListCertificates(role string, revoked bool) ([]model.PKICertificate, error) {
+	query := s.db.Model(&model.PKICertificate{})
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if revoked {
+		query = query.Where("revoked_at IS NOT NULL")
+	}
+
+	var certs []model.PKICertificate
+	if err := query.Order("created_at DESC").Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// GetCertificate looks a certificate up by serial, with or without colons.
+func (s *PKIService) GetCertificate(serial string) (*model.PKICertificate, error) {
+	normalized, err := normalizePKISerial(serial)
+	if err != nil {
+		return nil, err
+	}
+
+	var record model.PKICertificate
+	if err := s.db.Where("serial = ?", normalized).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPKICertificateNotFound
+		}
+		return nil, fmt.Errorf("failed to get certificate: %w", err)
+	}
+	return &record, nil
+}
+
+// CRL returns the DER-encoded CRL, rebuilding it after a revocation and
+// once half of its validity has passed.
+func (s *PKIService) CRL() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.crl != nil && time.Now().Before(s.crlNextUpdate.Add(-s.crlExpiry/2)) {
+		return s.crl, nil
+	}
+
+	cert, key, err := s.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []model.PKICertificate
+	if err := s.db.Where("revoked_at IS NOT NULL AND not_after > ?", time.Now()).
+		Order("revoked_at ASC").Find(&revoked).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revoked certificates: %w", err)
+	}
+
+	entries := make([]x509.RevocationListEntry, 0, len(revoked))
+	for _, record := range revoked {
+		serial, err := parsePKISerial(record.Serial)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, x509.RevocationListEntry{
+			SerialNumber:   serial,
+			RevocationTime: *record.RevokedAt,
+		})
+	}
+
+	now := time.Now()
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		RevokedCertificateEntries: entries,
+		// Generation time keeps the CRL number increasing across restarts
+		Number:     big.NewInt(now.UnixNano()),
+		ThisUpdate: now,
+		NextUpdate: now.Add(s.crlExpiry),
+	}, cert, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRL: %w", err)
+	}
+
+	s.crl, s.crlNextUpdate = crl, now.Add(s.crlExpiry)
+	return crl, nil
+}
+
+// OCSP answers a DER-encoded OCSP request for a certificate of this CA.
+func (s *PKIService) OCSP(request []byte) ([]byte, error) {
+	req, err := ocsp.ParseRequest(request)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidOCSPRequest, err)
+	}
+
+	cert, key, err := s.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC().Truncate(time.Minute)
+	template := ocsp.Response{
+		Status:       ocsp.Unknown,
+		SerialNumber: req.SerialNumber,
+		ThisUpdate:   now,
+		NextUpdate:   now.Add(pkiOCSPValidity),
+	}
+
+	issuerKeyHash, err := pkiIssuerKeyHash(cert, req.HashAlgorithm)
+	if err == nil && bytes.Equal(issuerKeyHash, req.IssuerKeyHash) {
+		record, err := s.GetCertificate(formatPKISerial(req.SerialNumber))
+		switch {
+		case err == nil && record.RevokedAt != nil:
+			template.Status = ocsp.Revoked
+			template.RevokedAt = *record.RevokedAt
+			template.RevocationReason = ocsp.Unspecified
+		case err == nil:
+			template.Status = ocsp.Good
+		case !errors.Is(err, ErrPKICertificateNotFound):
+			return nil, err
+		}
+	}
+
+	return ocsp.CreateResponse(cert, cert, template, key)
+}
+
+func (s *PKIService) Name() string {
+	return "pki"
+}
+
+func (s *PKIService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.PKICA{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count CA keys pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *PKIService) RewrapKeys(batchSize int) (int, error) {
+	var cas []model.PKICA
+	if err := s.db.Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&cas).Error; err != nil {
+		return 0, fmt.Errorf("failed to find CA keys pending rewrap: %w", err)
+	}
+
+	for i := range cas {
+		ca := &cas[i]
+		dataKey, err := s.keyring.UnwrapDataKey(ca.DataKey, ca.KeyVersion)
+		if err != nil {
+			return i, fmt.Errorf("failed to rewrap CA key %s: %w", ca.ID, err)
+		}
+		if ca.DataKey, ca.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+			return i, fmt.Errorf("failed to rewrap CA key %s: %w", ca.ID, err)
+		}
+
+		if err := s.db.Model(ca).UpdateColumns(map[string]interface{}{
+			"data_key":    ca.DataKey,
+			"key_version": ca.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update CA key %s: %w", ca.ID, err)
+		}
+	}
+
+	return len(cas), nil
+}
+
+// sign completes template with a serial, validity and issuer URLs, signs it
+// with the CA and records the result.
+func (s *PKIService) sign(template *x509.Certificate, publicKey crypto.PublicKey, ttl time.Duration, roleName string, userID uuid.UUID, role *model.PKIRole) (*model.PKICertificateResponse, error) {
+	caCert, key, err := s.signer()
+	if err != nil {
+		return nil, err
+	}
+	ca, err := s.CA()
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newPKISerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template.SerialNumber = serial
+	template.NotBefore = now.Add(-pkiBackdate)
+	template.NotAfter = now.Add(ttl)
+	// A certificate must not outlive the CA that signed it
+	if template.NotAfter.After(caCert.NotAfter) {
+		template.NotAfter = caCert.NotAfter
+	}
+	if s.baseURL != "" {
+		template.IssuingCertificateURL = []string{s.baseURL + "/api/v1/pki/ca"}
+		template.OCSPServer = []string{s.baseURL + "/api/v1/pki/ocsp"}
+		template.CRLDistributionPoints = []string{s.baseURL + "/api/v1/pki/crl"}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, publicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	record := pkiCertificateRecord(cert, roleName, &userID)
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record certificate: %w", err)
+	}
+
+	chain := []string{ca.Certificate}
+	for _, c := range splitPEMCertificates(ca.CAChain) {
+		chain = append(chain, c)
+	}
+	return &model.PKICertificateResponse{
+		Certificate:  record.Certificate,
+		IssuingCA:    ca.Certificate,
+		CAChain:      chain,
+		SerialNumber: record.Serial,
+		Expiration:   cert.NotAfter.Unix(),
+	}, nil
+}
+
+// signer returns the CA certificate and its opened private key.
+func (s *PKIService) signer() (*x509.Certificate, crypto.Signer, error) {
+	ca, err := s.CA()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode([]byte(ca.Certificate))
+	if block == nil {
+		return nil, nil, fmt.Errorf("failed to decode CA certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+
+	key, err := s.openCAKey(ca)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+func (s *PKIService) getCA() (*model.PKICA, error) {
+	var ca model.PKICA
+	if err := s.db.Order("created_at DESC").First(&ca).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPKICANotFound
+		}
+		return nil, fmt.Errorf("failed to get CA: %w", err)
+	}
+	return &ca, nil
+}
+
+func (s *PKIService) ensureNoCA() error {
+	var count int64
+	if err := s.db.Model(&model.PKICA{}).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check CA: %w", err)
+	}
+	if count > 0 {
+		return ErrPKICAExists
+	}
+	return nil
+}
+
+func (s *PKIService) invalidateCRL() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.crl = nil
+}
+
+func (s *PKIService) sealCAKey(ca *model.PKICA, key crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("failed to encode CA key: %w", err)
+	}
+
+	dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sealGCM(dataKey, der)
+	if err != nil {
+		return err
+	}
+
+	ca.PrivateKey, ca.DataKey, ca.KeyVersion = sealed, wrapped, version
+	return nil
+}
+
+func (s *PKIService) openCAKey(ca *model.PKICA) (crypto.Signer, error) {
+	dataKey, err := s.keyring.UnwrapDataKey(ca.DataKey, ca.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	der, err := openGCM(dataKey, ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("CA key cannot sign")
+	}
+	return signer, nil
+}
+
+// pkiLeafTemplate checks the requested names against the role and builds
+// the certificate template for them.
+func pkiLeafTemplate(role *model.PKIRole, commonName string, altNames, ipSANs []string) (*x509.Certificate, error) {
+	if commonName == "" {
+		return nil, fmt.Errorf("%w: common name is required", ErrInvalidPKIRequest)
+	}
+
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: commonName},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		BasicConstraintsValid: true,
+	}
+	if role.ServerFlag {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+	if role.ClientFlag {
+		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+
+	names := append([]string{commonName}, altNames...)
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		// An IP address given as a name is checked like an IP SAN
+		if ip := net.ParseIP(name); ip != nil {
+			ipSANs = append(ipSANs, name)
+			continue
+		}
+		if !pkiNameAllowed(role, name) {
+			return nil, fmt.Errorf("%w: %s", ErrPKINameNotAllowed, name)
+		}
+		template.DNSNames = append(template.DNSNames, name)
+	}
+
+	for _, raw := range ipSANs {
+		ip := net.ParseIP(strings.TrimSpace(raw))
+		if ip == nil {
+			return nil, fmt.Errorf("%w: invalid IP address %q", ErrInvalidPKIRequest, raw)
+		}
+		if !role.AllowIPSANs {
+			return nil, fmt.Errorf("%w: IP SANs are not allowed", ErrPKINameNotAllowed)
+		}
+		template.IPAddresses = append(template.IPAddresses, ip)
+	}
+
+	return template, nil
+}
+
+func pkiNameAllowed(role *model.PKIRole, name string) bool {
+	if role.AllowAnyName {
+		return true
+	}
+	for _, domain := range strings.Split(role.AllowedDomains, ",") {
+		if domain == "" {
+			continue
+		}
+		if name == domain && role.AllowBareDomains {
+			return true
+		}
+		if role.AllowSubdomains && strings.HasSuffix(name, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// roleTTL returns the requested TTL, or the role's when none is given,
+// capped at the role's max TTL.
+func roleTTL(role *model.PKIRole, requested int) time.Duration {
+	ttl := requested
+	if ttl <= 0 {
+		ttl = role.TTL
+	}
+	if ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+	return time.Duration(ttl) * time.Second
+}
+
+func generatePKIKey(keyType string, bits int) (string, crypto.Signer, error) {
+	if keyType == "" {
+		keyType = model.PKIKeyTypeRSA
+	}
+	bits, err := pkiKeyBits(keyType, bits)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var key crypto.Signer
+	switch keyType {
+	case model.PKIKeyTypeRSA:
+		key, err = rsa.GenerateKey(rand.Reader, bits)
+	case model.PKIKeyTypeEC:
+		curve := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}[bits]
+		key, err = ecdsa.GenerateKey(curve, rand.Reader)
+	case model.PKIKeyTypeEd25519:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	}
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate %s key: %w", keyType, err)
+	}
+	return keyType, key, nil
+}
+
+// pkiKeyBits validates the key size of a key type, filling in the default.
+func pkiKeyBits(keyType string, bits int) (int, error) {
+	switch keyType {
+	case model.PKIKeyTypeRSA:
+		if bits == 0 {
+			return 2048, nil
+		}
+		if bits == 2048 || bits == 3072 || bits == 4096 {
+			return bits, nil
+		}
+	case model.PKIKeyTypeEC:
+		if bits == 0 {
+			return 256, nil
+		}
+		if bits == 256 || bits == 384 || bits == 521 {
+			return bits, nil
+		}
+	case model.PKIKeyTypeEd25519:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrInvalidPKIKeyType, keyType)
+	}
+	return 0, fmt.Errorf("%w: %d bits for %s", ErrInvalidPKIKeyType, bits, keyType)
+}
+
+func pkiKeyType(key interface{}) string {
+	switch k := key.(type) {
+	case *rsa.PublicKey, *rsa.PrivateKey:
+		return model.PKIKeyTypeRSA
+	case *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return model.PKIKeyTypeEC
+	case ed25519.PublicKey, ed25519.PrivateKey:
+		return model.PKIKeyTypeEd25519
+	case crypto.Signer:
+		return pkiKeyType(k.Public())
+	default:
+		return ""
+	}
+}
+
+func pkiPublicKeysEqual(a, b crypto.PublicKey) bool {
+	key, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && key.Equal(b)
+}
+
+func pkiSubject(commonName, organization string) pkix.Name {
+	subject := pkix.Name{CommonName: commonName}
+	if organization != "" {
+		subject.Organization = []string{organization}
+	}
+	return subject
+}
+
+func setPKICACertificate(ca *model.PKICA, cert *x509.Certificate, chain string) {
+	notAfter := cert.NotAfter
+	ca.Certificate = encodeCertificates([]*x509.Certificate{cert})
+	ca.CAChain = chain
+	ca.Serial = formatPKISerial(cert.SerialNumber)
+	ca.NotAfter = &notAfter
+}
+
+func pkiCertificateRecord(cert *x509.Certificate, roleName string, userID *uuid.UUID) *model.PKICertificate {
+	return &model.PKICertificate{
+		Serial:      formatPKISerial(cert.SerialNumber),
+		Role:        roleName,
+		CommonName:  cert.Subject.CommonName,
+		Certificate: encodeCertificates([]*x509.Certificate{cert}),
+		IsCA:        cert.IsCA,
+		NotBefore:   cert.NotBefore,
+		NotAfter:    cert.NotAfter,
+		IssuedBy:    userID,
+	}
+}
+
+// parsePEMBundle returns the certificates of a PEM bundle, in order, and its
+// private key if it holds one.
+func parsePEMBundle(bundle string) ([]*x509.Certificate, crypto.Signer, error) {
+	var certs []*x509.Certificate
+	var key crypto.Signer
+
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPKIBundle, err)
+			}
+			certs = append(certs, cert)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			parsed, err := parsePKIPrivateKey(block)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %v", ErrInvalidPKIBundle, err)
+			}
+			key = parsed
+		}
+	}
+
+	if len(certs) == 0 && key == nil {
+		return nil, nil, fmt.Errorf("%w: no PEM data found", ErrInvalidPKIBundle)
+	}
+	return certs, key, nil
+}
+
+func parsePKIPrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key")
+	}
+	return signer, nil
+}
+
+func parsePKICSR(raw string) (*x509.CertificateRequest, error) {
+	block, _ := pem.Decode([]byte(raw))
+	if block == nil || block.Type != "CERTIFICATE REQUEST" && block.Type != "NEW CERTIFICATE REQUEST" {
+		return nil, fmt.Errorf("%w: CSR must be PEM encoded", ErrInvalidPKIRequest)
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPKIRequest, err)
+	}
+	if err := csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: CSR signature is invalid", ErrInvalidPKIRequest)
+	}
+	return csr, nil
+}
+
+func encodeCertificates(certs []*x509.Certificate) string {
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		pem.Encode(&buf, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return buf.String()
+}
+
+func splitPEMCertificates(bundle string) []string {
+	var certs []string
+	rest := []byte(bundle)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return certs
+		}
+		certs = append(certs, string(pem.EncodeToMemory(block)))
+	}
+}
+
+// pkiIssuerKeyHash hashes the CA's public key the way OCSP requests
+// identify their issuer.
+func pkiIssuerKeyHash(cert *x509.Certificate, hash crypto.Hash) ([]byte, error) {
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(cert.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return nil, err
+	}
+	if hash == 0 {
+		hash = crypto.SHA1
+	}
+	if !hash.Available() {
+		return nil, fmt.Errorf("unsupported hash")
+	}
+	if hash == crypto.SHA1 {
+		sum := sha1.Sum(spki.PublicKey.RightAlign())
+		return sum[:], nil
+	}
+	h := hash.New()
+	h.Write(spki.PublicKey.RightAlign())
+	return h.Sum(nil), nil
+}
+
+// newPKISerial returns a random positive 159-bit serial number.
+func newPKISerial() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 159))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serial, nil
+}
+
+// formatPKISerial renders a serial as colon-separated hex bytes.
+func formatPKISerial(serial *big.Int) string {
+	raw := hex.EncodeToString(serial.Bytes())
+	if len(raw)%2 == 1 {
+		raw = "0" + raw
+	}
+	parts := make([]string, 0, len(raw)/2)
+	for i := 0; i < len(raw); i += 2 {
+		parts = append(parts, raw[i:i+2])
+	}
+	return strings.Join(parts, ":")
+}
+
+func parsePKISerial(serial string) (*big.Int, error) {
+	raw := strings.NewReplacer(":", "", "-", "").Replace(strings.TrimSpace(serial))
+	n, ok := new(big.Int).SetString(raw, 16)
+	if !ok || raw == "" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidPKISerial, serial)
+	}
+	return n, nil
+}
+
+func normalizePKISerial(serial string) (string, error) {
+	n, err := parsePKISerial(serial)
+	if err != nil {
+		return "", err
+	}
+	return formatPKISerial(n), nil
+}
+
+func normalizeDomains(domains []string) []string {
+	var result []string
+	for _, domain := range domains {
+		domain = strings.Trim(strings.ToLower(strings.TrimSpace(domain)), ".")
+		if domain != "" {
+			result = append(result, domain)
+		}
+	}
+	return result
+}
+
+var (
+	ErrPKICANotFound            = errors.New("no CA is configured")
+	ErrPKICAExists              = errors.New("a CA is already configured")
+	ErrPKICAPending             = errors.New("intermediate CA is waiting for its signed certificate")
+	ErrPKINoPendingIntermediate = errors.New("no intermediate CA is waiting for a signed certificate")
+	ErrPKICannotRevokeCA        = errors.New("the CA certificate cannot be revoked")
+	ErrPKIRoleNotFound          = errors.New("PKI role not found")
+	ErrPKICertificateNotFound   = errors.New("certificate not found")
+	ErrPKINameNotAllowed        = errors.New("name not allowed by role")
+	ErrInvalidPKIRole           = errors.New("invalid PKI role")
+	ErrInvalidPKIRoleTTL        = errors.New("TTL must not be negative or exceed the max TTL")
+	ErrInvalidPKIKeyType        = errors.New("unsupported key type or size")
+	ErrInvalidPKIRequest        = errors.New("invalid certificate request")
+	ErrInvalidPKIBundle         = errors.New("invalid PEM bundle")
+	ErrInvalidPKISerial         = errors.New("invalid serial number")
+	ErrInvalidOCSPRequest       = errors.New("invalid OCSP request")
+)
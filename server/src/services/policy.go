@@ -1,22 +1,49 @@
 package services
 
 import (
-	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"encoding/json"
 	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/config"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"net"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	PolicyModePermissive = "permissive"
+	PolicyModeStrict     = "strict"
+)
+
+// BootstrapPolicyName is the policy granting everything that is assigned to
+// the bootstrapped administrator.
+const BootstrapPolicyName = "vault-admin"
+
+// enginePolicyPaths are the secrets engines that mint credentials or use
+// keys. Permissive mode does not open them: they need an explicit grant.
+var enginePolicyPaths = []string{"database/**", "pki/**", "ssh/**", "transit/**"}
+
 type PolicyService struct {
-	db *gorm.DB
+	db     *gorm.DB
+	config *config.PolicyConfig
 }
 
-func NewPolicyService(db *gorm.DB) *PolicyService {
-	return &PolicyService{db: db}
+func NewPolicyService(db *gorm.DB, config *config.PolicyConfig) *PolicyService {
+	return &PolicyService{
+		db:     db,
+		config: config,
+	}
 }
 
 func (s *PolicyService) CreatePolicy(policy *model.Policy, userID uuid.UUID) error {
+	if err := ValidatePolicyRules(policy.Rules); err != nil {
+		return err
+	}
+
 	policy.UserID = userID
 
 	if err := s.db.Create(policy).Error; err != nil {
@@ -26,18 +53,9 @@ func (s *PolicyService) CreatePolicy(policy *model.Policy, userID uuid.UUID) err
 	return nil
 }
 
-func (s *PolicyService) GetPoliciesByUserID(userID uuid.UUID) ([]model.Policy, error) {
-	var policies []model.Policy
-	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&policies).Error; err != nil {
-		return nil, fmt.Errorf("failed to get policies: %w", err)
-	}
-
-	return policies, nil
-}
-
-func (s *PolicyService) GetPolicyByID(id uuid.UUID, userID uuid.UUID) (*model.Policy, error) {
+func (s *PolicyService) GetPolicy(id uuid.UUID) (*model.Policy, error) {
 	var policy model.Policy
-	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", id, userID, true).First(&policy).Error; err != nil {
+	if err := s.db.Where("id = ?", id).First(&policy).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, ErrPolicyNotFound
 		}
@@ -47,7 +65,53 @@ func (s *PolicyService) GetPolicyByID(id uuid.UUID, userID uuid.UUID) (*model.Po
 	return &policy, nil
 }
 
+func (s *PolicyService) ListPolicies(filter *model.ListPoliciesRequest) ([]model.Policy, int64, error) {
+	query := s.db.Model(&model.Policy{})
+	if filter.Name != "" {
+		query = query.Where("name LIKE ?", "%"+filter.Name+"%")
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count policies: %w", err)
+	}
+
+	var policies []model.Policy
+	if err := query.Order("priority DESC, name ASC").Limit(filter.Limit).Offset(filter.Offset).Find(&policies).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	return policies, total, nil
+}
+
+// GetPoliciesByUserID returns the active policies assigned to the user,
+// directly or through one of their groups.
+func (s *PolicyService) GetPoliciesByUserID(userID uuid.UUID) ([]model.Policy, error) {
+	var policies []model.Policy
+	err := s.db.
+		Joins("JOIN policy_assignments ON policy_assignments.policy_id = policies.id").
+		Where("policies.is_active = ? AND policy_assignments.is_active = ?", true, true).
+		Where("((policy_assignments.identity_type = ? AND policy_assignments.identity_id = ?) OR "+
+			"(policy_assignments.identity_type = ? AND policy_assignments.identity_id IN (SELECT CAST(group_id AS TEXT) FROM user_groups WHERE user_id = ?)))",
+			model.IdentityTypeUser, userID.String(), model.IdentityTypeGroup, userID).
+		Distinct().
+		Order("policies.priority DESC").
+		Find(&policies).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policies: %w", err)
+	}
+
+	return policies, nil
+}
+
 func (s *PolicyService) UpdatePolicy(policy *model.Policy) error {
+	if err := ValidatePolicyRules(policy.Rules); err != nil {
+		return err
+	}
+
 	if err := s.db.Save(policy).Error; err != nil {
 		return fmt.Errorf("failed to update policy: %w", err)
 	}
@@ -55,33 +119,489 @@ func (s *PolicyService) UpdatePolicy(policy *model.Policy) error {
 	return nil
 }
 
-func (s *PolicyService) DeletePolicy(id uuid.UUID, userID uuid.UUID) error {
-	if err := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Policy{}).Error; err != nil {
-		return fmt.Errorf("failed to delete policy: %w", err)
+func (s *PolicyService) SetPolicyActive(id uuid.UUID, active bool) error {
+	result := s.db.Model(&model.Policy{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update policy: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrPolicyNotFound
 	}
 
 	return nil
 }
 
+func (s *PolicyService) DeletePolicy(id uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", id).Delete(&model.Policy{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete policy: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrPolicyNotFound
+		}
+
+		if err := tx.Where("policy_id = ?", id).Delete(&model.PolicyAssignment{}).Error; err != nil {
+			return fmt.Errorf("failed to delete policy assignments: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *PolicyService) CreateAssignment(assignment *model.PolicyAssignment) error {
+	switch assignment.IdentityType {
+	case "":
+		assignment.IdentityType = model.IdentityTypeUser
+	case model.IdentityTypeUser, model.IdentityTypeGroup:
+	default:
+		return ErrInvalidIdentityType
+	}
+
+	if assignment.IdentityType == model.IdentityTypeUser {
+		if _, err := uuid.Parse(assignment.IdentityID); err != nil {
+			return ErrInvalidIdentityType
+		}
+	} else {
+		// Group assignments are stored by group ID so renames keep them.
+		group, err := resolveGroup(s.db, assignment.IdentityID)
+		if err != nil {
+			return err
+		}
+		assignment.IdentityID = group.ID.String()
+	}
+
+	if _, err := s.GetPolicy(assignment.PolicyID); err != nil {
+		return err
+	}
+
+	var existing int64
+	if err := s.db.Model(&model.PolicyAssignment{}).
+		Where("policy_id = ? AND identity_type = ? AND identity_id = ?", assignment.PolicyID, assignment.IdentityType, assignment.IdentityID).
+		Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check policy assignment: %w", err)
+	}
+	if existing > 0 {
+		return ErrAssignmentExists
+	}
+
+	if err := s.db.Create(assignment).Error; err != nil {
+		return fmt.Errorf("failed to create policy assignment: %w", err)
+	}
+
+	return nil
+}
+
+func (s *PolicyService) DeleteAssignment(id uuid.UUID) error {
+	result := s.db.Where("id = ?", id).Delete(&model.PolicyAssignment{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete policy assignment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrAssignmentNotFound
+	}
+
+	return nil
+}
+
+func (s *PolicyService) ListAssignments(filter *model.ListPolicyAssignmentsRequest) ([]model.PolicyAssignment, int64, error) {
+	query := s.db.Model(&model.PolicyAssignment{})
+	if filter.IdentityType != "" {
+		query = query.Where("identity_type = ?", filter.IdentityType)
+	}
+	if filter.IdentityID != "" {
+		query = query.Where("identity_id = ?", filter.IdentityID)
+	}
+	if filter.PolicyID != "" {
+		query = query.Where("policy_id = ?", filter.PolicyID)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count policy assignments: %w", err)
+	}
+
+	var assignments []model.PolicyAssignment
+	if err := query.Order("created_at DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&assignments).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list policy assignments: %w", err)
+	}
+
+	return assignments, total, nil
+}
+
 func (s *PolicyService) CheckAccess(userID uuid.UUID, resource, action string) (bool, error) {
-	policies, err := s.GetPoliciesByUserID(userID)
+	decision, err := s.Evaluate(userID, &model.AccessRequest{
+		Path:       resource,
+		Capability: model.PolicyCapability(action),
+		Time:       time.Now(),
+	})
 	if err != nil {
 		return false, err
 	}
 
+	return decision.Allowed, nil
+}
+
+func (s *PolicyService) Evaluate(userID uuid.UUID, req *model.AccessRequest) (*model.PolicyDecision, error) {
+	policies, err := s.GetPoliciesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.evaluatePolicies(policies, req), nil
+}
+
+func (s *PolicyService) Check(userID uuid.UUID, req *model.AccessRequest) (*model.PolicyCheckResponse, error) {
+	policies, err := s.GetPoliciesByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	decision := s.evaluatePolicies(policies, req)
+
+	names := make([]string, 0, len(policies))
 	for _, policy := range policies {
-		if s.evaluatePolicy(policy.Rules, resource, action) {
-			return true, nil
+		names = append(names, policy.Name)
+	}
+
+	return &model.PolicyCheckResponse{
+		Allowed:    decision.Allowed,
+		Reason:     decision.Reason,
+		Policies:   names,
+		PolicyID:   decision.PolicyID,
+		PolicyName: decision.PolicyName,
+		Rule:       decision.Rule,
+		Evaluated:  req.Time,
+	}, nil
+}
+
+// evaluatePolicies applies deny-overrides: any matching rule carrying the deny
+// capability wins over every grant, regardless of policy order.
+func (s *PolicyService) evaluatePolicies(policies []model.Policy, req *model.AccessRequest) *model.PolicyDecision {
+	var granted *model.PolicyDecision
+
+	for i := range policies {
+		policy := &policies[i]
+		document, err := policy.GetDocument()
+		if err != nil {
+			continue
 		}
+
+		for j := range document.Rules {
+			rule := &document.Rules[j]
+			if !s.ruleMatches(rule, req) {
+				continue
+			}
+
+			if rule.HasCapability(model.CapabilityDeny) {
+				return &model.PolicyDecision{
+					Allowed:    false,
+					Reason:     "explicitly denied by policy",
+					PolicyID:   &policy.ID,
+					PolicyName: policy.Name,
+					Rule:       rule,
+				}
+			}
+
+			if granted == nil && rule.HasCapability(req.Capability) {
+				granted = &model.PolicyDecision{
+					Allowed:    true,
+					Reason:     "granted by policy",
+					PolicyID:   &policy.ID,
+					PolicyName: policy.Name,
+					Rule:       rule,
+				}
+			}
+		}
+	}
+
+	if granted != nil {
+		return granted
+	}
+
+	if len(policies) == 0 && s.config.Mode != PolicyModeStrict {
+		if isEnginePolicyPath(req.Path) {
+			return &model.PolicyDecision{
+				Allowed: false,
+				Reason:  "secrets engines require an explicit policy grant",
+			}
+		}
+		return &model.PolicyDecision{
+			Allowed: true,
+			Reason:  "no policies assigned (permissive mode)",
+		}
+	}
+
+	return &model.PolicyDecision{
+		Allowed: false,
+		Reason:  "no matching rule grants this capability",
 	}
+}
+
+// EnsureAdminPolicy assigns the bootstrap policy, creating it if needed, to
+// admin so the first administrator can use the secrets engines.
+func (s *PolicyService) EnsureAdminPolicy(admin *model.User) (*model.Policy, error) {
+	var policy model.Policy
+	err := s.db.Where("name = ?", BootstrapPolicyName).First(&policy).Error
+	if err == gorm.ErrRecordNotFound {
+		document := model.PolicyDocument{
+			Rules: []model.PolicyRule{{
+				Path: "**",
+				Capabilities: []model.PolicyCapability{
+					model.CapabilityCreate,
+					model.CapabilityRead,
+					model.CapabilityUpdate,
+					model.CapabilityDelete,
+					model.CapabilityList,
+				},
+			}},
+		}
+		rules, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		policy = model.Policy{
+			Name:        BootstrapPolicyName,
+			Description: "Full access for the bootstrapped administrator",
+			Rules:       string(rules),
+			IsActive:    true,
+		}
+		if err := s.CreatePolicy(&policy, admin.ID); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get bootstrap policy: %w", err)
+	}
+
+	assignment := &model.PolicyAssignment{
+		PolicyID:     policy.ID,
+		IdentityType: model.IdentityTypeUser,
+		IdentityID:   admin.ID.String(),
+		IsActive:     true,
+		CreatedBy:    admin.ID,
+	}
+	if err := s.CreateAssignment(assignment); err != nil && err != ErrAssignmentExists {
+		return nil, err
+	}
+	return &policy, nil
+}
 
-	return false, nil
+func isEnginePolicyPath(target string) bool {
+	for _, pattern := range enginePolicyPaths {
+		if MatchPolicyPath(pattern, target) {
+			return true
+		}
+	}
+	return false
 }
 
-func (s *PolicyService) evaluatePolicy(rules, resource, action string) bool {
+func (s *PolicyService) ruleMatches(rule *model.PolicyRule, req *model.AccessRequest) bool {
+	if !MatchPolicyPath(rule.Path, req.Path) {
+		return false
+	}
+
+	if rule.Conditions == nil {
+		return true
+	}
+
+	conditions := rule.Conditions
+	if conditions.NotBefore != nil && req.Time.Before(*conditions.NotBefore) {
+		return false
+	}
+	if conditions.NotAfter != nil && req.Time.After(*conditions.NotAfter) {
+		return false
+	}
+
+	if len(conditions.SourceIPs) > 0 {
+		ip := net.ParseIP(req.ClientIP)
+		if ip == nil {
+			return false
+		}
+
+		allowed := false
+		for _, cidr := range conditions.SourceIPs {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				if candidate := net.ParseIP(cidr); candidate != nil && candidate.Equal(ip) {
+					allowed = true
+					break
+				}
+				continue
+			}
+			if network.Contains(ip) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+
 	return true
 }
 
+func MatchPolicyPath(pattern, target string) bool {
+	return matchSegments(splitPolicyPath(pattern), splitPolicyPath(target))
+}
+
+func matchSegments(pattern, target []string) bool {
+	if len(pattern) == 0 {
+		return len(target) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(target); i++ {
+			if matchSegments(pattern[1:], target[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(target) == 0 {
+		return false
+	}
+
+	matched, err := path.Match(pattern[0], target[0])
+	if err != nil || !matched {
+		return false
+	}
+
+	return matchSegments(pattern[1:], target[1:])
+}
+
+func splitPolicyPath(p string) []string {
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, "/")
+}
+
+// NewPolicyDocument builds a single-rule document from the resource/actions/
+// effect shorthand used by the SDK policies client.
+func NewPolicyDocument(resource string, actions []string, effect string, conditions map[string]interface{}) (*model.PolicyDocument, error) {
+	rule := model.PolicyRule{Path: resource}
+
+	if strings.EqualFold(effect, "deny") {
+		rule.Capabilities = []model.PolicyCapability{model.CapabilityDeny}
+	} else if effect != "" && !strings.EqualFold(effect, "allow") {
+		return nil, fmt.Errorf("%w: unknown effect %q", ErrInvalidPolicy, effect)
+	} else {
+		for _, action := range actions {
+			rule.Capabilities = append(rule.Capabilities, model.PolicyCapability(strings.ToLower(action)))
+		}
+	}
+
+	if len(conditions) > 0 {
+		data, err := json.Marshal(conditions)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+		}
+		var parsed model.PolicyConditions
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			return nil, fmt.Errorf("%w: invalid conditions: %v", ErrInvalidPolicy, err)
+		}
+		rule.Conditions = &parsed
+	}
+
+	return &model.PolicyDocument{Rules: []model.PolicyRule{rule}}, nil
+}
+
+func ToPolicyResponse(policy *model.Policy) model.PolicyResponse {
+	response := model.PolicyResponse{
+		ID:          policy.ID,
+		UserID:      policy.UserID,
+		Name:        policy.Name,
+		Description: policy.Description,
+		Priority:    policy.Priority,
+		IsActive:    policy.IsActive,
+		CreatedAt:   policy.CreatedAt,
+		UpdatedAt:   policy.UpdatedAt,
+	}
+
+	document, err := policy.GetDocument()
+	if err != nil {
+		return response
+	}
+	response.Rules = document
+
+	if len(document.Rules) == 1 {
+		rule := document.Rules[0]
+		response.Resource = rule.Path
+		response.Conditions = rule.Conditions
+		if rule.HasCapability(model.CapabilityDeny) {
+			response.Effect = "deny"
+		} else {
+			response.Effect = "allow"
+			for _, capability := range rule.Capabilities {
+				response.Actions = append(response.Actions, string(capability))
+			}
+		}
+	}
+
+	return response
+}
+
+func ValidatePolicyRules(rules string) error {
+	policy := model.Policy{Rules: rules}
+	document, err := policy.GetDocument()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+
+	if len(document.Rules) == 0 {
+		return fmt.Errorf("%w: at least one rule is required", ErrInvalidPolicy)
+	}
+
+	for i, rule := range document.Rules {
+		if strings.TrimSpace(rule.Path) == "" {
+			return fmt.Errorf("%w: rule %d has an empty path", ErrInvalidPolicy, i)
+		}
+		for _, segment := range splitPolicyPath(rule.Path) {
+			if _, err := path.Match(segment, ""); err != nil {
+				return fmt.Errorf("%w: rule %d has a malformed path glob", ErrInvalidPolicy, i)
+			}
+		}
+
+		if len(rule.Capabilities) == 0 {
+			return fmt.Errorf("%w: rule %d has no capabilities", ErrInvalidPolicy, i)
+		}
+		for _, capability := range rule.Capabilities {
+			if !isValidCapability(capability) {
+				return fmt.Errorf("%w: rule %d has unknown capability %q", ErrInvalidPolicy, i, capability)
+			}
+		}
+
+		if rule.Conditions != nil {
+			for _, source := range rule.Conditions.SourceIPs {
+				if _, _, err := net.ParseCIDR(source); err != nil && net.ParseIP(source) == nil {
+					return fmt.Errorf("%w: rule %d has invalid source IP %q", ErrInvalidPolicy, i, source)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func isValidCapability(capability model.PolicyCapability) bool {
+	switch capability {
+	case model.CapabilityRead, model.CapabilityCreate, model.CapabilityUpdate,
+		model.CapabilityDelete, model.CapabilityList, model.CapabilityDeny:
+		return true
+	default:
+		return false
+	}
+}
+
 var (
-	ErrPolicyNotFound = fmt.Errorf("policy not found")
+	ErrPolicyNotFound      = fmt.Errorf("policy not found")
+	ErrInvalidPolicy       = fmt.Errorf("invalid policy")
+	ErrAssignmentNotFound  = fmt.Errorf("policy assignment not found")
+	ErrAssignmentExists    = fmt.Errorf("policy is already assigned to this identity")
+	ErrInvalidIdentityType = fmt.Errorf("invalid identity")
 )
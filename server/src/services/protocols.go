@@ -0,0 +1,1123 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"golang.org/x/crypto/ssh"
+)
+
+// defaultProtocolTimeout bounds a check whose config sets no timeout.
+const defaultProtocolTimeout = 10 * time.Second
+
+// SSHClient connects and authenticates over SSH with the password or private
+// key of the config. A certificate, such as one issued by the SSH engine, is
+// presented along with the private key. The "host_key_fingerprint" option
+// pins the server's SHA256 host key fingerprint; a password is only sent
+// once the host key is pinned, since any server answering could read it.
+// Stored networks pin the key they see first, see NetworkService.
+type SSHClient struct{}
+
+func (c *SSHClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+
+	client, details, err := dialSSH(config)
+	if err != nil {
+		return failedTest(start, "SSH connection failed: %v", err), nil
+	}
+	defer client.Close()
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: "SSH authentication successful",
+		Latency: time.Since(start).Milliseconds(),
+		Details: details,
+	}, nil
+}
+
+func (c *SSHClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolSSH, c, config)
+}
+
+// SFTPClient authenticates over SSH like SSHClient and lists the directory
+// of the "path" option, the login directory by default.
+type SFTPClient struct{}
+
+func (c *SFTPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+
+	client, details, err := dialSSH(config)
+	if err != nil {
+		return failedTest(start, "SFTP connection failed: %v", err), nil
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return failedTest(start, "SFTP session failed: %v", err), nil
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return failedTest(start, "SFTP subsystem unavailable: %v", err), nil
+	}
+
+	conn := &sftpConn{w: stdin, r: stdout}
+	version, err := conn.init()
+	if err != nil {
+		return failedTest(start, "SFTP handshake failed: %v", err), nil
+	}
+	path, err := conn.realPath(protocolOption(config, "path", "."))
+	if err != nil {
+		return failedTest(start, "SFTP path lookup failed: %v", err), nil
+	}
+	entries, err := conn.countEntries(path)
+	if err != nil {
+		return failedTest(start, "SFTP listing of %s failed: %v", path, err), nil
+	}
+
+	details["sftp_version"] = version
+	details["path"] = path
+	details["entries"] = entries
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: fmt.Sprintf("SFTP listed %d entries in %s", entries, path),
+		Latency: time.Since(start).Milliseconds(),
+		Details: details,
+	}, nil
+}
+
+func (c *SFTPClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolSFTP, c, config)
+}
+
+// FTPClient logs in, anonymously without a username, and reads the system
+// type and working directory. The "tls" option upgrades the control
+// connection with AUTH TLS first.
+type FTPClient struct{}
+
+func (c *FTPClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+	timeout := protocolTimeout(config)
+
+	conn, err := net.DialTimeout("tcp", protocolAddress(config, 21), timeout)
+	if err != nil {
+		return failedTest(start, "FTP connection failed: %v", err), nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	text := textproto.NewConn(conn)
+	_, banner, err := text.ReadResponse(220)
+	if err != nil {
+		return failedTest(start, "FTP greeting failed: %v", err), nil
+	}
+	details := map[string]interface{}{
+		"banner": banner,
+		"tls":    false,
+	}
+
+	if protocolFlag(config, "tls") {
+		if _, _, err := ftpCommand(text, 234, "AUTH TLS"); err != nil {
+			return failedTest(start, "FTP AUTH TLS failed: %v", err), nil
+		}
+		tlsConn := tls.Client(conn, protocolTLSConfig(config))
+		if err := tlsConn.Handshake(); err != nil {
+			return failedTest(start, "FTP TLS handshake failed: %v", err), nil
+		}
+		text = textproto.NewConn(tlsConn)
+		details["tls"] = true
+	}
+
+	username, password := config.Username, config.Password
+	if username == "" {
+		username, password = "anonymous", "anonymous@"
+	}
+	code, _, err := ftpCommand(text, 0, "USER %s", username)
+	if err != nil {
+		return failedTest(start, "FTP login failed: %v", err), nil
+	}
+	if code == 331 {
+		code, _, err = ftpCommand(text, 0, "PASS %s", password)
+		if err != nil {
+			return failedTest(start, "FTP login failed: %v", err), nil
+		}
+	}
+	if code != 230 && code != 202 {
+		return failedTest(start, "FTP login refused with code %d", code), nil
+	}
+	details["user"] = username
+
+	if _, system, err := ftpCommand(text, 215, "SYST"); err == nil {
+		details["system"] = system
+	}
+	if _, directory, err := ftpCommand(text, 257, "PWD"); err == nil {
+		details["directory"] = directory
+	}
+	ftpCommand(text, 221, "QUIT")
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: "FTP login successful",
+		Latency: time.Since(start).Milliseconds(),
+		Details: details,
+	}, nil
+}
+
+func (c *FTPClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolFTP, c, config)
+}
+
+// WebDAVClient sends a depth 0 PROPFIND for the "path" option, "/" by
+// default, over HTTPS when the "tls" option is set or the port is 443.
+type WebDAVClient struct{}
+
+func (c *WebDAVClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+
+	scheme, port := "http", 80
+	if protocolFlag(config, "tls") || config.Port == 443 {
+		scheme, port = "https", 443
+	}
+	client := &http.Client{
+		Timeout: protocolTimeout(config),
+		Transport: &http.Transport{
+			TLSClientConfig: protocolTLSConfig(config),
+		},
+	}
+
+	url := scheme + "://" + protocolAddress(config, port) + "/" + strings.TrimPrefix(protocolOption(config, "path", "/"), "/")
+	body := `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/><getlastmodified/></prop></propfind>`
+	req, err := http.NewRequest("PROPFIND", url, strings.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Depth", "0")
+	req.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	if config.Username != "" && config.Password != "" {
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return failedTest(start, "WebDAV request failed: %v", err), nil
+	}
+	defer resp.Body.Close()
+
+	details := map[string]interface{}{
+		"status_code": resp.StatusCode,
+		"url":         url,
+	}
+	if dav := resp.Header.Get("DAV"); dav != "" {
+		details["dav"] = dav
+	}
+	if resp.StatusCode != http.StatusMultiStatus {
+		return &model.ProtocolTestResponse{
+			Success: false,
+			Message: fmt.Sprintf("WebDAV PROPFIND returned %s", resp.Status),
+			Latency: time.Since(start).Milliseconds(),
+			Details: details,
+		}, nil
+	}
+
+	var multistatus davMultistatus
+	if err := xml.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&multistatus); err != nil {
+		return failedTest(start, "WebDAV response is not a multistatus: %v", err), nil
+	}
+	if len(multistatus.Responses) > 0 {
+		response := multistatus.Responses[0]
+		details["href"] = response.Href
+		for _, propstat := range response.Propstat {
+			if strings.Contains(propstat.Status, " 200 ") {
+				details["collection"] = propstat.Prop.ResourceType.Collection != nil
+				if propstat.Prop.LastModified != "" {
+					details["last_modified"] = propstat.Prop.LastModified
+				}
+			}
+		}
+	}
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: "WebDAV PROPFIND successful",
+		Latency: time.Since(start).Milliseconds(),
+		Details: details,
+	}, nil
+}
+
+func (c *WebDAVClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolWebDAV, c, config)
+}
+
+type davMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"href"`
+		Propstat []struct {
+			Status string `xml:"status"`
+			Prop   struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"collection"`
+				} `xml:"resourcetype"`
+				LastModified string `xml:"getlastmodified"`
+			} `xml:"prop"`
+		} `xml:"propstat"`
+	} `xml:"response"`
+}
+
+// SMBClient runs an SMB2 negotiate and reports the dialect and security mode
+// the server picks. It does not authenticate.
+type SMBClient struct{}
+
+// SMB2 dialects offered by SMBClient. 3.1.1 is left out: it requires
+// negotiate contexts.
+var smbDialects = map[uint16]string{
+	0x0202: "2.0.2",
+	0x0210: "2.1",
+	0x0300: "3.0",
+	0x0302: "3.0.2",
+}
+
+func (c *SMBClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+	timeout := protocolTimeout(config)
+
+	conn, err := net.DialTimeout("tcp", protocolAddress(config, 445), timeout)
+	if err != nil {
+		return failedTest(start, "SMB connection failed: %v", err), nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write(smbNegotiateRequest()); err != nil {
+		return failedTest(start, "SMB negotiate failed: %v", err), nil
+	}
+
+	var frame [4]byte
+	if _, err := io.ReadFull(conn, frame[:]); err != nil {
+		return failedTest(start, "SMB negotiate failed: %v", err), nil
+	}
+	length := int(frame[1])<<16 | int(frame[2])<<8 | int(frame[3])
+	if frame[0] != 0 || length < 64+65 || length > 1<<16 {
+		return failedTest(start, "SMB negotiate failed: unexpected response framing"), nil
+	}
+	message := make([]byte, length)
+	if _, err := io.ReadFull(conn, message); err != nil {
+		return failedTest(start, "SMB negotiate failed: %v", err), nil
+	}
+	if !bytes.Equal(message[:4], []byte{0xfe, 'S', 'M', 'B'}) {
+		return failedTest(start, "SMB negotiate failed: server does not speak SMB2"), nil
+	}
+	if status := binary.LittleEndian.Uint32(message[8:]); status != 0 {
+		return failedTest(start, "SMB negotiate failed with status 0x%08x", status), nil
+	}
+
+	body := message[64:]
+	securityMode := binary.LittleEndian.Uint16(body[2:])
+	revision := binary.LittleEndian.Uint16(body[4:])
+	dialect, ok := smbDialects[revision]
+	if !ok {
+		dialect = fmt.Sprintf("0x%04x", revision)
+	}
+	guid, _ := uuid.FromBytes(body[8:24])
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: "SMB negotiate successful, dialect " + dialect,
+		Latency: time.Since(start).Milliseconds(),
+		Details: map[string]interface{}{
+			"dialect":          dialect,
+			"signing_enabled":  securityMode&0x01 != 0,
+			"signing_required": securityMode&0x02 != 0,
+			"server_guid":      guid.String(),
+			"capabilities":     binary.LittleEndian.Uint32(body[24:]),
+			"max_read_size":    binary.LittleEndian.Uint32(body[32:]),
+			"max_write_size":   binary.LittleEndian.Uint32(body[36:]),
+		},
+	}, nil
+}
+
+func (c *SMBClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolSMB, c, config)
+}
+
+// smbNegotiateRequest builds an SMB2 NEGOTIATE request in its NetBIOS
+// session frame.
+func smbNegotiateRequest() []byte {
+	var message bytes.Buffer
+	header := make([]byte, 64)
+	copy(header, []byte{0xfe, 'S', 'M', 'B'})
+	binary.LittleEndian.PutUint16(header[4:], 64) // structure size
+	binary.LittleEndian.PutUint16(header[14:], 1) // credits requested
+	message.Write(header)
+
+	dialects := []uint16{0x0202, 0x0210, 0x0300, 0x0302}
+	body := make([]byte, 36)
+	binary.LittleEndian.PutUint16(body[0:], 36) // structure size
+	binary.LittleEndian.PutUint16(body[2:], uint16(len(dialects)))
+	binary.LittleEndian.PutUint16(body[4:], 0x01) // signing enabled
+	clientGUID := uuid.New()
+	copy(body[12:28], clientGUID[:])
+	message.Write(body)
+	for _, dialect := range dialects {
+		binary.Write(&message, binary.LittleEndian, dialect)
+	}
+
+	frame := []byte{0, byte(message.Len() >> 16), byte(message.Len() >> 8), byte(message.Len())}
+	return append(frame, message.Bytes()...)
+}
+
+// RsyncClient talks to an rsync daemon. Without a "module" option it lists
+// the daemon's modules; with one it opens the module, authenticating with
+// the username and password when the daemon asks.
+type RsyncClient struct{}
+
+func (c *RsyncClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+	timeout := protocolTimeout(config)
+
+	conn, err := net.DialTimeout("tcp", protocolAddress(config, 873), timeout)
+	if err != nil {
+		return failedTest(start, "rsync connection failed: %v", err), nil
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	reader := bufio.NewReader(conn)
+	greeting, err := reader.ReadString('\n')
+	if err != nil || !strings.HasPrefix(greeting, "@RSYNCD:") {
+		return failedTest(start, "rsync greeting failed: not an rsync daemon"), nil
+	}
+	fields := strings.Fields(strings.TrimPrefix(greeting, "@RSYNCD: "))
+	if len(fields) == 0 {
+		return failedTest(start, "rsync greeting failed: no protocol version"), nil
+	}
+	details := map[string]interface{}{
+		"protocol_version": fields[0],
+	}
+
+	// Protocol 30 makes the daemon use MD5 for the challenge response
+	module := protocolOption(config, "module", "")
+	if _, err := fmt.Fprintf(conn, "@RSYNCD: 30.0\n%s\n", module); err != nil {
+		return failedTest(start, "rsync handshake failed: %v", err), nil
+	}
+
+	var modules []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return failedTest(start, "rsync handshake failed: %v", err), nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case strings.HasPrefix(line, "@ERROR"):
+			return failedTest(start, "rsync daemon refused: %s", strings.TrimSpace(strings.TrimPrefix(line, "@ERROR:"))), nil
+		case line == "@RSYNCD: EXIT":
+			if module != "" {
+				return failedTest(start, "rsync daemon closed the connection"), nil
+			}
+			details["modules"] = modules
+			return &model.ProtocolTestResponse{
+				Success: true,
+				Message: fmt.Sprintf("rsync daemon lists %d modules", len(modules)),
+				Latency: time.Since(start).Milliseconds(),
+				Details: details,
+			}, nil
+		case strings.HasPrefix(line, "@RSYNCD: AUTHREQD "):
+			if config.Username == "" || config.Password == "" {
+				return failedTest(start, "rsync module %s requires a username and password", module), nil
+			}
+			challenge := strings.TrimPrefix(line, "@RSYNCD: AUTHREQD ")
+			sum := md5.Sum([]byte(config.Password + challenge))
+			if _, err := fmt.Fprintf(conn, "%s %s\n", config.Username, base64.RawStdEncoding.EncodeToString(sum[:])); err != nil {
+				return failedTest(start, "rsync authentication failed: %v", err), nil
+			}
+			details["authenticated"] = true
+		case line == "@RSYNCD: OK":
+			details["module"] = module
+			return &model.ProtocolTestResponse{
+				Success: true,
+				Message: fmt.Sprintf("rsync module %s opened", module),
+				Latency: time.Since(start).Milliseconds(),
+				Details: details,
+			}, nil
+		case module == "" && strings.Contains(line, "\t"):
+			modules = append(modules, strings.TrimSpace(strings.SplitN(line, "\t", 2)[0]))
+		}
+	}
+}
+
+func (c *RsyncClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolRsync, c, config)
+}
+
+// GitClient lists the refs of the repository at the "path" option, like git
+// ls-remote. The "transport" option picks https, http, ssh or git (the git
+// daemon); by default it follows the port, https otherwise.
+type GitClient struct{}
+
+func (c *GitClient) Test(config *model.ProtocolConfig) (*model.ProtocolTestResponse, error) {
+	start := time.Now()
+
+	path := protocolOption(config, "path", "")
+	if path == "" {
+		return failedTest(start, "git check needs the repository path in the path option"), nil
+	}
+	transport := gitTransport(config)
+
+	var refs *gitRefs
+	var err error
+	switch transport {
+	case "http", "https":
+		refs, err = gitHTTPRefs(config, transport, path)
+	case "ssh":
+		refs, err = gitSSHRefs(config, path)
+	case "git":
+		refs, err = gitDaemonRefs(config, path)
+	default:
+		return failedTest(start, "unsupported git transport: %s", transport), nil
+	}
+	if err != nil {
+		return failedTest(start, "git ls-remote over %s failed: %v", transport, err), nil
+	}
+
+	details := map[string]interface{}{
+		"transport": transport,
+		"refs":      refs.count,
+	}
+	if refs.head != "" {
+		details["head"] = refs.head
+	}
+	if refs.defaultBranch != "" {
+		details["default_branch"] = refs.defaultBranch
+	}
+
+	return &model.ProtocolTestResponse{
+		Success: true,
+		Message: fmt.Sprintf("git repository lists %d refs", refs.count),
+		Latency: time.Since(start).Milliseconds(),
+		Details: details,
+	}, nil
+}
+
+func (c *GitClient) GetStatus(config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	return protocolStatus(model.ProtocolGit, c, config)
+}
+
+// gitTransport returns the "transport" option, or the transport the port
+// implies.
+func gitTransport(config *model.ProtocolConfig) string {
+	if transport := protocolOption(config, "transport", ""); transport != "" {
+		return transport
+	}
+	switch config.Port {
+	case 22:
+		return "ssh"
+	case 80:
+		return "http"
+	case 9418:
+		return "git"
+	default:
+		return "https"
+	}
+}
+
+type gitRefs struct {
+	count         int
+	head          string
+	defaultBranch string
+}
+
+func gitHTTPRefs(config *model.ProtocolConfig, scheme, path string) (*gitRefs, error) {
+	port := 443
+	if scheme == "http" {
+		port = 80
+	}
+	client := &http.Client{
+		Timeout: protocolTimeout(config),
+		Transport: &http.Transport{
+			TLSClientConfig: protocolTLSConfig(config),
+		},
+	}
+
+	url := scheme + "://" + protocolAddress(config, port) + "/" + strings.Trim(path, "/") + "/info/refs?service=git-upload-pack"
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.Username != "" && config.Password != "" {
+		req.SetBasicAuth(config.Username, config.Password)
+	}
+	for key, value := range config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned %s", resp.Status)
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/x-git-upload-pack-advertisement" {
+		return nil, fmt.Errorf("server does not speak the smart HTTP protocol")
+	}
+
+	// Smart HTTP prefixes the advertisement with the service name
+	reader := bufio.NewReader(resp.Body)
+	if _, err := readGitAdvertisement(reader); err != nil {
+		return nil, err
+	}
+	return readGitAdvertisement(reader)
+}
+
+func gitSSHRefs(config *model.ProtocolConfig, path string) (*gitRefs, error) {
+	client, _, err := dialSSH(config)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := session.Start("git-upload-pack '" + strings.ReplaceAll(path, "'", `'\''`) + "'"); err != nil {
+		return nil, err
+	}
+
+	refs, err := readGitAdvertisement(bufio.NewReader(stdout))
+	// A flush packet ends the exchange without fetching anything
+	io.WriteString(stdin, "0000")
+	stdin.Close()
+	return refs, err
+}
+
+func gitDaemonRefs(config *model.ProtocolConfig, path string) (*gitRefs, error) {
+	timeout := protocolTimeout(config)
+	conn, err := net.DialTimeout("tcp", protocolAddress(config, 9418), timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	request := "git-upload-pack /" + strings.TrimPrefix(path, "/") + "\x00host=" + config.Host + "\x00"
+	if _, err := fmt.Fprintf(conn, "%04x%s", len(request)+4, request); err != nil {
+		return nil, err
+	}
+
+	refs, err := readGitAdvertisement(bufio.NewReader(conn))
+	io.WriteString(conn, "0000")
+	return refs, err
+}
+
+// readGitAdvertisement reads pkt-lines up to a flush packet. The first ref
+// carries the capabilities after a NUL byte; symref=HEAD:<ref> names the
+// default branch.
+func readGitAdvertisement(reader *bufio.Reader) (*gitRefs, error) {
+	refs := &gitRefs{}
+	for first := true; ; first = false {
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return nil, err
+		}
+		length, err := strconv.ParseUint(string(size[:]), 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pkt-line length %q", size[:])
+		}
+		if length == 0 {
+			return refs, nil
+		}
+		if length < 4 || length > 65520 {
+			return nil, fmt.Errorf("invalid pkt-line length %d", length)
+		}
+		line := make([]byte, length-4)
+		if _, err := io.ReadFull(reader, line); err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(line, []byte("ERR ")) {
+			return nil, errors.New(strings.TrimSpace(string(line[4:])))
+		}
+		if bytes.HasPrefix(line, []byte("# service=")) {
+			continue
+		}
+
+		refLine, capabilities, _ := strings.Cut(strings.TrimRight(string(line), "\n"), "\x00")
+		if first {
+			for _, capability := range strings.Fields(capabilities) {
+				if target, ok := strings.CutPrefix(capability, "symref=HEAD:"); ok {
+					refs.defaultBranch = strings.TrimPrefix(target, "refs/heads/")
+				}
+			}
+		}
+		id, name, _ := strings.Cut(refLine, " ")
+		if name == "capabilities^{}" {
+			continue
+		}
+		refs.count++
+		if name == "HEAD" {
+			refs.head = id
+		}
+	}
+}
+
+// dialSSH connects and authenticates with the config's credentials, and
+// returns details on the server and the way it was authenticated.
+func dialSSH(config *model.ProtocolConfig) (*ssh.Client, map[string]interface{}, error) {
+	if config.Username == "" {
+		return nil, nil, fmt.Errorf("a username is required")
+	}
+
+	details := map[string]interface{}{}
+	var auth []ssh.AuthMethod
+	var methods []string
+	if config.PrivateKey != "" {
+		signer, err := ssh.ParsePrivateKey([]byte(config.PrivateKey))
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) && config.Password != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(config.PrivateKey), []byte(config.Password))
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid private key: %w", err)
+		}
+		if config.Certificate != "" {
+			publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(config.Certificate))
+			if err != nil {
+				return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+			}
+			certificate, ok := publicKey.(*ssh.Certificate)
+			if !ok {
+				return nil, nil, fmt.Errorf("certificate is not an SSH certificate")
+			}
+			if signer, err = ssh.NewCertSigner(certificate, signer); err != nil {
+				return nil, nil, fmt.Errorf("invalid certificate: %w", err)
+			}
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+		methods = append(methods, "publickey")
+	}
+	if config.Password != "" && config.PrivateKey == "" {
+		password := config.Password
+		auth = append(auth, ssh.Password(password), ssh.KeyboardInteractive(func(_, _ string, questions []string, _ []bool) ([]string, error) {
+			answers := make([]string, len(questions))
+			for i := range answers {
+				answers[i] = password
+			}
+			return answers, nil
+		}))
+		methods = append(methods, "password")
+	}
+	if len(auth) == 0 {
+		return nil, nil, fmt.Errorf("a password or private key is required")
+	}
+	details["auth_methods"] = methods
+
+	// The deadline also bounds whatever the caller runs over the client
+	timeout := protocolTimeout(config)
+	address := protocolAddress(config, 22)
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	pinned := protocolOption(config, "host_key_fingerprint", "")
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, &ssh.ClientConfig{
+		User: config.Username,
+		Auth: auth,
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			fingerprint := ssh.FingerprintSHA256(key)
+			details["host_key_type"] = key.Type()
+			details["host_key_fingerprint"] = fingerprint
+			if pinned == "" && config.PrivateKey == "" {
+				return fmt.Errorf("host key %s is not pinned; set the host_key_fingerprint option to authenticate with a password", fingerprint)
+			}
+			if pinned != "" && pinned != fingerprint {
+				return fmt.Errorf("host key %s does not match the pinned %s", fingerprint, pinned)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	client := ssh.NewClient(sshConn, channels, requests)
+
+	details["server_version"] = string(client.ServerVersion())
+	return client, details, nil
+}
+
+// errSSHHostKeyRead stops a handshake once the host key has been read.
+var errSSHHostKeyRead = errors.New("host key read")
+
+// readSSHHostKey returns the SHA256 fingerprint of the server's host key,
+// ending the handshake before any credential is sent.
+func readSSHHostKey(config *model.ProtocolConfig) (string, error) {
+	timeout := protocolTimeout(config)
+	address := protocolAddress(config, 22)
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	var fingerprint string
+	_, _, _, err = ssh.NewClientConn(conn, address, &ssh.ClientConfig{
+		User: config.Username,
+		HostKeyCallback: func(_ string, _ net.Addr, key ssh.PublicKey) error {
+			fingerprint = ssh.FingerprintSHA256(key)
+			return errSSHHostKeyRead
+		},
+	})
+	if fingerprint == "" {
+		return "", err
+	}
+	return fingerprint, nil
+}
+
+// usesSSH reports whether checks of the protocol log in over SSH.
+func usesSSH(protocol model.ProtocolType, config *model.ProtocolConfig) bool {
+	switch protocol {
+	case model.ProtocolSSH, model.ProtocolSFTP:
+		return true
+	case model.ProtocolGit:
+		return gitTransport(config) == "ssh"
+	}
+	return false
+}
+
+// SFTP version 3 packet types and status codes used by SFTPClient.
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpClose    = 4
+	sftpOpenDir  = 11
+	sftpReadDir  = 12
+	sftpRealPath = 16
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpName     = 104
+
+	sftpStatusEOF = 1
+)
+
+type sftpConn struct {
+	w  io.Writer
+	r  io.Reader
+	id uint32
+}
+
+func (c *sftpConn) init() (uint32, error) {
+	if err := c.send(sftpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return 0, err
+	}
+	typ, payload, err := c.recv()
+	if err != nil {
+		return 0, err
+	}
+	if typ != sftpVersion || len(payload) < 4 {
+		return 0, fmt.Errorf("unexpected packet %d", typ)
+	}
+	return binary.BigEndian.Uint32(payload), nil
+}
+
+func (c *sftpConn) realPath(path string) (string, error) {
+	payload, err := c.request(sftpRealPath, sftpName, appendSFTPString(nil, path))
+	if err != nil {
+		return "", err
+	}
+	if len(payload) < 4 || binary.BigEndian.Uint32(payload) == 0 {
+		return "", fmt.Errorf("empty realpath reply")
+	}
+	name, _, err := readSFTPString(payload[4:])
+	return string(name), err
+}
+
+// countEntries reads the whole directory, skipping "." and "..".
+func (c *sftpConn) countEntries(path string) (int, error) {
+	handle, err := c.request(sftpOpenDir, sftpHandle, appendSFTPString(nil, path))
+	if err != nil {
+		return 0, err
+	}
+	handle, _, err = readSFTPString(handle)
+	if err != nil {
+		return 0, err
+	}
+	defer c.request(sftpClose, sftpStatus, appendSFTPString(nil, string(handle)))
+
+	entries := 0
+	for {
+		payload, err := c.request(sftpReadDir, sftpName, appendSFTPString(nil, string(handle)))
+		if errors.Is(err, errSFTPEOF) {
+			return entries, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		if len(payload) < 4 {
+			return 0, fmt.Errorf("short readdir reply")
+		}
+		count := binary.BigEndian.Uint32(payload)
+		rest := payload[4:]
+		for i := uint32(0); i < count; i++ {
+			var name []byte
+			if name, rest, err = readSFTPString(rest); err != nil {
+				return 0, err
+			}
+			if _, rest, err = readSFTPString(rest); err != nil {
+				return 0, err
+			}
+			if rest, err = skipSFTPAttrs(rest); err != nil {
+				return 0, err
+			}
+			if string(name) != "." && string(name) != ".." {
+				entries++
+			}
+		}
+	}
+}
+
+var errSFTPEOF = errors.New("end of file")
+
+// request sends a packet with a new request id and returns the payload of
+// the reply after its id. A status reply is an error unless want is
+// sftpStatus.
+func (c *sftpConn) request(typ byte, want byte, payload []byte) ([]byte, error) {
+	c.id++
+	if err := c.send(typ, append(binary.BigEndian.AppendUint32(nil, c.id), payload...)); err != nil {
+		return nil, err
+	}
+	replyType, reply, err := c.recv()
+	if err != nil {
+		return nil, err
+	}
+	if len(reply) < 4 || binary.BigEndian.Uint32(reply) != c.id {
+		return nil, fmt.Errorf("unexpected reply id")
+	}
+	reply = reply[4:]
+
+	if replyType == sftpStatus && want != sftpStatus {
+		if len(reply) < 4 {
+			return nil, fmt.Errorf("short status reply")
+		}
+		code := binary.BigEndian.Uint32(reply)
+		if code == sftpStatusEOF {
+			return nil, errSFTPEOF
+		}
+		message, _, _ := readSFTPString(reply[4:])
+		return nil, fmt.Errorf("status %d: %s", code, message)
+	}
+	if replyType != want {
+		return nil, fmt.Errorf("unexpected packet %d", replyType)
+	}
+	return reply, nil
+}
+
+func (c *sftpConn) send(typ byte, payload []byte) error {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+	packet = append(packet, typ)
+	_, err := c.w.Write(append(packet, payload...))
+	return err
+}
+
+func (c *sftpConn) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > 1<<18 {
+		return 0, nil, fmt.Errorf("invalid packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[4], payload, nil
+}
+
+func appendSFTPString(buf []byte, value string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(value)))
+	return append(buf, value...)
+}
+
+func readSFTPString(buf []byte) ([]byte, []byte, error) {
+	if len(buf) < 4 {
+		return nil, nil, fmt.Errorf("short string")
+	}
+	length := binary.BigEndian.Uint32(buf)
+	if uint32(len(buf)-4) < length {
+		return nil, nil, fmt.Errorf("short string")
+	}
+	return buf[4 : 4+length], buf[4+length:], nil
+}
+
+// skipSFTPAttrs skips a version 3 ATTRS structure.
+func skipSFTPAttrs(buf []byte) ([]byte, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("short attributes")
+	}
+	flags := binary.BigEndian.Uint32(buf)
+	size := 0
+	if flags&0x1 != 0 {
+		size += 8 // size
+	}
+	if flags&0x2 != 0 {
+		size += 8 // uid and gid
+	}
+	if flags&0x4 != 0 {
+		size += 4 // permissions
+	}
+	if flags&0x8 != 0 {
+		size += 8 // atime and mtime
+	}
+	if len(buf) < 4+size {
+		return nil, fmt.Errorf("short attributes")
+	}
+	buf = buf[4+size:]
+
+	if flags&0x80000000 != 0 {
+		if len(buf) < 4 {
+			return nil, fmt.Errorf("short attributes")
+		}
+		count := binary.BigEndian.Uint32(buf)
+		buf = buf[4:]
+		for i := uint32(0); i < count*2; i++ {
+			var err error
+			if _, buf, err = readSFTPString(buf); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buf, nil
+}
+
+// ftpCommand sends a command and reads the reply, which must have code
+// expect unless expect is 0.
+func ftpCommand(text *textproto.Conn, expect int, format string, args ...interface{}) (int, string, error) {
+	id, err := text.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	return text.ReadResponse(expect)
+}
+
+// protocolAddress joins the config's host and port, or defaultPort when the
+// config has none.
+func protocolAddress(config *model.ProtocolConfig, defaultPort int) string {
+	port := config.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	return net.JoinHostPort(config.Host, strconv.Itoa(port))
+}
+
+func protocolTimeout(config *model.ProtocolConfig) time.Duration {
+	if config.Timeout <= 0 {
+		return defaultProtocolTimeout
+	}
+	return time.Duration(config.Timeout) * time.Second
+}
+
+// protocolOption returns a string option of the config, or fallback.
+func protocolOption(config *model.ProtocolConfig, name, fallback string) string {
+	if value, ok := config.Options[name].(string); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// protocolFlag reports whether a boolean option is set, as true or "true".
+func protocolFlag(config *model.ProtocolConfig, name string) bool {
+	switch value := config.Options[name].(type) {
+	case bool:
+		return value
+	case string:
+		flag, _ := strconv.ParseBool(value)
+		return flag
+	}
+	return false
+}
+
+// protocolTLSConfig verifies the server's certificate unless the
+// "tls_skip_verify" option is set.
+func protocolTLSConfig(config *model.ProtocolConfig) *tls.Config {
+	return &tls.Config{
+		ServerName:         config.Host,
+		InsecureSkipVerify: protocolFlag(config, "tls_skip_verify"),
+	}
+}
+
+func failedTest(start time.Time, format string, args ...interface{}) *model.ProtocolTestResponse {
+	return &model.ProtocolTestResponse{
+		Success: false,
+		Message: fmt.Sprintf(format, args...),
+		Latency: time.Since(start).Milliseconds(),
+	}
+}
+
+// protocolStatus runs the client's test and reports it as a status.
+func protocolStatus(protocol model.ProtocolType, client ProtocolClient, config *model.ProtocolConfig) (*model.ProtocolStatus, error) {
+	test, err := client.Test(config)
+	if err != nil {
+		return nil, err
+	}
+
+	status := model.NetworkStatusActive
+	if !test.Success {
+		status = model.NetworkStatusInactive
+	}
+
+	return &model.ProtocolStatus{
+		Protocol:             protocol,
+		Status:               status,
+		LastCheck:            time.Now(),
+		Message:              test.Message,
+		Latency:              test.Latency,
+		CertificateExpiresAt: test.CertificateExpiresAt,
+	}, nil
+}
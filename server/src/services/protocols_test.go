@@ -0,0 +1,577 @@
+package services
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"golang.org/x/crypto/ssh"
+)
+
+// The handshake tests run each protocol client against a minimal in-process
+// server speaking just enough of the protocol for the client's check.
+
+const (
+	testGitHead = "9f4c5c58c98b13ce05d9be918305ff07b4ccc0a0"
+	testGitTag  = "1d2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e"
+)
+
+// testListener listens on a free loopback port, closed with the test.
+func testListener(t *testing.T) (net.Listener, int) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener, listener.Addr().(*net.TCPAddr).Port
+}
+
+// serveTest runs handle for every connection accepted on a new listener.
+func serveTest(t *testing.T, handle func(net.Conn)) int {
+	listener, port := testListener(t)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return port
+}
+
+func testHTTPPort(t *testing.T, handler http.Handler) int {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server.Listener.Addr().(*net.TCPAddr).Port
+}
+
+func runProtocolTest(t *testing.T, client ProtocolClient, config *model.ProtocolConfig) *model.ProtocolTestResponse {
+	t.Helper()
+
+	if config.Host == "" {
+		config.Host = "127.0.0.1"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5
+	}
+	result, err := client.Test(config)
+	if err != nil {
+		t.Fatalf("test returned an error: %v", err)
+	}
+	return result
+}
+
+func expectProtocolSuccess(t *testing.T, result *model.ProtocolTestResponse, details map[string]interface{}) {
+	t.Helper()
+
+	if !result.Success {
+		t.Fatalf("check failed: %s", result.Message)
+	}
+	for key, want := range details {
+		if got := result.Details[key]; fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("details[%q] = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func expectProtocolFailure(t *testing.T, result *model.ProtocolTestResponse, message string) {
+	t.Helper()
+
+	if result.Success {
+		t.Fatalf("check succeeded, want failure containing %q", message)
+	}
+	if !strings.Contains(result.Message, message) {
+		t.Fatalf("message %q does not contain %q", result.Message, message)
+	}
+}
+
+// gitTestAdvertisement is the pkt-line ref advertisement of a repository
+// with HEAD, main and one tag.
+func gitTestAdvertisement() string {
+	var b strings.Builder
+	for _, line := range []string{
+		testGitHead + " HEAD\x00multi_ack side-band-64k symref=HEAD:refs/heads/main\n",
+		testGitHead + " refs/heads/main\n",
+		testGitTag + " refs/tags/v1.0.0\n",
+	} {
+		fmt.Fprintf(&b, "%04x%s", len(line)+4, line)
+	}
+	b.WriteString("0000")
+	return b.String()
+}
+
+func gitTestPacket(line string) string {
+	return fmt.Sprintf("%04x%s", len(line)+4, line)
+}
+
+// testSSHServer accepts the password "secret" or the given public key for
+// user alice, and returns its port and host key fingerprint. Sessions serve
+// the sftp subsystem and git-upload-pack on /repo.git.
+func testSSHServer(t *testing.T, userKey ssh.PublicKey) (int, string) {
+	t.Helper()
+
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "alice" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("password rejected")
+		},
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if conn.User() == "alice" && string(key.Marshal()) == string(userKey.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("key rejected")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	port := serveTest(t, func(conn net.Conn) {
+		server, channels, requests, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		defer server.Close()
+		go ssh.DiscardRequests(requests)
+
+		for newChannel := range channels {
+			if newChannel.ChannelType() != "session" {
+				newChannel.Reject(ssh.UnknownChannelType, "session only")
+				continue
+			}
+			channel, requests, err := newChannel.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHSession(channel, requests)
+		}
+	})
+	return port, ssh.FingerprintSHA256(hostSigner.PublicKey())
+}
+
+func serveTestSSHSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+
+	for req := range requests {
+		var payload struct{ Value string }
+		ssh.Unmarshal(req.Payload, &payload)
+
+		switch {
+		case req.Type == "subsystem" && payload.Value == "sftp":
+			req.Reply(true, nil)
+			serveTestSFTP(channel)
+			return
+		case req.Type == "exec" && payload.Value == "git-upload-pack '/repo.git'":
+			req.Reply(true, nil)
+			io.WriteString(channel, gitTestAdvertisement())
+			io.ReadFull(channel, make([]byte, 4))
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			req.Reply(false, nil)
+		}
+	}
+}
+
+// serveTestSFTP answers the SFTP version 3 requests SFTPClient sends: the
+// home directory resolves to /home/alice, which holds a.txt, b.txt and
+// c.txt spread over two READDIR replies.
+func serveTestSFTP(rw io.ReadWriter) {
+	send := func(typ byte, payload []byte) {
+		packet := binary.BigEndian.AppendUint32(nil, uint32(len(payload)+1))
+		rw.Write(append(append(packet, typ), payload...))
+	}
+	name := func(id []byte, names ...string) []byte {
+		payload := binary.BigEndian.AppendUint32(append([]byte{}, id...), uint32(len(names)))
+		for _, n := range names {
+			payload = appendSFTPString(payload, n)
+			payload = appendSFTPString(payload, "-rw-r--r-- 1 alice alice 0 Jan 1 00:00 "+n)
+			payload = binary.BigEndian.AppendUint32(payload, 0x4) // permissions only
+			payload = binary.BigEndian.AppendUint32(payload, 0o644)
+		}
+		return payload
+	}
+	status := func(id []byte, code uint32) []byte {
+		payload := binary.BigEndian.AppendUint32(append([]byte{}, id...), code)
+		return appendSFTPString(appendSFTPString(payload, ""), "")
+	}
+
+	reads := 0
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(rw, header[:]); err != nil {
+			return
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[:4])-1)
+		if _, err := io.ReadFull(rw, payload); err != nil {
+			return
+		}
+
+		typ := header[4]
+		if typ == sftpInit {
+			send(sftpVersion, binary.BigEndian.AppendUint32(nil, 3))
+			continue
+		}
+		id := payload[:4]
+		switch typ {
+		case sftpRealPath:
+			send(sftpName, name(id, "/home/alice"))
+		case sftpOpenDir:
+			send(sftpHandle, appendSFTPString(append([]byte{}, id...), "dir"))
+		case sftpReadDir:
+			switch reads {
+			case 0:
+				send(sftpName, name(id, ".", "..", "a.txt", "b.txt"))
+			case 1:
+				send(sftpName, name(id, "c.txt"))
+			default:
+				send(sftpStatus, status(id, sftpStatusEOF))
+			}
+			reads++
+		case sftpClose:
+			send(sftpStatus, status(id, 0)) // SSH_FX_OK
+		default:
+			send(sftpStatus, status(id, 8)) // SSH_FX_OP_UNSUPPORTED
+		}
+	}
+}
+
+func TestSSHClientHandshake(t *testing.T) {
+	_, userKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSigner, err := ssh.NewSignerFromKey(userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := ssh.MarshalPrivateKey(userKey, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, fingerprint := testSSHServer(t, userSigner.PublicKey())
+	pinned := map[string]interface{}{"host_key_fingerprint": fingerprint}
+
+	t.Run("password", func(t *testing.T) {
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "secret", Options: pinned})
+		expectProtocolSuccess(t, result, map[string]interface{}{"host_key_type": "ssh-ed25519", "host_key_fingerprint": fingerprint, "auth_methods": []string{"password"}})
+	})
+	t.Run("private key", func(t *testing.T) {
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: port, Username: "alice", PrivateKey: string(pem.EncodeToMemory(block))})
+		expectProtocolSuccess(t, result, map[string]interface{}{"auth_methods": []string{"publickey"}})
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "wrong", Options: pinned})
+		expectProtocolFailure(t, result, "unable to authenticate")
+	})
+	t.Run("password without pinned host key", func(t *testing.T) {
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "secret"})
+		expectProtocolFailure(t, result, "host key "+fingerprint+" is not pinned")
+	})
+	t.Run("read host key", func(t *testing.T) {
+		got, err := readSSHHostKey(&model.ProtocolConfig{Host: "127.0.0.1", Port: port, Username: "alice", Timeout: 5})
+		if err != nil || got != fingerprint {
+			t.Fatalf("got %q, %v; want %q", got, err, fingerprint)
+		}
+	})
+	t.Run("pinned host key mismatch", func(t *testing.T) {
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "secret",
+			Options: map[string]interface{}{"host_key_fingerprint": "SHA256:AAAA"}})
+		expectProtocolFailure(t, result, "does not match the pinned")
+	})
+	t.Run("silent server", func(t *testing.T) {
+		silent := serveTest(t, func(conn net.Conn) { io.Copy(io.Discard, conn) })
+		result := runProtocolTest(t, &SSHClient{}, &model.ProtocolConfig{Port: silent, Username: "alice", Password: "secret", Timeout: 1})
+		expectProtocolFailure(t, result, "SSH connection failed")
+	})
+}
+
+func TestSFTPClientHandshake(t *testing.T) {
+	_, userKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userSigner, err := ssh.NewSignerFromKey(userKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, fingerprint := testSSHServer(t, userSigner.PublicKey())
+
+	result := runProtocolTest(t, &SFTPClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "secret",
+		Options: map[string]interface{}{"host_key_fingerprint": fingerprint}})
+	expectProtocolSuccess(t, result, map[string]interface{}{"sftp_version": 3, "path": "/home/alice", "entries": 3})
+}
+
+// testFTPServer accepts bob/secret and anonymous logins.
+func testFTPServer(t *testing.T) int {
+	return serveTest(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "220-Welcome\r\n220 Test FTP\r\n")
+		user := ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			command, argument, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch command {
+			case "USER":
+				user = argument
+				fmt.Fprint(conn, "331 Password required\r\n")
+			case "PASS":
+				if user == "anonymous" || user == "bob" && argument == "secret" {
+					fmt.Fprint(conn, "230 Logged in\r\n")
+				} else {
+					fmt.Fprint(conn, "530 Login incorrect\r\n")
+				}
+			case "SYST":
+				fmt.Fprint(conn, "215 UNIX Type: L8\r\n")
+			case "PWD":
+				fmt.Fprint(conn, "257 \"/home/bob\" is current directory\r\n")
+			case "QUIT":
+				fmt.Fprint(conn, "221 Bye\r\n")
+				return
+			default:
+				fmt.Fprint(conn, "502 Command not implemented\r\n")
+			}
+		}
+	})
+}
+
+func TestFTPClientHandshake(t *testing.T) {
+	port := testFTPServer(t)
+
+	t.Run("login", func(t *testing.T) {
+		result := runProtocolTest(t, &FTPClient{}, &model.ProtocolConfig{Port: port, Username: "bob", Password: "secret"})
+		expectProtocolSuccess(t, result, map[string]interface{}{"user": "bob", "system": "UNIX Type: L8", "tls": false})
+	})
+	t.Run("anonymous", func(t *testing.T) {
+		result := runProtocolTest(t, &FTPClient{}, &model.ProtocolConfig{Port: port})
+		expectProtocolSuccess(t, result, map[string]interface{}{"user": "anonymous"})
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		result := runProtocolTest(t, &FTPClient{}, &model.ProtocolConfig{Port: port, Username: "bob", Password: "wrong"})
+		expectProtocolFailure(t, result, "530")
+	})
+}
+
+func TestWebDAVClientHandshake(t *testing.T) {
+	port := testHTTPPort(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "dave" || password != "secret" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="dav"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "PROPFIND" || r.Header.Get("Depth") != "0" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("DAV", "1, 2")
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?>
+<D:multistatus xmlns:D="DAV:"><D:response><D:href>%s</D:href><D:propstat><D:prop>
+<D:resourcetype><D:collection/></D:resourcetype><D:getlastmodified>Mon, 02 Jan 2006 15:04:05 GMT</D:getlastmodified>
+</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat></D:response></D:multistatus>`, r.URL.Path)
+	}))
+
+	t.Run("propfind", func(t *testing.T) {
+		result := runProtocolTest(t, &WebDAVClient{}, &model.ProtocolConfig{Port: port, Username: "dave", Password: "secret",
+			Options: map[string]interface{}{"path": "/files/"}})
+		expectProtocolSuccess(t, result, map[string]interface{}{"status_code": 207, "dav": "1, 2", "href": "/files/", "collection": true})
+	})
+	t.Run("unauthorized", func(t *testing.T) {
+		result := runProtocolTest(t, &WebDAVClient{}, &model.ProtocolConfig{Port: port, Username: "dave", Password: "wrong"})
+		expectProtocolFailure(t, result, "401")
+	})
+}
+
+// testSMBServer answers an SMB2 negotiate with dialect 3.0.2 and signing
+// required.
+func testSMBServer(t *testing.T) int {
+	return serveTest(t, func(conn net.Conn) {
+		var frame [4]byte
+		if _, err := io.ReadFull(conn, frame[:]); err != nil {
+			return
+		}
+		request := make([]byte, int(frame[1])<<16|int(frame[2])<<8|int(frame[3]))
+		if _, err := io.ReadFull(conn, request); err != nil || !strings.HasPrefix(string(request), "\xfeSMB") {
+			return
+		}
+
+		header := make([]byte, 64)
+		copy(header, "\xfeSMB")
+		binary.LittleEndian.PutUint16(header[4:], 64)
+		header[16] = 1 // server to client
+		body := make([]byte, 65+7)
+		binary.LittleEndian.PutUint16(body[0:], 65)
+		binary.LittleEndian.PutUint16(body[2:], 0x03)
+		binary.LittleEndian.PutUint16(body[4:], 0x0302)
+		copy(body[8:24], "0123456789abcdef")
+		binary.LittleEndian.PutUint32(body[24:], 0x2f)
+		binary.LittleEndian.PutUint32(body[28:], 8388608)
+		binary.LittleEndian.PutUint32(body[32:], 8388608)
+		binary.LittleEndian.PutUint32(body[36:], 8388608)
+
+		message := append(header, body...)
+		conn.Write(append([]byte{0, byte(len(message) >> 16), byte(len(message) >> 8), byte(len(message))}, message...))
+	})
+}
+
+func TestSMBClientHandshake(t *testing.T) {
+	t.Run("negotiate", func(t *testing.T) {
+		result := runProtocolTest(t, &SMBClient{}, &model.ProtocolConfig{Port: testSMBServer(t)})
+		expectProtocolSuccess(t, result, map[string]interface{}{"dialect": "3.0.2", "signing_enabled": true, "signing_required": true})
+	})
+	t.Run("not smb", func(t *testing.T) {
+		result := runProtocolTest(t, &SMBClient{}, &model.ProtocolConfig{Port: testFTPServer(t), Timeout: 2})
+		expectProtocolFailure(t, result, "SMB negotiate failed")
+	})
+}
+
+// testRsyncServer lists the backup and priv modules; priv needs carol with
+// the password "secret".
+func testRsyncServer(t *testing.T) int {
+	return serveTest(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "@RSYNCD: 31.0 sha512 sha256 sha1 md5 md4\n")
+		if _, err := reader.ReadString('\n'); err != nil {
+			return
+		}
+		module, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		switch module = strings.TrimSpace(module); module {
+		case "":
+			fmt.Fprint(conn, "Welcome\n")
+			fmt.Fprintf(conn, "%-15s\t%s\n", "backup", "Backups")
+			fmt.Fprintf(conn, "%-15s\t%s\n", "priv", "Private")
+			fmt.Fprint(conn, "@RSYNCD: EXIT\n")
+		case "priv":
+			fmt.Fprint(conn, "@RSYNCD: AUTHREQD challenge\n")
+			response, _ := reader.ReadString('\n')
+			sum := md5.Sum([]byte("secret" + "challenge"))
+			if strings.TrimSpace(response) == "carol "+base64.RawStdEncoding.EncodeToString(sum[:]) {
+				fmt.Fprint(conn, "@RSYNCD: OK\n")
+			} else {
+				fmt.Fprint(conn, "@ERROR: auth failed on module priv\n")
+			}
+		default:
+			fmt.Fprintf(conn, "@ERROR: Unknown module '%s'\n", module)
+		}
+	})
+}
+
+func TestRsyncClientHandshake(t *testing.T) {
+	port := testRsyncServer(t)
+
+	t.Run("list modules", func(t *testing.T) {
+		result := runProtocolTest(t, &RsyncClient{}, &model.ProtocolConfig{Port: port})
+		expectProtocolSuccess(t, result, map[string]interface{}{"protocol_version": "31.0", "modules": []string{"backup", "priv"}})
+	})
+	t.Run("authenticated module", func(t *testing.T) {
+		result := runProtocolTest(t, &RsyncClient{}, &model.ProtocolConfig{Port: port, Username: "carol", Password: "secret",
+			Options: map[string]interface{}{"module": "priv"}})
+		expectProtocolSuccess(t, result, map[string]interface{}{"module": "priv", "authenticated": true})
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		result := runProtocolTest(t, &RsyncClient{}, &model.ProtocolConfig{Port: port, Username: "carol", Password: "wrong",
+			Options: map[string]interface{}{"module": "priv"}})
+		expectProtocolFailure(t, result, "auth failed on module priv")
+	})
+}
+
+func TestGitClientHandshake(t *testing.T) {
+	refs := map[string]interface{}{"refs": 3, "head": testGitHead, "default_branch": "main"}
+
+	t.Run("http", func(t *testing.T) {
+		port := testHTTPPort(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/repo.git/info/refs" || r.URL.Query().Get("service") != "git-upload-pack" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-git-upload-pack-advertisement")
+			io.WriteString(w, gitTestPacket("# service=git-upload-pack\n")+"0000"+gitTestAdvertisement())
+		}))
+		result := runProtocolTest(t, &GitClient{}, &model.ProtocolConfig{Port: port,
+			Options: map[string]interface{}{"path": "repo.git", "transport": "http"}})
+		expectProtocolSuccess(t, result, refs)
+	})
+
+	daemon := serveTest(t, func(conn net.Conn) {
+		reader := bufio.NewReader(conn)
+		var size [4]byte
+		if _, err := io.ReadFull(reader, size[:]); err != nil {
+			return
+		}
+		var length int
+		fmt.Sscanf(string(size[:]), "%04x", &length)
+		request := make([]byte, length-4)
+		if _, err := io.ReadFull(reader, request); err != nil {
+			return
+		}
+		if !strings.HasPrefix(string(request), "git-upload-pack /repo.git\x00") {
+			io.WriteString(conn, gitTestPacket("ERR access denied or repository not exported"))
+			return
+		}
+		io.WriteString(conn, gitTestAdvertisement())
+		io.ReadFull(reader, size[:])
+	})
+	t.Run("daemon", func(t *testing.T) {
+		result := runProtocolTest(t, &GitClient{}, &model.ProtocolConfig{Port: daemon,
+			Options: map[string]interface{}{"path": "repo.git", "transport": "git"}})
+		expectProtocolSuccess(t, result, refs)
+	})
+	t.Run("daemon missing repository", func(t *testing.T) {
+		result := runProtocolTest(t, &GitClient{}, &model.ProtocolConfig{Port: daemon,
+			Options: map[string]interface{}{"path": "missing.git", "transport": "git"}})
+		expectProtocolFailure(t, result, "repository not exported")
+	})
+
+	t.Run("ssh", func(t *testing.T) {
+		_, userKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		userSigner, err := ssh.NewSignerFromKey(userKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		port, fingerprint := testSSHServer(t, userSigner.PublicKey())
+
+		result := runProtocolTest(t, &GitClient{}, &model.ProtocolConfig{Port: port, Username: "alice", Password: "secret",
+			Options: map[string]interface{}{"path": "/repo.git", "transport": "ssh", "host_key_fingerprint": fingerprint}})
+		expectProtocolSuccess(t, result, refs)
+	})
+}
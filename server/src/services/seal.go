@@ -0,0 +1,208 @@
+package services
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/utils"
+	"io"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+const (
+	sealConfigID = 1
+	rootKeySize  = 32
+)
+
+var rootKeyCheckValue = []byte("aether-vault-root-key")
+
+// SealService owns the seal barrier. The root key only exists in memory while
+// unsealed; at rest it is split into Shamir shares held by operators.
+type SealService struct {
+	db      *gorm.DB
+	keyring *KeyringService
+
+	mutex  sync.Mutex
+	config *model.SealConfig
+	sealed bool
+	shares [][]byte
+}
+
+func NewSealService(db *gorm.DB, keyring *KeyringService) *SealService {
+	return &SealService{
+		db:      db,
+		keyring: keyring,
+		sealed:  true,
+	}
+}
+
+func (s *SealService) Load() error {
+	var config model.SealConfig
+	if err := s.db.First(&config, sealConfigID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load seal configuration: %w", err)
+	}
+
+	s.mutex.Lock()
+	s.config = &config
+	s.mutex.Unlock()
+
+	return nil
+}
+
+func (s *SealService) IsSealed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sealed
+}
+
+func (s *SealService) Status() *model.SealStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.status()
+}
+
+func (s *SealService) Initialize(secretShares, secretThreshold int) ([][]byte, error) {
+	if secretThreshold < 1 || secretShares < secretThreshold || secretShares > 255 || (secretShares > 1 && secretThreshold < 2) {
+		return nil, ErrInvalidSealConfig
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config != nil {
+		return nil, ErrAlreadyInitialized
+	}
+
+	rootKey := make([]byte, rootKeySize)
+	if _, err := io.ReadFull(rand.Reader, rootKey); err != nil {
+		return nil, fmt.Errorf("failed to generate root key: %w", err)
+	}
+
+	check, err := sealGCM(rootKey, rootKeyCheckValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal root key check: %w", err)
+	}
+
+	shares, err := utils.SplitSecret(rootKey, secretShares, secretThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed to split root key: %w", err)
+	}
+
+	config := &model.SealConfig{
+		ID:              sealConfigID,
+		SecretShares:    secretShares,
+		SecretThreshold: secretThreshold,
+		RootKeyCheck:    check,
+	}
+	if err := s.db.Create(config).Error; err != nil {
+		return nil, fmt.Errorf("failed to store seal configuration: %w", err)
+	}
+
+	// Take over a keyring created before initialization, or create the first
+	// key, so every keyring key is wrapped by the new root key.
+	if err := s.keyring.Unlock(rootKey); err != nil {
+		s.db.Delete(config)
+		return nil, fmt.Errorf("failed to initialize keyring: %w", err)
+	}
+	s.keyring.Lock()
+
+	s.config = config
+	s.sealed = true
+	s.shares = nil
+
+	return shares, nil
+}
+
+func (s *SealService) Unseal(share []byte) (*model.SealStatus, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config == nil {
+		return nil, ErrNotInitialized
+	}
+	if !s.sealed {
+		return s.status(), nil
+	}
+	if len(share) != rootKeySize+1 {
+		return s.status(), ErrInvalidUnsealKey
+	}
+
+	for _, existing := range s.shares {
+		if bytes.Equal(existing, share) {
+			return s.status(), nil
+		}
+	}
+	s.shares = append(s.shares, share)
+
+	if len(s.shares) < s.config.SecretThreshold {
+		return s.status(), nil
+	}
+
+	rootKey, err := utils.CombineShares(s.shares)
+	s.shares = nil
+	if err != nil {
+		return s.status(), ErrInvalidUnsealKey
+	}
+
+	if _, err := openGCM(rootKey, s.config.RootKeyCheck); err != nil {
+		return s.status(), ErrInvalidUnsealKey
+	}
+
+	if err := s.keyring.Unlock(rootKey); err != nil {
+		return s.status(), fmt.Errorf("failed to unlock keyring: %w", err)
+	}
+
+	s.sealed = false
+
+	return s.status(), nil
+}
+
+func (s *SealService) ResetUnseal() *model.SealStatus {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.shares = nil
+	return s.status()
+}
+
+func (s *SealService) Seal() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.config == nil {
+		return ErrNotInitialized
+	}
+
+	s.keyring.Lock()
+	s.sealed = true
+	s.shares = nil
+
+	return nil
+}
+
+func (s *SealService) status() *model.SealStatus {
+	status := &model.SealStatus{
+		Initialized: s.config != nil,
+		Sealed:      s.sealed,
+		Progress:    len(s.shares),
+	}
+	if s.config != nil {
+		status.Threshold = s.config.SecretThreshold
+		status.Shares = s.config.SecretShares
+	}
+	return status
+}
+
+var (
+	ErrAlreadyInitialized = errors.New("vault is already initialized")
+	ErrNotInitialized     = errors.New("vault is not initialized")
+	ErrInvalidSealConfig  = errors.New("secret_threshold must be between 1 and secret_shares, at least 2 when using multiple shares, and secret_shares at most 255")
+	ErrInvalidUnsealKey   = errors.New("invalid unseal key")
+)
@@ -1,44 +1,61 @@
 package services
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"github.com/skygenesisenterprise/aether-vault/server/src/model"
-	"io"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/pbkdf2"
 	"gorm.io/gorm"
 )
 
+// Expiry actions applied by the reaper to secrets past their expiry.
+const (
+	SecretExpiryDeactivate = "deactivate"
+	SecretExpiryDelete     = "delete"
+)
+
 type SecretService struct {
 	db           *gorm.DB
+	keyring      *KeyringService
 	cryptoKey    []byte
 	kdfSalt      []byte
 	kdfIter      int
+	maxVersions  int
 	auditService *AuditService
 }
 
-func NewSecretService(db *gorm.DB, encryptionKey string, kdfSalt string, kdfIter int, auditService *AuditService) *SecretService {
+func NewSecretService(db *gorm.DB, keyring *KeyringService, encryptionKey string, kdfSalt string, kdfIter int, maxVersions int, auditService *AuditService) *SecretService {
 	salt := []byte(kdfSalt)
 	key := pbkdf2.Key([]byte(encryptionKey), salt, kdfIter, 32, sha256.New)
 
 	return &SecretService{
 		db:           db,
+		keyring:      keyring,
 		cryptoKey:    key,
 		kdfSalt:      salt,
 		kdfIter:      kdfIter,
+		maxVersions:  maxVersions,
 		auditService: auditService,
 	}
 }
 
 func (s *SecretService) CreateSecret(secret *model.Secret, userID uuid.UUID) error {
-	encryptedValue, err := s.encrypt(secret.Value)
+	if secret.TeamID != nil {
+		if err := authorizeTeam(s.db, *secret.TeamID, userID, model.TeamPermissionWrite); err != nil {
+			if s.auditService != nil {
+				s.auditService.LogAction(userID, "secret_access_denied", "team", secret.TeamID.String(), false, "action=create "+err.Error())
+			}
+			return err
+		}
+	}
+
+	encryptedValue, err := s.encrypt(secret, secret.Value)
 	if err != nil {
 		return fmt.Errorf("failed to encrypt secret: %w", err)
 	}
@@ -48,28 +65,51 @@ func (s *SecretService) CreateSecret(secret *model.Secret, userID uuid.UUID) err
 	secret.Value = encryptedValue
 	secret.ValueHash = valueHash
 	secret.UserID = userID
+	secret.Version = 1
 
-	if err := s.db.Create(secret).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(secret).Error; err != nil {
+			return err
+		}
+		return tx.Create(&model.SecretVersion{
+			SecretID:  secret.ID,
+			Version:   secret.Version,
+			Value:     secret.Value,
+			ValueHash: secret.ValueHash,
+			CreatedBy: userID,
+		}).Error
+	})
+	if err != nil {
 		return fmt.Errorf("failed to create secret: %w", err)
 	}
 
 	if s.auditService != nil {
-		s.auditService.LogAction(userID, "secret_created", "secret", secret.ID.String(), true, "")
+		s.auditService.LogAction(userID, "secret_created", "secret", secret.ID.String(), true, teamDetails(secret.TeamID))
 	}
 
 	return nil
 }
 
 func (s *SecretService) GetSecretByID(id uuid.UUID, userID uuid.UUID) (*model.Secret, error) {
-	var secret model.Secret
-	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", id, userID, true).First(&secret).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrSecretNotFound
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkExpiry(secret, userID); err != nil {
+		return nil, err
+	}
+
+	if secret.Version > 0 {
+		current, err := s.findVersion(s.db, secret.ID, secret.Version)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVersionReadable(current); err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to get secret: %w", err)
 	}
 
-	decryptedValue, err := s.decrypt(secret.Value)
+	decryptedValue, err := s.decrypt(secret, secret.Value)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
 	}
@@ -80,21 +120,21 @@ func (s *SecretService) GetSecretByID(id uuid.UUID, userID uuid.UUID) (*model.Se
 		s.auditService.LogAction(userID, "secret_accessed", "secret", secret.ID.String(), true, "")
 	}
 
-	return &secret, nil
+	return secret, nil
 }
 
+// GetSecretsByUserID returns the user's own secrets and those shared with
+// the teams they belong to.
 func (s *SecretService) GetSecretsByUserID(userID uuid.UUID) ([]model.Secret, error) {
 	var secrets []model.Secret
-	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&secrets).Error; err != nil {
+	if err := s.db.Where("(user_id = ? OR team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)) AND is_active = ?", userID, userID, true).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&secrets).Error; err != nil {
 		return nil, fmt.Errorf("failed to get secrets: %w", err)
 	}
 
-	for i := range secrets {
-		decryptedValue, err := s.decrypt(secrets[i].Value)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decrypt secret: %w", err)
-		}
-		secrets[i].Value = decryptedValue
+	if err := s.markDestroyed(secrets); err != nil {
+		return nil, err
 	}
 
 	if s.auditService != nil {
@@ -105,12 +145,9 @@ func (s *SecretService) GetSecretsByUserID(userID uuid.UUID) ([]model.Secret, er
 }
 
 func (s *SecretService) UpdateSecret(id uuid.UUID, updates *model.UpdateSecretRequest, userID uuid.UUID) (*model.Secret, error) {
-	var secret model.Secret
-	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", id, userID, true).First(&secret).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrSecretNotFound
-		}
-		return nil, fmt.Errorf("failed to get secret: %w", err)
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionWrite)
+	if err != nil {
+		return nil, err
 	}
 
 	if updates.Name != nil {
@@ -119,13 +156,23 @@ func (s *SecretService) UpdateSecret(id uuid.UUID, updates *model.UpdateSecretRe
 	if updates.Description != nil {
 		secret.Description = *updates.Description
 	}
+	var newVersion *model.SecretVersion
 	if updates.Value != nil {
-		encryptedValue, err := s.encrypt(*updates.Value)
+		if secret.DataKey == "" {
+			if err := s.rewrapSecret(secret); err != nil {
+				return nil, fmt.Errorf("failed to migrate secret encryption: %w", err)
+			}
+		}
+
+		encryptedValue, err := s.encrypt(secret, *updates.Value)
 		if err != nil {
 			return nil, fmt.Errorf("failed to encrypt secret: %w", err)
 		}
-		secret.Value = encryptedValue
-		secret.ValueHash = s.hashValue(*updates.Value)
+		newVersion = &model.SecretVersion{
+			Value:     encryptedValue,
+			ValueHash: s.hashValue(*updates.Value),
+			CreatedBy: userID,
+		}
 	}
 	if updates.Type != nil {
 		secret.Type = *updates.Type
@@ -135,30 +182,61 @@ func (s *SecretService) UpdateSecret(id uuid.UUID, updates *model.UpdateSecretRe
 	}
 	if updates.ExpiresAt != nil {
 		secret.ExpiresAt = updates.ExpiresAt
+		secret.NotifiedAt = nil
 	}
 	if updates.IsActive != nil {
 		secret.IsActive = *updates.IsActive
 	}
+	if updates.MaxVersions != nil {
+		if *updates.MaxVersions < 0 {
+			return nil, ErrInvalidMaxVersions
+		}
+		secret.MaxVersions = *updates.MaxVersions
+	}
 
-	if err := s.db.Save(&secret).Error; err != nil {
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if newVersion != nil {
+			if err := s.appendVersion(tx, secret, newVersion); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(secret).Error; err != nil {
+			return err
+		}
+		return s.pruneVersions(tx, secret)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to update secret: %w", err)
 	}
 
-	decryptedValue, err := s.decrypt(secret.Value)
+	if s.auditService != nil {
+		details := ""
+		if newVersion != nil {
+			details = fmt.Sprintf("version=%d", newVersion.Version)
+		}
+		s.auditService.LogAction(userID, "secret_updated", "secret", secret.ID.String(), true, details)
+	}
+
+	if secret.Value == "" {
+		return secret, nil
+	}
+
+	decryptedValue, err := s.decrypt(secret, secret.Value)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
 	}
 	secret.Value = decryptedValue
 
-	if s.auditService != nil {
-		s.auditService.LogAction(userID, "secret_updated", "secret", secret.ID.String(), true, "")
-	}
-
-	return &secret, nil
+	return secret, nil
 }
 
 func (s *SecretService) DeleteSecret(id uuid.UUID, userID uuid.UUID) error {
-	if err := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.Secret{}).Error; err != nil {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(secret).Error; err != nil {
 		return fmt.Errorf("failed to delete secret: %w", err)
 	}
 
@@ -169,53 +247,833 @@ func (s *SecretService) DeleteSecret(id uuid.UUID, userID uuid.UUID) error {
 	return nil
 }
 
-func (s *SecretService) encrypt(plaintext string) (string, error) {
-	block, err := aes.NewCipher(s.cryptoKey)
+func (s *SecretService) GetSecretVersion(id uuid.UUID, userID uuid.UUID, version int) (*model.SecretVersionResponse, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionRead)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := s.checkExpiry(secret, userID); err != nil {
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	secretVersion, err := s.findVersion(s.db, secret.ID, version)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := checkVersionReadable(secretVersion); err != nil {
+		return nil, err
 	}
 
-	nonce := make([]byte, gcm.NonceSize())
-	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
-		return "", err
+	decryptedValue, err := s.decrypt(secret, secretVersion.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+
+	response := toSecretVersionResponse(secretVersion)
+	response.Value = decryptedValue
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_version_accessed", "secret", secret.ID.String(), true, fmt.Sprintf("version=%d", version))
 	}
 
-	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
-	return base64.StdEncoding.EncodeToString(ciphertext), nil
+	return &response, nil
 }
 
-func (s *SecretService) decrypt(ciphertext string) (string, error) {
-	data, err := base64.StdEncoding.DecodeString(ciphertext)
+func (s *SecretService) ListSecretVersions(id uuid.UUID, userID uuid.UUID) (*model.ListSecretVersionsResponse, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionRead)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	block, err := aes.NewCipher(s.cryptoKey)
+	var versions []model.SecretVersion
+	if err := s.db.Where("secret_id = ?", secret.ID).Order("version DESC").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get secret versions: %w", err)
+	}
+
+	response := &model.ListSecretVersionsResponse{
+		Versions:       make([]model.SecretVersionResponse, 0, len(versions)),
+		Total:          len(versions),
+		CurrentVersion: secret.Version,
+		MaxVersions:    s.effectiveMaxVersions(secret),
+	}
+	for i := range versions {
+		response.Versions = append(response.Versions, toSecretVersionResponse(&versions[i]))
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_versions_listed", "secret", secret.ID.String(), true, "")
+	}
+
+	return response, nil
+}
+
+func (s *SecretService) RestoreSecretVersion(id uuid.UUID, userID uuid.UUID, version int) (*model.Secret, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := s.findVersion(s.db, secret.ID, version)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVersionReadable(source); err != nil {
+		return nil, err
+	}
+
+	restored := &model.SecretVersion{
+		Value:     source.Value,
+		ValueHash: source.ValueHash,
+		CreatedBy: userID,
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := s.appendVersion(tx, secret, restored); err != nil {
+			return err
+		}
+		if err := tx.Save(secret).Error; err != nil {
+			return err
+		}
+		return s.pruneVersions(tx, secret)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore secret version: %w", err)
+	}
+
+	decryptedValue, err := s.decrypt(secret, secret.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	secret.Value = decryptedValue
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_version_restored", "secret", secret.ID.String(), true, fmt.Sprintf("from=%d to=%d", version, restored.Version))
+	}
+
+	return secret, nil
+}
+
+func (s *SecretService) DeleteSecretVersions(id uuid.UUID, userID uuid.UUID, versions []int) error {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&model.SecretVersion{}).
+		Where("secret_id = ? AND version IN ? AND destroyed = ? AND deleted_at IS NULL", secret.ID, versions, false).
+		Update("deleted_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to delete secret versions: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_versions_deleted", "secret", secret.ID.String(), true, fmt.Sprintf("versions=%v", versions))
+	}
+
+	return nil
+}
+
+func (s *SecretService) UndeleteSecretVersions(id uuid.UUID, userID uuid.UUID, versions []int) error {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionWrite)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Model(&model.SecretVersion{}).
+		Where("secret_id = ? AND version IN ? AND destroyed = ?", secret.ID, versions, false).
+		Update("deleted_at", nil).Error; err != nil {
+		return fmt.Errorf("failed to undelete secret versions: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_versions_undeleted", "secret", secret.ID.String(), true, fmt.Sprintf("versions=%v", versions))
+	}
+
+	return nil
+}
+
+func (s *SecretService) DestroySecretVersions(id uuid.UUID, userID uuid.UUID, versions []int) error {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.SecretVersion{}).
+			Where("secret_id = ? AND version IN ?", secret.ID, versions).
+			Updates(map[string]interface{}{"value": "", "value_hash": "", "destroyed": true}).Error; err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			if version == secret.Version {
+				return tx.Model(secret).Updates(map[string]interface{}{"value": "", "value_hash": ""}).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to destroy secret versions: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_versions_destroyed", "secret", secret.ID.String(), true, fmt.Sprintf("versions=%v", versions))
+	}
+
+	return nil
+}
+
+// GetTeamSecrets lists the secrets in a team's collection.
+func (s *SecretService) GetTeamSecrets(teamID uuid.UUID, userID uuid.UUID) ([]model.Secret, error) {
+	if err := authorizeTeam(s.db, teamID, userID, model.TeamPermissionRead); err != nil {
+		if s.auditService != nil {
+			s.auditService.LogAction(userID, "secret_access_denied", "team", teamID.String(), false, "action=list "+err.Error())
+		}
+		return nil, err
+	}
+
+	var secrets []model.Secret
+	if err := s.db.Where("team_id = ? AND is_active = ?", teamID, true).
+		Where("expires_at IS NULL OR expires_at > ?", time.Now()).
+		Find(&secrets).Error; err != nil {
+		return nil, fmt.Errorf("failed to get team secrets: %w", err)
+	}
+
+	if err := s.markDestroyed(secrets); err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secrets_listed", "team", teamID.String(), true, "")
+	}
+
+	return secrets, nil
+}
+
+// markDestroyed flags listed secrets whose current version was destroyed.
+// Listings never return values, so nothing is decrypted and the stored
+// ciphertext is dropped; values are read one secret at a time with
+// GetSecret.
+func (s *SecretService) markDestroyed(secrets []model.Secret) error {
+	current := make(map[uuid.UUID]*model.Secret, len(secrets))
+	for i := range secrets {
+		secrets[i].Value = ""
+		current[secrets[i].ID] = &secrets[i]
+	}
+	if len(current) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(current))
+	for id := range current {
+		ids = append(ids, id)
+	}
+	var destroyed []model.SecretVersion
+	if err := s.db.Select("secret_id", "version").
+		Where("secret_id IN ? AND destroyed = ?", ids, true).
+		Find(&destroyed).Error; err != nil {
+		return fmt.Errorf("failed to get secret versions: %w", err)
+	}
+	for _, version := range destroyed {
+		if secret := current[version.SecretID]; secret.Version == version.Version {
+			secret.Destroyed = true
+		}
+	}
+	return nil
+}
+
+// ShareSecret places a secret in a team's collection, replacing any earlier
+// share. The value stays encrypted under the secret's own data key; sharing
+// only changes who may ask for it.
+func (s *SecretService) ShareSecret(id uuid.UUID, userID uuid.UUID, teamID uuid.UUID) (*model.Secret, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeTeam(s.db, teamID, userID, model.TeamPermissionWrite); err != nil {
+		if s.auditService != nil {
+			s.auditService.LogAction(userID, "secret_share_denied", "secret", secret.ID.String(), false, "team="+teamID.String()+" "+err.Error())
+		}
+		return nil, err
+	}
+
+	previous := teamDetails(secret.TeamID)
+	if err := s.db.Model(secret).Update("team_id", teamID).Error; err != nil {
+		return nil, fmt.Errorf("failed to share secret: %w", err)
+	}
+	secret.TeamID = &teamID
+	secret.Value = ""
+
+	if s.auditService != nil {
+		details := "team=" + teamID.String()
+		if previous != "" {
+			details += " previous_" + previous
+		}
+		s.auditService.LogAction(userID, "secret_shared", "secret", secret.ID.String(), true, details)
+	}
+
+	return secret, nil
+}
+
+// UnshareSecret takes a secret out of its team's collection; only its owner
+// keeps access.
+func (s *SecretService) UnshareSecret(id uuid.UUID, userID uuid.UUID) (*model.Secret, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return nil, err
+	}
+	if secret.TeamID == nil {
+		return nil, ErrSecretNotShared
+	}
+
+	previous := teamDetails(secret.TeamID)
+	if err := s.db.Model(secret).Update("team_id", nil).Error; err != nil {
+		return nil, fmt.Errorf("failed to unshare secret: %w", err)
+	}
+	secret.TeamID = nil
+	secret.Value = ""
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_unshared", "secret", secret.ID.String(), true, previous)
+	}
+
+	return secret, nil
+}
+
+// TransferSecret hands ownership of a secret to another active user. Team
+// shares are kept, so members of its team retain their access.
+func (s *SecretService) TransferSecret(id uuid.UUID, userID uuid.UUID, newOwnerID uuid.UUID) (*model.Secret, error) {
+	secret, err := s.authorize(s.db, id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return nil, err
+	}
+
+	var owner model.User
+	if err := s.db.Where("id = ? AND is_active = ?", newOwnerID, true).First(&owner).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	previousOwner := secret.UserID
+	if err := s.db.Model(secret).Update("user_id", newOwnerID).Error; err != nil {
+		return nil, fmt.Errorf("failed to transfer secret: %w", err)
+	}
+	secret.UserID = newOwnerID
+	secret.Value = ""
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_ownership_transferred", "secret", secret.ID.String(), true,
+			fmt.Sprintf("from=%s to=%s", previousOwner, newOwnerID))
+	}
+
+	return secret, nil
+}
+
+// StartExpiryJob periodically announces secrets expiring within lead (zero
+// disables the warnings) and applies the expiry action to expired ones.
+// notifier may be nil, in which case events only go to the audit log.
+func (s *SecretService) StartExpiryJob(interval, lead time.Duration, action string, notifier ExpiryNotifier) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if lead > 0 {
+				notified, err := s.NotifyExpiring(lead, notifier)
+				if err != nil {
+					log.Printf("⚠️  Secret expiry warnings failed: %v", err)
+				} else if notified > 0 {
+					log.Printf("⏰ %d secrets expire within %s", notified, lead)
+				}
+			}
+
+			reaped, err := s.ReapExpired(action, notifier)
+			if err != nil {
+				log.Printf("⚠️  Secret expiry reaper failed: %v", err)
+				continue
+			}
+			if reaped > 0 {
+				log.Printf("🧹 Applied %q to %d expired secrets", action, reaped)
+			}
+		}
+	}()
+}
+
+// NotifyExpiring emits one "expiring soon" event per secret whose expiry
+// falls within lead. Changing a secret's expiry re-arms its warning.
+func (s *SecretService) NotifyExpiring(lead time.Duration, notifier ExpiryNotifier) (int, error) {
+	now := time.Now()
+
+	var secrets []model.Secret
+	if err := s.db.Where("is_active = ? AND notified_at IS NULL AND expires_at > ? AND expires_at <= ?", true, now, now.Add(lead)).
+		Find(&secrets).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expiring secrets: %w", err)
+	}
+
+	for i := range secrets {
+		secret := &secrets[i]
+		if err := s.db.Model(secret).UpdateColumn("notified_at", now).Error; err != nil {
+			return i, fmt.Errorf("failed to mark secret %s as notified: %w", secret.ID, err)
+		}
+		s.emitExpiryEvent(notifier, "secret_expiring", secret, "")
+	}
+
+	return len(secrets), nil
+}
+
+// ReapExpired deactivates or deletes every active secret past its expiry,
+// depending on action.
+func (s *SecretService) ReapExpired(action string, notifier ExpiryNotifier) (int, error) {
+	var secrets []model.Secret
+	if err := s.db.Where("is_active = ? AND expires_at IS NOT NULL AND expires_at <= ?", true, time.Now()).
+		Find(&secrets).Error; err != nil {
+		return 0, fmt.Errorf("failed to find expired secrets: %w", err)
+	}
+
+	for i := range secrets {
+		secret := &secrets[i]
+
+		var err error
+		if action == SecretExpiryDelete {
+			err = s.db.Delete(secret).Error
+		} else {
+			err = s.db.Model(secret).UpdateColumn("is_active", false).Error
+		}
+		if err != nil {
+			return i, fmt.Errorf("failed to expire secret %s: %w", secret.ID, err)
+		}
+		s.emitExpiryEvent(notifier, "secret_expired", secret, action)
+	}
+
+	return len(secrets), nil
+}
+
+func (s *SecretService) emitExpiryEvent(notifier ExpiryNotifier, action string, secret *model.Secret, expiryAction string) {
+	if s.auditService != nil {
+		details := fmt.Sprintf("owner=%s expires_at=%s", secret.UserID, secret.ExpiresAt.UTC().Format(time.RFC3339))
+		if secret.TeamID != nil {
+			details += " " + teamDetails(secret.TeamID)
+		}
+		if expiryAction != "" {
+			details += " action=" + expiryAction
+		}
+		s.auditService.LogAnonymousAction(action, "secret", secret.ID.String(), "", "", true, details)
+	}
+
+	if notifier == nil {
+		return
+	}
+	event := &model.SecretExpiryEvent{
+		Event:     action,
+		SecretID:  secret.ID,
+		Name:      secret.Name,
+		OwnerID:   secret.UserID,
+		TeamID:    secret.TeamID,
+		ExpiresAt: *secret.ExpiresAt,
+		Action:    expiryAction,
+		Timestamp: time.Now(),
+	}
+	if err := notifier.NotifySecretExpiry(event); err != nil {
+		log.Printf("⚠️  Failed to notify %s for secret %s: %v", action, secret.ID, err)
+	}
+}
+
+func (s *SecretService) EnsureVersionHistory() error {
+	var secrets []model.Secret
+	if err := s.db.Where("version = ?", 0).Find(&secrets).Error; err != nil {
+		return fmt.Errorf("failed to find unversioned secrets: %w", err)
+	}
+
+	for i := range secrets {
+		secret := &secrets[i]
+		err := s.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&model.SecretVersion{
+				SecretID:  secret.ID,
+				Version:   1,
+				Value:     secret.Value,
+				ValueHash: secret.ValueHash,
+				CreatedBy: secret.UserID,
+				CreatedAt: secret.UpdatedAt,
+			}).Error; err != nil {
+				return err
+			}
+			return tx.Model(secret).Update("version", 1).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to backfill version for secret %s: %w", secret.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// authorize loads an active secret and checks that the user may act on it
+// with the wanted permission: owners hold every permission, team members
+// the one their team grants. Secrets the user cannot see at all are
+// reported as not found; every refusal is audited.
+func (s *SecretService) authorize(tx *gorm.DB, id uuid.UUID, userID uuid.UUID, want string) (*model.Secret, error) {
+	secret, err := s.findSecret(tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if secret.UserID == userID {
+		return secret, nil
+	}
+
+	permission := ""
+	if secret.TeamID != nil {
+		if permission, err = teamPermission(tx, *secret.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if model.TeamPermissionAllows(permission, want) {
+		return secret, nil
+	}
+
+	if s.auditService != nil {
+		details := "required=" + want
+		if permission != "" {
+			details += " granted=" + permission
+		}
+		if secret.TeamID != nil {
+			details += " " + teamDetails(secret.TeamID)
+		}
+		s.auditService.LogAction(userID, "secret_access_denied", "secret", secret.ID.String(), false, details)
+	}
+	if permission == "" {
+		return nil, ErrSecretNotFound
+	}
+	return nil, ErrSecretAccessDenied
+}
+
+func (s *SecretService) findSecret(tx *gorm.DB, id uuid.UUID) (*model.Secret, error) {
+	var secret model.Secret
+	if err := tx.Where("id = ? AND is_active = ?", id, true).First(&secret).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSecretNotFound
+		}
+		return nil, fmt.Errorf("failed to get secret: %w", err)
+	}
+	return &secret, nil
+}
+
+// storeInternal seals value as a new version of the secret id, or as a new
+// secret owned by userID when id is nil or no longer exists, and returns the
+// secret's ID. It skips the owner and team checks and the audit log:
+// services keeping their credentials in secrets guard and audit access to
+// the referencing record themselves, after tx commits.
+func (s *SecretService) storeInternal(tx *gorm.DB, id *uuid.UUID, name, value string, userID uuid.UUID) (uuid.UUID, error) {
+	if id != nil {
+		secret, err := s.findSecret(tx, *id)
+		if err == nil && secret.DataKey != "" {
+			encryptedValue, err := s.encrypt(secret, value)
+			if err != nil {
+				return uuid.Nil, fmt.Errorf("failed to encrypt secret: %w", err)
+			}
+			secretVersion := &model.SecretVersion{
+				Value:     encryptedValue,
+				ValueHash: s.hashValue(value),
+				CreatedBy: userID,
+			}
+			if err := s.appendVersion(tx, secret, secretVersion); err != nil {
+				return uuid.Nil, fmt.Errorf("failed to update secret: %w", err)
+			}
+			if err := tx.Save(secret).Error; err != nil {
+				return uuid.Nil, fmt.Errorf("failed to update secret: %w", err)
+			}
+			if err := s.pruneVersions(tx, secret); err != nil {
+				return uuid.Nil, fmt.Errorf("failed to update secret: %w", err)
+			}
+			return secret.ID, nil
+		}
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return uuid.Nil, err
+		}
+	}
+
+	secret := &model.Secret{
+		UserID:   userID,
+		Name:     name,
+		Type:     model.SecretTypePassword,
+		IsActive: true,
+		Version:  1,
+	}
+	encryptedValue, err := s.encrypt(secret, value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	secret.Value = encryptedValue
+	secret.ValueHash = s.hashValue(value)
+
+	if err := tx.Create(secret).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create secret: %w", err)
+	}
+	if err := tx.Create(&model.SecretVersion{
+		SecretID:  secret.ID,
+		Version:   secret.Version,
+		Value:     secret.Value,
+		ValueHash: secret.ValueHash,
+		CreatedBy: userID,
+	}).Error; err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create secret: %w", err)
+	}
+	return secret.ID, nil
+}
+
+// readInternal opens the current value of the secret id without the owner
+// and team checks; see storeInternal.
+func (s *SecretService) readInternal(id uuid.UUID) (string, error) {
+	secret, err := s.findSecret(s.db, id)
 	if err != nil {
 		return "", err
 	}
+	if secret.IsExpired(time.Now()) {
+		return "", ErrSecretExpired
+	}
+	if secret.Version > 0 {
+		current, err := s.findVersion(s.db, secret.ID, secret.Version)
+		if err != nil {
+			return "", err
+		}
+		if err := checkVersionReadable(current); err != nil {
+			return "", err
+		}
+	}
+
+	value, err := s.decrypt(secret, secret.Value)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return value, nil
+}
+
+// deleteInternal deletes the secret id without the owner and team checks;
+// see storeInternal.
+func (s *SecretService) deleteInternal(tx *gorm.DB, id uuid.UUID) error {
+	if err := tx.Where("id = ?", id).Delete(&model.Secret{}).Error; err != nil {
+		return fmt.Errorf("failed to delete secret: %w", err)
+	}
+	return nil
+}
+
+// checkExpiry refuses reads of expired secrets that the reaper has not yet
+// handled.
+func (s *SecretService) checkExpiry(secret *model.Secret, userID uuid.UUID) error {
+	if !secret.IsExpired(time.Now()) {
+		return nil
+	}
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "secret_accessed", "secret", secret.ID.String(), false, ErrSecretExpired.Error())
+	}
+	return ErrSecretExpired
+}
+
+func teamDetails(teamID *uuid.UUID) string {
+	if teamID == nil {
+		return ""
+	}
+	return "team=" + teamID.String()
+}
+
+func (s *SecretService) findVersion(tx *gorm.DB, secretID uuid.UUID, version int) (*model.SecretVersion, error) {
+	var secretVersion model.SecretVersion
+	if err := tx.Where("secret_id = ? AND version = ?", secretID, version).First(&secretVersion).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSecretVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get secret version: %w", err)
+	}
+	return &secretVersion, nil
+}
+
+func (s *SecretService) appendVersion(tx *gorm.DB, secret *model.Secret, secretVersion *model.SecretVersion) error {
+	secret.Version++
+	secret.Value = secretVersion.Value
+	secret.ValueHash = secretVersion.ValueHash
+
+	secretVersion.SecretID = secret.ID
+	secretVersion.Version = secret.Version
+	return tx.Create(secretVersion).Error
+}
+
+func (s *SecretService) pruneVersions(tx *gorm.DB, secret *model.Secret) error {
+	maxVersions := s.effectiveMaxVersions(secret)
+	if maxVersions <= 0 {
+		return nil
+	}
+
+	return tx.Where("secret_id = ? AND version <= ?", secret.ID, secret.Version-maxVersions).
+		Delete(&model.SecretVersion{}).Error
+}
+
+func (s *SecretService) effectiveMaxVersions(secret *model.Secret) int {
+	if secret.MaxVersions > 0 {
+		return secret.MaxVersions
+	}
+	return s.maxVersions
+}
+
+func checkVersionReadable(secretVersion *model.SecretVersion) error {
+	if secretVersion.Destroyed {
+		return ErrSecretVersionDestroyed
+	}
+	if secretVersion.DeletedAt != nil {
+		return ErrSecretVersionDeleted
+	}
+	return nil
+}
+
+func toSecretVersionResponse(secretVersion *model.SecretVersion) model.SecretVersionResponse {
+	return model.SecretVersionResponse{
+		ID:        secretVersion.ID,
+		SecretID:  secretVersion.SecretID,
+		Version:   secretVersion.Version,
+		CreatedBy: secretVersion.CreatedBy,
+		CreatedAt: secretVersion.CreatedAt,
+		DeletedAt: secretVersion.DeletedAt,
+		Destroyed: secretVersion.Destroyed,
+	}
+}
+
+func (s *SecretService) Name() string {
+	return "secrets"
+}
+
+func (s *SecretService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Unscoped().Model(&model.Secret{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count secrets pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *SecretService) RewrapKeys(batchSize int) (int, error) {
+	var secrets []model.Secret
+	if err := s.db.Unscoped().Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&secrets).Error; err != nil {
+		return 0, fmt.Errorf("failed to find secrets pending rewrap: %w", err)
+	}
+
+	for i := range secrets {
+		if err := s.rewrapSecret(&secrets[i]); err != nil {
+			return i, fmt.Errorf("failed to rewrap secret %s: %w", secrets[i].ID, err)
+		}
+	}
+
+	return len(secrets), nil
+}
+
+// rewrapSecret moves a secret's data key to the active keyring version. Legacy
+// secrets encrypted directly with the configured key get a fresh data key and
+// every stored ciphertext, including old versions, is re-encrypted under it.
+func (s *SecretService) rewrapSecret(secret *model.Secret) error {
+	if secret.DataKey != "" {
+		dataKey, err := s.keyring.UnwrapDataKey(secret.DataKey, secret.KeyVersion)
+		if err != nil {
+			return err
+		}
+		wrapped, version, err := s.keyring.WrapDataKey(dataKey)
+		if err != nil {
+			return err
+		}
+
+		if err := s.db.Unscoped().Model(secret).UpdateColumns(map[string]interface{}{
+			"data_key":    wrapped,
+			"key_version": version,
+		}).Error; err != nil {
+			return err
+		}
+		secret.DataKey, secret.KeyVersion = wrapped, version
+		return nil
+	}
 
-	gcm, err := cipher.NewGCM(block)
+	dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return err
+	}
+
+	reencrypt := func(ciphertext string) (string, error) {
+		if ciphertext == "" {
+			return "", nil
+		}
+		plaintext, err := s.decryptLegacy(ciphertext)
+		if err != nil {
+			return "", err
+		}
+		return sealGCM(dataKey, []byte(plaintext))
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var versions []model.SecretVersion
+		if err := tx.Where("secret_id = ?", secret.ID).Find(&versions).Error; err != nil {
+			return err
+		}
+		for i := range versions {
+			value, err := reencrypt(versions[i].Value)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&versions[i]).UpdateColumn("value", value).Error; err != nil {
+				return err
+			}
+		}
+
+		value, err := reencrypt(secret.Value)
+		if err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(secret).UpdateColumns(map[string]interface{}{
+			"value":       value,
+			"data_key":    wrapped,
+			"key_version": version,
+		}).Error; err != nil {
+			return err
+		}
+
+		secret.Value, secret.DataKey, secret.KeyVersion = value, wrapped, version
+		return nil
+	})
+}
+
+func (s *SecretService) encrypt(secret *model.Secret, plaintext string) (string, error) {
+	if secret.DataKey == "" {
+		dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+		if err != nil {
+			return "", err
+		}
+		secret.DataKey, secret.KeyVersion = wrapped, version
+		return sealGCM(dataKey, []byte(plaintext))
+	}
+
+	dataKey, err := s.keyring.UnwrapDataKey(secret.DataKey, secret.KeyVersion)
 	if err != nil {
 		return "", err
 	}
+	return sealGCM(dataKey, []byte(plaintext))
+}
 
-	nonceSize := gcm.NonceSize()
-	if len(data) < nonceSize {
-		return "", errors.New("ciphertext too short")
+func (s *SecretService) decrypt(secret *model.Secret, ciphertext string) (string, error) {
+	if secret.DataKey == "" {
+		return s.decryptLegacy(ciphertext)
 	}
 
-	nonce, ciphertext_bytes := data[:nonceSize], data[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext_bytes, nil)
+	dataKey, err := s.keyring.UnwrapDataKey(secret.DataKey, secret.KeyVersion)
 	if err != nil {
 		return "", err
 	}
 
+	plaintext, err := openGCM(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func (s *SecretService) decryptLegacy(ciphertext string) (string, error) {
+	plaintext, err := openGCM(s.cryptoKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
 	return string(plaintext), nil
 }
 
@@ -225,6 +1083,12 @@ func (s *SecretService) hashValue(value string) string {
 }
 
 var (
-	ErrSecretNotFound = errors.New("secret not found")
-	ErrSecretExpired  = errors.New("secret has expired")
+	ErrSecretNotFound         = errors.New("secret not found")
+	ErrSecretExpired          = errors.New("secret has expired")
+	ErrSecretVersionNotFound  = errors.New("secret version not found")
+	ErrSecretVersionDeleted   = errors.New("secret version has been deleted")
+	ErrSecretVersionDestroyed = errors.New("secret version has been destroyed")
+	ErrInvalidMaxVersions     = errors.New("max versions must not be negative")
+	ErrSecretAccessDenied     = errors.New("insufficient permission on secret")
+	ErrSecretNotShared        = errors.New("secret is not shared with a team")
 )
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// touchInterval limits how often a session's last activity is written.
+const touchInterval = time.Minute
+
+// SessionService tracks logins. Revoking a session invalidates its refresh
+// token family and every access token carrying its ID.
+type SessionService struct {
+	db          *gorm.DB
+	idleTimeout time.Duration
+}
+
+func NewSessionService(db *gorm.DB, idleTimeout time.Duration) *SessionService {
+	return &SessionService{
+		db:          db,
+		idleTimeout: idleTimeout,
+	}
+}
+
+func (s *SessionService) Create(session *model.Session) error {
+	session.IsActive = true
+	session.LastActivity = time.Now()
+	if err := s.db.Create(session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// Refresh records the access token issued by a refresh token rotation.
+func (s *SessionService) Refresh(sessionID uuid.UUID, tokenID string, expiresAt time.Time) error {
+	if err := s.db.Model(&model.Session{}).Where("id = ?", sessionID).Updates(map[string]interface{}{
+		"token_id":      tokenID,
+		"last_activity": time.Now(),
+		"expires_at":    expiresAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+	return nil
+}
+
+// CheckActive returns ErrSessionNotFound unless the session is active and
+// neither idle nor expired.
+func (s *SessionService) CheckActive(sessionID uuid.UUID) error {
+	_, err := s.GetActive(sessionID)
+	return err
+}
+
+// GetActive returns a session that is active and neither idle nor expired.
+func (s *SessionService) GetActive(sessionID uuid.UUID) (*model.Session, error) {
+	var session model.Session
+	if err := s.db.Where("id = ? AND is_active = ?", sessionID, true).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	now := time.Now()
+	if now.After(session.ExpiresAt) || now.Sub(session.LastActivity) > s.idleTimeout {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// Touch updates the last activity of a session, at most once per
+// touchInterval.
+func (s *SessionService) Touch(sessionID uuid.UUID) {
+	now := time.Now()
+	if err := s.db.Model(&model.Session{}).
+		Where("id = ? AND last_activity < ?", sessionID, now.Add(-touchInterval)).
+		Update("last_activity", now).Error; err != nil {
+		log.Printf("⚠️  Failed to update session %s activity: %v", sessionID, err)
+	}
+}
+
+func (s *SessionService) List(filter *model.ListSessionsRequest) ([]model.Session, int64, error) {
+	query := s.db.Model(&model.Session{})
+	if filter.IdentityID != "" {
+		query = query.Where("user_id = ?", filter.IdentityID)
+	}
+	if filter.IsActive != nil {
+		query = query.Where("is_active = ?", *filter.IsActive)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count sessions: %w", err)
+	}
+
+	var sessions []model.Session
+	if err := query.Order("last_activity DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&sessions).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	return sessions, total, nil
+}
+
+// Revoke ends a session. When userID is not nil the session must belong to
+// that user.
+func (s *SessionService) Revoke(sessionID uuid.UUID, userID *uuid.UUID) error {
+	query := s.db.Model(&model.Session{}).Where("id = ?", sessionID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var session model.Session
+	if err := query.First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, []uuid.UUID{session.ID})
+	})
+}
+
+// RevokeAll ends every active session of a user.
+func (s *SessionService) RevokeAll(userID uuid.UUID) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeUserSessions(tx, userID)
+	})
+}
+
+// ExpireIdleSessions ends sessions that have been idle longer than the idle
+// timeout or have passed their expiry.
+func (s *SessionService) ExpireIdleSessions() (int, error) {
+	now := time.Now()
+
+	var ids []uuid.UUID
+	if err := s.db.Model(&model.Session{}).
+		Where("is_active = ? AND (last_activity < ? OR expires_at < ?)", true, now.Add(-s.idleTimeout), now).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to find idle sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		return revokeSessions(tx, ids)
+	}); err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+func (s *SessionService) StartSweeper(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			expired, err := s.ExpireIdleSessions()
+			if err != nil {
+				log.Printf("⚠️  Session sweep failed: %v", err)
+				continue
+			}
+			if expired > 0 {
+				log.Printf("🧹 Expired %d idle sessions", expired)
+			}
+		}
+	}()
+}
+
+func revokeSessions(tx *gorm.DB, ids []uuid.UUID) error {
+	now := time.Now()
+	if err := tx.Model(&model.Session{}).Where("id IN ? AND is_active = ?", ids, true).Updates(map[string]interface{}{
+		"is_active":  false,
+		"revoked_at": now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if err := tx.Model(&model.RefreshToken{}).Where("family_id IN ? AND revoked_at IS NULL", ids).Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke session refresh tokens: %w", err)
+	}
+	return nil
+}
+
+func revokeUserSessions(tx *gorm.DB, userID uuid.UUID) error {
+	var ids []uuid.UUID
+	if err := tx.Model(&model.Session{}).Where("user_id = ? AND is_active = ?", userID, true).Pluck("id", &ids).Error; err != nil {
+		return fmt.Errorf("failed to find user sessions: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	return revokeSessions(tx, ids)
+}
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+)
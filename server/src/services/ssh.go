@@ -0,0 +1,681 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSSHRoleTTL    = time.Hour
+	defaultSSHRoleMaxTTL = 24 * time.Hour
+
+	// sshBackdate covers clock skew between the vault and the SSH servers.
+	sshBackdate = 30 * time.Second
+
+	sshAllowAll = "*"
+)
+
+// KRL layout from OpenSSH's PROTOCOL.krl.
+const (
+	krlMagic                 = 0x5353484b524c0a00
+	krlFormatVersion         = 1
+	krlSectionCertificates   = 1
+	krlSectionCertSerialList = 0x20
+)
+
+// SSHService is the SSH secrets engine: a CA signing user and host public
+// keys into OpenSSH certificates within the limits of a role, and
+// publishing revoked serials as a KRL.
+type SSHService struct {
+	db      *gorm.DB
+	keyring *KeyringService
+
+	mutex sync.Mutex
+	krl   []byte
+}
+
+func NewSSHService(db *gorm.DB, keyring *KeyringService) *SSHService {
+	return &SSHService{
+		db:      db,
+		keyring: keyring,
+	}
+}
+
+// ConfigureCA generates the CA key, or imports req.PrivateKey.
+func (s *SSHService) ConfigureCA(req *model.SSHConfigCARequest, userID uuid.UUID) (*model.SSHCA, error) {
+	var count int64
+	if err := s.db.Model(&model.SSHCA{}).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check SSH CA: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrSSHCAExists
+	}
+
+	var key crypto.Signer
+	var err error
+	if req.PrivateKey != "" {
+		key, err = parseSSHCAKey(req.PrivateKey)
+	} else {
+		key, err = generateSSHCAKey(req.KeyType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, err := ssh.NewPublicKey(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHCAKey, err)
+	}
+
+	ca := &model.SSHCA{
+		PublicKey: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		KeyType:   publicKey.Type(),
+		CreatedBy: &userID,
+	}
+	if err := s.sealCAKey(ca, key); err != nil {
+		return nil, err
+	}
+	if err := s.db.Create(ca).Error; err != nil {
+		return nil, fmt.Errorf("failed to store SSH CA: %w", err)
+	}
+
+	s.invalidateKRL()
+	return ca, nil
+}
+
+func (s *SSHService) GetCA() (*model.SSHCA, error) {
+	var ca model.SSHCA
+	if err := s.db.Order("created_at DESC").First(&ca).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSSHCANotFound
+		}
+		return nil, fmt.Errorf("failed to get SSH CA: %w", err)
+	}
+	return &ca, nil
+}
+
+// DeleteCA removes the CA key so a new one can be configured. Certificate
+// records are kept.
+func (s *SSHService) DeleteCA() error {
+	result := s.db.Where("1 = 1").Delete(&model.SSHCA{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete SSH CA: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSSHCANotFound
+	}
+	s.invalidateKRL()
+	return nil
+}
+
+// WriteRole creates or replaces a role.
+func (s *SSHService) WriteRole(req *model.SSHRoleRequest) (*model.SSHRole, error) {
+	role := &model.SSHRole{
+		Name:                   req.Name,
+		CertType:               req.CertType,
+		AllowedPrincipals:      joinSSHList(req.AllowedPrincipals),
+		DefaultPrincipals:      joinSSHList(req.DefaultPrincipals),
+		AllowedExtensions:      joinSSHList(req.AllowedExtensions),
+		AllowedCriticalOptions: joinSSHList(req.AllowedCriticalOptions),
+		TTL:                    req.TTL,
+		MaxTTL:                 req.MaxTTL,
+	}
+	if role.CertType == "" {
+		role.CertType = model.SSHCertTypeUser
+	}
+	if role.CertType != model.SSHCertTypeUser && role.CertType != model.SSHCertTypeHost {
+		return nil, fmt.Errorf("%w: cert type must be user or host", ErrInvalidSSHRole)
+	}
+	if role.AllowedPrincipals == "" {
+		return nil, fmt.Errorf("%w: allowed principals are required", ErrInvalidSSHRole)
+	}
+
+	defaultExtensions := req.DefaultExtensions
+	if role.CertType == model.SSHCertTypeHost {
+		if role.AllowedExtensions != "" || len(defaultExtensions) > 0 {
+			return nil, fmt.Errorf("%w: host certificates cannot carry extensions", ErrInvalidSSHRole)
+		}
+	} else if role.AllowedExtensions == "" && len(defaultExtensions) == 0 {
+		// A user certificate without permit-pty cannot open a shell
+		role.AllowedExtensions = "permit-pty"
+		defaultExtensions = map[string]string{"permit-pty": ""}
+	}
+
+	for _, check := range []struct {
+		allowed  string
+		defaults map[string]string
+		target   *string
+	}{
+		{role.AllowedExtensions, defaultExtensions, &role.DefaultExtensions},
+		{role.AllowedCriticalOptions, req.DefaultCriticalOptions, &role.DefaultCriticalOptions},
+	} {
+		for name := range check.defaults {
+			if !sshListAllows(check.allowed, name) {
+				return nil, fmt.Errorf("%w: default %q is not allowed", ErrInvalidSSHRole, name)
+			}
+		}
+		encoded, err := encodeSSHOptions(check.defaults)
+		if err != nil {
+			return nil, err
+		}
+		*check.target = encoded
+	}
+
+	for _, principal := range req.DefaultPrincipals {
+		if !sshListAllows(role.AllowedPrincipals, principal) {
+			return nil, fmt.Errorf("%w: default principal %q is not allowed", ErrInvalidSSHRole, principal)
+		}
+	}
+
+	if role.TTL < 0 || role.MaxTTL < 0 {
+		return nil, ErrInvalidSSHRoleTTL
+	}
+	if role.MaxTTL == 0 {
+		role.MaxTTL = int(defaultSSHRoleMaxTTL / time.Second)
+	}
+	if role.TTL == 0 {
+		role.TTL = int(defaultSSHRoleTTL / time.Second)
+		if role.TTL > role.MaxTTL {
+			role.TTL = role.MaxTTL
+		}
+	}
+	if role.TTL > role.MaxTTL {
+		return nil, ErrInvalidSSHRoleTTL
+	}
+
+	var existing model.SSHRole
+	err := s.db.Where("name = ?", req.Name).First(&existing).Error
+	switch {
+	case err == nil:
+		role.ID = existing.ID
+		role.CreatedAt = existing.CreatedAt
+		if err := s.db.Save(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to update SSH role: %w", err)
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := s.db.Create(role).Error; err != nil {
+			return nil, fmt.Errorf("failed to create SSH role: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("failed to get SSH role: %w", err)
+	}
+	return role, nil
+}
+
+func (s *SSHService) ListRoles() ([]model.SSHRole, error) {
+	var roles []model.SSHRole
+	if err := s.db.Order("name ASC").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("failed to list SSH roles: %w", err)
+	}
+	return roles, nil
+}
+
+func (s *SSHService) GetRole(name string) (*model.SSHRole, error) {
+	var role model.SSHRole
+	if err := s.db.Where("name = ?", name).First(&role).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSSHRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to get SSH role: %w", err)
+	}
+	return &role, nil
+}
+
+func (s *SSHService) DeleteRole(name string) error {
+	result := s.db.Where("name = ?", name).Delete(&model.SSHRole{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete SSH role: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrSSHRoleNotFound
+	}
+	return nil
+}
+
+// Sign signs a public key into a certificate within the role's limits. The
+// key ID names the role and the key's fingerprint, so every certificate of a
+// key can be revoked at once.
+func (s *SSHService) Sign(roleName string, req *model.SSHSignRequest, userID uuid.UUID) (*model.SSHSignResponse, error) {
+	role, err := s.GetRole(roleName)
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHPublicKey, err)
+	}
+	if _, ok := publicKey.(*ssh.Certificate); ok {
+		return nil, fmt.Errorf("%w: certificates cannot be signed", ErrInvalidSSHPublicKey)
+	}
+
+	principals := req.ValidPrincipals
+	if len(principals) == 0 {
+		principals = splitSSHList(role.DefaultPrincipals)
+	}
+	if len(principals) == 0 {
+		return nil, fmt.Errorf("%w: at least one principal is required", ErrInvalidSSHRequest)
+	}
+	for _, principal := range principals {
+		if principal == "" || !sshListAllows(role.AllowedPrincipals, principal) {
+			return nil, fmt.Errorf("%w: %q", ErrSSHPrincipalNotAllowed, principal)
+		}
+	}
+
+	extensions, err := sshPermissions(req.Extensions, role.DefaultExtensions, role.AllowedExtensions, "extension")
+	if err != nil {
+		return nil, err
+	}
+	criticalOptions, err := sshPermissions(req.CriticalOptions, role.DefaultCriticalOptions, role.AllowedCriticalOptions, "critical option")
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := req.TTL
+	if ttl <= 0 {
+		ttl = role.TTL
+	}
+	if ttl > role.MaxTTL {
+		ttl = role.MaxTTL
+	}
+
+	signer, err := s.signer()
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := newSSHSerial()
+	if err != nil {
+		return nil, err
+	}
+
+	certType := uint32(ssh.UserCert)
+	if role.CertType == model.SSHCertTypeHost {
+		certType = ssh.HostCert
+	}
+	now := time.Now()
+	validBefore := now.Add(time.Duration(ttl) * time.Second)
+	fingerprint := ssh.FingerprintSHA256(publicKey)
+	cert := &ssh.Certificate{
+		Key:             publicKey,
+		Serial:          serial,
+		CertType:        certType,
+		KeyId:           fmt.Sprintf("vault-%s-%s", role.Name, fingerprint),
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(now.Add(-sshBackdate).Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+		Permissions: ssh.Permissions{
+			CriticalOptions: criticalOptions,
+			Extensions:      extensions,
+		},
+	}
+	if err := cert.SignCert(rand.Reader, signer); err != nil {
+		return nil, fmt.Errorf("failed to sign SSH certificate: %w", err)
+	}
+
+	record := &model.SSHCertificate{
+		Serial:      serial,
+		KeyID:       cert.KeyId,
+		Role:        role.Name,
+		CertType:    role.CertType,
+		Principals:  strings.Join(principals, ","),
+		Fingerprint: fingerprint,
+		ValidAfter:  time.Unix(int64(cert.ValidAfter), 0),
+		ValidBefore: time.Unix(int64(cert.ValidBefore), 0),
+		IssuedBy:    &userID,
+	}
+	if err := s.db.Create(record).Error; err != nil {
+		return nil, fmt.Errorf("failed to record SSH certificate: %w", err)
+	}
+
+	return &model.SSHSignResponse{
+		SerialNumber: serial,
+		KeyID:        cert.KeyId,
+		SignedKey:    string(ssh.MarshalAuthorizedKey(cert)),
+		ValidBefore:  record.ValidBefore,
+	}, nil
+}
+
+// Revoke revokes a certificate by serial, or every certificate with a key
+// ID, and refreshes the KRL. Already revoked certificates are skipped.
+func (s *SSHService) Revoke(req *model.SSHRevokeRequest) (*model.SSHRevokeResponse, error) {
+	query := s.db.Model(&model.SSHCertificate{})
+	switch {
+	case req.SerialNumber != 0 && req.KeyID == "":
+		query = query.Where("serial = ?", req.SerialNumber)
+	case req.KeyID != "" && req.SerialNumber == 0:
+		query = query.Where("key_id = ?", req.KeyID)
+	default:
+		return nil, fmt.Errorf("%w: either a serial number or a key ID is required", ErrInvalidSSHRequest)
+	}
+
+	var certs []model.SSHCertificate
+	if err := query.Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find SSH certificates: %w", err)
+	}
+	if len(certs) == 0 {
+		return nil, ErrSSHCertificateNotFound
+	}
+
+	revoked := []uint64{}
+	for _, cert := range certs {
+		if cert.RevokedAt == nil {
+			revoked = append(revoked, cert.Serial)
+		}
+	}
+	if len(revoked) > 0 {
+		if err := s.db.Model(&model.SSHCertificate{}).Where("serial IN ?", revoked).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return nil, fmt.Errorf("failed to revoke SSH certificates: %w", err)
+		}
+		s.invalidateKRL()
+	}
+
+	return &model.SSHRevokeResponse{Revoked: revoked}, nil
+}
+
+// ListCertificates returns certificate records, newest first, optionally
+// only those of one role or only revoked ones.
+func (s *SSHService) ListCertificates(role string, revoked bool) ([]model.SSHCertificate, error) {
+	query := s.db.Model(&model.SSHCertificate{})
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	if revoked {
+		query = query.Where("revoked_at IS NOT NULL")
+	}
+
+	var certs []model.SSHCertificate
+	if err := query.Order("created_at DESC").Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("failed to list SSH certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// KRL returns an OpenSSH key revocation list of the revoked certificates
+// that have not expired, for RevokedKeys or ssh-keygen -Q.
+func (s *SSHService) KRL() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.krl != nil {
+		return s.krl, nil
+	}
+
+	ca, err := s.GetCA()
+	if err != nil {
+		return nil, err
+	}
+	caKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(ca.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH CA key: %w", err)
+	}
+
+	var serials []uint64
+	if err := s.db.Model(&model.SSHCertificate{}).
+		Where("revoked_at IS NOT NULL AND valid_before > ?", time.Now()).
+		Order("serial ASC").Pluck("serial", &serials).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revoked SSH certificates: %w", err)
+	}
+
+	now := uint64(time.Now().Unix())
+	var krl bytes.Buffer
+	binary.Write(&krl, binary.BigEndian, uint64(krlMagic))
+	binary.Write(&krl, binary.BigEndian, uint32(krlFormatVersion))
+	// The generation time doubles as the KRL version, which must increase
+	binary.Write(&krl, binary.BigEndian, now)
+	binary.Write(&krl, binary.BigEndian, now)
+	binary.Write(&krl, binary.BigEndian, uint64(0))
+	writeSSHString(&krl, nil)
+	writeSSHString(&krl, []byte("aether-vault"))
+
+	if len(serials) > 0 {
+		var section bytes.Buffer
+		writeSSHString(&section, caKey.Marshal())
+		writeSSHString(&section, nil)
+
+		var list bytes.Buffer
+		for _, serial := range serials {
+			binary.Write(&list, binary.BigEndian, serial)
+		}
+		section.WriteByte(krlSectionCertSerialList)
+		writeSSHString(&section, list.Bytes())
+
+		krl.WriteByte(krlSectionCertificates)
+		writeSSHString(&krl, section.Bytes())
+	}
+
+	s.krl = krl.Bytes()
+	return s.krl, nil
+}
+
+func (s *SSHService) Name() string {
+	return "ssh"
+}
+
+func (s *SSHService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.SSHCA{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count SSH CA keys pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *SSHService) RewrapKeys(batchSize int) (int, error) {
+	var cas []model.SSHCA
+	if err := s.db.Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&cas).Error; err != nil {
+		return 0, fmt.Errorf("failed to find SSH CA keys pending rewrap: %w", err)
+	}
+
+	for i := range cas {
+		ca := &cas[i]
+		dataKey, err := s.keyring.UnwrapDataKey(ca.DataKey, ca.KeyVersion)
+		if err != nil {
+			return i, fmt.Errorf("failed to rewrap SSH CA key %s: %w", ca.ID, err)
+		}
+		if ca.DataKey, ca.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+			return i, fmt.Errorf("failed to rewrap SSH CA key %s: %w", ca.ID, err)
+		}
+
+		if err := s.db.Model(ca).UpdateColumns(map[string]interface{}{
+			"data_key":    ca.DataKey,
+			"key_version": ca.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update SSH CA key %s: %w", ca.ID, err)
+		}
+	}
+
+	return len(cas), nil
+}
+
+func (s *SSHService) signer() (ssh.Signer, error) {
+	ca, err := s.GetCA()
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey, err := s.keyring.UnwrapDataKey(ca.DataKey, ca.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := openGCM(dataKey, ca.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	signer, err := ssh.ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH CA key: %w", err)
+	}
+	return signer, nil
+}
+
+func (s *SSHService) sealCAKey(ca *model.SSHCA, key crypto.Signer) error {
+	block, err := ssh.MarshalPrivateKey(key, "")
+	if err != nil {
+		return fmt.Errorf("failed to encode SSH CA key: %w", err)
+	}
+
+	dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return err
+	}
+
+	sealed, err := sealGCM(dataKey, pem.EncodeToMemory(block))
+	if err != nil {
+		return err
+	}
+
+	ca.PrivateKey, ca.DataKey, ca.KeyVersion = sealed, wrapped, version
+	return nil
+}
+
+func (s *SSHService) invalidateKRL() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.krl = nil
+}
+
+func generateSSHCAKey(keyType string) (crypto.Signer, error) {
+	var key crypto.Signer
+	var err error
+	switch keyType {
+	case "", "ed25519":
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	case "rsa":
+		key, err = rsa.GenerateKey(rand.Reader, 4096)
+	case "ec":
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSSHCAKey, keyType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate SSH CA key: %w", err)
+	}
+	return key, nil
+}
+
+func parseSSHCAKey(raw string) (crypto.Signer, error) {
+	key, err := ssh.ParseRawPrivateKey([]byte(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSSHCAKey, err)
+	}
+
+	// OpenSSH-format Ed25519 keys parse to a pointer
+	if k, ok := key.(*ed25519.PrivateKey); ok {
+		key = *k
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%w: unsupported key type", ErrInvalidSSHCAKey)
+	}
+	return signer, nil
+}
+
+// sshPermissions returns the requested extensions or critical options, or
+// the role's defaults when none are requested, checked against the role.
+func sshPermissions(requested map[string]string, defaults, allowed, kind string) (map[string]string, error) {
+	if requested == nil {
+		var options map[string]string
+		if defaults != "" {
+			if err := json.Unmarshal([]byte(defaults), &options); err != nil {
+				return nil, fmt.Errorf("failed to decode role %ss: %w", kind, err)
+			}
+		}
+		return options, nil
+	}
+
+	for name := range requested {
+		if !sshListAllows(allowed, name) {
+			return nil, fmt.Errorf("%w: %s %q", ErrSSHOptionNotAllowed, kind, name)
+		}
+	}
+	return requested, nil
+}
+
+func encodeSSHOptions(options map[string]string) (string, error) {
+	if len(options) == 0 {
+		return "", nil
+	}
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+func sshListAllows(list, value string) bool {
+	for _, entry := range splitSSHList(list) {
+		if entry == sshAllowAll || entry == value {
+			return true
+		}
+	}
+	return false
+}
+
+func joinSSHList(values []string) string {
+	var entries []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			entries = append(entries, value)
+		}
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func splitSSHList(list string) []string {
+	if list == "" {
+		return nil
+	}
+	return strings.Split(list, ",")
+}
+
+// newSSHSerial returns a random non-zero 63-bit serial; SQL drivers cannot
+// store larger unsigned values.
+func newSSHSerial() (uint64, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).SetUint64(1<<63-1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return n.Uint64() + 1, nil
+}
+
+func writeSSHString(buf *bytes.Buffer, data []byte) {
+	binary.Write(buf, binary.BigEndian, uint32(len(data)))
+	buf.Write(data)
+}
+
+var (
+	ErrSSHCANotFound          = errors.New("no SSH CA is configured")
+	ErrSSHCAExists            = errors.New("an SSH CA is already configured")
+	ErrSSHRoleNotFound        = errors.New("SSH role not found")
+	ErrSSHCertificateNotFound = errors.New("SSH certificate not found")
+	ErrSSHPrincipalNotAllowed = errors.New("principal not allowed by role")
+	ErrSSHOptionNotAllowed    = errors.New("option not allowed by role")
+	ErrInvalidSSHRole         = errors.New("invalid SSH role")
+	ErrInvalidSSHRoleTTL      = errors.New("TTL must not be negative or exceed the max TTL")
+	ErrInvalidSSHCAKey        = errors.New("invalid or unsupported SSH CA key")
+	ErrInvalidSSHPublicKey    = errors.New("invalid SSH public key")
+	ErrInvalidSSHRequest      = errors.New("invalid SSH signing request")
+)
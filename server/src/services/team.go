@@ -0,0 +1,227 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TeamService manages teams and their members. Every call is made on behalf
+// of an acting user and checked against that user's team permission; teams
+// the actor does not belong to are reported as not found.
+type TeamService struct {
+	db *gorm.DB
+}
+
+func NewTeamService(db *gorm.DB) *TeamService {
+	return &TeamService{db: db}
+}
+
+// CreateTeam creates a team with its creator as the first manager.
+func (s *TeamService) CreateTeam(team *model.Team, creatorID uuid.UUID) error {
+	var existing int64
+	if err := s.db.Model(&model.Team{}).Where("name = ?", team.Name).Count(&existing).Error; err != nil {
+		return fmt.Errorf("failed to check team: %w", err)
+	}
+	if existing > 0 {
+		return ErrTeamExists
+	}
+
+	team.CreatedBy = creatorID
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Members").Create(team).Error; err != nil {
+			return err
+		}
+		member := model.TeamMember{
+			TeamID:     team.ID,
+			UserID:     creatorID,
+			Permission: model.TeamPermissionManage,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return err
+		}
+		team.Members = []model.TeamMember{member}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+	return nil
+}
+
+// ListTeams returns the teams the user is a member of.
+func (s *TeamService) ListTeams(userID uuid.UUID) ([]model.Team, error) {
+	var teams []model.Team
+	if err := s.db.Where("id IN (SELECT team_id FROM team_members WHERE user_id = ?)", userID).
+		Order("name ASC").Find(&teams).Error; err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	return teams, nil
+}
+
+// GetTeam returns a team with its members; any member may read it.
+func (s *TeamService) GetTeam(id, userID uuid.UUID) (*model.Team, error) {
+	if err := authorizeTeam(s.db, id, userID, model.TeamPermissionRead); err != nil {
+		return nil, err
+	}
+
+	var team model.Team
+	if err := s.db.Preload("Members").Where("id = ?", id).First(&team).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return &team, nil
+}
+
+// DeleteTeam removes an empty team; items in its collection must be
+// unshared or moved first so none are orphaned.
+func (s *TeamService) DeleteTeam(id, userID uuid.UUID) error {
+	if err := authorizeTeam(s.db, id, userID, model.TeamPermissionManage); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range []interface{}{&model.Secret{}, &model.TOTP{}} {
+			var count int64
+			if err := tx.Model(item).Where("team_id = ?", id).Count(&count).Error; err != nil {
+				return fmt.Errorf("failed to check team collection: %w", err)
+			}
+			if count > 0 {
+				return ErrTeamNotEmpty
+			}
+		}
+
+		if err := tx.Where("team_id = ?", id).Delete(&model.TeamMember{}).Error; err != nil {
+			return fmt.Errorf("failed to remove team members: %w", err)
+		}
+		if err := tx.Where("id = ?", id).Delete(&model.Team{}).Error; err != nil {
+			return fmt.Errorf("failed to delete team: %w", err)
+		}
+		return nil
+	})
+}
+
+// SetMember adds a user to a team or changes their permission.
+func (s *TeamService) SetMember(teamID, actorID, userID uuid.UUID, permission string) error {
+	if !model.IsValidTeamPermission(permission) {
+		return ErrInvalidTeamPermission
+	}
+	if err := authorizeTeam(s.db, teamID, actorID, model.TeamPermissionManage); err != nil {
+		return err
+	}
+
+	var user model.User
+	if err := s.db.Where("id = ? AND is_active = ?", userID, true).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var member model.TeamMember
+		err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&model.TeamMember{
+				TeamID:     teamID,
+				UserID:     userID,
+				Permission: permission,
+			}).Error
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get team member: %w", err)
+		}
+
+		if member.Permission == model.TeamPermissionManage && permission != model.TeamPermissionManage {
+			if err := checkTeamManagerRemoval(tx, teamID); err != nil {
+				return err
+			}
+		}
+		return tx.Model(&member).Update("permission", permission).Error
+	})
+}
+
+// RemoveMember takes a user out of a team. Managers may remove anyone;
+// other members may only leave.
+func (s *TeamService) RemoveMember(teamID, actorID, userID uuid.UUID) error {
+	required := model.TeamPermissionManage
+	if actorID == userID {
+		required = model.TeamPermissionRead
+	}
+	if err := authorizeTeam(s.db, teamID, actorID, required); err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var member model.TeamMember
+		if err := tx.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTeamMemberNotFound
+			}
+			return fmt.Errorf("failed to get team member: %w", err)
+		}
+
+		if member.Permission == model.TeamPermissionManage {
+			if err := checkTeamManagerRemoval(tx, teamID); err != nil {
+				return err
+			}
+		}
+		return tx.Where("team_id = ? AND user_id = ?", teamID, userID).Delete(&model.TeamMember{}).Error
+	})
+}
+
+// teamPermission returns the user's permission on a team, or "" when the
+// user is not a member.
+func teamPermission(db *gorm.DB, teamID, userID uuid.UUID) (string, error) {
+	var member model.TeamMember
+	if err := db.Where("team_id = ? AND user_id = ?", teamID, userID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get team member: %w", err)
+	}
+	return member.Permission, nil
+}
+
+func authorizeTeam(db *gorm.DB, teamID, userID uuid.UUID, want string) error {
+	permission, err := teamPermission(db, teamID, userID)
+	if err != nil {
+		return err
+	}
+	if permission == "" {
+		return ErrTeamNotFound
+	}
+	if !model.TeamPermissionAllows(permission, want) {
+		return ErrTeamAccessDenied
+	}
+	return nil
+}
+
+// checkTeamManagerRemoval keeps at least one manager on every team.
+func checkTeamManagerRemoval(tx *gorm.DB, teamID uuid.UUID) error {
+	var managers int64
+	if err := tx.Model(&model.TeamMember{}).
+		Where("team_id = ? AND permission = ?", teamID, model.TeamPermissionManage).
+		Count(&managers).Error; err != nil {
+		return fmt.Errorf("failed to count team managers: %w", err)
+	}
+	if managers <= 1 {
+		return ErrLastTeamManager
+	}
+	return nil
+}
+
+var (
+	ErrTeamNotFound          = errors.New("team not found")
+	ErrTeamExists            = errors.New("team already exists")
+	ErrTeamNotEmpty          = errors.New("team still holds shared items")
+	ErrTeamAccessDenied      = errors.New("insufficient team permission")
+	ErrTeamMemberNotFound    = errors.New("team member not found")
+	ErrLastTeamManager       = errors.New("cannot remove the last manager of a team")
+	ErrInvalidTeamPermission = errors.New("permission must be read, write or manage")
+)
@@ -1,64 +1,159 @@
 package services
 
 import (
-	"github.com/skygenesisenterprise/aether-vault/server/src/model"
 	"crypto/rand"
 	"encoding/base32"
 	"errors"
 	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/config"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"github.com/skygenesisenterprise/aether-vault/server/utils"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/skip2/go-qrcode"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// totpMigrationBatchSize is the number of entries per otpauth-migration://
+// URI, which keeps each batch readable as one QR code.
+const totpMigrationBatchSize = 10
+
 type TOTPService struct {
 	db           *gorm.DB
+	keyring      *KeyringService
+	config       *config.TOTPConfig
 	auditService *AuditService
 }
 
-func NewTOTPService(db *gorm.DB, auditService *AuditService) *TOTPService {
+func NewTOTPService(db *gorm.DB, keyring *KeyringService, config *config.TOTPConfig, auditService *AuditService) *TOTPService {
 	return &TOTPService{
 		db:           db,
+		keyring:      keyring,
+		config:       config,
 		auditService: auditService,
 	}
 }
 
-func (s *TOTPService) CreateTOTP(totp *model.TOTP, userID uuid.UUID) error {
-	if totp.Secret == "" {
-		secret, err := s.generateSecret()
-		if err != nil {
-			return fmt.Errorf("failed to generate TOTP secret: %w", err)
-		}
-		totp.Secret = secret
+// CreateTOTP stores a new entry and returns its backup codes, which are
+// only kept as hashes.
+func (s *TOTPService) CreateTOTP(totp *model.TOTP, userID uuid.UUID) ([]string, error) {
+	if err := s.prepare(totp, userID); err != nil {
+		return nil, err
 	}
 
-	if totp.Algorithm == "" {
-		totp.Algorithm = "SHA1"
+	var codes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.insert(tx, totp)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create TOTP: %w", err)
 	}
-	if totp.Digits == 0 {
-		totp.Digits = 6
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "totp_created", "totp", totp.ID.String(), true, teamDetails(totp.TeamID))
 	}
-	if totp.Period == 0 {
-		totp.Period = 30
+
+	return codes, nil
+}
+
+// ImportTOTPs creates entries from otpauth:// and otpauth-migration://
+// URIs. Every URI is parsed before anything is stored, and the entries are
+// created together or not at all. A dry run returns the entries that would
+// be created.
+func (s *TOTPService) ImportTOTPs(req *model.TOTPImportRequest, userID uuid.UUID) ([]model.CreateTOTPResponse, error) {
+	var keys []utils.OTPAuthKey
+	for i, uri := range req.URIs {
+		var parsed []utils.OTPAuthKey
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(uri)), "otpauth-migration:") {
+			var err error
+			if parsed, err = utils.ParseOTPAuthMigration(uri); err != nil {
+				return nil, fmt.Errorf("%w: uri %d: %v", ErrInvalidTOTP, i+1, err)
+			}
+		} else {
+			key, err := utils.ParseOTPAuthURI(uri)
+			if err != nil {
+				return nil, fmt.Errorf("%w: uri %d: %v", ErrInvalidTOTP, i+1, err)
+			}
+			parsed = []utils.OTPAuthKey{*key}
+		}
+		keys = append(keys, parsed...)
 	}
 
-	totp.UserID = userID
+	totps := make([]*model.TOTP, len(keys))
+	for i, key := range keys {
+		name := key.Account
+		if name == "" {
+			name = key.Issuer
+		}
+		if name == "" {
+			return nil, fmt.Errorf("%w: entry %d has no account name", ErrInvalidTOTP, i+1)
+		}
 
-	if err := s.db.Create(totp).Error; err != nil {
-		return fmt.Errorf("failed to create TOTP: %w", err)
+		totps[i] = &model.TOTP{
+			Name:      name,
+			Issuer:    key.Issuer,
+			Secret:    key.Secret,
+			Algorithm: key.Algorithm,
+			Digits:    key.Digits,
+			Period:    key.Period,
+			IsActive:  true,
+			TeamID:    req.TeamID,
+		}
+		if req.DryRun {
+			if err := s.applyDefaults(totps[i]); err != nil {
+				return nil, fmt.Errorf("entry %d: %w", i+1, err)
+			}
+			continue
+		}
+		if err := s.prepare(totps[i], userID); err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+	}
+
+	imported := make([]model.CreateTOTPResponse, len(totps))
+	if req.DryRun {
+		for i, totp := range totps {
+			imported[i] = model.CreateTOTPResponse{TOTP: *totp}
+			imported[i].Secret = ""
+		}
+		return imported, nil
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for i, totp := range totps {
+			codes, err := s.insert(tx, totp)
+			if err != nil {
+				return err
+			}
+			imported[i] = model.CreateTOTPResponse{TOTP: *totp, BackupCodes: codes}
+			imported[i].Secret = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import TOTPs: %w", err)
 	}
 
 	if s.auditService != nil {
-		s.auditService.LogAction(userID, "totp_created", "totp", totp.ID.String(), true, "")
+		for _, totp := range totps {
+			s.auditService.LogAction(userID, "totp_imported", "totp", totp.ID.String(), true, teamDetails(totp.TeamID))
+		}
 	}
 
-	return nil
+	return imported, nil
 }
 
+// GetTOTPsByUserID returns the user's own TOTPs and those in the
+// collections of the teams they belong to.
 func (s *TOTPService) GetTOTPsByUserID(userID uuid.UUID) ([]model.TOTP, error) {
 	var totps []model.TOTP
-	if err := s.db.Where("user_id = ? AND is_active = ?", userID, true).Find(&totps).Error; err != nil {
+	if err := s.db.Where("(user_id = ? OR team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)) AND is_active = ?", userID, userID, true).
+		Find(&totps).Error; err != nil {
 		return nil, fmt.Errorf("failed to get TOTPs: %w", err)
 	}
 
@@ -74,29 +169,28 @@ func (s *TOTPService) GetTOTPsByUserID(userID uuid.UUID) ([]model.TOTP, error) {
 }
 
 func (s *TOTPService) GetTOTPByID(id uuid.UUID, userID uuid.UUID) (*model.TOTP, error) {
-	var totp model.TOTP
-	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", id, userID, true).First(&totp).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrTOTPNotFound
-		}
-		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	totp, err := s.authorize(id, userID, model.TeamPermissionRead)
+	if err != nil {
+		return nil, err
 	}
 
 	totp.Secret = ""
 
-	return &totp, nil
+	return totp, nil
 }
 
 func (s *TOTPService) GenerateCode(id uuid.UUID, userID uuid.UUID) (*model.TOTPGenerateResponse, error) {
-	var totp model.TOTP
-	if err := s.db.Where("id = ? AND user_id = ? AND is_active = ?", id, userID, true).First(&totp).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, ErrTOTPNotFound
-		}
-		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	totp, err := s.authorize(id, userID, model.TeamPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := s.openSecret(totp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
 	}
 
-	code, err := s.generateTOTPCode(totp.Secret, totp.Algorithm, totp.Digits, totp.Period)
+	code, err := s.generateTOTPCode(secret, totp.Algorithm, totp.Digits, totp.Period)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate TOTP code: %w", err)
 	}
@@ -117,8 +211,205 @@ func (s *TOTPService) GenerateCode(id uuid.UUID, userID uuid.UUID) (*model.TOTPG
 	return response, nil
 }
 
+// ExportURI returns the entry as an otpauth:// URI. The URI holds the seed,
+// so exporting is audited like reading a secret.
+func (s *TOTPService) ExportURI(id uuid.UUID, userID uuid.UUID) (string, error) {
+	key, err := s.exportKey(id, userID, "otpauth")
+	if err != nil {
+		return "", err
+	}
+	return key.URI(), nil
+}
+
+// ExportTOTPs returns every entry the user can read, as one otpauth:// URI
+// each or, for the "migration" format, as otpauth-migration:// batches that
+// Google Authenticator imports. Entries that format cannot hold are left
+// out and their names returned as skipped.
+func (s *TOTPService) ExportTOTPs(userID uuid.UUID, format string) ([]string, []string, error) {
+	if format != "otpauth" && format != "migration" {
+		return nil, nil, fmt.Errorf("%w: unsupported export format %q", ErrInvalidTOTP, format)
+	}
+
+	var totps []model.TOTP
+	if err := s.db.Where("(user_id = ? OR team_id IN (SELECT team_id FROM team_members WHERE user_id = ?)) AND is_active = ?", userID, userID, true).
+		Order("created_at").Find(&totps).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to get TOTPs: %w", err)
+	}
+
+	var keys []utils.OTPAuthKey
+	var skipped []string
+	for i := range totps {
+		key, err := s.otpAuthKey(&totps[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		if format == "migration" && key.CheckMigration() != nil {
+			skipped = append(skipped, totps[i].Name)
+			continue
+		}
+		keys = append(keys, *key)
+	}
+
+	uris := make([]string, len(keys))
+	if format == "migration" {
+		var err error
+		if uris, err = utils.FormatOTPAuthMigration(keys, totpMigrationBatchSize); err != nil {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidTOTP, err)
+		}
+	} else {
+		for i := range keys {
+			uris[i] = keys[i].URI()
+		}
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "totps_exported", "totp", "", true, fmt.Sprintf("format=%s count=%d", format, len(keys)))
+	}
+
+	return uris, skipped, nil
+}
+
+// QRCode renders the entry's otpauth:// URI as a PNG QR code of size pixels,
+// for authenticator apps to scan.
+func (s *TOTPService) QRCode(id uuid.UUID, userID uuid.UUID, size int) ([]byte, error) {
+	key, err := s.exportKey(id, userID, "qr")
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(key.URI(), qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render QR code: %w", err)
+	}
+	return png, nil
+}
+
+// Verify checks a code for the entry: a TOTP code within the drift window
+// whose time step has not been accepted before, or an unused backup code.
+// Failures in a row lock the entry for the configured lockout; while it is
+// locked, ErrTOTPLocked is returned with the time it unlocks. A wrong code
+// is not an error, only invalid.
+func (s *TOTPService) Verify(id uuid.UUID, userID uuid.UUID, req *model.TOTPVerifyRequest) (*model.TOTPVerifyResponse, error) {
+	totp, err := s.authorize(id, userID, model.TeamPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if totp.LockedUntil != nil && now.Before(*totp.LockedUntil) {
+		if s.auditService != nil {
+			s.auditService.LogAction(userID, "totp_verify_locked", "totp", totp.ID.String(), false, "")
+		}
+		return &model.TOTPVerifyResponse{LockedUntil: totp.LockedUntil}, ErrTOTPLocked
+	}
+
+	window := s.config.Skew
+	if req.Window != nil && *req.Window >= 0 && *req.Window < window {
+		window = *req.Window
+	}
+
+	code := strings.TrimSpace(req.Code)
+	resp := &model.TOTPVerifyResponse{}
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		step, err := s.matchCode(totp, code, window, now)
+		if err != nil {
+			return nil, err
+		}
+		if step > 0 {
+			// The condition on last_used_step keeps two concurrent requests
+			// from both accepting the same step
+			result := s.db.Model(&model.TOTP{}).
+				Where("id = ? AND last_used_step < ?", totp.ID, step).
+				Updates(map[string]interface{}{
+					"last_used_step":  step,
+					"last_used_at":    now,
+					"failed_attempts": 0,
+					"locked_until":    nil,
+				})
+			if result.Error != nil {
+				return nil, fmt.Errorf("failed to record TOTP code use: %w", result.Error)
+			}
+			if result.RowsAffected > 0 {
+				resp.Valid, resp.Counter = true, step
+			}
+		}
+	} else {
+		result := s.db.Model(&model.TOTPBackupCode{}).
+			Where("totp_id = ? AND code_hash = ? AND used_at IS NULL", totp.ID, hashRecoveryCode(code)).
+			Update("used_at", now)
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to record backup code use: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			if err := s.db.Model(&model.TOTP{}).Where("id = ?", totp.ID).Updates(map[string]interface{}{
+				"last_used_at":    now,
+				"failed_attempts": 0,
+				"locked_until":    nil,
+			}).Error; err != nil {
+				return nil, fmt.Errorf("failed to record backup code use: %w", err)
+			}
+			resp.Valid, resp.UsedBackup = true, true
+		}
+	}
+
+	if resp.Valid {
+		resp.LastUsed = &now
+		resp.RemainingAttempts = s.config.MaxFailedAttempts
+	} else {
+		resp.LastUsed = totp.LastUsedAt
+		if err := s.recordFailure(totp, now, resp); err != nil {
+			return nil, err
+		}
+	}
+
+	var remaining int64
+	if err := s.db.Model(&model.TOTPBackupCode{}).Where("totp_id = ? AND used_at IS NULL", totp.ID).Count(&remaining).Error; err != nil {
+		return nil, fmt.Errorf("failed to count backup codes: %w", err)
+	}
+	resp.RemainingBackupCodes = int(remaining)
+
+	if s.auditService != nil {
+		details := ""
+		if resp.UsedBackup {
+			details = "backup_code=true"
+		}
+		s.auditService.LogAction(userID, "totp_verified", "totp", totp.ID.String(), resp.Valid, details)
+	}
+
+	return resp, nil
+}
+
+// RegenerateBackupCodes replaces all backup codes of the entry.
+func (s *TOTPService) RegenerateBackupCodes(id uuid.UUID, userID uuid.UUID) ([]string, error) {
+	totp, err := s.authorize(id, userID, model.TeamPermissionWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceBackupCodes(tx, totp.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to regenerate backup codes: %w", err)
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "totp_backup_codes_regenerated", "totp", totp.ID.String(), true, "")
+	}
+
+	return codes, nil
+}
+
 func (s *TOTPService) DeleteTOTP(id uuid.UUID, userID uuid.UUID) error {
-	if err := s.db.Where("id = ? AND user_id = ?", id, userID).Delete(&model.TOTP{}).Error; err != nil {
+	totp, err := s.authorize(id, userID, model.TeamPermissionManage)
+	if err != nil {
+		return err
+	}
+
+	if err := s.db.Delete(totp).Error; err != nil {
 		return fmt.Errorf("failed to delete TOTP: %w", err)
 	}
 
@@ -129,6 +420,208 @@ func (s *TOTPService) DeleteTOTP(id uuid.UUID, userID uuid.UUID) error {
 	return nil
 }
 
+func (s *TOTPService) Name() string {
+	return "totp"
+}
+
+func (s *TOTPService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Unscoped().Model(&model.TOTP{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count TOTPs pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+// RewrapKeys encrypts legacy plaintext seeds (key version 0) and moves the
+// data keys of the others to the active keyring version.
+func (s *TOTPService) RewrapKeys(batchSize int) (int, error) {
+	var totps []model.TOTP
+	if err := s.db.Unscoped().Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&totps).Error; err != nil {
+		return 0, fmt.Errorf("failed to find TOTPs pending rewrap: %w", err)
+	}
+
+	for i := range totps {
+		totp := &totps[i]
+		if totp.KeyVersion == 0 {
+			if err := s.sealSecret(totp); err != nil {
+				return i, fmt.Errorf("failed to encrypt TOTP %s: %w", totp.ID, err)
+			}
+		} else {
+			dataKey, err := s.keyring.UnwrapDataKey(totp.DataKey, totp.KeyVersion)
+			if err != nil {
+				return i, fmt.Errorf("failed to rewrap TOTP %s: %w", totp.ID, err)
+			}
+			if totp.DataKey, totp.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+				return i, fmt.Errorf("failed to rewrap TOTP %s: %w", totp.ID, err)
+			}
+		}
+
+		if err := s.db.Unscoped().Model(totp).UpdateColumns(map[string]interface{}{
+			"secret":      totp.Secret,
+			"data_key":    totp.DataKey,
+			"key_version": totp.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update TOTP %s: %w", totp.ID, err)
+		}
+	}
+
+	return len(totps), nil
+}
+
+// prepare applies the defaults of a new entry, validates it, checks the
+// team permission and seals its seed.
+func (s *TOTPService) prepare(totp *model.TOTP, userID uuid.UUID) error {
+	if err := s.applyDefaults(totp); err != nil {
+		return err
+	}
+
+	totp.UserID = userID
+
+	if totp.TeamID != nil {
+		if err := authorizeTeam(s.db, *totp.TeamID, userID, model.TeamPermissionWrite); err != nil {
+			if s.auditService != nil {
+				s.auditService.LogAction(userID, "totp_access_denied", "team", totp.TeamID.String(), false, "action=create "+err.Error())
+			}
+			return err
+		}
+	}
+
+	if err := s.sealSecret(totp); err != nil {
+		return fmt.Errorf("failed to encrypt TOTP secret: %w", err)
+	}
+	return nil
+}
+
+func (s *TOTPService) applyDefaults(totp *model.TOTP) error {
+	if totp.Secret == "" {
+		secret, err := s.generateSecret()
+		if err != nil {
+			return fmt.Errorf("failed to generate TOTP secret: %w", err)
+		}
+		totp.Secret = secret
+	}
+
+	if totp.Algorithm == "" {
+		totp.Algorithm = "SHA1"
+	}
+	if totp.Digits == 0 {
+		totp.Digits = 6
+	}
+	if totp.Period == 0 {
+		totp.Period = 30
+	}
+	if totp.Period < 0 {
+		return fmt.Errorf("%w: period must be positive", ErrInvalidTOTP)
+	}
+	if _, err := utils.HOTPCode(totp.Secret, totp.Algorithm, totp.Digits, 0); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidTOTP, err)
+	}
+	return nil
+}
+
+// insert stores a prepared entry with a fresh set of backup codes.
+func (s *TOTPService) insert(tx *gorm.DB, totp *model.TOTP) ([]string, error) {
+	if err := tx.Create(totp).Error; err != nil {
+		return nil, err
+	}
+	return s.replaceBackupCodes(tx, totp.ID)
+}
+
+// exportKey returns the entry's key for an export in format, which is
+// audited.
+func (s *TOTPService) exportKey(id uuid.UUID, userID uuid.UUID, format string) (*utils.OTPAuthKey, error) {
+	totp, err := s.authorize(id, userID, model.TeamPermissionRead)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.otpAuthKey(totp)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.auditService != nil {
+		s.auditService.LogAction(userID, "totp_exported", "totp", totp.ID.String(), true, "format="+format)
+	}
+
+	return key, nil
+}
+
+func (s *TOTPService) otpAuthKey(totp *model.TOTP) (*utils.OTPAuthKey, error) {
+	secret, err := s.openSecret(totp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+
+	return &utils.OTPAuthKey{
+		Issuer:    totp.Issuer,
+		Account:   totp.Name,
+		Secret:    secret,
+		Algorithm: totp.Algorithm,
+		Digits:    totp.Digits,
+		Period:    totp.Period,
+	}, nil
+}
+
+// authorize loads an active TOTP and checks the user's access the same way
+// SecretService does: owners hold every permission, team members the one
+// their team grants.
+func (s *TOTPService) authorize(id uuid.UUID, userID uuid.UUID, want string) (*model.TOTP, error) {
+	var totp model.TOTP
+	if err := s.db.Where("id = ? AND is_active = ?", id, true).First(&totp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTOTPNotFound
+		}
+		return nil, fmt.Errorf("failed to get TOTP: %w", err)
+	}
+	if totp.UserID == userID {
+		return &totp, nil
+	}
+
+	permission := ""
+	if totp.TeamID != nil {
+		var err error
+		if permission, err = teamPermission(s.db, *totp.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+	if model.TeamPermissionAllows(permission, want) {
+		return &totp, nil
+	}
+
+	if s.auditService != nil {
+		details := "required=" + want
+		if permission != "" {
+			details += " granted=" + permission
+		}
+		if totp.TeamID != nil {
+			details += " " + teamDetails(totp.TeamID)
+		}
+		s.auditService.LogAction(userID, "totp_access_denied", "totp", totp.ID.String(), false, details)
+	}
+	if permission == "" {
+		return nil, ErrTOTPNotFound
+	}
+	return nil, ErrTOTPAccessDenied
+}
+
+func (s *TOTPService) sealSecret(totp *model.TOTP) error {
+	sealed, wrapped, version, err := sealOTPSeed(s.keyring, totp.Secret)
+	if err != nil {
+		return err
+	}
+
+	totp.Secret, totp.DataKey, totp.KeyVersion = sealed, wrapped, version
+	return nil
+}
+
+func (s *TOTPService) openSecret(totp *model.TOTP) (string, error) {
+	if totp.KeyVersion == 0 {
+		return totp.Secret, nil
+	}
+	return openOTPSeed(s.keyring, totp.Secret, totp.DataKey, totp.KeyVersion)
+}
+
 func (s *TOTPService) generateSecret() (string, error) {
 	secret := make([]byte, 20)
 	if _, err := rand.Read(secret); err != nil {
@@ -138,9 +631,79 @@ func (s *TOTPService) generateSecret() (string, error) {
 }
 
 func (s *TOTPService) generateTOTPCode(secret, algorithm string, digits, period int) (string, error) {
-	return "123456", nil
+	return utils.HOTPCode(secret, algorithm, digits, uint64(time.Now().Unix()/int64(period)))
+}
+
+// matchCode returns the time step matching code within window steps of now,
+// or 0 if none does. Steps already accepted do not match.
+func (s *TOTPService) matchCode(totp *model.TOTP, code string, window int, now time.Time) (int64, error) {
+	secret, err := s.openSecret(totp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decrypt TOTP secret: %w", err)
+	}
+	return matchOTPStep(secret, totp.Algorithm, totp.Digits, totp.Period, code, window, totp.LastUsedStep, now)
+}
+
+// recordFailure counts a failed verification and locks the entry once the
+// failures in a row reach the limit. The count is incremented and checked in
+// one UPDATE, so concurrent failures cannot all read the same count and slip
+// past the limit.
+func (s *TOTPService) recordFailure(totp *model.TOTP, now time.Time, resp *model.TOTPVerifyResponse) error {
+	lockedUntil := now.Add(time.Duration(s.config.LockoutDuration) * time.Second)
+	var updated model.TOTP
+	result := s.db.Model(&updated).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "failed_attempts"}}}).
+		Where("id = ?", totp.ID).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN 0 ELSE failed_attempts + 1 END", s.config.MaxFailedAttempts),
+			"locked_until":    gorm.Expr("CASE WHEN failed_attempts + 1 >= ? THEN ? ELSE locked_until END", s.config.MaxFailedAttempts, lockedUntil),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to record TOTP failure: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTOTPNotFound
+	}
+
+	// The lock restarts the count, so only the failure that set it reads
+	// back zero.
+	if updated.FailedAttempts > 0 {
+		resp.RemainingAttempts = s.config.MaxFailedAttempts - updated.FailedAttempts
+		return nil
+	}
+	resp.LockedUntil = &lockedUntil
+	if s.auditService != nil {
+		s.auditService.LogAction(totp.UserID, "totp_locked", "totp", totp.ID.String(), true, fmt.Sprintf("failed_attempts=%d", s.config.MaxFailedAttempts))
+	}
+	return nil
+}
+
+// replaceBackupCodes replaces the backup codes of an entry with a fresh set
+// from generateRecoveryCodes.
+func (s *TOTPService) replaceBackupCodes(tx *gorm.DB, totpID uuid.UUID) ([]string, error) {
+	if err := tx.Where("totp_id = ?", totpID).Delete(&model.TOTPBackupCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes, err := generateRecoveryCodes(s.config.BackupCodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := tx.Create(&model.TOTPBackupCode{
+			TOTPID:   totpID,
+			CodeHash: hashRecoveryCode(code),
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return codes, nil
 }
 
 var (
-	ErrTOTPNotFound = errors.New("TOTP not found")
+	ErrTOTPNotFound     = errors.New("TOTP not found")
+	ErrTOTPAccessDenied = errors.New("insufficient permission on TOTP")
+	ErrTOTPLocked       = errors.New("TOTP verification is locked after too many failed attempts")
+	ErrInvalidTOTP      = errors.New("invalid TOTP parameters")
 )
@@ -0,0 +1,887 @@
+package services
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/chacha20poly1305"
+	"gorm.io/gorm"
+)
+
+// transitPrefix starts every ciphertext, signature and HMAC the engine
+// returns, followed by "v<version>:" and the base64 payload.
+const transitPrefix = "vault:v"
+
+// TransitService is the transit secrets engine: it encrypts, signs and
+// HMACs data for applications with named, versioned keys that never leave
+// the vault.
+type TransitService struct {
+	db      *gorm.DB
+	keyring *KeyringService
+	mutex   sync.Mutex
+}
+
+func NewTransitService(db *gorm.DB, keyring *KeyringService) *TransitService {
+	return &TransitService{
+		db:      db,
+		keyring: keyring,
+	}
+}
+
+// transitMaterial is the sealed content of a key version: the raw key of a
+// symmetric type or the PKCS #8 private key of an asymmetric one.
+type transitMaterial struct {
+	Key     []byte `json:"key"`
+	HMACKey []byte `json:"hmac_key"`
+}
+
+// transitKeyVersion is an opened key version.
+type transitKeyVersion struct {
+	version int
+	keyType string
+	key     []byte
+	signer  crypto.Signer
+	hmacKey []byte
+}
+
+// CreateKey creates a key with its first version. The type defaults to
+// aes256-gcm96.
+func (s *TransitService) CreateKey(name string, req *model.TransitCreateKeyRequest, userID uuid.UUID) (*model.TransitKeyResponse, error) {
+	keyType := req.Type
+	if keyType == "" {
+		keyType = model.TransitKeyAES256GCM96
+	}
+	if !transitKeyTypeSupported(keyType) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTransitKeyType, keyType)
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTransitRequest)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var count int64
+	if err := s.db.Model(&model.TransitKey{}).Where("name = ?", name).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check transit key: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrTransitKeyExists
+	}
+
+	key := &model.TransitKey{
+		Name:                 name,
+		Type:                 keyType,
+		LatestVersion:        1,
+		MinDecryptionVersion: 1,
+		CreatedBy:            &userID,
+	}
+	version, err := s.newKeyVersion(keyType, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(key).Error; err != nil {
+			return fmt.Errorf("failed to create transit key: %w", err)
+		}
+		version.KeyID = key.ID
+		if err := tx.Create(version).Error; err != nil {
+			return fmt.Errorf("failed to create transit key version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.keyResponse(key)
+}
+
+func (s *TransitService) ListKeys() ([]model.TransitKey, error) {
+	var keys []model.TransitKey
+	if err := s.db.Order("name").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transit keys: %w", err)
+	}
+	return keys, nil
+}
+
+// GetKey returns a key with its versions and, for asymmetric keys, their
+// public keys.
+func (s *TransitService) GetKey(name string) (*model.TransitKeyResponse, error) {
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	return s.keyResponse(key)
+}
+
+// ConfigureKey sets the minimum versions and whether the key may be
+// deleted.
+func (s *TransitService) ConfigureKey(name string, req *model.TransitKeyConfigRequest) (*model.TransitKeyResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MinDecryptionVersion != nil {
+		key.MinDecryptionVersion = *req.MinDecryptionVersion
+	}
+	if req.MinEncryptionVersion != nil {
+		key.MinEncryptionVersion = *req.MinEncryptionVersion
+	}
+	if req.DeletionAllowed != nil {
+		key.DeletionAllowed = *req.DeletionAllowed
+	}
+
+	if key.MinDecryptionVersion < 1 || key.MinDecryptionVersion > key.LatestVersion {
+		return nil, fmt.Errorf("%w: min_decryption_version must be between 1 and %d", ErrInvalidTransitKeyConfig, key.LatestVersion)
+	}
+	if key.MinEncryptionVersion < 0 || key.MinEncryptionVersion > key.LatestVersion {
+		return nil, fmt.Errorf("%w: min_encryption_version must be between 0 and %d", ErrInvalidTransitKeyConfig, key.LatestVersion)
+	}
+	if key.MinEncryptionVersion != 0 && key.MinEncryptionVersion < key.MinDecryptionVersion {
+		return nil, fmt.Errorf("%w: min_encryption_version must not be below min_decryption_version", ErrInvalidTransitKeyConfig)
+	}
+
+	if err := s.db.Model(key).Updates(map[string]interface{}{
+		"min_decryption_version": key.MinDecryptionVersion,
+		"min_encryption_version": key.MinEncryptionVersion,
+		"deletion_allowed":       key.DeletionAllowed,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update transit key: %w", err)
+	}
+
+	return s.keyResponse(key)
+}
+
+// RotateKey adds a version, which becomes the one new ciphertexts and
+// signatures use. Older versions keep working down to MinDecryptionVersion.
+func (s *TransitService) RotateKey(name string) (*model.TransitKeyResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.newKeyVersion(key.Type, key.LatestVersion+1)
+	if err != nil {
+		return nil, err
+	}
+	version.KeyID = key.ID
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(version).Error; err != nil {
+			return fmt.Errorf("failed to create transit key version: %w", err)
+		}
+		if err := tx.Model(key).Update("latest_version", version.Version).Error; err != nil {
+			return fmt.Errorf("failed to update transit key: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	key.LatestVersion = version.Version
+
+	return s.keyResponse(key)
+}
+
+// DeleteKey removes a key and all its versions, which makes everything
+// encrypted with it unrecoverable. The key must allow deletion first.
+func (s *TransitService) DeleteKey(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return err
+	}
+	if !key.DeletionAllowed {
+		return ErrTransitDeletionNotAllowed
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("key_id = ?", key.ID).Delete(&model.TransitKeyVersion{}).Error; err != nil {
+			return fmt.Errorf("failed to delete transit key versions: %w", err)
+		}
+		if err := tx.Delete(key).Error; err != nil {
+			return fmt.Errorf("failed to delete transit key: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *TransitService) Encrypt(name string, req *model.TransitEncryptRequest) (*model.TransitEncryptResponse, error) {
+	plaintext, err := decodeTransitInput("plaintext", req.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	aad, err := decodeTransitInput("associated_data", req.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.encryptionVersion(key, req.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := version.encrypt(plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransitEncryptResponse{
+		Ciphertext: formatTransitValue(version.version, ciphertext),
+		KeyVersion: version.version,
+	}, nil
+}
+
+func (s *TransitService) Decrypt(name string, req *model.TransitDecryptRequest) (*model.TransitDecryptResponse, error) {
+	aad, err := decodeTransitInput("associated_data", req.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.decrypt(key, req.Ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransitDecryptResponse{
+		Plaintext: base64.StdEncoding.EncodeToString(plaintext),
+	}, nil
+}
+
+// Rewrap decrypts a ciphertext and encrypts it again with the latest key
+// version, or the requested one; the plaintext is never returned.
+func (s *TransitService) Rewrap(name string, req *model.TransitRewrapRequest) (*model.TransitEncryptResponse, error) {
+	aad, err := decodeTransitInput("associated_data", req.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := s.decrypt(key, req.Ciphertext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := s.encryptionVersion(key, req.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := version.encrypt(plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransitEncryptResponse{
+		Ciphertext: formatTransitValue(version.version, ciphertext),
+		KeyVersion: version.version,
+	}, nil
+}
+
+// GenerateDataKey creates a random key and returns it encrypted with the
+// transit key. kind "plaintext" also returns it in base64, "wrapped" only
+// encrypted, for callers that store it and decrypt it when needed.
+func (s *TransitService) GenerateDataKey(name, kind string, req *model.TransitDataKeyRequest) (*model.TransitDataKeyResponse, error) {
+	if kind != "plaintext" && kind != "wrapped" {
+		return nil, fmt.Errorf("%w: data key type must be plaintext or wrapped", ErrInvalidTransitRequest)
+	}
+	bits := req.Bits
+	if bits == 0 {
+		bits = 256
+	}
+	if bits != 128 && bits != 256 && bits != 512 {
+		return nil, fmt.Errorf("%w: bits must be 128, 256 or 512", ErrInvalidTransitRequest)
+	}
+	aad, err := decodeTransitInput("associated_data", req.AssociatedData)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.encryptionVersion(key, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dataKey := make([]byte, bits/8)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	ciphertext, err := version.encrypt(dataKey, aad)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &model.TransitDataKeyResponse{
+		Ciphertext: formatTransitValue(version.version, ciphertext),
+		KeyVersion: version.version,
+	}
+	if kind == "plaintext" {
+		resp.Plaintext = base64.StdEncoding.EncodeToString(dataKey)
+	}
+	return resp, nil
+}
+
+func (s *TransitService) Sign(name string, req *model.TransitSignRequest) (*model.TransitSignResponse, error) {
+	input, err := decodeTransitInput("input", req.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	if !transitKeySigns(key.Type) {
+		return nil, fmt.Errorf("%w: %s keys cannot sign", ErrTransitOperationUnsupported, key.Type)
+	}
+	version, err := s.encryptionVersion(key, req.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := version.sign(input, req.HashAlgorithm, req.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransitSignResponse{
+		Signature:  formatTransitValue(version.version, signature),
+		KeyVersion: version.version,
+	}, nil
+}
+
+// HMAC works with every key type, using the version's HMAC key.
+func (s *TransitService) HMAC(name string, req *model.TransitHMACRequest) (*model.TransitHMACResponse, error) {
+	input, err := decodeTransitInput("input", req.Input)
+	if err != nil {
+		return nil, err
+	}
+	hash, err := transitHash(req.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.encryptionVersion(key, req.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TransitHMACResponse{
+		HMAC:       formatTransitValue(version.version, version.hmac(hash, input)),
+		KeyVersion: version.version,
+	}, nil
+}
+
+// Verify checks a signature or an HMAC made by any version from
+// MinDecryptionVersion on. A mismatch is not an error, only invalid.
+func (s *TransitService) Verify(name string, req *model.TransitVerifyRequest) (*model.TransitVerifyResponse, error) {
+	if (req.Signature == "") == (req.HMAC == "") {
+		return nil, fmt.Errorf("%w: give either a signature or an hmac", ErrInvalidTransitRequest)
+	}
+	input, err := decodeTransitInput("input", req.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.getKey(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.HMAC != "" {
+		hash, err := transitHash(req.HashAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		version, mac, err := s.decryptionVersion(key, req.HMAC)
+		if err != nil {
+			return nil, err
+		}
+		return &model.TransitVerifyResponse{Valid: hmac.Equal(mac, version.hmac(hash, input))}, nil
+	}
+
+	if !transitKeySigns(key.Type) {
+		return nil, fmt.Errorf("%w: %s keys cannot sign", ErrTransitOperationUnsupported, key.Type)
+	}
+	version, signature, err := s.decryptionVersion(key, req.Signature)
+	if err != nil {
+		return nil, err
+	}
+	valid, err := version.verify(input, signature, req.HashAlgorithm, req.SignatureAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &model.TransitVerifyResponse{Valid: valid}, nil
+}
+
+func (s *TransitService) Name() string {
+	return "transit"
+}
+
+func (s *TransitService) PendingRewrap() (int64, error) {
+	var count int64
+	if err := s.db.Model(&model.TransitKeyVersion{}).Where("key_version < ?", s.keyring.ActiveVersion()).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count transit keys pending rewrap: %w", err)
+	}
+	return count, nil
+}
+
+func (s *TransitService) RewrapKeys(batchSize int) (int, error) {
+	var versions []model.TransitKeyVersion
+	if err := s.db.Where("key_version < ?", s.keyring.ActiveVersion()).Limit(batchSize).Find(&versions).Error; err != nil {
+		return 0, fmt.Errorf("failed to find transit keys pending rewrap: %w", err)
+	}
+
+	for i := range versions {
+		version := &versions[i]
+		dataKey, err := s.keyring.UnwrapDataKey(version.DataKey, version.KeyVersion)
+		if err != nil {
+			return i, fmt.Errorf("failed to rewrap transit key version %s: %w", version.ID, err)
+		}
+		if version.DataKey, version.KeyVersion, err = s.keyring.WrapDataKey(dataKey); err != nil {
+			return i, fmt.Errorf("failed to rewrap transit key version %s: %w", version.ID, err)
+		}
+
+		if err := s.db.Model(version).UpdateColumns(map[string]interface{}{
+			"data_key":    version.DataKey,
+			"key_version": version.KeyVersion,
+		}).Error; err != nil {
+			return i, fmt.Errorf("failed to update transit key version %s: %w", version.ID, err)
+		}
+	}
+
+	return len(versions), nil
+}
+
+func (s *TransitService) getKey(name string) (*model.TransitKey, error) {
+	var key model.TransitKey
+	if err := s.db.Where("name = ?", name).First(&key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTransitKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get transit key: %w", err)
+	}
+	return &key, nil
+}
+
+func (s *TransitService) keyResponse(key *model.TransitKey) (*model.TransitKeyResponse, error) {
+	var versions []model.TransitKeyVersion
+	if err := s.db.Where("key_id = ?", key.ID).Order("version").Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transit key versions: %w", err)
+	}
+
+	return &model.TransitKeyResponse{
+		TransitKey:         *key,
+		SupportsEncryption: transitKeyEncrypts(key.Type),
+		SupportsSigning:    transitKeySigns(key.Type),
+		Versions:           versions,
+	}, nil
+}
+
+// encryptionVersion opens the version new output is made with: the latest,
+// or the requested one if the key's minimum versions allow it.
+func (s *TransitService) encryptionVersion(key *model.TransitKey, requested int) (*transitKeyVersion, error) {
+	version := requested
+	if version == 0 {
+		version = key.LatestVersion
+	}
+	if version < 0 || version > key.LatestVersion ||
+		version < key.MinEncryptionVersion || version < key.MinDecryptionVersion {
+		return nil, fmt.Errorf("%w: version %d", ErrTransitKeyVersionNotAllowed, version)
+	}
+	return s.openKeyVersion(key, version)
+}
+
+// decryptionVersion parses a value made by the engine and opens the version
+// it names, which must not be below MinDecryptionVersion.
+func (s *TransitService) decryptionVersion(key *model.TransitKey, value string) (*transitKeyVersion, []byte, error) {
+	version, payload, err := parseTransitValue(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version > key.LatestVersion || version < key.MinDecryptionVersion {
+		return nil, nil, fmt.Errorf("%w: version %d", ErrTransitKeyVersionNotAllowed, version)
+	}
+
+	opened, err := s.openKeyVersion(key, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opened, payload, nil
+}
+
+func (s *TransitService) decrypt(key *model.TransitKey, value string, aad []byte) ([]byte, error) {
+	if !transitKeyEncrypts(key.Type) {
+		return nil, fmt.Errorf("%w: %s keys cannot decrypt", ErrTransitOperationUnsupported, key.Type)
+	}
+	version, ciphertext, err := s.decryptionVersion(key, value)
+	if err != nil {
+		return nil, err
+	}
+	return version.decrypt(ciphertext, aad)
+}
+
+// newKeyVersion generates and seals the material of a key version.
+func (s *TransitService) newKeyVersion(keyType string, number int) (*model.TransitKeyVersion, error) {
+	material := transitMaterial{HMACKey: make([]byte, 32)}
+	if _, err := io.ReadFull(rand.Reader, material.HMACKey); err != nil {
+		return nil, fmt.Errorf("failed to generate HMAC key: %w", err)
+	}
+
+	version := &model.TransitKeyVersion{Version: number}
+	switch keyType {
+	case model.TransitKeyAES256GCM96, model.TransitKeyChaCha20Poly1305:
+		material.Key = make([]byte, 32)
+		if _, err := io.ReadFull(rand.Reader, material.Key); err != nil {
+			return nil, fmt.Errorf("failed to generate transit key: %w", err)
+		}
+	default:
+		signer, err := generateTransitSigner(keyType)
+		if err != nil {
+			return nil, err
+		}
+		if material.Key, err = x509.MarshalPKCS8PrivateKey(signer); err != nil {
+			return nil, fmt.Errorf("failed to encode transit key: %w", err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(signer.Public())
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode transit public key: %w", err)
+		}
+		version.PublicKey = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	}
+
+	plaintext, err := json.Marshal(material)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, wrapped, keyringVersion, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	if version.Material, err = sealGCM(dataKey, plaintext); err != nil {
+		return nil, err
+	}
+	version.DataKey, version.KeyVersion = wrapped, keyringVersion
+	return version, nil
+}
+
+func (s *TransitService) openKeyVersion(key *model.TransitKey, number int) (*transitKeyVersion, error) {
+	var version model.TransitKeyVersion
+	if err := s.db.Where("key_id = ? AND version = ?", key.ID, number).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: version %d", ErrTransitKeyVersionNotAllowed, number)
+		}
+		return nil, fmt.Errorf("failed to get transit key version: %w", err)
+	}
+
+	dataKey, err := s.keyring.UnwrapDataKey(version.DataKey, version.KeyVersion)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := openGCM(dataKey, version.Material)
+	if err != nil {
+		return nil, err
+	}
+	var material transitMaterial
+	if err := json.Unmarshal(plaintext, &material); err != nil {
+		return nil, fmt.Errorf("failed to decode transit key: %w", err)
+	}
+
+	opened := &transitKeyVersion{
+		version: number,
+		keyType: key.Type,
+		key:     material.Key,
+		hmacKey: material.HMACKey,
+	}
+	if !transitSymmetric(key.Type) {
+		parsed, err := x509.ParsePKCS8PrivateKey(material.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transit key: %w", err)
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("transit key cannot sign")
+		}
+		opened.signer = signer
+	}
+	return opened, nil
+}
+
+func (v *transitKeyVersion) aead() (cipher.AEAD, error) {
+	if v.keyType == model.TransitKeyChaCha20Poly1305 {
+		return chacha20poly1305.New(v.key)
+	}
+	block, err := aes.NewCipher(v.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encrypt seals with the AEAD of a symmetric key, prefixing the nonce, or
+// with RSA-OAEP, where the associated data is the OAEP label.
+func (v *transitKeyVersion) encrypt(plaintext, aad []byte) ([]byte, error) {
+	if rsaKey, ok := v.signer.(*rsa.PrivateKey); ok {
+		ciphertext, err := rsa.EncryptOAEP(crypto.SHA256.New(), rand.Reader, &rsaKey.PublicKey, plaintext, aad)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidTransitRequest, err)
+		}
+		return ciphertext, nil
+	}
+	if !transitSymmetric(v.keyType) {
+		return nil, fmt.Errorf("%w: %s keys cannot encrypt", ErrTransitOperationUnsupported, v.keyType)
+	}
+
+	aead, err := v.aead()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+func (v *transitKeyVersion) decrypt(ciphertext, aad []byte) ([]byte, error) {
+	if rsaKey, ok := v.signer.(*rsa.PrivateKey); ok {
+		plaintext, err := rsa.DecryptOAEP(crypto.SHA256.New(), rand.Reader, rsaKey, ciphertext, aad)
+		if err != nil {
+			return nil, ErrTransitDecryptionFailed
+		}
+		return plaintext, nil
+	}
+
+	aead, err := v.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrTransitDecryptionFailed
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, aad)
+	if err != nil {
+		return nil, ErrTransitDecryptionFailed
+	}
+	return plaintext, nil
+}
+
+func (v *transitKeyVersion) sign(input []byte, hashAlgorithm, signatureAlgorithm string) ([]byte, error) {
+	if _, ok := v.signer.(ed25519.PrivateKey); ok {
+		return v.signer.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+
+	hash, err := transitHash(hashAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch key := v.signer.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, key, digest)
+	case *rsa.PrivateKey:
+		pss, err := transitRSAPSS(signatureAlgorithm)
+		if err != nil {
+			return nil, err
+		}
+		if pss {
+			return rsa.SignPSS(rand.Reader, key, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		}
+		return rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+	}
+	return nil, fmt.Errorf("%w: %s keys cannot sign", ErrTransitOperationUnsupported, v.keyType)
+}
+
+func (v *transitKeyVersion) verify(input, signature []byte, hashAlgorithm, signatureAlgorithm string) (bool, error) {
+	if key, ok := v.signer.Public().(ed25519.PublicKey); ok {
+		return ed25519.Verify(key, input, signature), nil
+	}
+
+	hash, err := transitHash(hashAlgorithm)
+	if err != nil {
+		return false, err
+	}
+	h := hash.New()
+	h.Write(input)
+	digest := h.Sum(nil)
+
+	switch key := v.signer.Public().(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest, signature), nil
+	case *rsa.PublicKey:
+		pss, err := transitRSAPSS(signatureAlgorithm)
+		if err != nil {
+			return false, err
+		}
+		if pss {
+			return rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto}) == nil, nil
+		}
+		return rsa.VerifyPKCS1v15(key, hash, digest, signature) == nil, nil
+	}
+	return false, fmt.Errorf("%w: %s keys cannot sign", ErrTransitOperationUnsupported, v.keyType)
+}
+
+func (v *transitKeyVersion) hmac(hash crypto.Hash, input []byte) []byte {
+	mac := hmac.New(hash.New, v.hmacKey)
+	mac.Write(input)
+	return mac.Sum(nil)
+}
+
+func generateTransitSigner(keyType string) (crypto.Signer, error) {
+	switch keyType {
+	case model.TransitKeyEd25519:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case model.TransitKeyECDSAP256:
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case model.TransitKeyECDSAP384:
+		return ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	case model.TransitKeyRSA2048:
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case model.TransitKeyRSA3072:
+		return rsa.GenerateKey(rand.Reader, 3072)
+	case model.TransitKeyRSA4096:
+		return rsa.GenerateKey(rand.Reader, 4096)
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidTransitKeyType, keyType)
+}
+
+func transitKeyTypeSupported(keyType string) bool {
+	switch keyType {
+	case model.TransitKeyAES256GCM96, model.TransitKeyChaCha20Poly1305,
+		model.TransitKeyEd25519, model.TransitKeyECDSAP256, model.TransitKeyECDSAP384,
+		model.TransitKeyRSA2048, model.TransitKeyRSA3072, model.TransitKeyRSA4096:
+		return true
+	}
+	return false
+}
+
+func transitSymmetric(keyType string) bool {
+	return keyType == model.TransitKeyAES256GCM96 || keyType == model.TransitKeyChaCha20Poly1305
+}
+
+func transitKeyEncrypts(keyType string) bool {
+	return transitSymmetric(keyType) || strings.HasPrefix(keyType, "rsa-")
+}
+
+func transitKeySigns(keyType string) bool {
+	return !transitSymmetric(keyType)
+}
+
+func transitHash(algorithm string) (crypto.Hash, error) {
+	switch algorithm {
+	case "", "sha2-256":
+		return crypto.SHA256, nil
+	case "sha2-384":
+		return crypto.SHA384, nil
+	case "sha2-512":
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("%w: unsupported hash algorithm %q", ErrInvalidTransitRequest, algorithm)
+}
+
+func transitRSAPSS(algorithm string) (bool, error) {
+	switch algorithm {
+	case "", "pss":
+		return true, nil
+	case "pkcs1v15":
+		return false, nil
+	}
+	return false, fmt.Errorf("%w: unsupported signature algorithm %q", ErrInvalidTransitRequest, algorithm)
+}
+
+func decodeTransitInput(field, value string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be base64", ErrInvalidTransitRequest, field)
+	}
+	return data, nil
+}
+
+func formatTransitValue(version int, payload []byte) string {
+	return transitPrefix + strconv.Itoa(version) + ":" + base64.StdEncoding.EncodeToString(payload)
+}
+
+func parseTransitValue(value string) (int, []byte, error) {
+	if !strings.HasPrefix(value, transitPrefix) {
+		return 0, nil, ErrInvalidTransitCiphertext
+	}
+	versionPart, payloadPart, ok := strings.Cut(strings.TrimPrefix(value, transitPrefix), ":")
+	if !ok {
+		return 0, nil, ErrInvalidTransitCiphertext
+	}
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version < 1 {
+		return 0, nil, ErrInvalidTransitCiphertext
+	}
+	payload, err := base64.StdEncoding.DecodeString(payloadPart)
+	if err != nil {
+		return 0, nil, ErrInvalidTransitCiphertext
+	}
+	return version, payload, nil
+}
+
+var (
+	ErrTransitKeyNotFound          = errors.New("transit key not found")
+	ErrTransitKeyExists            = errors.New("a transit key with this name already exists")
+	ErrTransitDeletionNotAllowed   = errors.New("deletion is not allowed for this key")
+	ErrTransitKeyVersionNotAllowed = errors.New("key version is not available for this operation")
+	ErrTransitOperationUnsupported = errors.New("operation not supported by the key type")
+	ErrTransitDecryptionFailed     = errors.New("ciphertext could not be decrypted")
+	ErrInvalidTransitKeyType       = errors.New("unsupported transit key type")
+	ErrInvalidTransitKeyConfig     = errors.New("invalid transit key configuration")
+	ErrInvalidTransitCiphertext    = errors.New("invalid ciphertext format")
+	ErrInvalidTransitRequest       = errors.New("invalid transit request")
+)
@@ -19,6 +19,10 @@ func NewUserService(db *gorm.DB) *UserService {
 }
 
 func (s *UserService) CreateUser(user *model.User) error {
+	if user.Role != "" && !model.IsValidRole(user.Role) {
+		return ErrInvalidRole
+	}
+
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
 		return fmt.Errorf("failed to hash password: %w", err)
@@ -74,11 +78,79 @@ func (s *UserService) DeleteUser(id uuid.UUID) error {
 	return nil
 }
 
+// CheckAdminRemoval returns ErrLastAdmin if demoting, disabling or deleting
+// the user would leave the vault without an active administrator.
+func (s *UserService) CheckAdminRemoval(user *model.User) error {
+	if user.Role != model.RoleAdmin || !user.IsActive {
+		return nil
+	}
+
+	var admins int64
+	if err := s.db.Model(&model.User{}).
+		Where("role = ? AND is_active = ? AND id <> ?", model.RoleAdmin, true, user.ID).
+		Count(&admins).Error; err != nil {
+		return fmt.Errorf("failed to count administrators: %w", err)
+	}
+	if admins == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+// EnsureAdmin bootstraps the first administrator. If no active admin exists,
+// the user with the given email is created when a password is provided. An
+// existing account with that email is only promoted when promoteExisting is
+// set, so a self-registered user cannot claim the address. It returns the
+// bootstrapped user, or nil if nothing was done.
+func (s *UserService) EnsureAdmin(email, password string, promoteExisting bool) (*model.User, error) {
+	var admins int64
+	if err := s.db.Model(&model.User{}).Where("role = ? AND is_active = ?", model.RoleAdmin, true).Count(&admins).Error; err != nil {
+		return nil, fmt.Errorf("failed to count administrators: %w", err)
+	}
+	if admins > 0 || email == "" {
+		return nil, nil
+	}
+
+	user, err := s.GetUserByEmail(email)
+	if err == nil {
+		if !promoteExisting {
+			return nil, ErrBootstrapUserExists
+		}
+		if err := s.db.Model(user).Update("role", model.RoleAdmin).Error; err != nil {
+			return nil, fmt.Errorf("failed to promote administrator: %w", err)
+		}
+		user.Role = model.RoleAdmin
+		return user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return nil, err
+	}
+	if password == "" {
+		return nil, nil
+	}
+
+	user = &model.User{
+		Email:     email,
+		Password:  password,
+		FirstName: "Vault",
+		LastName:  "Administrator",
+		IsActive:  true,
+		Role:      model.RoleAdmin,
+	}
+	if err := s.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
 func (s *UserService) GetDB() *gorm.DB {
 	return s.db
 }
 
 var (
-	ErrUserNotFound       = errors.New("user not found")
-	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRole         = errors.New("invalid role")
+	ErrLastAdmin           = errors.New("cannot remove the last active administrator")
+	ErrBootstrapUserExists = errors.New("bootstrap admin email belongs to an existing account; set VAULT_BOOTSTRAP_PROMOTE_EXISTING to promote it")
 )
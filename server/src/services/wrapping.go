@@ -0,0 +1,184 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/skygenesisenterprise/aether-vault/server/src/model"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// wrappingTokenPrefix marks wrapping tokens so they are not mistaken for
+// access or refresh tokens.
+const wrappingTokenPrefix = "w."
+
+// LeaseKindWrapping is the lease kind of wrapping tokens.
+const LeaseKindWrapping = "wrapping"
+
+// WrappingService stores wrapped responses behind single-use tokens. The
+// token itself is the only credential needed to unwrap, so it can be handed
+// to a job that holds no other access. Each token is leased; the lease
+// manager deletes tokens nobody unwrapped before their TTL.
+type WrappingService struct {
+	db      *gorm.DB
+	keyring *KeyringService
+	leases  *LeaseService
+	maxTTL  time.Duration
+}
+
+func NewWrappingService(db *gorm.DB, keyring *KeyringService, leases *LeaseService, maxTTL time.Duration) *WrappingService {
+	service := &WrappingService{
+		db:      db,
+		keyring: keyring,
+		leases:  leases,
+		maxTTL:  maxTTL,
+	}
+	leases.RegisterRevoker(LeaseKindWrapping, service)
+	return service
+}
+
+// Wrap seals payload in a new cubbyhole and returns the token for it.
+func (s *WrappingService) Wrap(payload []byte, creationPath string, ttl time.Duration, createdBy *uuid.UUID) (*model.WrapInfo, error) {
+	if ttl < time.Second || ttl > s.maxTTL {
+		return nil, fmt.Errorf("%w: must be between 1s and %s", ErrInvalidWrapTTL, s.maxTTL)
+	}
+	if !json.Valid(payload) {
+		return nil, ErrInvalidWrapPayload
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate wrapping token: %w", err)
+	}
+	token := wrappingTokenPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	dataKey, wrapped, version, err := s.keyring.GenerateDataKey()
+	if err != nil {
+		return nil, err
+	}
+	cubbyhole, err := sealGCM(dataKey, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seal wrapped response: %w", err)
+	}
+
+	id := uuid.New()
+	lease, err := s.leases.Issue(LeaseKindWrapping, "sys/wrapping/wrap", id.String(), createdBy, ttl, ttl, false, "")
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.WrappingToken{
+		ID:           id,
+		LeaseID:      lease.ID,
+		TokenHash:    hashToken(token),
+		CreationPath: creationPath,
+		CreatedBy:    createdBy,
+		Cubbyhole:    cubbyhole,
+		DataKey:      wrapped,
+		KeyVersion:   version,
+		TTL:          lease.TTL,
+		ExpiresAt:    lease.ExpireTime,
+		CreatedAt:    lease.IssueTime,
+	}
+	if err := s.db.Create(entry).Error; err != nil {
+		s.leases.Release(lease.ID)
+		return nil, fmt.Errorf("failed to store wrapping token: %w", err)
+	}
+
+	return &model.WrapInfo{
+		Token:        token,
+		Accessor:     entry.ID,
+		TTL:          entry.TTL,
+		CreationTime: entry.CreatedAt,
+		CreationPath: entry.CreationPath,
+	}, nil
+}
+
+// Unwrap returns the wrapped response and destroys the token. The entry is
+// deleted before its cubbyhole is opened, so of two concurrent calls with the
+// same token only one can succeed; an expired token is consumed as well.
+func (s *WrappingService) Unwrap(token string) ([]byte, *model.WrappingToken, error) {
+	entry, err := s.find(token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataKey, err := s.keyring.UnwrapDataKey(entry.DataKey, entry.KeyVersion)
+	if err != nil {
+		return nil, entry, err
+	}
+
+	result := s.db.Where("id = ?", entry.ID).Delete(&model.WrappingToken{})
+	if result.Error != nil {
+		return nil, entry, fmt.Errorf("failed to consume wrapping token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, entry, ErrWrappingTokenNotFound
+	}
+	if err := s.leases.Release(entry.LeaseID); err != nil {
+		log.Printf("⚠️  Failed to release lease of wrapping token %s: %v", entry.ID, err)
+	}
+	if time.Now().After(entry.ExpiresAt) {
+		return nil, entry, ErrWrappingTokenExpired
+	}
+
+	payload, err := openGCM(dataKey, entry.Cubbyhole)
+	if err != nil {
+		return nil, entry, fmt.Errorf("failed to open wrapped response: %w", err)
+	}
+
+	return payload, entry, nil
+}
+
+// Lookup describes a token without consuming it.
+func (s *WrappingService) Lookup(token string) (*model.WrappingLookupResponse, error) {
+	entry, err := s.find(token)
+	if err != nil {
+		return nil, err
+	}
+	remaining := time.Until(entry.ExpiresAt)
+	if remaining <= 0 {
+		return nil, ErrWrappingTokenExpired
+	}
+
+	return &model.WrappingLookupResponse{
+		Accessor:     entry.ID,
+		CreationPath: entry.CreationPath,
+		CreationTime: entry.CreatedAt,
+		CreationTTL:  entry.TTL,
+		TTL:          int(remaining.Round(time.Second) / time.Second),
+		ExpiresAt:    entry.ExpiresAt,
+	}, nil
+}
+
+// RevokeLease deletes a wrapping token together with its cubbyhole.
+func (s *WrappingService) RevokeLease(lease *model.Lease) error {
+	if err := s.db.Where("id = ?", lease.ResourceID).Delete(&model.WrappingToken{}).Error; err != nil {
+		return fmt.Errorf("failed to delete wrapping token: %w", err)
+	}
+	return nil
+}
+
+func (s *WrappingService) find(token string) (*model.WrappingToken, error) {
+	var entry model.WrappingToken
+	if err := s.db.Where("token_hash = ?", hashToken(token)).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWrappingTokenNotFound
+		}
+		return nil, fmt.Errorf("failed to get wrapping token: %w", err)
+	}
+	return &entry, nil
+}
+
+var (
+	ErrWrappingTokenNotFound = errors.New("wrapping token not found or already used")
+	ErrWrappingTokenExpired  = errors.New("wrapping token has expired")
+	ErrInvalidWrapTTL        = errors.New("invalid wrap TTL")
+	ErrInvalidWrapPayload    = errors.New("wrapped response must be JSON")
+)
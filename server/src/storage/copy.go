@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// copyBatchSize bounds the rows held in memory while copying a table.
+const copyBatchSize = 500
+
+// joinTables are many2many tables without a model of their own.
+var joinTables = []string{"user_groups"}
+
+// CopyStats reports the rows copied per table.
+type CopyStats struct {
+	Table string
+	Rows  int64
+}
+
+// Copy migrates the schema on dst and copies every row from src, including
+// soft-deleted ones. Tables on dst must be empty so a partial earlier run is
+// never silently merged.
+func Copy(src, dst *gorm.DB) ([]CopyStats, error) {
+	if err := Migrate(dst); err != nil {
+		return nil, fmt.Errorf("failed to migrate target database: %w", err)
+	}
+
+	schemas := make([]*schema.Schema, 0, len(Models()))
+	tables := make([]string, 0, len(Models())+len(joinTables))
+	for _, m := range Models() {
+		stmt := &gorm.Statement{DB: dst}
+		if err := stmt.Parse(m); err != nil {
+			return nil, fmt.Errorf("failed to parse model: %w", err)
+		}
+		schemas = append(schemas, stmt.Schema)
+		tables = append(tables, stmt.Schema.Table)
+	}
+	tables = append(tables, joinTables...)
+
+	for _, table := range tables {
+		var count int64
+		if err := dst.Table(table).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("failed to inspect %s: %w", table, err)
+		}
+		if count > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrTargetNotEmpty, table)
+		}
+	}
+
+	var stats []CopyStats
+	err := dst.Transaction(func(tx *gorm.DB) error {
+		for i, m := range Models() {
+			rows, err := copyModel(src, tx, m, schemas[i])
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", tables[i], err)
+			}
+			stats = append(stats, CopyStats{Table: tables[i], Rows: rows})
+		}
+
+		for _, table := range joinTables {
+			rows, err := copyTable(src, tx, table)
+			if err != nil {
+				return fmt.Errorf("failed to copy %s: %w", table, err)
+			}
+			stats = append(stats, CopyStats{Table: table, Rows: rows})
+		}
+
+		return resetSequences(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func copyModel(src, dst *gorm.DB, m interface{}, sch *schema.Schema) (int64, error) {
+	batch := reflect.New(reflect.SliceOf(reflect.TypeOf(m).Elem()))
+
+	var copied int64
+	result := src.Model(m).Unscoped().FindInBatches(batch.Interface(), copyBatchSize, func(tx *gorm.DB, _ int) error {
+		items := batch.Elem()
+		if items.Len() == 0 {
+			return nil
+		}
+
+		// Rows are written as column maps: creating the structs would run
+		// hooks and replace false or zero values with the column defaults.
+		rows := make([]map[string]interface{}, 0, items.Len())
+		for i := 0; i < items.Len(); i++ {
+			row := make(map[string]interface{}, len(sch.DBNames))
+			for _, name := range sch.DBNames {
+				row[name], _ = sch.FieldsByDBName[name].ValueOf(tx.Statement.Context, items.Index(i))
+			}
+			rows = append(rows, row)
+		}
+
+		if err := dst.Table(sch.Table).Create(rows).Error; err != nil {
+			return err
+		}
+		copied += int64(len(rows))
+		return nil
+	})
+	if result.Error != nil {
+		return copied, result.Error
+	}
+	return copied, nil
+}
+
+func copyTable(src, dst *gorm.DB, table string) (int64, error) {
+	var rows []map[string]interface{}
+	if err := src.Table(table).Find(&rows).Error; err != nil {
+		return 0, err
+	}
+	if len(rows) == 0 {
+		return 0, nil
+	}
+	if err := dst.Table(table).CreateInBatches(rows, copyBatchSize).Error; err != nil {
+		return 0, err
+	}
+	return int64(len(rows)), nil
+}
+
+// resetSequences moves Postgres serial sequences past the copied IDs, which
+// were inserted explicitly and so never advanced them.
+func resetSequences(db *gorm.DB) error {
+	if db.Dialector.Name() != DriverPostgres {
+		return nil
+	}
+
+	for _, m := range Models() {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return err
+		}
+		field := stmt.Schema.PrioritizedPrimaryField
+		if field == nil || !field.AutoIncrement {
+			continue
+		}
+
+		table, column := stmt.Schema.Table, field.DBName
+		if err := db.Exec(fmt.Sprintf(
+			"SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE((SELECT MAX(%s) FROM %s), 0) + 1, false)",
+			table, column, column, table,
+		)).Error; err != nil {
+			return fmt.Errorf("failed to reset sequence of %s: %w", table, err)
+		}
+	}
+	return nil
+}
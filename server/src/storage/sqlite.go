@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/migrator"
+	"gorm.io/gorm/schema"
+)
+
+// sqlitePragmas enforce foreign keys like Postgres does, let readers run
+// alongside the writer and take the write lock up front so concurrent
+// transactions wait instead of failing with SQLITE_BUSY.
+const sqlitePragmas = "_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+// sqliteDialector stores the uuid columns declared by the models as text;
+// Postgres keeps its native uuid type.
+type sqliteDialector struct {
+	sqlite.Dialector
+}
+
+func (d sqliteDialector) DataTypeOf(field *schema.Field) string {
+	if field.DataType == "uuid" {
+		return "text"
+	}
+	return d.Dialector.DataTypeOf(field)
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqlite.Migrator{Migrator: migrator.Migrator{Config: migrator.Config{
+		DB:                          db,
+		Dialector:                   d,
+		CreateIndexAfterCreateTable: true,
+	}}}
+}
+
+func openSQLite(path string) (*gorm.DB, error) {
+	if path == "" {
+		return nil, fmt.Errorf("sqlite database path is required")
+	}
+
+	memory := path == ":memory:"
+	if !memory {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create database directory: %w", err)
+		}
+	}
+
+	dialector := sqliteDialector{Dialector: sqlite.Dialector{DSN: path + "?" + sqlitePragmas}}
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	}
+
+	// Every connection to :memory: opens its own empty database.
+	if memory {
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,349 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// OTPAuthKey is the content of an otpauth:// URI, the format authenticator
+// apps read from QR codes.
+type OTPAuthKey struct {
+	Issuer    string
+	Account   string
+	Secret    string
+	Algorithm string
+	Digits    int
+	Period    int
+}
+
+// URI formats the key as an otpauth://totp/ URI.
+func (k *OTPAuthKey) URI() string {
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+
+	params := url.Values{}
+	params.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		params.Set("issuer", k.Issuer)
+	}
+	params.Set("algorithm", k.Algorithm)
+	params.Set("digits", strconv.Itoa(k.Digits))
+	params.Set("period", strconv.Itoa(k.Period))
+
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + params.Encode()
+}
+
+// ParseOTPAuthURI parses an otpauth://totp/ URI. Missing parameters take the
+// defaults of the Key URI Format: SHA1, 6 digits and 30 seconds.
+func ParseOTPAuthURI(raw string) (*OTPAuthKey, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid otpauth URI: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, "otpauth") {
+		return nil, fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return nil, fmt.Errorf("unsupported OTP type: %s", u.Host)
+	}
+
+	key := &OTPAuthKey{Account: strings.TrimPrefix(u.Path, "/")}
+	if issuer, account, found := strings.Cut(key.Account, ":"); found {
+		key.Issuer, key.Account = issuer, strings.TrimSpace(account)
+	}
+
+	query := u.Query()
+	if issuer := query.Get("issuer"); issuer != "" {
+		key.Issuer = issuer
+	}
+	if key.Secret, err = normalizeSecret(query.Get("secret")); err != nil {
+		return nil, err
+	}
+
+	key.Algorithm = strings.ToUpper(query.Get("algorithm"))
+	switch key.Algorithm {
+	case "":
+		key.Algorithm = "SHA1"
+	case "SHA1", "SHA256", "SHA512":
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", key.Algorithm)
+	}
+
+	if key.Digits, err = intParam(query, "digits", 6); err != nil {
+		return nil, err
+	}
+	if key.Period, err = intParam(query, "period", 30); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Enum values of the Google Authenticator migration payload.
+const (
+	migrationAlgorithmSHA1   = 1
+	migrationAlgorithmSHA256 = 2
+	migrationAlgorithmSHA512 = 3
+	migrationDigitsSix       = 1
+	migrationDigitsEight     = 2
+	migrationTypeHOTP        = 1
+	migrationTypeTOTP        = 2
+)
+
+// ParseOTPAuthMigration decodes an otpauth-migration://offline URI, the
+// batch export of Google Authenticator. Its entries all use 30 second
+// periods; HOTP entries are refused.
+func ParseOTPAuthMigration(raw string) ([]OTPAuthKey, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid migration URI: %w", err)
+	}
+	if !strings.EqualFold(u.Scheme, "otpauth-migration") {
+		return nil, fmt.Errorf("unsupported URI scheme: %s", u.Scheme)
+	}
+
+	// An unescaped "+" in the data reads back as a space
+	data := strings.ReplaceAll(u.Query().Get("data"), " ", "+")
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if payload, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+			return nil, fmt.Errorf("invalid migration data: %w", err)
+		}
+	}
+
+	var keys []OTPAuthKey
+	err = walkProto(payload, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		key, err := parseMigrationEntry(value)
+		if err != nil {
+			return fmt.Errorf("entry %d: %w", len(keys)+1, err)
+		}
+		keys = append(keys, *key)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("migration data holds no entries")
+	}
+
+	return keys, nil
+}
+
+// FormatOTPAuthMigration encodes keys as otpauth-migration:// URIs of at
+// most batchSize entries each, the way Google Authenticator splits an
+// export over several QR codes. The format has no period, so keys must use
+// 30 seconds.
+func FormatOTPAuthMigration(keys []OTPAuthKey, batchSize int) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(keys)
+	}
+
+	var id [4]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	batchID := uint64(binary.BigEndian.Uint32(id[:]) & 0x7fffffff)
+	batches := (len(keys) + batchSize - 1) / batchSize
+
+	var uris []string
+	for index := 0; index < batches; index++ {
+		var payload []byte
+		for _, key := range keys[index*batchSize : min((index+1)*batchSize, len(keys))] {
+			entry, err := formatMigrationEntry(&key)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key.Account, err)
+			}
+			payload = protowire.AppendTag(payload, 1, protowire.BytesType)
+			payload = protowire.AppendBytes(payload, entry)
+		}
+		for _, field := range []struct {
+			num   protowire.Number
+			value uint64
+		}{{2, 1}, {3, uint64(batches)}, {4, uint64(index)}, {5, batchID}} {
+			payload = protowire.AppendTag(payload, field.num, protowire.VarintType)
+			payload = protowire.AppendVarint(payload, field.value)
+		}
+
+		uris = append(uris, "otpauth-migration://offline?data="+url.QueryEscape(base64.StdEncoding.EncodeToString(payload)))
+	}
+
+	return uris, nil
+}
+
+func parseMigrationEntry(entry []byte) (*OTPAuthKey, error) {
+	key := &OTPAuthKey{Algorithm: "SHA1", Digits: 6, Period: 30}
+	var secret []byte
+	err := walkProto(entry, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			secret = value
+		case num == 2 && typ == protowire.BytesType:
+			key.Account = string(value)
+		case num == 3 && typ == protowire.BytesType:
+			key.Issuer = string(value)
+		case num == 4 && typ == protowire.VarintType:
+			switch varint {
+			case 0, migrationAlgorithmSHA1:
+			case migrationAlgorithmSHA256:
+				key.Algorithm = "SHA256"
+			case migrationAlgorithmSHA512:
+				key.Algorithm = "SHA512"
+			default:
+				return fmt.Errorf("unsupported algorithm: %d", varint)
+			}
+		case num == 5 && typ == protowire.VarintType:
+			if varint == migrationDigitsEight {
+				key.Digits = 8
+			}
+		case num == 6 && typ == protowire.VarintType:
+			if varint == migrationTypeHOTP {
+				return fmt.Errorf("HOTP entries are not supported")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("missing secret")
+	}
+
+	key.Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret)
+	if issuer, account, found := strings.Cut(key.Account, ":"); found && (key.Issuer == "" || key.Issuer == issuer) {
+		key.Issuer, key.Account = issuer, strings.TrimSpace(account)
+	}
+
+	return key, nil
+}
+
+// CheckMigration reports why the key cannot be expressed in an
+// otpauth-migration:// payload, which only knows 30 second periods and 6 or
+// 8 digits.
+func (k *OTPAuthKey) CheckMigration() error {
+	if k.Period != 30 {
+		return fmt.Errorf("period %d cannot be exported for migration", k.Period)
+	}
+	if k.Digits != 6 && k.Digits != 8 {
+		return fmt.Errorf("%d digits cannot be exported for migration", k.Digits)
+	}
+	return nil
+}
+
+func formatMigrationEntry(key *OTPAuthKey) ([]byte, error) {
+	if err := key.CheckMigration(); err != nil {
+		return nil, err
+	}
+
+	var algorithm uint64
+	switch strings.ToUpper(key.Algorithm) {
+	case "", "SHA1":
+		algorithm = migrationAlgorithmSHA1
+	case "SHA256":
+		algorithm = migrationAlgorithmSHA256
+	case "SHA512":
+		algorithm = migrationAlgorithmSHA512
+	default:
+		return nil, fmt.Errorf("unsupported algorithm: %s", key.Algorithm)
+	}
+	digits := uint64(migrationDigitsSix)
+	if key.Digits == 8 {
+		digits = migrationDigitsEight
+	}
+
+	secret, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(key.Secret)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base32 secret: %w", err)
+	}
+
+	var entry []byte
+	entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+	entry = protowire.AppendBytes(entry, secret)
+	entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+	entry = protowire.AppendString(entry, key.Account)
+	if key.Issuer != "" {
+		entry = protowire.AppendTag(entry, 3, protowire.BytesType)
+		entry = protowire.AppendString(entry, key.Issuer)
+	}
+	for _, field := range []struct {
+		num   protowire.Number
+		value uint64
+	}{{4, algorithm}, {5, digits}, {6, migrationTypeTOTP}} {
+		entry = protowire.AppendTag(entry, field.num, protowire.VarintType)
+		entry = protowire.AppendVarint(entry, field.value)
+	}
+
+	return entry, nil
+}
+
+// walkProto calls fn for every field of a protobuf message, with the
+// payload of length-delimited fields or the value of varints.
+func walkProto(message []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(message) > 0 {
+		num, typ, n := protowire.ConsumeTag(message)
+		if n < 0 {
+			return fmt.Errorf("invalid migration data: %w", protowire.ParseError(n))
+		}
+		message = message[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(message)
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(message)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, message)
+		}
+		if n < 0 {
+			return fmt.Errorf("invalid migration data: %w", protowire.ParseError(n))
+		}
+		message = message[n:]
+
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func normalizeSecret(secret string) (string, error) {
+	secret = strings.ToUpper(strings.TrimRight(strings.ReplaceAll(secret, " ", ""), "="))
+	if secret == "" {
+		return "", fmt.Errorf("missing secret")
+	}
+	if _, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret); err != nil {
+		return "", fmt.Errorf("invalid base32 secret: %w", err)
+	}
+	return secret, nil
+}
+
+func intParam(query url.Values, name string, fallback int) (int, error) {
+	value := query.Get(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
+}
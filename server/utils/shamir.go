@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// SplitSecret splits secret into parts Shamir shares, any threshold of which
+// reconstruct it. Arithmetic is over GF(2^8); each share is the polynomial
+// values for every secret byte followed by a one-byte x coordinate.
+func SplitSecret(secret []byte, parts, threshold int) ([][]byte, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("cannot split an empty secret")
+	}
+	if threshold < 1 || parts < threshold || parts > 255 {
+		return nil, fmt.Errorf("invalid share configuration: %d shares with threshold %d", parts, threshold)
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	for b, value := range secret {
+		coefficients[0] = value
+		if _, err := rand.Read(coefficients[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate polynomial: %w", err)
+		}
+
+		for i := range shares {
+			shares[i][b] = evaluatePolynomial(coefficients, byte(i+1))
+		}
+	}
+
+	return shares, nil
+}
+
+// CombineShares reconstructs a secret from at least threshold shares produced
+// by SplitSecret. Too few shares yield a wrong value rather than an error, so
+// callers must verify the result.
+func CombineShares(shares [][]byte) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares provided")
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errors.New("share is too short")
+	}
+
+	xs := make([]byte, len(shares))
+	seen := make(map[byte]bool)
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errors.New("shares have different lengths")
+		}
+		x := share[size-1]
+		if x == 0 || seen[x] {
+			return nil, errors.New("invalid or duplicate share")
+		}
+		seen[x] = true
+		xs[i] = x
+	}
+
+	secret := make([]byte, size-1)
+	for b := range secret {
+		var value byte
+		for i, share := range shares {
+			basis := byte(1)
+			for j := range shares {
+				if i != j {
+					basis = gfMul(basis, gfDiv(xs[j], xs[j]^xs[i]))
+				}
+			}
+			value ^= gfMul(share[b], basis)
+		}
+		secret[b] = value
+	}
+
+	return secret, nil
+}
+
+func evaluatePolynomial(coefficients []byte, x byte) byte {
+	var result byte
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		result = gfMul(result, x) ^ coefficients[i]
+	}
+	return result
+}
+
+// gfMul multiplies in GF(2^8) with the AES polynomial x^8+x^4+x^3+x+1,
+// without data-dependent branches.
+func gfMul(a, b byte) byte {
+	var product byte
+	for i := 0; i < 8; i++ {
+		product ^= a & -(b & 1)
+		carry := -(a >> 7)
+		a = (a << 1) ^ (0x1b & carry)
+		b >>= 1
+	}
+	return product
+}
+
+func gfDiv(a, b byte) byte {
+	// b^254 is the multiplicative inverse of b
+	inverse := b
+	for i := 0; i < 6; i++ {
+		inverse = gfMul(gfMul(inverse, inverse), b)
+	}
+	return gfMul(a, gfMul(inverse, inverse))
+}